@@ -0,0 +1,1262 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/user"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/fees"
+	"github.com/shevgn/simplebank/interest"
+	"github.com/shevgn/simplebank/ledger"
+	"github.com/shevgn/simplebank/util"
+	"github.com/spf13/cobra"
+)
+
+// adminTimeout bounds every admin command, so a stuck lock cannot hang an
+// operator's terminal.
+const adminTimeout = 30 * time.Second
+
+// newAdminCommands returns the support staff commands. They go through
+// db.Store, like the API, so the same rules apply to both.
+func newAdminCommands(config func() *util.Config) []*cobra.Command {
+	return []*cobra.Command{
+		newUsersCommand(config),
+		newAccountsCommand(config),
+		newTransfersCommand(config),
+		newSessionsCommand(config),
+		newJobsCommand(config),
+		newReconcileCommand(config),
+		newInterestCommand(config),
+		newFeesCommand(config),
+		newLedgerCommand(config),
+	}
+}
+
+func newUsersCommand(config func() *util.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "users",
+		Short: "Manage users",
+	}
+
+	var req struct {
+		Username string `validate:"required,alphanum,min=1,max=100"`
+		Password string `validate:"required,min=8"`
+		FullName string `validate:"required"`
+		Email    string `validate:"required,email"`
+	}
+
+	create := &cobra.Command{
+		Use:   "create",
+		Short: "Create a user; the password is read from stdin unless --password is given",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if req.Password == "" {
+				password, err := readLine(cmd.InOrStdin())
+				if err != nil {
+					return fmt.Errorf("cannot read password: %w", err)
+				}
+				req.Password = password
+			}
+
+			if err := validator.New().Struct(req); err != nil {
+				return err
+			}
+
+			hashedPassword, err := util.HashPassword(req.Password)
+			if err != nil {
+				return err
+			}
+
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				user, err := store.CreateUser(ctx, db.CreateUserParams{
+					Username:       req.Username,
+					HashedPassword: hashedPassword,
+					FullName:       req.FullName,
+					Email:          req.Email,
+				})
+				if err != nil {
+					return err
+				}
+
+				audit("user created", slog.String("username", user.Username))
+
+				return printUsers(cmd.OutOrStdout(), user)
+			})
+		},
+	}
+	create.Flags().StringVar(&req.Username, "username", "", "username")
+	create.Flags().StringVar(&req.FullName, "full-name", "", "full name")
+	create.Flags().StringVar(&req.Email, "email", "", "email address")
+	create.Flags().StringVar(&req.Password, "password", "", "password (prefer stdin, flags end up in shell history)")
+
+	disable := &cobra.Command{
+		Use:   "disable USERNAME",
+		Short: "Disable a user and block all of their sessions",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				result, err := store.DisableUserTx(ctx, args[0])
+				if err != nil {
+					return err
+				}
+
+				audit("user disabled",
+					slog.String("username", result.User.Username),
+					slog.Int64("blocked_sessions", result.BlockedSessions),
+				)
+
+				return printUsers(cmd.OutOrStdout(), result.User)
+			})
+		},
+	}
+
+	enable := &cobra.Command{
+		Use:   "enable USERNAME",
+		Short: "Re-enable a disabled user; blocked sessions stay blocked",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				user, err := store.UpdateUserDisabled(ctx, db.UpdateUserDisabledParams{
+					Username:   args[0],
+					IsDisabled: false,
+				})
+				if err != nil {
+					return err
+				}
+
+				audit("user enabled", slog.String("username", user.Username))
+
+				return printUsers(cmd.OutOrStdout(), user)
+			})
+		},
+	}
+
+	show := &cobra.Command{
+		Use:   "show USERNAME",
+		Short: "Show a user, including failed logins and lockout",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				user, err := store.GetUser(ctx, args[0])
+				if err != nil {
+					return err
+				}
+
+				return printUsers(cmd.OutOrStdout(), user)
+			})
+		},
+	}
+
+	unlock := &cobra.Command{
+		Use:   "unlock USERNAME",
+		Short: "Clear failed logins and lift a lockout",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				user, err := store.ResetFailedLogins(ctx, args[0])
+				if err != nil {
+					return err
+				}
+
+				audit("user unlocked", slog.String("username", user.Username))
+
+				return printUsers(cmd.OutOrStdout(), user)
+			})
+		},
+	}
+
+	cmd.AddCommand(create, show, disable, enable, unlock)
+
+	return cmd
+}
+
+func newAccountsCommand(config func() *util.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "accounts",
+		Short: "Inspect and freeze accounts",
+	}
+
+	var (
+		owner  string
+		page   pageFlags
+		reason string
+		system bool
+	)
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List accounts, optionally of a single owner",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				var (
+					accounts []db.Account
+					err      error
+				)
+
+				if owner != "" {
+					accounts, err = store.ListAccounts(ctx, db.ListAccountsParams{
+						Owner:  owner,
+						Limit:  page.limit,
+						Offset: page.offset,
+					})
+				} else {
+					accounts, err = store.ListAllAccounts(ctx, db.ListAllAccountsParams{
+						Limit:  page.limit,
+						Offset: page.offset,
+					})
+				}
+				if err != nil {
+					return err
+				}
+
+				return printAccounts(cmd.OutOrStdout(), accounts...)
+			})
+		},
+	}
+	list.Flags().StringVar(&owner, "owner", "", "only list accounts of this user")
+	page.register(list)
+
+	setStatus := func(from, status string) func(cmd *cobra.Command, args []string) error {
+		return func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				// A frozen system account stops the bank's own bookings, such
+				// as fees and interest, so it takes an explicit override.
+				if !system {
+					isSystem, err := store.IsSystemAccount(ctx, id)
+					if err != nil {
+						return err
+					}
+
+					if isSystem {
+						return fmt.Errorf("account %d is a system account; pass --system to change its status", id)
+					}
+				}
+
+				account, err := store.UpdateAccountStatusTx(ctx, db.UpdateAccountStatusTxParams{
+					ID:     id,
+					From:   from,
+					Status: status,
+				})
+				if err != nil {
+					return err
+				}
+
+				audit("account status changed",
+					slog.Int64("account_id", account.ID),
+					slog.String("status", account.Status),
+					slog.String("reason", reason),
+					slog.Bool("system", system),
+				)
+
+				return printAccounts(cmd.OutOrStdout(), account)
+			})
+		}
+	}
+
+	freeze := &cobra.Command{
+		Use:   "freeze ID",
+		Short: "Freeze an account so it can neither send nor receive transfers",
+		Args:  cobra.ExactArgs(1),
+		RunE:  setStatus(db.AccountStatusActive, db.AccountStatusFrozen),
+	}
+	freeze.Flags().StringVar(&reason, "reason", "", "reason recorded in the audit log")
+	freeze.Flags().BoolVar(&system, "system", false, "allow freezing one of the bank's system accounts")
+
+	unfreeze := &cobra.Command{
+		Use:   "unfreeze ID",
+		Short: "Make a frozen account active again",
+		Args:  cobra.ExactArgs(1),
+		RunE:  setStatus(db.AccountStatusFrozen, db.AccountStatusActive),
+	}
+	unfreeze.Flags().StringVar(&reason, "reason", "", "reason recorded in the audit log")
+	unfreeze.Flags().BoolVar(&system, "system", false, "allow unfreezing one of the bank's system accounts")
+
+	cmd.AddCommand(list, freeze, unfreeze)
+
+	return cmd
+}
+
+func newTransfersCommand(config func() *util.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "transfers",
+		Short: "Inspect transfers",
+	}
+
+	var (
+		accountID int64
+		page      pageFlags
+	)
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List the transfers of an account",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if accountID < 1 {
+				return errors.New("--account is required")
+			}
+
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				transfers, err := store.ListTransfers(ctx, db.ListTransfersParams{
+					FromAccountID: accountID,
+					ToAccountID:   accountID,
+					Limit:         page.limit,
+					Offset:        page.offset,
+				})
+				if err != nil {
+					return err
+				}
+
+				return printTransfers(cmd.OutOrStdout(), transfers...)
+			})
+		},
+	}
+	list.Flags().Int64Var(&accountID, "account", 0, "account ID")
+	page.register(list)
+
+	show := &cobra.Command{
+		Use:   "show ID",
+		Short: "Show a transfer and the accounts involved",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				transfer, err := store.GetTransfer(ctx, id)
+				if err != nil {
+					return err
+				}
+
+				from, err := store.GetAccount(ctx, transfer.FromAccountID)
+				if err != nil {
+					return err
+				}
+
+				to, err := store.GetAccount(ctx, transfer.ToAccountID)
+				if err != nil {
+					return err
+				}
+
+				if err := printTransfers(cmd.OutOrStdout(), transfer); err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout())
+
+				return printAccounts(cmd.OutOrStdout(), from, to)
+			})
+		},
+	}
+
+	cmd.AddCommand(list, show)
+
+	return cmd
+}
+
+func newSessionsCommand(config func() *util.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "Inspect and block sessions",
+	}
+
+	list := &cobra.Command{
+		Use:   "list USERNAME",
+		Short: "List the sessions of a user, newest first",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				sessions, err := store.ListUserSessions(ctx, args[0])
+				if err != nil {
+					return err
+				}
+
+				return printSessions(cmd.OutOrStdout(), sessions...)
+			})
+		},
+	}
+
+	var all bool
+
+	block := &cobra.Command{
+		Use:   "block SESSION_ID | --all USERNAME",
+		Short: "Block a session, or every session of a user, so its refresh token stops working",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all {
+				return withStore(config(), func(ctx context.Context, store db.Store) error {
+					blocked, err := store.BlockUserSessions(ctx, args[0])
+					if err != nil {
+						return err
+					}
+
+					audit("sessions blocked", slog.String("username", args[0]), slog.Int64("count", blocked))
+					_, err = fmt.Fprintf(cmd.OutOrStdout(), "blocked %d sessions\n", blocked)
+
+					return err
+				})
+			}
+
+			id, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid session ID %q: %w", args[0], err)
+			}
+
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				session, err := store.BlockSession(ctx, id)
+				if err != nil {
+					return err
+				}
+
+				audit("session blocked", slog.String("session_id", session.ID.String()))
+
+				return printSessions(cmd.OutOrStdout(), session)
+			})
+		},
+	}
+	block.Flags().BoolVar(&all, "all", false, "block every session of the given user")
+
+	cmd.AddCommand(list, block)
+
+	return cmd
+}
+
+func newJobsCommand(config func() *util.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "Inspect and retry background jobs",
+	}
+
+	var (
+		status string
+		page   pageFlags
+	)
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List background jobs with a given status, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			switch status {
+			case db.JobStatusPending, db.JobStatusRunning, db.JobStatusDone, db.JobStatusDead:
+			default:
+				return fmt.Errorf("invalid status %q", status)
+			}
+
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				jobs, err := store.ListJobs(ctx, db.ListJobsParams{
+					Status: status,
+					Limit:  page.limit,
+					Offset: page.offset,
+				})
+				if err != nil {
+					return err
+				}
+
+				return printJobs(cmd.OutOrStdout(), jobs...)
+			})
+		},
+	}
+	list.Flags().StringVar(&status, "status", db.JobStatusDead, "one of pending, running, done or dead")
+	page.register(list)
+
+	retry := &cobra.Command{
+		Use:   "retry ID",
+		Short: "Queue a dead job again with a fresh set of attempts",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				job, err := store.ReviveJob(ctx, id)
+				if errors.Is(err, db.ErrRecordNotFound) {
+					return fmt.Errorf("job %d does not exist or is not dead", id)
+				}
+				if err != nil {
+					return err
+				}
+
+				audit("job retried", slog.Int64("job_id", job.ID), slog.String("task", job.Type))
+
+				return printJobs(cmd.OutOrStdout(), job)
+			})
+		},
+	}
+
+	cmd.AddCommand(list, retry)
+
+	return cmd
+}
+
+func newReconcileCommand(config func() *util.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "reconcile",
+		Short: "Check that every account balance equals the sum of its entries",
+		Long: "Check that every account balance equals the sum of its entries.\n" +
+			"Mismatched accounts are listed and the command exits with an error.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				rows, err := store.ListUnreconciledAccounts(ctx)
+				if err != nil {
+					return err
+				}
+
+				if len(rows) == 0 {
+					_, err := fmt.Fprintln(cmd.OutOrStdout(), "all accounts reconciled")
+					return err
+				}
+
+				w := newTabWriter(cmd.OutOrStdout())
+				fmt.Fprintln(w, "ACCOUNT\tOWNER\tCURRENCY\tBALANCE\tENTRIES\tDIFFERENCE")
+				for _, row := range rows {
+					fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%d\n",
+						row.ID, row.Owner, row.Currency, row.Balance, row.EntriesTotal, row.Balance-row.EntriesTotal)
+				}
+				if err := w.Flush(); err != nil {
+					return err
+				}
+
+				return fmt.Errorf("%d accounts out of balance", len(rows))
+			})
+		},
+	}
+}
+
+func newInterestCommand(config func() *util.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "interest",
+		Short: "Accrue and post interest on savings products",
+		Long: "Accrue and post interest on savings products.\n" +
+			"The task processor accrues every day and posts every month on its own;\n" +
+			"these commands catch up on runs it missed. Both may be rerun safely:\n" +
+			"a date or month is never paid twice.",
+	}
+
+	var date string
+
+	accrue := &cobra.Command{
+		Use:   "accrue",
+		Short: "Record a day of interest at the rates in INTEREST_RATES",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			today := interest.Day(time.Now())
+
+			day := today.AddDate(0, 0, -1)
+			if date != "" {
+				t, err := time.Parse(time.DateOnly, date)
+				if err != nil {
+					return fmt.Errorf("invalid date %q", date)
+				}
+				day = t
+			}
+
+			if !day.Before(today) {
+				return fmt.Errorf("%s has not ended yet", day.Format(time.DateOnly))
+			}
+
+			rates, err := interest.ParseRates(config().InterestRates)
+			if err != nil {
+				return fmt.Errorf("invalid INTEREST_RATES: %w", err)
+			}
+
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				accrual, err := interest.Accrue(ctx, store, rates, day)
+				if err != nil {
+					return err
+				}
+
+				audit("interest accrued",
+					slog.String("date", accrual.Date.Format(time.DateOnly)),
+					slog.Int("accounts", accrual.Accounts),
+					slog.Int("created", accrual.Created))
+
+				_, err = fmt.Fprintf(cmd.OutOrStdout(), "%s: %d accounts accrued interest, %d of them for the first time\n",
+					accrual.Date.Format(time.DateOnly), accrual.Accounts, accrual.Created)
+
+				return err
+			})
+		},
+	}
+	accrue.Flags().StringVar(&date, "date", "", "UTC date to accrue, as YYYY-MM-DD (default yesterday)")
+
+	var month string
+
+	post := &cobra.Command{
+		Use:   "post",
+		Short: "Pay the interest accrued up to the end of a month",
+		Long: "Pay the interest accrued up to the end of a month.\n" +
+			"Accounts that cannot be paid are listed and the command exits with\n" +
+			"an error; running it again retries them.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			current := interest.Month(time.Now())
+
+			first := current.AddDate(0, -1, 0)
+			if month != "" {
+				t, err := time.Parse("2006-01", month)
+				if err != nil {
+					return fmt.Errorf("invalid month %q", month)
+				}
+				first = t
+			}
+
+			if !first.Before(current) {
+				return fmt.Errorf("%s has not ended yet", first.Format("2006-01"))
+			}
+
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				posting, err := interest.Post(ctx, store, first)
+				if err != nil {
+					return err
+				}
+
+				audit("interest posted",
+					slog.String("month", posting.Month.Format("2006-01")),
+					slog.Int("posted", posting.Posted),
+					slog.Int("failed", len(posting.Failures)))
+
+				fmt.Fprintf(cmd.OutOrStdout(), "%s: interest posted to %d accounts, %d already posted\n",
+					posting.Month.Format("2006-01"), posting.Posted, posting.Skipped)
+
+				if len(posting.Failures) == 0 {
+					return nil
+				}
+
+				w := newTabWriter(cmd.OutOrStdout())
+				fmt.Fprintln(w, "ACCOUNT\tERROR")
+				for _, failure := range posting.Failures {
+					fmt.Fprintf(w, "%d\t%s\n", failure.AccountID, failure.Error)
+				}
+				if err := w.Flush(); err != nil {
+					return err
+				}
+
+				return fmt.Errorf("%d accounts not posted", len(posting.Failures))
+			})
+		},
+	}
+	post.Flags().StringVar(&month, "month", "", "UTC month to post, as YYYY-MM (default last month)")
+
+	cmd.AddCommand(accrue, post)
+
+	return cmd
+}
+
+func newFeesCommand(config func() *util.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fees",
+		Short: "Manage fee rules and charge maintenance fees",
+	}
+
+	rules := &cobra.Command{
+		Use:   "rules",
+		Short: "Manage the rules transfer and maintenance fees are charged by",
+	}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List fee rules",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				rules, err := store.ListFeeRules(ctx)
+				if err != nil {
+					return err
+				}
+
+				return printFeeRules(cmd.OutOrStdout(), rules...)
+			})
+		},
+	}
+
+	var rule db.CreateFeeRuleParams
+
+	add := &cobra.Command{
+		Use:   "add",
+		Short: "Add a fee rule",
+		Long: "Add a fee rule. The fee is --flat plus --rate times the transfer amount,\n" +
+			"or the balance at the end of the month for maintenance, kept between\n" +
+			"--min-fee and --max-fee. Of the rules for an event, those for the\n" +
+			"account's currency win over those for any currency, then those for its\n" +
+			"type over those for any type, and the highest --min-amount reached wins.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := fees.ValidateRule(rule); err != nil {
+				return err
+			}
+
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				created, err := store.CreateFeeRule(ctx, rule)
+				if db.ErrorCode(err) == db.UniqueViolation {
+					return errors.New("a rule for the same event, currency, account type and minimum amount exists")
+				}
+				if err != nil {
+					return err
+				}
+
+				audit("fee rule added", slog.Int64("fee_rule_id", created.ID), slog.String("event", created.Event))
+
+				return printFeeRules(cmd.OutOrStdout(), created)
+			})
+		},
+	}
+	add.Flags().StringVar(&rule.Event, "event", db.FeeEventTransfer, "transfer or maintenance")
+	add.Flags().StringVar(&rule.Currency, "currency", "", "only charge accounts in this currency")
+	add.Flags().StringVar(&rule.AccountType, "account-type", "", "only charge accounts of this type")
+	add.Flags().Int64Var(&rule.MinAmount, "min-amount", 0, "smallest amount or balance the rule applies to, for tiers")
+	add.Flags().Int64Var(&rule.Flat, "flat", 0, "fixed part of the fee, in minor units")
+	add.Flags().StringVar(&rule.Rate, "rate", "0", "part of the amount charged, as a decimal fraction such as 0.005")
+	add.Flags().Int64Var(&rule.MinFee, "min-fee", 0, "smallest fee charged")
+	add.Flags().Int64Var(&rule.MaxFee, "max-fee", 0, "largest fee charged, 0 for no limit")
+	add.Flags().StringVar(&rule.Description, "description", "", "what the fee is for")
+
+	remove := &cobra.Command{
+		Use:   "remove ID",
+		Short: "Remove a fee rule; fees it already charged are kept",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				removed, err := store.DeleteFeeRule(ctx, id)
+				if err != nil {
+					return err
+				}
+				if removed == 0 {
+					return fmt.Errorf("fee rule %d does not exist", id)
+				}
+
+				audit("fee rule removed", slog.Int64("fee_rule_id", id))
+
+				_, err = fmt.Fprintf(cmd.OutOrStdout(), "fee rule %d removed\n", id)
+
+				return err
+			})
+		},
+	}
+
+	rules.AddCommand(list, add, remove)
+
+	var month string
+
+	maintenance := &cobra.Command{
+		Use:   "maintenance",
+		Short: "Charge the maintenance fees of a month",
+		Long: "Charge the maintenance fees of a month.\n" +
+			"Accounts that cannot be charged are listed and the command exits with\n" +
+			"an error; running it again retries them and skips the others. The task\n" +
+			"processor charges every month on its own; this command catches up on\n" +
+			"months it missed.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			current := util.StartOfMonth(time.Now())
+
+			first := current.AddDate(0, -1, 0)
+			if month != "" {
+				t, err := time.Parse("2006-01", month)
+				if err != nil {
+					return fmt.Errorf("invalid month %q", month)
+				}
+				first = t
+			}
+
+			if !first.Before(current) {
+				return fmt.Errorf("%s has not ended yet", first.Format("2006-01"))
+			}
+
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				result, err := fees.ChargeMaintenance(ctx, store, first)
+				if err != nil {
+					return err
+				}
+
+				audit("maintenance fees charged",
+					slog.String("month", result.Month.Format("2006-01")),
+					slog.Int("charged", result.Charged),
+					slog.Int("failed", len(result.Failures)))
+
+				fmt.Fprintf(cmd.OutOrStdout(), "%s: maintenance fees charged to %d accounts, %d already charged\n",
+					result.Month.Format("2006-01"), result.Charged, result.Skipped)
+
+				if len(result.Failures) == 0 {
+					return nil
+				}
+
+				w := newTabWriter(cmd.OutOrStdout())
+				fmt.Fprintln(w, "ACCOUNT\tERROR")
+				for _, failure := range result.Failures {
+					fmt.Fprintf(w, "%d\t%s\n", failure.AccountID, failure.Error)
+				}
+				if err := w.Flush(); err != nil {
+					return err
+				}
+
+				return fmt.Errorf("%d accounts not charged", len(result.Failures))
+			})
+		},
+	}
+	maintenance.Flags().StringVar(&month, "month", "", "UTC month to charge, as YYYY-MM (default last month)")
+
+	cmd.AddCommand(rules, maintenance)
+
+	return cmd
+}
+
+func newLedgerCommand(config func() *util.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ledger",
+		Short: "Manage the chart of accounts and the bank's system accounts",
+	}
+
+	chart := &cobra.Command{
+		Use:   "chart",
+		Short: "List the chart of accounts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				accounts, err := store.ListLedgerAccounts(ctx)
+				if err != nil {
+					return err
+				}
+
+				return printLedgerAccounts(cmd.OutOrStdout(), accounts...)
+			})
+		},
+	}
+
+	var parent string
+
+	addCode := &cobra.Command{
+		Use:   "add-code CODE NAME",
+		Short: "Add a ledger account to the chart of accounts",
+		Long: "Add a ledger account to the chart of accounts. Codes have four digits;\n" +
+			"the first is the type: 1 asset, 2 liability, 3 equity, 4 income, 5 expense.\n" +
+			"Accounts without --parent are top level groups and cannot hold system accounts.",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			code, name := args[0], strings.TrimSpace(args[1])
+
+			ledgerType, err := ledger.TypeOfCode(code)
+			if err != nil {
+				return err
+			}
+
+			if name == "" {
+				return errors.New("name cannot be empty")
+			}
+
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				if parent != "" {
+					group, err := store.GetLedgerAccount(ctx, parent)
+					if errors.Is(err, db.ErrRecordNotFound) {
+						return fmt.Errorf("ledger account %s does not exist", parent)
+					}
+					if err != nil {
+						return err
+					}
+
+					if group.Type != ledgerType {
+						return fmt.Errorf("code %s is for %s accounts, but %s %s is %s", code, ledgerType, group.Code, group.Name, group.Type)
+					}
+				}
+
+				account, err := store.CreateLedgerAccount(ctx, db.CreateLedgerAccountParams{
+					Code:       code,
+					Name:       name,
+					Type:       ledgerType,
+					ParentCode: parent,
+				})
+				if db.ErrorCode(err) == db.UniqueViolation {
+					return fmt.Errorf("ledger account %s already exists", code)
+				}
+				if err != nil {
+					return err
+				}
+
+				audit("ledger account added", slog.String("code", account.Code), slog.String("name", account.Name))
+
+				return printLedgerAccounts(cmd.OutOrStdout(), account)
+			})
+		},
+	}
+	addCode.Flags().StringVar(&parent, "parent", "", "code of the group the account belongs to")
+
+	accounts := &cobra.Command{
+		Use:   "accounts",
+		Short: "List the system accounts and the ledger accounts they are booked to",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				rows, err := store.ListSystemAccounts(ctx)
+				if err != nil {
+					return err
+				}
+
+				w := newTabWriter(cmd.OutOrStdout())
+				fmt.Fprintln(w, "CODE\tLEDGER ACCOUNT\tACCOUNT\tCURRENCY\tBALANCE")
+				for _, row := range rows {
+					fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%d\n", row.Code, row.LedgerName, row.ID, row.Currency, row.Balance)
+				}
+
+				return w.Flush()
+			})
+		},
+	}
+
+	open := &cobra.Command{
+		Use:   "open CODE CURRENCY",
+		Short: "Open a system account booked to a ledger account",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			code, currency := args[0], args[1]
+			if !util.IsSupportedCurrency(currency) {
+				return fmt.Errorf("currency %q is not supported", currency)
+			}
+
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				account, err := store.CreateSystemAccountTx(ctx, db.CreateSystemAccountTxParams{
+					Code:     code,
+					Currency: currency,
+				})
+				if errors.Is(err, db.ErrRecordNotFound) {
+					return fmt.Errorf("ledger account %s does not exist", code)
+				}
+				if err != nil {
+					return err
+				}
+
+				audit("system account opened",
+					slog.Int64("account_id", account.ID),
+					slog.String("code", code),
+					slog.String("currency", currency))
+
+				return printAccounts(cmd.OutOrStdout(), account)
+			})
+		},
+	}
+
+	deposit := newPostLedgerCommand(config, "deposit", db.LedgerCash,
+		"Book a cash deposit to a customer's account",
+		"Book a cash deposit to a customer's account, as a transfer from the\n"+
+			"bank's cash account in the account's currency.")
+
+	openingBalance := newPostLedgerCommand(config, "opening-balance", db.LedgerOpeningBalances,
+		"Book a customer's opening balance",
+		"Book a balance carried over from another system to a customer's account,\n"+
+			"as a transfer from the bank's opening balances account in the account's\n"+
+			"currency.")
+
+	trialBalance := &cobra.Command{
+		Use:   "trial-balance",
+		Short: "Check that the debits and credits of the ledger are equal in every currency",
+		Long: "Check that the debits and credits of the ledger are equal in every currency.\n" +
+			"The balance of every ledger account is summed from its entries and listed\n" +
+			"per currency. The command exits with an error when a currency does not sum\n" +
+			"to zero, or when an account's balance does not match its entries.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				report, err := ledger.NewTrialBalance(ctx, store)
+				if err != nil {
+					return err
+				}
+
+				w := newTabWriter(cmd.OutOrStdout())
+				fmt.Fprintln(w, "CURRENCY\tCODE\tLEDGER ACCOUNT\tACCOUNTS\tDEBIT\tCREDIT\tMISMATCHED")
+
+				var unbalanced, unreconciled []string
+				for _, currency := range report.Currencies {
+					for _, line := range currency.Lines {
+						fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%d\n",
+							currency.Currency, line.Code, line.Name, line.Accounts, line.Debit, line.Credit, line.Mismatched)
+					}
+					fmt.Fprintf(w, "%s\t\tTotal\t\t%d\t%d\t%d\n",
+						currency.Currency, currency.Debits, currency.Credits, currency.Mismatched)
+
+					if !currency.Balanced() {
+						unbalanced = append(unbalanced, currency.Currency)
+					}
+					if !currency.Reconciled() {
+						unreconciled = append(unreconciled, currency.Currency)
+					}
+				}
+				if err := w.Flush(); err != nil {
+					return err
+				}
+
+				if len(unbalanced) > 0 {
+					return fmt.Errorf("ledger out of balance in %s", strings.Join(unbalanced, ", "))
+				}
+
+				if len(unreconciled) > 0 {
+					return fmt.Errorf("account balances do not match their entries in %s", strings.Join(unreconciled, ", "))
+				}
+
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), "ledger balanced")
+
+				return err
+			})
+		},
+	}
+
+	cmd.AddCommand(chart, addCode, accounts, open, deposit, openingBalance, trialBalance)
+
+	return cmd
+}
+
+// newPostLedgerCommand creates a command that books money reaching a
+// customer's account from outside the ledger against the ledger account
+// code.
+func newPostLedgerCommand(config func() *util.Config, use, code, short, long string) *cobra.Command {
+	return &cobra.Command{
+		Use:   use + " ACCOUNT_ID AMOUNT",
+		Short: short,
+		Long:  long,
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+
+			amount, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil || amount < 1 {
+				return fmt.Errorf("invalid amount %q", args[1])
+			}
+
+			return withStore(config(), func(ctx context.Context, store db.Store) error {
+				result, err := store.PostLedgerTx(ctx, db.PostLedgerTxParams{
+					Code:      code,
+					AccountID: id,
+					Amount:    amount,
+				})
+				if errors.Is(err, db.ErrRecordNotFound) {
+					return fmt.Errorf("account %d or its system account for %s does not exist", id, code)
+				}
+				if err != nil {
+					return err
+				}
+
+				audit("ledger posting booked",
+					slog.String("code", code),
+					slog.Int64("account_id", id),
+					slog.Int64("amount", amount),
+					slog.Int64("transfer_id", result.Transfer.ID))
+
+				return printTransfers(cmd.OutOrStdout(), result.Transfer)
+			})
+		},
+	}
+}
+
+// pageFlags adds --limit and --offset to list commands.
+type pageFlags struct {
+	limit  int32
+	offset int32
+}
+
+func (p *pageFlags) register(cmd *cobra.Command) {
+	cmd.Flags().Int32Var(&p.limit, "limit", 50, "maximum number of rows")
+	cmd.Flags().Int32Var(&p.offset, "offset", 0, "number of rows to skip")
+}
+
+// openStore connects to the database; tests replace it with a mock store.
+var openStore = func(ctx context.Context, config *util.Config) (db.Store, func(), error) {
+	connPool, err := pgxpool.New(ctx, config.DBSource)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot connect to database: %w", err)
+	}
+
+	return db.NewStore(connPool), connPool.Close, nil
+}
+
+func withStore(config *util.Config, fn func(ctx context.Context, store db.Store) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), adminTimeout)
+	defer cancel()
+
+	store, closeStore, err := openStore(ctx, config)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	return fn(ctx, store)
+}
+
+// audit records an action taken by support staff, along with the operating
+// system user who ran the command.
+func audit(msg string, attrs ...slog.Attr) {
+	operator := "unknown"
+	if u, err := user.Current(); err == nil {
+		operator = u.Username
+	}
+
+	attrs = append(attrs, slog.Bool("admin", true), slog.String("operator", operator))
+	slog.LogAttrs(context.Background(), slog.LevelInfo, msg, attrs...)
+}
+
+func parseID(arg string) (int64, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid ID %q", arg)
+	}
+
+	return id, nil
+}
+
+func readLine(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func newTabWriter(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+}
+
+func printUsers(out io.Writer, users ...db.User) error {
+	w := newTabWriter(out)
+	fmt.Fprintln(w, "USERNAME\tFULL NAME\tEMAIL\tVERIFIED\tDISABLED\tFAILED LOGINS\tLOCKED UNTIL\tCREATED AT")
+	for _, user := range users {
+		lockedUntil := "-"
+		if user.LockedUntil.Time.After(time.Now()) {
+			lockedUntil = formatTime(user.LockedUntil.Time)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%t\t%d\t%s\t%s\n",
+			user.Username, user.FullName, user.Email, user.IsEmailVerified, user.IsDisabled,
+			user.FailedLoginAttempts, lockedUntil,
+			formatTime(user.CreatedAt.Time))
+	}
+
+	return w.Flush()
+}
+
+func printAccounts(out io.Writer, accounts ...db.Account) error {
+	w := newTabWriter(out)
+	fmt.Fprintln(w, "ID\tOWNER\tTYPE\tBALANCE\tCURRENCY\tSTATUS\tNICKNAME\tCREATED AT")
+	for _, account := range accounts {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%s\t%s\t%s\n",
+			account.ID, account.Owner, account.Type, account.Balance, account.Currency, account.Status,
+			account.Nickname, formatTime(account.CreatedAt.Time))
+	}
+
+	return w.Flush()
+}
+
+func printFeeRules(out io.Writer, rules ...db.FeeRule) error {
+	orAny := func(s string) string {
+		if s == "" {
+			return "*"
+		}
+		return s
+	}
+
+	w := newTabWriter(out)
+	fmt.Fprintln(w, "ID\tEVENT\tCURRENCY\tTYPE\tMIN AMOUNT\tFLAT\tRATE\tMIN FEE\tMAX FEE\tDESCRIPTION")
+	for _, rule := range rules {
+		maxFee := "-"
+		if rule.MaxFee > 0 {
+			maxFee = strconv.FormatInt(rule.MaxFee, 10)
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%d\t%s\t%d\t%s\t%s\n",
+			rule.ID, rule.Event, orAny(rule.Currency), orAny(rule.AccountType), rule.MinAmount,
+			rule.Flat, rule.Rate, rule.MinFee, maxFee, rule.Description)
+	}
+
+	return w.Flush()
+}
+
+// printLedgerAccounts lists ledger accounts indented under their group,
+// which comes first as codes sort by group.
+func printLedgerAccounts(out io.Writer, accounts ...db.LedgerAccount) error {
+	depth := make(map[string]int)
+
+	w := newTabWriter(out)
+	fmt.Fprintln(w, "CODE\tNAME\tTYPE")
+	for _, account := range accounts {
+		if account.ParentCode != "" {
+			depth[account.Code] = depth[account.ParentCode] + 1
+		}
+
+		fmt.Fprintf(w, "%s\t%s%s\t%s\n",
+			account.Code, strings.Repeat("  ", depth[account.Code]), account.Name, account.Type)
+	}
+
+	return w.Flush()
+}
+
+func printTransfers(out io.Writer, transfers ...db.Transfer) error {
+	w := newTabWriter(out)
+	fmt.Fprintln(w, "ID\tFROM\tTO\tAMOUNT\tCREATED AT")
+	for _, transfer := range transfers {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%s\n",
+			transfer.ID, transfer.FromAccountID, transfer.ToAccountID, transfer.Amount,
+			formatTime(transfer.CreatedAt.Time))
+	}
+
+	return w.Flush()
+}
+
+func printSessions(out io.Writer, sessions ...db.Session) error {
+	w := newTabWriter(out)
+	fmt.Fprintln(w, "ID\tUSERNAME\tCLIENT IP\tUSER AGENT\tBLOCKED\tEXPIRES AT")
+	for _, session := range sessions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\t%s\n",
+			session.ID, session.Username, session.ClientIp, session.UserAgent, session.IsBlocked,
+			formatTime(session.ExpiresAt.Time))
+	}
+
+	return w.Flush()
+}
+
+func printJobs(out io.Writer, jobs ...db.Job) error {
+	w := newTabWriter(out)
+	fmt.Fprintln(w, "ID\tTYPE\tSTATUS\tATTEMPTS\tRUN AT\tLAST ERROR")
+	for _, job := range jobs {
+		lastError := job.LastError
+		if lastError == "" {
+			lastError = "-"
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d/%d\t%s\t%s\n",
+			job.ID, job.Type, job.Status, job.Attempts, job.MaxAttempts,
+			formatTime(job.RunAt.Time), lastError)
+	}
+
+	return w.Flush()
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,528 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// runCommand executes the CLI against store and returns what it printed.
+func runCommand(t *testing.T, store db.Store, stdin string, args ...string) (string, error) {
+	previous := openStore
+	openStore = func(context.Context, *util.Config) (db.Store, func(), error) {
+		return store, func() {}, nil
+	}
+	t.Cleanup(func() { openStore = previous })
+
+	var out bytes.Buffer
+
+	cmd := newRootCommand()
+	cmd.SetArgs(args)
+	cmd.SetIn(strings.NewReader(stdin))
+	cmd.SetOut(&out)
+	cmd.SetErr(io.Discard)
+
+	err := cmd.Execute()
+
+	return out.String(), err
+}
+
+func TestUsersCreate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		CreateUser(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateUserParams) (db.User, error) {
+			require.Equal(t, "alice", arg.Username)
+			require.NoError(t, util.CheckPassword("secret-password", arg.HashedPassword))
+
+			return db.User{Username: arg.Username, FullName: arg.FullName, Email: arg.Email}, nil
+		})
+
+	out, err := runCommand(t, store, "secret-password\n",
+		"users", "create", "--username", "alice", "--full-name", "Alice", "--email", "alice@example.com")
+	require.NoError(t, err)
+	require.Contains(t, out, "alice@example.com")
+}
+
+func TestUsersCreateInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := runCommand(t, store, "short\n",
+		"users", "create", "--username", "alice", "--full-name", "Alice", "--email", "alice@example.com")
+	require.Error(t, err)
+}
+
+func TestUsersDisable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		DisableUserTx(gomock.Any(), gomock.Eq("alice")).
+		Times(1).
+		Return(db.DisableUserTxResult{
+			User:            db.User{Username: "alice", IsDisabled: true},
+			BlockedSessions: 2,
+		}, nil)
+
+	out, err := runCommand(t, store, "", "users", "disable", "alice")
+	require.NoError(t, err)
+	require.Contains(t, out, "alice")
+	require.Contains(t, out, "true")
+}
+
+func TestUsersShowLocked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	lockedUntil := time.Now().Add(time.Hour)
+
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq("alice")).
+		Times(1).
+		Return(db.User{
+			Username:            "alice",
+			FailedLoginAttempts: 6,
+			LockedUntil:         pgtype.Timestamptz{Time: lockedUntil, Valid: true},
+		}, nil)
+
+	out, err := runCommand(t, store, "", "users", "show", "alice")
+	require.NoError(t, err)
+	require.Contains(t, out, "FAILED LOGINS")
+	require.Contains(t, out, formatTime(lockedUntil))
+}
+
+func TestUsersUnlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		ResetFailedLogins(gomock.Any(), gomock.Eq("alice")).
+		Times(1).
+		Return(db.User{Username: "alice"}, nil)
+
+	out, err := runCommand(t, store, "", "users", "unlock", "alice")
+	require.NoError(t, err)
+	require.Contains(t, out, "alice")
+}
+
+func TestAccountsFreeze(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().IsSystemAccount(gomock.Any(), gomock.Eq(int64(42))).Times(1).Return(false, nil)
+	store.EXPECT().
+		UpdateAccountStatusTx(gomock.Any(), gomock.Eq(db.UpdateAccountStatusTxParams{
+			ID:     42,
+			From:   db.AccountStatusActive,
+			Status: db.AccountStatusFrozen,
+		})).
+		Times(1).
+		Return(db.Account{ID: 42, Owner: "alice", Status: db.AccountStatusFrozen}, nil)
+
+	out, err := runCommand(t, store, "", "accounts", "freeze", "42", "--reason", "fraud review")
+	require.NoError(t, err)
+	require.Contains(t, out, db.AccountStatusFrozen)
+
+	_, err = runCommand(t, store, "", "accounts", "freeze", "abc")
+	require.Error(t, err)
+}
+
+func TestAccountsUnfreezeClosed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().IsSystemAccount(gomock.Any(), gomock.Eq(int64(42))).Times(1).Return(false, nil)
+	store.EXPECT().
+		UpdateAccountStatusTx(gomock.Any(), gomock.Eq(db.UpdateAccountStatusTxParams{
+			ID:     42,
+			From:   db.AccountStatusFrozen,
+			Status: db.AccountStatusActive,
+		})).
+		Times(1).
+		Return(db.Account{}, db.ErrInvalidStatusTransition)
+
+	_, err := runCommand(t, store, "", "accounts", "unfreeze", "42")
+	require.ErrorIs(t, err, db.ErrInvalidStatusTransition)
+}
+
+func TestAccountsFreezeSystem(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().IsSystemAccount(gomock.Any(), gomock.Eq(int64(7))).Times(1).Return(true, nil)
+	store.EXPECT().
+		UpdateAccountStatusTx(gomock.Any(), gomock.Eq(db.UpdateAccountStatusTxParams{
+			ID:     7,
+			From:   db.AccountStatusActive,
+			Status: db.AccountStatusFrozen,
+		})).
+		Times(1).
+		Return(db.Account{ID: 7, Owner: db.BankUsername, Status: db.AccountStatusFrozen}, nil)
+
+	_, err := runCommand(t, store, "", "accounts", "freeze", "7")
+	require.ErrorContains(t, err, "pass --system")
+
+	out, err := runCommand(t, store, "", "accounts", "freeze", "7", "--system", "--reason", "ledger migration")
+	require.NoError(t, err)
+	require.Contains(t, out, db.AccountStatusFrozen)
+}
+
+func TestReconcile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	gomock.InOrder(
+		store.EXPECT().
+			ListUnreconciledAccounts(gomock.Any()).
+			Return([]db.ListUnreconciledAccountsRow{}, nil),
+		store.EXPECT().
+			ListUnreconciledAccounts(gomock.Any()).
+			Return([]db.ListUnreconciledAccountsRow{
+				{ID: 7, Owner: "alice", Currency: util.USD, Balance: 100, EntriesTotal: 90},
+			}, nil),
+	)
+
+	out, err := runCommand(t, store, "", "reconcile")
+	require.NoError(t, err)
+	require.Contains(t, out, "all accounts reconciled")
+
+	out, err = runCommand(t, store, "", "reconcile")
+	require.EqualError(t, err, "1 accounts out of balance")
+	require.Contains(t, out, "alice")
+}
+
+func TestJobsList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		ListJobs(gomock.Any(), gomock.Eq(db.ListJobsParams{Status: db.JobStatusDead, Limit: 50})).
+		Times(1).
+		Return([]db.Job{{
+			ID:          7,
+			Type:        "send_verify_email",
+			Status:      db.JobStatusDead,
+			Attempts:    5,
+			MaxAttempts: 5,
+			LastError:   "smtp unavailable",
+		}}, nil)
+
+	out, err := runCommand(t, store, "", "jobs", "list")
+	require.NoError(t, err)
+	require.Contains(t, out, "send_verify_email")
+	require.Contains(t, out, "5/5")
+	require.Contains(t, out, "smtp unavailable")
+
+	_, err = runCommand(t, store, "", "jobs", "list", "--status", "lost")
+	require.ErrorContains(t, err, "invalid status")
+}
+
+func TestJobsRetry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		ReviveJob(gomock.Any(), gomock.Eq(int64(7))).
+		Times(1).
+		Return(db.Job{ID: 7, Type: "send_verify_email", Status: db.JobStatusPending, MaxAttempts: 5}, nil)
+	store.EXPECT().
+		ReviveJob(gomock.Any(), gomock.Eq(int64(8))).
+		Times(1).
+		Return(db.Job{}, db.ErrRecordNotFound)
+
+	out, err := runCommand(t, store, "", "jobs", "retry", "7")
+	require.NoError(t, err)
+	require.Contains(t, out, db.JobStatusPending)
+
+	_, err = runCommand(t, store, "", "jobs", "retry", "8")
+	require.ErrorContains(t, err, "not dead")
+}
+
+func TestInterestAccrue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	// app.env sets INTEREST_RATES=savings=0.02.
+	store.EXPECT().
+		ListInterestBearingAccounts(gomock.Any(), gomock.Eq(db.ListInterestBearingAccountsParams{
+			DayEnd: pgtype.Timestamptz{Time: time.Date(2025, time.March, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+			Types:  []string{db.AccountTypeSavings},
+		})).
+		Times(1).
+		Return([]db.ListInterestBearingAccountsRow{{ID: 7, Type: db.AccountTypeSavings, Currency: util.USD, Balance: 365000}}, nil)
+	store.EXPECT().
+		CreateInterestAccrual(gomock.Any(), gomock.Eq(db.CreateInterestAccrualParams{
+			AccountID:    7,
+			AccrualDate:  pgtype.Date{Time: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+			Balance:      365000,
+			Rate:         "0.02",
+			AmountMicros: 20 * db.MicrosPerUnit,
+		})).
+		Times(1).
+		Return(int64(1), nil)
+
+	out, err := runCommand(t, store, "", "interest", "accrue", "--date", "2025-03-01")
+	require.NoError(t, err)
+	require.Contains(t, out, "2025-03-01: 1 accounts accrued interest")
+
+	_, err = runCommand(t, store, "", "interest", "accrue", "--date", time.Now().UTC().Format(time.DateOnly))
+	require.ErrorContains(t, err, "has not ended yet")
+
+	_, err = runCommand(t, store, "", "interest", "accrue", "--date", "01/03/2025")
+	require.ErrorContains(t, err, "invalid date")
+}
+
+func TestInterestPost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	month := time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)
+
+	store.EXPECT().
+		ListAccountsWithUnpostedInterest(gomock.Any(), gomock.Eq(pgtype.Date{Time: month.AddDate(0, 1, 0), Valid: true})).
+		Times(1).
+		Return([]int64{7, 8}, nil)
+	store.EXPECT().
+		PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{AccountID: 7, Month: month})).
+		Times(1).
+		Return(db.InterestPosting{ID: 1, AccountID: 7, Amount: 560}, nil)
+	store.EXPECT().
+		PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{AccountID: 8, Month: month})).
+		Times(1).
+		Return(db.InterestPosting{}, db.ErrAccountFrozen)
+
+	out, err := runCommand(t, store, "", "interest", "post", "--month", "2025-02")
+	require.EqualError(t, err, "1 accounts not posted")
+	require.Contains(t, out, "interest posted to 1 accounts")
+	require.Contains(t, out, db.ErrAccountFrozen.Error())
+
+	_, err = runCommand(t, store, "", "interest", "post", "--month", time.Now().UTC().Format("2006-01"))
+	require.ErrorContains(t, err, "has not ended yet")
+}
+
+func TestFeeRulesAdd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	rule := db.CreateFeeRuleParams{
+		Event:       db.FeeEventTransfer,
+		Currency:    util.EUR,
+		MinAmount:   100000,
+		Flat:        25,
+		Rate:        "0.001",
+		MaxFee:      1000,
+		Description: "SEPA above 1000",
+	}
+
+	gomock.InOrder(
+		store.EXPECT().
+			CreateFeeRule(gomock.Any(), gomock.Eq(rule)).
+			Return(db.FeeRule{ID: 3, Event: rule.Event, Currency: rule.Currency, MinAmount: rule.MinAmount,
+				Flat: rule.Flat, Rate: rule.Rate, MaxFee: rule.MaxFee, Description: rule.Description}, nil),
+		store.EXPECT().
+			CreateFeeRule(gomock.Any(), gomock.Eq(rule)).
+			Return(db.FeeRule{}, &pgconn.PgError{Code: db.UniqueViolation}),
+	)
+
+	args := []string{"fees", "rules", "add", "--currency", "EUR", "--min-amount", "100000",
+		"--flat", "25", "--rate", "0.001", "--max-fee", "1000", "--description", "SEPA above 1000"}
+
+	out, err := runCommand(t, store, "", args...)
+	require.NoError(t, err)
+	require.Contains(t, out, "SEPA above 1000")
+
+	_, err = runCommand(t, store, "", args...)
+	require.ErrorContains(t, err, "exists")
+
+	_, err = runCommand(t, store, "", "fees", "rules", "add", "--rate", "1%")
+	require.Error(t, err)
+}
+
+func TestFeeRulesRemove(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().DeleteFeeRule(gomock.Any(), gomock.Eq(int64(3))).Times(1).Return(int64(1), nil)
+	store.EXPECT().DeleteFeeRule(gomock.Any(), gomock.Eq(int64(4))).Times(1).Return(int64(0), nil)
+
+	out, err := runCommand(t, store, "", "fees", "rules", "remove", "3")
+	require.NoError(t, err)
+	require.Contains(t, out, "fee rule 3 removed")
+
+	_, err = runCommand(t, store, "", "fees", "rules", "remove", "4")
+	require.ErrorContains(t, err, "does not exist")
+}
+
+func TestFeesMaintenance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	month := time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)
+
+	store.EXPECT().
+		ListMaintenanceFeeAccounts(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]int64{7}, nil)
+	store.EXPECT().
+		ChargeMaintenanceFeeTx(gomock.Any(), gomock.Eq(db.ChargeMaintenanceFeeTxParams{AccountID: 7, Month: month})).
+		Times(1).
+		Return(db.Fee{ID: 1, AccountID: 7, Event: db.FeeEventMaintenance, Amount: 500}, nil)
+
+	out, err := runCommand(t, store, "", "fees", "maintenance", "--month", "2025-02")
+	require.NoError(t, err)
+	require.Contains(t, out, "maintenance fees charged to 1 accounts")
+
+	_, err = runCommand(t, store, "", "fees", "maintenance", "--month", time.Now().UTC().Format("2006-01"))
+	require.ErrorContains(t, err, "has not ended yet")
+}
+
+func TestLedgerChart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		ListLedgerAccounts(gomock.Any()).
+		Times(1).
+		Return([]db.LedgerAccount{
+			{Code: "4000", Name: "Income", Type: db.LedgerTypeIncome},
+			{Code: db.LedgerFeeRevenue, Name: "Fee revenue", Type: db.LedgerTypeIncome, ParentCode: "4000"},
+		}, nil)
+
+	out, err := runCommand(t, store, "", "ledger", "chart")
+	require.NoError(t, err)
+	require.Contains(t, out, "4000  Income")
+	require.Contains(t, out, "4100    Fee revenue")
+}
+
+func TestLedgerAddCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		GetLedgerAccount(gomock.Any(), gomock.Eq("4000")).
+		AnyTimes().
+		Return(db.LedgerAccount{Code: "4000", Name: "Income", Type: db.LedgerTypeIncome}, nil)
+	store.EXPECT().
+		CreateLedgerAccount(gomock.Any(), gomock.Eq(db.CreateLedgerAccountParams{
+			Code:       "4300",
+			Name:       "Card interchange",
+			Type:       db.LedgerTypeIncome,
+			ParentCode: "4000",
+		})).
+		Times(1).
+		Return(db.LedgerAccount{Code: "4300", Name: "Card interchange", Type: db.LedgerTypeIncome, ParentCode: "4000"}, nil)
+
+	out, err := runCommand(t, store, "", "ledger", "add-code", "4300", "Card interchange", "--parent", "4000")
+	require.NoError(t, err)
+	require.Contains(t, out, "Card interchange")
+
+	// An expense cannot be grouped under income.
+	_, err = runCommand(t, store, "", "ledger", "add-code", "5300", "Card fees", "--parent", "4000")
+	require.ErrorContains(t, err, "is income")
+
+	_, err = runCommand(t, store, "", "ledger", "add-code", "9000", "Memo")
+	require.Error(t, err)
+}
+
+func TestLedgerOpen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	gomock.InOrder(
+		store.EXPECT().
+			CreateSystemAccountTx(gomock.Any(), gomock.Eq(db.CreateSystemAccountTxParams{Code: db.LedgerCash, Currency: util.USD})).
+			Return(db.Account{ID: 9, Owner: db.BankUsername, Currency: util.USD, Nickname: "Cash USD"}, nil),
+		store.EXPECT().
+			CreateSystemAccountTx(gomock.Any(), gomock.Any()).
+			Return(db.Account{}, db.ErrSystemAccountExists),
+	)
+
+	out, err := runCommand(t, store, "", "ledger", "open", db.LedgerCash, util.USD)
+	require.NoError(t, err)
+	require.Contains(t, out, "Cash USD")
+
+	_, err = runCommand(t, store, "", "ledger", "open", db.LedgerCash, util.USD)
+	require.ErrorIs(t, err, db.ErrSystemAccountExists)
+
+	_, err = runCommand(t, store, "", "ledger", "open", db.LedgerCash, "GBP")
+	require.ErrorContains(t, err, "not supported")
+}
+
+func TestLedgerPost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	gomock.InOrder(
+		store.EXPECT().
+			PostLedgerTx(gomock.Any(), gomock.Eq(db.PostLedgerTxParams{Code: db.LedgerCash, AccountID: 7, Amount: 500})).
+			Return(db.TransferTxResult{Transfer: db.Transfer{ID: 3, FromAccountID: 1, ToAccountID: 7, Amount: 500}}, nil),
+		store.EXPECT().
+			PostLedgerTx(gomock.Any(), gomock.Eq(db.PostLedgerTxParams{Code: db.LedgerOpeningBalances, AccountID: 7, Amount: 250})).
+			Return(db.TransferTxResult{Transfer: db.Transfer{ID: 4, FromAccountID: 2, ToAccountID: 7, Amount: 250}}, nil),
+		store.EXPECT().
+			PostLedgerTx(gomock.Any(), gomock.Any()).
+			Return(db.TransferTxResult{}, db.ErrRecordNotFound),
+	)
+
+	out, err := runCommand(t, store, "", "ledger", "deposit", "7", "500")
+	require.NoError(t, err)
+	require.Contains(t, out, "500")
+
+	out, err = runCommand(t, store, "", "ledger", "opening-balance", "7", "250")
+	require.NoError(t, err)
+	require.Contains(t, out, "250")
+
+	_, err = runCommand(t, store, "", "ledger", "deposit", "8", "500")
+	require.ErrorContains(t, err, "does not exist")
+
+	_, err = runCommand(t, store, "", "ledger", "deposit", "7", "0")
+	require.ErrorContains(t, err, "invalid amount")
+}
+
+func TestLedgerTrialBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	balanced := []db.ListTrialBalanceRow{
+		{Code: db.LedgerCustomerDeposits, Name: "Customer deposits", Currency: util.USD, Accounts: 2, Balance: 100},
+		{Code: db.LedgerInterestExpense, Name: "Interest expense", Currency: util.USD, Accounts: 1, Balance: -100},
+	}
+
+	mismatched := slices.Clone(balanced)
+	mismatched[0].Mismatched = 1
+
+	gomock.InOrder(
+		store.EXPECT().ListTrialBalance(gomock.Any(), gomock.Eq(db.LedgerCustomerDeposits)).Return(balanced, nil),
+		store.EXPECT().ListTrialBalance(gomock.Any(), gomock.Any()).Return(balanced[:1], nil),
+		store.EXPECT().ListTrialBalance(gomock.Any(), gomock.Any()).Return(mismatched, nil),
+	)
+
+	out, err := runCommand(t, store, "", "ledger", "trial-balance")
+	require.NoError(t, err)
+	require.Contains(t, out, "Interest expense")
+	require.Contains(t, out, "ledger balanced")
+
+	_, err = runCommand(t, store, "", "ledger", "trial-balance")
+	require.EqualError(t, err, "ledger out of balance in USD")
+
+	_, err = runCommand(t, store, "", "ledger", "trial-balance")
+	require.EqualError(t, err, "account balances do not match their entries in USD")
+}
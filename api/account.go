@@ -2,11 +2,10 @@ package api
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx"
-	"github.com/lib/pq"
 	db "github.com/shevgn/simplebank/db/sqlc"
 	"github.com/shevgn/simplebank/token"
 )
@@ -14,6 +13,9 @@ import (
 // CreateAccountRequest represents a request to create an account.
 type CreateAccountRequest struct {
 	Currency string `json:"currency" binding:"required,currency"`
+	// Type defaults to checking.
+	Type     string `json:"type"     binding:"omitempty,account_type"`
+	Nickname string `json:"nickname" binding:"max=64"`
 }
 
 func (s *Server) createAccount(ctx *gin.Context) {
@@ -26,24 +28,30 @@ func (s *Server) createAccount(ctx *gin.Context) {
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
+	if req.Type == "" {
+		req.Type = db.AccountTypeChecking
+	}
+
 	arg := db.CreateAccountParams{
 		Owner:    authPayload.Username,
 		Currency: req.Currency,
 		Balance:  0,
+		Type:     req.Type,
+		Nickname: req.Nickname,
 	}
 
 	account, err := s.store.CreateAccount(ctx, arg)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "unique_violation":
-				ctx.JSON(http.StatusConflict, errorResponse(err))
-				return
-			case "foreign_key_violation":
-				ctx.JSON(http.StatusBadRequest, errorResponse(err))
-				return
-			}
+		switch db.ErrorCode(err) {
+		case db.UniqueViolation:
+			err := fmt.Errorf("an account named %q already exists", req.Nickname)
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		case db.ForeignKeyViolation:
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
 		}
+
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
@@ -66,7 +74,7 @@ func (s *Server) getAccount(ctx *gin.Context) {
 
 	account, err := s.store.GetAccount(ctx, req.ID)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, db.ErrRecordNotFound) {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
 			return
 		}
@@ -87,8 +95,10 @@ func (s *Server) getAccount(ctx *gin.Context) {
 
 // ListAccountsRequest represents a request to list accounts.
 type ListAccountsRequest struct {
-	PageID   int32 `form:"page_id"   binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+	PageID   int32  `form:"page_id"   binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=10"`
+	Type     string `form:"type"      binding:"omitempty,account_type"`
+	Currency string `form:"currency"  binding:"omitempty,currency"`
 }
 
 func (s *Server) listAccounts(ctx *gin.Context) {
@@ -102,9 +112,11 @@ func (s *Server) listAccounts(ctx *gin.Context) {
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
 	arg := db.ListAccountsParams{
-		Owner:  authPayload.Username,
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+		Owner:    authPayload.Username,
+		Type:     req.Type,
+		Currency: req.Currency,
+		Limit:    req.PageSize,
+		Offset:   (req.PageID - 1) * req.PageSize,
 	}
 
 	accounts, err := s.store.ListAccounts(ctx, arg)
@@ -116,62 +128,89 @@ func (s *Server) listAccounts(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, accounts)
 }
 
-// UpdateAccountRequest represents a request to update an account.
-type UpdateAccountRequest struct {
-	ID      int64 `json:"id"      binding:"required,min=1"`
-	Balance int64 `json:"balance" binding:"required,min=0"`
+// DeleteAccountRequest represents a request to delete an account.
+type DeleteAccountRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
 }
 
-func (s *Server) updateAccount(ctx *gin.Context) {
-	var req UpdateAccountRequest
+// deleteAccount closes an account. The row stays, with its entries and
+// transfers, so the account's history can still be read.
+func (s *Server) deleteAccount(ctx *gin.Context) {
+	var req DeleteAccountRequest
 
-	if err := ctx.ShouldBindJSON(&req); err != nil {
+	if err := ctx.ShouldBindUri(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	arg := db.UpdateAccountParams{
-		ID:      req.ID,
-		Balance: req.Balance,
+	if _, ok := s.changeAccountStatus(ctx, req.ID, db.AccountStatusActive, db.AccountStatusClosed); ok {
+		ctx.Status(http.StatusNoContent)
 	}
+}
 
-	account, err := s.store.UpdateAccount(ctx, arg)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
+func (s *Server) closeAccount(ctx *gin.Context) {
+	var req GetAccountRequest
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
-}
-
-// DeleteAccountRequest represents a request to delete an account.
-type DeleteAccountRequest struct {
-	ID int64 `uri:"id" binding:"required,min=1"`
+	if account, ok := s.changeAccountStatus(ctx, req.ID, db.AccountStatusActive, db.AccountStatusClosed); ok {
+		ctx.JSON(http.StatusOK, account)
+	}
 }
 
-func (s *Server) deleteAccount(ctx *gin.Context) {
-	var req DeleteAccountRequest
+func (s *Server) reopenAccount(ctx *gin.Context) {
+	var req GetAccountRequest
 
 	if err := ctx.ShouldBindUri(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	err := s.store.DeleteAccount(ctx, req.ID)
+	if account, ok := s.changeAccountStatus(ctx, req.ID, db.AccountStatusClosed, db.AccountStatusActive); ok {
+		ctx.JSON(http.StatusOK, account)
+	}
+}
+
+// changeAccountStatus moves one of the caller's accounts from one status to
+// another. Owners can only close and reopen their accounts; freezing is left
+// to the admin CLI. It writes the error response and returns false when the
+// account cannot be changed.
+func (s *Server) changeAccountStatus(ctx *gin.Context, id int64, from, status string) (db.Account, bool) {
+	account, err := s.store.GetAccount(ctx, id)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, db.ErrRecordNotFound) {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
+			return account, false
 		}
 
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return account, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if account.Owner != authPayload.Username {
+		err := errors.New("account does not belong to the user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return account, false
+	}
+
+	account, err = s.store.UpdateAccountStatusTx(ctx, db.UpdateAccountStatusTxParams{
+		ID:     id,
+		From:   from,
+		Status: status,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidStatusTransition) || errors.Is(err, db.ErrAccountNotEmpty) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return account, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return account, false
 	}
 
-	ctx.Status(http.StatusNoContent)
+	return account, true
 }
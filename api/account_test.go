@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -11,7 +12,7 @@ import (
 
 	"io"
 
-	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/v5/pgconn"
 	mockdb "github.com/shevgn/simplebank/db/mock"
 	db "github.com/shevgn/simplebank/db/sqlc"
 	"github.com/shevgn/simplebank/token"
@@ -26,6 +27,8 @@ func randomAccount(owner string) db.Account {
 		Owner:    owner,
 		Balance:  util.RandomBalance(),
 		Currency: util.RandomCurrency(),
+		Status:   db.AccountStatusActive,
+		Type:     db.AccountTypeChecking,
 	}
 }
 
@@ -117,7 +120,7 @@ func TestGetAccountAPI(t *testing.T) {
 				store.EXPECT().
 					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
 					Times(1).
-					Return(db.Account{}, pgx.ErrNoRows)
+					Return(db.Account{}, db.ErrRecordNotFound)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
@@ -133,7 +136,7 @@ func TestGetAccountAPI(t *testing.T) {
 				store.EXPECT().
 					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
 					Times(1).
-					Return(db.Account{}, pgx.ErrClosedPool)
+					Return(db.Account{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -192,6 +195,7 @@ func TestCreateAccountAPI(t *testing.T) {
 		Owner:    user.Username,
 		Currency: req.Currency,
 		Balance:  0,
+		Type:     db.AccountTypeChecking,
 	}
 
 	testCases := []struct {
@@ -218,6 +222,67 @@ func TestCreateAccountAPI(t *testing.T) {
 				requireBodyMatchAccount(t, recorder.Body, account)
 			},
 		},
+		{
+			name: "SavingsWithNickname",
+			requestBody: CreateAccountRequest{
+				Currency: req.Currency,
+				Type:     db.AccountTypeSavings,
+				Nickname: "Rainy day",
+			},
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				savings := arg
+				savings.Type = db.AccountTypeSavings
+				savings.Nickname = "Rainy day"
+
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Eq(savings)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NicknameTaken",
+			requestBody: CreateAccountRequest{
+				Currency: req.Currency,
+				Nickname: "Rainy day",
+			},
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, &pgconn.PgError{Code: db.UniqueViolation})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidType",
+			requestBody: CreateAccountRequest{
+				Currency: req.Currency,
+				Type:     "brokerage",
+			},
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name:        "InternalServerError",
 			requestBody: req,
@@ -228,7 +293,7 @@ func TestCreateAccountAPI(t *testing.T) {
 				store.EXPECT().
 					CreateAccount(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(db.Account{}, pgx.ErrClosedPool)
+					Return(db.Account{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -341,6 +406,50 @@ func TestListAccountsAPI(t *testing.T) {
 				requireBodyMatchAccountList(t, recorder.Body, accounts)
 			},
 		},
+		{
+			name: "FilterByTypeAndCurrency",
+			requestBody: ListAccountsRequest{
+				PageID:   1,
+				PageSize: 5,
+				Type:     db.AccountTypeSavings,
+				Currency: util.EUR,
+			},
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				filtered := arg
+				filtered.Type = db.AccountTypeSavings
+				filtered.Currency = util.EUR
+
+				store.EXPECT().
+					ListAccounts(gomock.Any(), gomock.Eq(filtered)).
+					Times(1).
+					Return([]db.Account{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidType",
+			requestBody: ListAccountsRequest{
+				PageID:   1,
+				PageSize: 5,
+				Type:     "brokerage",
+			},
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccounts(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name:        "InternalServerError",
 			requestBody: req,
@@ -351,7 +460,7 @@ func TestListAccountsAPI(t *testing.T) {
 				store.EXPECT().
 					ListAccounts(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return([]db.Account{}, pgx.ErrClosedPool)
+					Return([]db.Account{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -441,6 +550,13 @@ func TestListAccountsAPI(t *testing.T) {
 			recorder := httptest.NewRecorder()
 
 			url := fmt.Sprintf("/accounts?page_id=%d&page_size=%d", tc.requestBody.PageID, tc.requestBody.PageSize)
+			if tc.requestBody.Type != "" {
+				url += "&type=" + tc.requestBody.Type
+			}
+			if tc.requestBody.Currency != "" {
+				url += "&currency=" + tc.requestBody.Currency
+			}
+
 			request, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
 
@@ -451,139 +567,161 @@ func TestListAccountsAPI(t *testing.T) {
 	}
 }
 
-func TestUpdateAccountAPI(t *testing.T) {
+func TestDeleteAccountAPI(t *testing.T) {
 	user, _ := randomUser(t)
 	account := randomAccount(user.Username)
+	account.Balance = 0
 
-	req := UpdateAccountRequest{
-		ID:      account.ID,
-		Balance: util.RandomBalance(),
+	closed := account
+	closed.Status = db.AccountStatusClosed
+
+	closeParams := db.UpdateAccountStatusTxParams{
+		ID:     account.ID,
+		From:   db.AccountStatusActive,
+		Status: db.AccountStatusClosed,
 	}
 
-	arg := db.UpdateAccountParams{
-		ID:      req.ID,
-		Balance: req.Balance,
+	req := DeleteAccountRequest{
+		ID: account.ID,
 	}
 
 	testCases := []struct {
 		name          string
-		requestBody   UpdateAccountRequest
+		request       DeleteAccountRequest
 		setupAuth     func(t *testing.T, req *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
-			name:        "OK",
-			requestBody: req,
+			name:    "OK",
+			request: req,
 			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateAccount(gomock.Any(), gomock.Eq(arg)).
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
 					Times(1).
 					Return(account, nil)
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Eq(closeParams)).
+					Times(1).
+					Return(closed, nil)
+				store.EXPECT().
+					DeleteAccount(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMatchAccount(t, recorder.Body, account)
+				require.Equal(t, http.StatusNoContent, recorder.Code)
 			},
 		},
 		{
-			name:        "NotFound",
-			requestBody: req,
+			name:    "NotFound",
+			request: req,
 			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateAccount(gomock.Any(), gomock.Eq(arg)).
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
 					Times(1).
-					Return(db.Account{}, pgx.ErrNoRows)
+					Return(db.Account{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
 			},
 		},
 		{
-			name:        "InternalServerError",
-			requestBody: req,
+			name:    "UnauthorizedUser",
+			request: req,
 			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, "unauthorized_user", time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateAccount(gomock.Any(), gomock.Eq(arg)).
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
 					Times(1).
-					Return(db.Account{}, pgx.ErrClosedPool)
+					Return(account, nil)
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
 		{
-			name:        "BadRequest",
-			requestBody: UpdateAccountRequest{},
+			name:    "NotEmpty",
+			request: req,
 			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateAccount(gomock.Any(), gomock.Any()).
-					Times(0)
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Eq(closeParams)).
+					Times(1).
+					Return(db.Account{}, db.ErrAccountNotEmpty)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, http.StatusConflict, recorder.Code)
 			},
 		},
 		{
-			name: "InvalidID",
-			requestBody: UpdateAccountRequest{
-				ID:      0,
-				Balance: util.RandomBalance(),
-			},
+			name:    "Frozen",
+			request: req,
 			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateAccount(gomock.Any(), gomock.Any()).
-					Times(0)
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Eq(closeParams)).
+					Times(1).
+					Return(db.Account{}, db.ErrInvalidStatusTransition)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, http.StatusConflict, recorder.Code)
 			},
 		},
 		{
-			name: "InvalidBalance",
-			requestBody: UpdateAccountRequest{
-				ID:      account.ID,
-				Balance: 0,
-			},
+			name:    "InternalServerError",
+			request: req,
 			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateAccount(gomock.Any(), gomock.Any()).
-					Times(0)
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Eq(closeParams)).
+					Times(1).
+					Return(db.Account{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
 		{
-			name: "InvalidIDAndBalance",
-			requestBody: UpdateAccountRequest{
-				ID:      0,
-				Balance: -1,
-			},
+			name:    "BadRequest",
+			request: DeleteAccountRequest{},
 			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateAccount(gomock.Any(), gomock.Any()).
+					GetAccount(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -604,14 +742,10 @@ func TestUpdateAccountAPI(t *testing.T) {
 			server := NewTestServer(t, store)
 			recorder := httptest.NewRecorder()
 
-			url := "/accounts"
-			body, err := json.Marshal(tc.requestBody)
+			url := fmt.Sprintf("/accounts/%d", tc.request.ID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
 			require.NoError(t, err)
 
-			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
-			require.NoError(t, err)
-			request.Header.Set("Content-Type", "application/json")
-
 			tc.setupAuth(t, request, server.tokenMaker)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
@@ -619,99 +753,79 @@ func TestUpdateAccountAPI(t *testing.T) {
 	}
 }
 
-func TestDeleteAccountAPI(t *testing.T) {
+func TestChangeAccountStatusAPI(t *testing.T) {
 	user, _ := randomUser(t)
 	account := randomAccount(user.Username)
+	account.Balance = 0
 
-	req := DeleteAccountRequest{
-		ID: account.ID,
-	}
+	closed := account
+	closed.Status = db.AccountStatusClosed
 
 	testCases := []struct {
 		name          string
-		request       DeleteAccountRequest
-		setupAuth     func(t *testing.T, req *http.Request, tokenMaker token.Maker)
+		action        string
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
-			name:    "OK",
-			request: req,
-			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
-			},
+			name:   "Close",
+			action: "close",
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					DeleteAccount(gomock.Any(), gomock.Eq(account.ID)).
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Eq(db.UpdateAccountStatusTxParams{
+						ID:     account.ID,
+						From:   db.AccountStatusActive,
+						Status: db.AccountStatusClosed,
+					})).
 					Times(1).
-					Return(nil)
+					Return(closed, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNoContent, recorder.Code)
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, closed)
 			},
 		},
 		{
-			name:    "NotFound",
-			request: req,
-			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
-			},
+			name:   "Reopen",
+			action: "reopen",
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					DeleteAccount(gomock.Any(), gomock.Eq(account.ID)).
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
 					Times(1).
-					Return(pgx.ErrNoRows)
-			},
-			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
-			},
-		},
-		{
-			name:    "InternalServerError",
-			request: req,
-			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
-			},
-			buildStubs: func(store *mockdb.MockStore) {
+					Return(closed, nil)
 				store.EXPECT().
-					DeleteAccount(gomock.Any(), gomock.Eq(account.ID)).
+					UpdateAccountStatusTx(gomock.Any(), gomock.Eq(db.UpdateAccountStatusTxParams{
+						ID:     account.ID,
+						From:   db.AccountStatusClosed,
+						Status: db.AccountStatusActive,
+					})).
 					Times(1).
-					Return(pgx.ErrClosedPool)
+					Return(account, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, account)
 			},
 		},
 		{
-			name:    "BadRequest",
-			request: DeleteAccountRequest{},
-			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
-			},
+			name:   "ReopenActive",
+			action: "reopen",
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					DeleteAccount(gomock.Any(), gomock.Any()).
-					Times(0)
-			},
-			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
-			},
-		},
-		{
-			name: "InvalidID",
-			request: DeleteAccountRequest{
-				ID: 0,
-			},
-			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
-			},
-			buildStubs: func(store *mockdb.MockStore) {
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
 				store.EXPECT().
-					DeleteAccount(gomock.Any(), gomock.Any()).
-					Times(0)
+					UpdateAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, db.ErrInvalidStatusTransition)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, http.StatusConflict, recorder.Code)
 			},
 		},
 	}
@@ -728,11 +842,11 @@ func TestDeleteAccountAPI(t *testing.T) {
 			server := NewTestServer(t, store)
 			recorder := httptest.NewRecorder()
 
-			url := fmt.Sprintf("/accounts/%d", tc.request.ID)
-			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			url := fmt.Sprintf("/accounts/%d/%s", account.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
 			require.NoError(t, err)
 
-			tc.setupAuth(t, request, server.tokenMaker)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shevgn/simplebank/bulk"
+	"github.com/shevgn/simplebank/token"
+)
+
+// maxBulkTransferBytes bounds the size of an uploaded batch.
+const maxBulkTransferBytes = 4 << 20
+
+// bulkTransferFormats maps the media types a batch may be uploaded as to
+// its format.
+var bulkTransferFormats = map[string]string{
+	"text/csv":        bulk.FormatCSV,
+	"application/xml": bulk.FormatPain001,
+	"text/xml":        bulk.FormatPain001,
+}
+
+func bulkTransferContentTypes() []string {
+	contentTypes := make([]string, 0, len(bulkTransferFormats))
+	for contentType := range bulkTransferFormats {
+		contentTypes = append(contentTypes, contentType)
+	}
+
+	slices.Sort(contentTypes)
+
+	return contentTypes
+}
+
+type bulkTransferRequest struct {
+	Mode string `form:"mode" binding:"omitempty,oneof=atomic best_effort"`
+}
+
+func (s *Server) createBulkTransfer(ctx *gin.Context) {
+	var req bulkTransferRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.Mode == "" {
+		req.Mode = string(bulk.ModeAtomic)
+	}
+
+	format, ok := bulkTransferFormats[ctx.ContentType()]
+	if !ok {
+		err := fmt.Errorf("content type %q is not supported, upload text/csv or application/xml", ctx.ContentType())
+		ctx.JSON(http.StatusUnsupportedMediaType, errorResponse(err))
+		return
+	}
+
+	body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBulkTransferBytes)
+
+	instructions, err := bulk.Parse(format, body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	batch, err := bulk.Execute(ctx, s.store, authPayload.Username, instructions, bulk.Mode(req.Mode))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, batch)
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shevgn/simplebank/bulk"
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateBulkTransferAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account2.Currency = account1.Currency
+
+	file := fmt.Sprintf("from_account_id,to_account_id,amount,currency,reference\n"+
+		"%d,%d,10.00,%s,SALARY-1\n"+
+		"%d,%d,5.25,%s,SALARY-2\n",
+		account1.ID, account2.ID, account1.Currency,
+		account1.ID, account2.ID, account1.Currency)
+
+	stubAccounts := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+		store.EXPECT().IsSystemAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(false, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		contentType   string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "Atomic",
+			contentType: "text/csv",
+			body:        file,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+
+				arg := []db.TransferTxParams{
+					{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 1000},
+					{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 525},
+				}
+				store.EXPECT().
+					BulkTransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.TransferTxResult{{Transfer: db.Transfer{ID: 1}}, {Transfer: db.Transfer{ID: 2}}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var batch bulk.Batch
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &batch))
+				require.Equal(t, bulk.ModeAtomic, batch.Mode)
+				require.Equal(t, bulk.BatchCompleted, batch.Status)
+				require.Equal(t, 2, batch.Succeeded)
+				require.Equal(t, "SALARY-2", batch.Results[1].Reference)
+				require.Equal(t, int64(2), batch.Results[1].Transfer.ID)
+			},
+		},
+		{
+			name:        "BestEffort",
+			query:       "?mode=best_effort",
+			contentType: "text/csv; charset=utf-8",
+			body:        file,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(db.TransferTxResult{}, db.ErrAccountFrozen)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var batch bulk.Batch
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &batch))
+				require.Equal(t, bulk.ModeBestEffort, batch.Mode)
+				require.Equal(t, bulk.BatchFailed, batch.Status)
+				require.Equal(t, 2, batch.Failed)
+			},
+		},
+		{
+			name:        "InvalidMode",
+			query:       "?mode=eventually",
+			contentType: "text/csv",
+			body:        file,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "UnsupportedMediaType",
+			contentType: "application/json",
+			body:        "[]",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+			},
+		},
+		{
+			name:        "MalformedFile",
+			contentType: "application/xml",
+			body:        "<Document>",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "TooLarge",
+			contentType: "text/csv",
+			body:        "from_account_id,to_account_id,amount,currency,reference\n1,2,3,USD," + strings.Repeat("x", maxBulkTransferBytes),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers/bulk"+tc.query, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", tc.contentType)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shevgn/simplebank/health"
+)
+
+// healthz reports that the process is alive and able to serve requests.
+func (s *Server) healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, health.Report{Status: health.StatusOK})
+}
+
+// readyz reports whether the dependencies needed to serve traffic are up.
+func (s *Server) readyz(ctx *gin.Context) {
+	report := s.health.Check(ctx)
+	if report.Status != health.StatusOK {
+		ctx.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
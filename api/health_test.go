@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shevgn/simplebank/health"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		path          string
+		setupChecks   func(checker *health.Checker)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "Healthz",
+			path:        "/healthz",
+			setupChecks: func(_ *health.Checker) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "HealthzIgnoresChecks",
+			path: "/healthz",
+			setupChecks: func(checker *health.Checker) {
+				checker.Register("database", func(_ context.Context) error { return errors.New("down") })
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Ready",
+			path: "/readyz",
+			setupChecks: func(checker *health.Checker) {
+				checker.Register("database", func(_ context.Context) error { return nil })
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var report health.Report
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+				require.Equal(t, health.StatusOK, report.Checks["database"].Status)
+			},
+		},
+		{
+			name: "NotReady",
+			path: "/readyz",
+			setupChecks: func(checker *health.Checker) {
+				checker.Register("database", func(_ context.Context) error { return nil })
+				checker.Register("migrations", func(_ context.Context) error {
+					return errors.New("schema version is 2, expected 3")
+				})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+				var report health.Report
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+				require.Equal(t, health.StatusFailed, report.Status)
+				require.Equal(t, "schema version is 2, expected 3", report.Checks["migrations"].Error)
+			},
+		},
+		{
+			name: "ShuttingDown",
+			path: "/readyz",
+			setupChecks: func(checker *health.Checker) {
+				checker.SetShuttingDown()
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := NewTestServer(t, nil)
+			tc.setupChecks(server.health)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, tc.path, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shevgn/simplebank/logging"
+	"github.com/shevgn/simplebank/token"
+)
+
+// maxLoggedBodySize caps how much of a request body is read for the debug
+// log; larger bodies are logged as redacted.
+const maxLoggedBodySize = 64 << 10
+
+// requestIDMiddleware reuses the caller's X-Request-ID, or generates one,
+// echoes it in the response and stores it in the request context.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := logging.RequestIDOrNew(ctx.GetHeader(logging.RequestIDHeader))
+
+		ctx.Header(logging.RequestIDHeader, requestID)
+		ctx.Request = ctx.Request.WithContext(logging.WithRequestID(ctx.Request.Context(), requestID))
+
+		ctx.Next()
+	}
+}
+
+// loggerMiddleware writes one structured log line per request. Request
+// bodies are only logged at debug level, with secrets redacted.
+func loggerMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		path := ctx.Request.URL.Path
+		if isQuiet(path) {
+			ctx.Next()
+			return
+		}
+
+		logger := slog.Default()
+		start := time.Now()
+
+		var body []byte
+		if logger.Enabled(ctx, slog.LevelDebug) && ctx.Request.Body != nil {
+			body = peekBody(ctx.Request)
+		}
+
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		status := ctx.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String("method", ctx.Request.Method),
+			slog.String("route", route),
+			slog.String("path", path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", ctx.ClientIP()),
+			slog.Int("bytes", ctx.Writer.Size()),
+		}
+		// The request context is restored once inner middleware return, so
+		// the user is taken from the payload left by authMiddleware.
+		if payload, ok := ctx.Get(authorizationPayloadKey); ok {
+			attrs = append(attrs, slog.String("username", payload.(*token.Payload).Username))
+		}
+		if body != nil {
+			attrs = append(attrs, slog.Any("request_body", logging.RedactJSON(body)))
+		}
+		if len(ctx.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", ctx.Errors.String()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		logger.LogAttrs(ctx.Request.Context(), level, "http request", attrs...)
+	}
+}
+
+// recoveryMiddleware turns panics into 500 responses and logs them with the
+// stack trace.
+func recoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(ctx *gin.Context, err any) {
+		slog.ErrorContext(ctx.Request.Context(), "panic recovered",
+			slog.Any("error", err),
+			slog.String("stack", string(debug.Stack())),
+		)
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
+
+// peekBody reads the start of the request body for logging and puts it back
+// so handlers still see the full body.
+func peekBody(req *http.Request) []byte {
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxLoggedBodySize))
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+
+	if err != nil {
+		return nil
+	}
+
+	return body
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	"github.com/shevgn/simplebank/logging"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// captureLogs routes the default logger into a buffer for the duration of
+// the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&buf, slog.LevelDebug))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	return &buf
+}
+
+func lastLogLine(t *testing.T, buf *bytes.Buffer) map[string]any {
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &line))
+
+	return line
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	server := NewTestServer(t, nil)
+	captureLogs(t)
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	request.Header.Set(logging.RequestIDHeader, "client-id-1")
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, "client-id-1", recorder.Header().Get(logging.RequestIDHeader))
+
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	request.Header.Set(logging.RequestIDHeader, "not a valid id")
+	server.router.ServeHTTP(recorder, request)
+	require.NotEmpty(t, recorder.Header().Get(logging.RequestIDHeader))
+	require.NotEqual(t, "not a valid id", recorder.Header().Get(logging.RequestIDHeader))
+}
+
+func TestLoggerMiddlewareRedactsBody(t *testing.T) {
+	server := NewTestServer(t, nil)
+	logs := captureLogs(t)
+
+	body := `{"username":"al ice","password":"supersecret"}`
+	request := httptest.NewRequest(http.MethodPost, "/users/login", strings.NewReader(body))
+	request.Header.Set(logging.RequestIDHeader, "req-42")
+	server.router.ServeHTTP(httptest.NewRecorder(), request)
+
+	require.NotContains(t, logs.String(), "supersecret")
+
+	line := lastLogLine(t, logs)
+	require.Equal(t, "WARN", line["level"])
+	require.Equal(t, "req-42", line["request_id"])
+	require.Equal(t, "/users/login", line["route"])
+	require.EqualValues(t, http.StatusBadRequest, line["status"])
+	require.Equal(t, logging.Redacted, line["request_body"].(map[string]any)["password"])
+}
+
+func TestLoggerMiddlewareUsername(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+	server := NewTestServer(t, store)
+	logs := captureLogs(t)
+
+	request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d", account.ID), nil)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(httptest.NewRecorder(), request)
+
+	line := lastLogLine(t, logs)
+	require.Equal(t, "INFO", line["level"])
+	require.Equal(t, user.Username, line["username"])
+	require.Equal(t, "/accounts/:id", line["route"])
+	require.NotEmpty(t, line["request_id"])
+}
+
+func TestLoggerMiddlewareSkipsQuietPaths(t *testing.T) {
+	server := NewTestServer(t, nil)
+	logs := captureLogs(t)
+
+	server.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.Zero(t, logs.Len())
+}
@@ -1,26 +1,52 @@
 package api
 
 import (
+	"log/slog"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/health"
+	"github.com/shevgn/simplebank/notify"
+	"github.com/shevgn/simplebank/ratelimit"
 	"github.com/shevgn/simplebank/util"
+	"github.com/shevgn/simplebank/worker"
+	mockworker "github.com/shevgn/simplebank/worker/mock"
+	"go.uber.org/mock/gomock"
 )
 
 func NewTestServer(t *testing.T, store db.Store) *Server {
 	config := &util.Config{
-		TokenSymmetricKey:   util.RandomString(32),
-		AccessTokenDuration: time.Minute,
+		TokenSymmetricKey:       util.RandomString(32),
+		AccessTokenDuration:     time.Minute,
+		LoginRateLimitPerIP:     100,
+		LoginRateLimitPerUser:   100,
+		LoginRateLimitPeriod:    time.Minute,
+		LoginMaxFailedAttempts:  5,
+		LoginLockoutDuration:    time.Minute,
+		LoginMaxLockoutDuration: time.Hour,
+		PasswordResetURL:        "http://localhost:8080/reset_password",
+		PasswordResetDuration:   time.Hour,
+		WebhookAllowedNetworks:  "127.0.0.1",
 	}
 
-	return NewServer(config, store)
+	return NewServer(config, store, health.NewChecker(), ratelimit.NewMemoryLimiter(), newTestDistributor(t),
+		notify.NewHub())
+}
+
+// newTestDistributor accepts every task without running it.
+func newTestDistributor(t *testing.T) worker.Distributor {
+	distributor := mockworker.NewMockDistributor(gomock.NewController(t))
+	distributor.EXPECT().Enqueue(gomock.Any(), gomock.Any()).AnyTimes()
+
+	return distributor
 }
 
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
+	slog.SetDefault(slog.New(slog.DiscardHandler))
 
 	os.Exit(m.Run())
 }
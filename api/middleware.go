@@ -6,7 +6,11 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shevgn/simplebank/logging"
 	"github.com/shevgn/simplebank/token"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
 )
 
 const (
@@ -15,37 +19,52 @@ const (
 	authorizationPayloadKey = "auth_payload"
 )
 
+var tracer = otel.Tracer("github.com/shevgn/simplebank/api")
+
 func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		header := ctx.GetHeader(authorizationHeaderKey)
-		if len(header) == 0 {
-			err := errors.New("missing authorization header")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
-			return
-		}
+		_, span := tracer.Start(ctx.Request.Context(), "authMiddleware")
 
-		fileds := strings.Fields(header)
-		if len(fileds) != 2 {
-			err := errors.New("invalid authorization header")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
-			return
-		}
+		payload, err := authorizeRequest(ctx, tokenMaker)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			span.End()
 
-		authType := fileds[0]
-		if !strings.EqualFold(authType, authorizationTypeBearer) {
-			err := errors.New("invalid authorization type")
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
 			return
 		}
 
-		accessToken := fileds[1]
-		payload, err := tokenMaker.VerifyToken(accessToken)
-		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
-			return
-		}
+		span.SetAttributes(semconv.EnduserID(payload.Username))
+		span.End()
 
 		ctx.Set(authorizationPayloadKey, payload)
+		ctx.Request = ctx.Request.WithContext(logging.WithUsername(ctx.Request.Context(), payload.Username))
 		ctx.Next()
 	}
 }
+
+func authorizeRequest(ctx *gin.Context, tokenMaker token.Maker) (*token.Payload, error) {
+	header := ctx.GetHeader(authorizationHeaderKey)
+	if len(header) == 0 {
+		if accessToken, ok := websocketToken(ctx.Request); ok {
+			return tokenMaker.VerifyToken(accessToken)
+		}
+
+		return nil, errors.New("missing authorization header")
+	}
+
+	fileds := strings.Fields(header)
+	if len(fileds) != 2 {
+		return nil, errors.New("invalid authorization header")
+	}
+
+	authType := fileds[0]
+	if !strings.EqualFold(authType, authorizationTypeBearer) {
+		return nil, errors.New("invalid authorization type")
+	}
+
+	accessToken := fileds[1]
+
+	return tokenMaker.VerifyToken(accessToken)
+}
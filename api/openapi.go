@@ -0,0 +1,789 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shevgn/simplebank/bulk"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/health"
+	"github.com/shevgn/simplebank/statement"
+	"github.com/shevgn/simplebank/util"
+	"github.com/shevgn/simplebank/webhook"
+)
+
+const bearerAuthScheme = "bearerAuth"
+
+// apiOperation documents one route registered in registerRoutes. Params is a
+// struct with `uri` or `form` tags, Body and Response are the JSON payloads.
+// A response sent as another media type, such as an event stream, names it
+// in ContentType; Response then describes each message. A file download
+// lists the media types it can be sent as in Files instead of a Response,
+// and a file upload lists the media types it accepts in Uploads instead of a
+// Body.
+// Error responses use the Error schema unless ErrorBody says otherwise.
+type apiOperation struct {
+	Method      string
+	Path        string
+	Summary     string
+	Description string
+	Tag         string
+	Auth        bool
+	Params      any
+	Body        any
+	Uploads     []string
+	Status      int
+	Response    any
+	ContentType string
+	Files       []string
+	Errors      []int
+	ErrorBody   any
+}
+
+// apiOperations is the source of the OpenAPI document. Every route added to
+// registerRoutes needs an entry here, which TestOpenAPICoversAllRoutes checks.
+var apiOperations = []apiOperation{
+	{
+		Method:   http.MethodGet,
+		Path:     "/healthz",
+		Summary:  "Liveness probe",
+		Tag:      "health",
+		Status:   http.StatusOK,
+		Response: health.Report{},
+	},
+	{
+		Method:    http.MethodGet,
+		Path:      "/readyz",
+		Summary:   "Readiness probe with the status of each dependency",
+		Tag:       "health",
+		Status:    http.StatusOK,
+		Response:  health.Report{},
+		Errors:    []int{http.StatusServiceUnavailable},
+		ErrorBody: health.Report{},
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/users",
+		Summary:  "Create a user",
+		Tag:      "users",
+		Body:     createUserRequest{},
+		Status:   http.StatusOK,
+		Response: userResponse{},
+		Errors:   []int{http.StatusBadRequest, http.StatusConflict, http.StatusInternalServerError},
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/users/login",
+		Summary:  "Log in and open a session",
+		Tag:      "users",
+		Body:     loginUserRequest{},
+		Status:   http.StatusOK,
+		Response: loginUserResponse{},
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusLocked,
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+		},
+	},
+	{
+		Method:   http.MethodPut,
+		Path:     "/users/password",
+		Summary:  "Change the current user's password and block their other sessions",
+		Tag:      "users",
+		Auth:     true,
+		Body:     changePasswordRequest{},
+		Status:   http.StatusOK,
+		Response: passwordResponse{},
+		Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError},
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/users/password/reset_request",
+		Summary:  "Queue a single-use password reset link for mailing",
+		Tag:      "users",
+		Body:     requestPasswordResetRequest{},
+		Status:   http.StatusAccepted,
+		Response: requestPasswordResetResponse{},
+		Errors:   []int{http.StatusBadRequest, http.StatusTooManyRequests},
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/users/password/reset",
+		Summary:  "Set a new password with a reset token and block all sessions",
+		Tag:      "users",
+		Body:     resetPasswordRequest{},
+		Status:   http.StatusOK,
+		Response: passwordResponse{},
+		Errors:   []int{http.StatusBadRequest, http.StatusTooManyRequests, http.StatusInternalServerError},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/users/verify_email",
+		Summary:  "Verify an email with the token from the verification link",
+		Tag:      "users",
+		Params:   verifyEmailRequest{},
+		Status:   http.StatusOK,
+		Response: userResponse{},
+		Errors:   []int{http.StatusBadRequest, http.StatusInternalServerError},
+	},
+	{
+		Method:  http.MethodPost,
+		Path:    "/users/verify_email/resend",
+		Summary: "Send the current user a new verification email",
+		Tag:     "users",
+		Auth:    true,
+		Status:  http.StatusAccepted,
+		Errors:  []int{http.StatusUnauthorized, http.StatusConflict, http.StatusInternalServerError},
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/tokens/renew_access",
+		Summary:  "Issue a new access token from a refresh token",
+		Tag:      "tokens",
+		Body:     renewAccessTokenRequest{},
+		Status:   http.StatusOK,
+		Response: renewAccessTokenResponse{},
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusUnauthorized,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	},
+	{
+		Method:  http.MethodGet,
+		Path:    "/accounts/stream",
+		Summary: "Stream balance changes of the current user's accounts",
+		Description: "Sends an event for every committed entry on the user's accounts as Server-Sent Events, " +
+			"or as JSON text messages on a WebSocket when the request is an upgrade. Browsers may pass the " +
+			"access token as the subprotocol \"bearer.<token>\" next to \"simplebank.v1\". The stream ends " +
+			"when updates may have been missed; reload balances before reconnecting.",
+		Tag:         "accounts",
+		Auth:        true,
+		Status:      http.StatusOK,
+		Response:    accountEventResponse{},
+		ContentType: "text/event-stream",
+		Errors:      []int{http.StatusBadRequest},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/accounts/:id",
+		Summary:  "Get an account of the current user",
+		Tag:      "accounts",
+		Auth:     true,
+		Params:   GetAccountRequest{},
+		Status:   http.StatusOK,
+		Response: db.Account{},
+		Errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	{
+		Method:  http.MethodGet,
+		Path:    "/accounts/:id/statement",
+		Summary: "Download a statement of an account of the current user",
+		Description: "Lists the entries booked between from and to, both inclusive and in UTC, with their " +
+			"counterparty and the running balance, between the opening and closing balance. The format " +
+			"defaults to json; the file is streamed, so a failure part way through leaves it truncated.",
+		Tag:  "accounts",
+		Auth: true,
+		Params: struct {
+			GetAccountRequest
+			statementRequest
+		}{},
+		Status: http.StatusOK,
+		Files:  statementContentTypes(),
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/accounts",
+		Summary:  "List accounts of the current user",
+		Tag:      "accounts",
+		Auth:     true,
+		Params:   ListAccountsRequest{},
+		Status:   http.StatusOK,
+		Response: []db.Account{},
+		Errors:   []int{http.StatusBadRequest, http.StatusInternalServerError},
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/accounts",
+		Summary:  "Open an account for the current user",
+		Tag:      "accounts",
+		Auth:     true,
+		Body:     CreateAccountRequest{},
+		Status:   http.StatusOK,
+		Response: db.Account{},
+		Errors:   []int{http.StatusBadRequest, http.StatusConflict, http.StatusInternalServerError},
+	},
+	{
+		Method:      http.MethodDelete,
+		Path:        "/accounts/:id",
+		Summary:     "Close an account",
+		Description: "Same as POST /accounts/{id}/close. The account and its history are kept.",
+		Tag:         "accounts",
+		Auth:        true,
+		Params:      DeleteAccountRequest{},
+		Status:      http.StatusNoContent,
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusInternalServerError,
+		},
+	},
+	{
+		Method:  http.MethodPost,
+		Path:    "/accounts/:id/close",
+		Summary: "Close an account",
+		Description: "Only an active account with a zero balance can be closed. A closed account keeps its " +
+			"entries and transfers, takes no transfers and can be reopened.",
+		Tag:      "accounts",
+		Auth:     true,
+		Params:   GetAccountRequest{},
+		Status:   http.StatusOK,
+		Response: db.Account{},
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusInternalServerError,
+		},
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/accounts/:id/reopen",
+		Summary:  "Reopen a closed account",
+		Tag:      "accounts",
+		Auth:     true,
+		Params:   GetAccountRequest{},
+		Status:   http.StatusOK,
+		Response: db.Account{},
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusInternalServerError,
+		},
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/transfers",
+		Summary:  "Transfer money between two accounts",
+		Tag:      "transfers",
+		Auth:     true,
+		Body:     CreateTransferRequest{},
+		Status:   http.StatusOK,
+		Response: db.TransferTxResult{},
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusInternalServerError,
+		},
+	},
+	{
+		Method:  http.MethodPost,
+		Path:    "/transfers/quote",
+		Summary: "Show the fee a transfer would be charged, without making it",
+		Description: "Checks the transfer as POST /transfers does and returns the fee the current rules " +
+			"charge the sender for it. The fee is booked to the bank as a transfer of its own.",
+		Tag:      "transfers",
+		Auth:     true,
+		Body:     CreateTransferRequest{},
+		Status:   http.StatusOK,
+		Response: db.TransferQuote{},
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusInternalServerError,
+		},
+	},
+	{
+		Method:  http.MethodPost,
+		Path:    "/transfers/bulk",
+		Summary: "Execute a batch of transfers from a CSV or pain.001 file",
+		Description: "A CSV batch has a header row with the columns from_account_id, to_account_id, amount " +
+			"and currency, and optionally reference, then a row per transfer with the amount as a decimal. " +
+			"A pain.001 batch is an ISO 20022 customer credit transfer initiation whose accounts are " +
+			"identified in Othr/Id. Every transfer is validated first. An atomic batch is rejected if any " +
+			"transfer is invalid and otherwise books them all or none; a best_effort batch skips invalid " +
+			"transfers and books the rest one by one. The results report the outcome of each transfer.",
+		Tag:      "transfers",
+		Auth:     true,
+		Params:   bulkTransferRequest{},
+		Uploads:  bulkTransferContentTypes(),
+		Status:   http.StatusOK,
+		Response: bulk.Batch{},
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusRequestEntityTooLarge,
+			http.StatusUnsupportedMediaType,
+			http.StatusInternalServerError,
+		},
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/webhooks",
+		Summary:  "Register a webhook endpoint; the response holds its signing secret",
+		Tag:      "webhooks",
+		Auth:     true,
+		Body:     createWebhookEndpointRequest{},
+		Status:   http.StatusOK,
+		Response: createWebhookEndpointResponse{},
+		Errors:   []int{http.StatusBadRequest, http.StatusInternalServerError},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/webhooks",
+		Summary:  "List webhook endpoints of the current user",
+		Tag:      "webhooks",
+		Auth:     true,
+		Status:   http.StatusOK,
+		Response: []webhookEndpointResponse{},
+		Errors:   []int{http.StatusInternalServerError},
+	},
+	{
+		Method:  http.MethodDelete,
+		Path:    "/webhooks/:id",
+		Summary: "Delete a webhook endpoint and its delivery log",
+		Tag:     "webhooks",
+		Auth:    true,
+		Params:  webhookEndpointRequest{},
+		Status:  http.StatusNoContent,
+		Errors:  []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	{
+		Method:  http.MethodGet,
+		Path:    "/webhooks/:id/deliveries",
+		Summary: "List deliveries to a webhook endpoint, newest first",
+		Tag:     "webhooks",
+		Auth:    true,
+		Params: struct {
+			webhookEndpointRequest
+			listWebhookDeliveriesRequest
+		}{},
+		Status:   http.StatusOK,
+		Response: []webhookDeliveryResponse{},
+		Errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/webhooks/:id/test",
+		Summary:  "Send a test event to a webhook endpoint and return the delivery",
+		Tag:      "webhooks",
+		Auth:     true,
+		Params:   webhookEndpointRequest{},
+		Status:   http.StatusOK,
+		Response: webhookDeliveryResponse{},
+		Errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+}
+
+// internalRoutes serve the documentation itself and Prometheus metrics, and
+// are left out of the spec.
+var internalRoutes = map[string]bool{
+	"GET /openapi.json":      true,
+	"GET /swagger/*filepath": true,
+	"GET /metrics":           true,
+}
+
+var ginPathParam = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+// openAPIPath converts a gin route path such as /accounts/:id to the
+// OpenAPI template /accounts/{id}.
+func openAPIPath(path string) string {
+	return ginPathParam.ReplaceAllString(path, "{$1}")
+}
+
+// newOpenAPISpec builds the OpenAPI 3 document from apiOperations, deriving
+// schemas from the request and response types and their binding tags.
+func newOpenAPISpec() (*openapi3.T, error) {
+	gen := &schemaGenerator{schemas: openapi3.Schemas{}}
+
+	gen.schemas["Error"] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+		WithProperty("error", openapi3.NewStringSchema()).
+		WithRequired([]string{"error"}))
+
+	spec := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:       "Simple Bank API",
+			Description: "HTTP API for managing users, accounts and transfers.",
+			Version:     "1.0.0",
+		},
+		Paths: openapi3.NewPaths(),
+		Components: &openapi3.Components{
+			Schemas: gen.schemas,
+			SecuritySchemes: openapi3.SecuritySchemes{
+				bearerAuthScheme: &openapi3.SecuritySchemeRef{
+					Value: openapi3.NewJWTSecurityScheme(),
+				},
+			},
+		},
+	}
+
+	for _, op := range apiOperations {
+		path := openAPIPath(op.Path)
+
+		item := spec.Paths.Value(path)
+		if item == nil {
+			item = &openapi3.PathItem{}
+			spec.Paths.Set(path, item)
+		}
+
+		if item.GetOperation(op.Method) != nil {
+			return nil, fmt.Errorf("duplicate operation %s %s", op.Method, op.Path)
+		}
+
+		item.SetOperation(op.Method, gen.operation(op))
+	}
+
+	if err := spec.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+
+	return spec, nil
+}
+
+func (g *schemaGenerator) operation(op apiOperation) *openapi3.Operation {
+	operation := &openapi3.Operation{
+		Summary:     op.Summary,
+		Description: op.Description,
+		Tags:        []string{op.Tag},
+		OperationID: operationID(op),
+		Responses:   openapi3.NewResponsesWithCapacity(len(op.Errors) + 2),
+	}
+
+	if op.Params != nil {
+		operation.Parameters = g.parameters(reflect.TypeOf(op.Params))
+	}
+
+	if op.Body != nil {
+		body := openapi3.NewRequestBody().WithRequired(true).WithJSONSchemaRef(g.ref(reflect.TypeOf(op.Body)))
+		operation.RequestBody = &openapi3.RequestBodyRef{Value: body}
+	}
+
+	if len(op.Uploads) > 0 {
+		file := openapi3.NewStringSchema().WithFormat("binary")
+		body := openapi3.NewRequestBody().WithRequired(true).WithContent(openapi3.NewContentWithSchema(file, op.Uploads))
+		operation.RequestBody = &openapi3.RequestBodyRef{Value: body}
+	}
+
+	if op.Auth {
+		operation.Security = openapi3.NewSecurityRequirements().
+			With(openapi3.NewSecurityRequirement().Authenticate(bearerAuthScheme))
+		op.Errors = append(op.Errors, http.StatusUnauthorized)
+	}
+
+	success := openapi3.NewResponse().WithDescription(http.StatusText(op.Status))
+	switch {
+	case op.Response != nil && op.ContentType != "":
+		success.WithContent(openapi3.NewContentWithSchemaRef(g.ref(reflect.TypeOf(op.Response)), []string{op.ContentType}))
+	case op.Response != nil:
+		success.WithJSONSchemaRef(g.ref(reflect.TypeOf(op.Response)))
+	case len(op.Files) > 0:
+		file := openapi3.NewStringSchema().WithFormat("binary")
+		success.WithContent(openapi3.NewContentWithSchema(file, op.Files))
+	}
+
+	operation.Responses.Set(strconv.Itoa(op.Status), &openapi3.ResponseRef{Value: success})
+
+	errorSchema := openapi3.NewSchemaRef("#/components/schemas/Error", g.schemas["Error"].Value)
+	if op.ErrorBody != nil {
+		errorSchema = g.ref(reflect.TypeOf(op.ErrorBody))
+	}
+
+	for _, code := range op.Errors {
+		response := openapi3.NewResponse().
+			WithDescription(http.StatusText(code)).
+			WithJSONSchemaRef(errorSchema)
+		operation.Responses.Set(strconv.Itoa(code), &openapi3.ResponseRef{Value: response})
+	}
+
+	return operation
+}
+
+// parameters documents the `uri` and `form` fields of a params struct as path
+// and query parameters.
+func (g *schemaGenerator) parameters(t reflect.Type) openapi3.Parameters {
+	var params openapi3.Parameters
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		// Operations with both path and query parameters embed a struct for
+		// each, as gin binds them separately.
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			params = append(params, g.parameters(field.Type)...)
+			continue
+		}
+
+		schema, required := g.field(field)
+
+		var param *openapi3.Parameter
+		if name := field.Tag.Get("uri"); name != "" {
+			param = openapi3.NewPathParameter(name)
+		} else if name := field.Tag.Get("form"); name != "" {
+			param = openapi3.NewQueryParameter(name).WithRequired(required)
+		} else {
+			continue
+		}
+
+		param.Schema = schema
+		params = append(params, &openapi3.ParameterRef{Value: param})
+	}
+
+	return params
+}
+
+func operationID(op apiOperation) string {
+	parts := []string{strings.ToLower(op.Method)}
+	for _, segment := range strings.Split(op.Path, "/") {
+		segment = strings.TrimPrefix(segment, ":")
+		if segment != "" {
+			parts = append(parts, segment)
+		}
+	}
+
+	return strings.Join(parts, "_")
+}
+
+// schemaGenerator converts Go types into OpenAPI schemas, registering named
+// structs as reusable components.
+type schemaGenerator struct {
+	schemas openapi3.Schemas
+}
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	timestampType   = reflect.TypeOf(pgtype.Timestamp{})
+	timestamptzType = reflect.TypeOf(pgtype.Timestamptz{})
+	dateType        = reflect.TypeOf(pgtype.Date{})
+	uuidType        = reflect.TypeOf(uuid.UUID{})
+	rawMessageType  = reflect.TypeOf(json.RawMessage{})
+)
+
+func (g *schemaGenerator) ref(t reflect.Type) *openapi3.SchemaRef {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType, timestampType, timestamptzType:
+		return openapi3.NewSchemaRef("", openapi3.NewDateTimeSchema())
+	case dateType:
+		return openapi3.NewSchemaRef("", openapi3.NewStringSchema().WithFormat("date"))
+	case uuidType:
+		return openapi3.NewSchemaRef("", openapi3.NewUUIDSchema())
+	case rawMessageType:
+		return openapi3.NewSchemaRef("", &openapi3.Schema{})
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return openapi3.NewSchemaRef("", openapi3.NewStringSchema())
+	case reflect.Bool:
+		return openapi3.NewSchemaRef("", openapi3.NewBoolSchema())
+	case reflect.Int, reflect.Int64, reflect.Uint64:
+		return openapi3.NewSchemaRef("", openapi3.NewInt64Schema())
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return openapi3.NewSchemaRef("", openapi3.NewInt32Schema())
+	case reflect.Float32, reflect.Float64:
+		return openapi3.NewSchemaRef("", openapi3.NewFloat64Schema())
+	case reflect.Slice, reflect.Array:
+		schema := openapi3.NewArraySchema()
+		schema.Items = g.ref(t.Elem())
+		return openapi3.NewSchemaRef("", schema)
+	case reflect.Map:
+		schema := openapi3.NewObjectSchema()
+		schema.AdditionalProperties = openapi3.AdditionalProperties{Schema: g.ref(t.Elem())}
+		return openapi3.NewSchemaRef("", schema)
+	case reflect.Struct:
+		return g.structRef(t)
+	default:
+		return openapi3.NewSchemaRef("", &openapi3.Schema{})
+	}
+}
+
+func (g *schemaGenerator) structRef(t reflect.Type) *openapi3.SchemaRef {
+	name := componentName(t)
+	ref := "#/components/schemas/" + name
+
+	if component, ok := g.schemas[name]; ok {
+		return openapi3.NewSchemaRef(ref, component.Value)
+	}
+
+	// Register the component before filling it in so that recursive types
+	// terminate.
+	schema := openapi3.NewObjectSchema()
+	g.schemas[name] = openapi3.NewSchemaRef("", schema)
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if jsonName == "-" {
+			continue
+		}
+
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+
+		property, required := g.field(field)
+		schema.WithPropertyRef(jsonName, property)
+
+		if required {
+			schema.Required = append(schema.Required, jsonName)
+		}
+	}
+
+	return openapi3.NewSchemaRef(ref, schema)
+}
+
+// field returns the schema of a struct field with the constraints of its
+// binding tag applied, and whether the field is required.
+func (g *schemaGenerator) field(field reflect.StructField) (*openapi3.SchemaRef, bool) {
+	property := g.ref(field.Type)
+
+	rules := field.Tag.Get("binding")
+	if rules == "" || property.Ref != "" {
+		return property, strings.Contains(rules, "required")
+	}
+
+	return applyRules(property, strings.Split(rules, ","))
+}
+
+// applyRules returns a copy of property constrained by binding rules, and
+// whether the rules require a value. Rules after "dive" apply to the items
+// of an array.
+func applyRules(property *openapi3.SchemaRef, rules []string) (*openapi3.SchemaRef, bool) {
+	schema := *property.Value
+	required := false
+
+	for i, rule := range rules {
+		name, value, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "dive":
+			if schema.Items != nil && schema.Items.Ref == "" {
+				schema.Items, _ = applyRules(schema.Items, rules[i+1:])
+			}
+
+			return openapi3.NewSchemaRef("", &schema), required
+		case "required":
+			required = true
+		case "min", "max", "gt":
+			applyBound(&schema, name, value)
+		case "email":
+			schema.Format = "email"
+		case "http_url":
+			schema.Format = "uri"
+		case "alphanum":
+			schema.Pattern = "^[a-zA-Z0-9]+$"
+		case "currency":
+			for _, currency := range util.SupportedCurrencies() {
+				schema.Enum = append(schema.Enum, currency)
+			}
+		case "account_type":
+			for _, accountType := range db.AccountTypes {
+				schema.Enum = append(schema.Enum, accountType)
+			}
+		case "webhook_event":
+			for _, eventType := range webhook.EventTypes {
+				schema.Enum = append(schema.Enum, eventType)
+			}
+		case "datetime":
+			if value == statement.DateLayout {
+				schema.Format = "date"
+			}
+		case "statement_format":
+			for _, format := range statement.FormatNames() {
+				schema.Enum = append(schema.Enum, format)
+			}
+		case "oneof":
+			for _, option := range strings.Fields(value) {
+				schema.Enum = append(schema.Enum, option)
+			}
+		}
+	}
+
+	return openapi3.NewSchemaRef("", &schema), required
+}
+
+func applyBound(schema *openapi3.Schema, rule string, value string) {
+	bound, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return
+	}
+
+	if schema.Type.Is(openapi3.TypeString) {
+		length := uint64(bound)
+		switch rule {
+		case "min":
+			schema.MinLength = length
+		case "max":
+			schema.MaxLength = &length
+		}
+
+		return
+	}
+
+	switch rule {
+	case "min":
+		schema.Min = &bound
+	case "max":
+		schema.Max = &bound
+	case "gt":
+		schema.Min = &bound
+		schema.ExclusiveMin = true
+	}
+}
+
+// componentName exports the type name, so that unexported request types
+// such as createUserRequest appear as CreateUserRequest.
+func componentName(t reflect.Type) string {
+	name := t.Name()
+	if name == "" {
+		return "Anonymous"
+	}
+
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func mustMarshalOpenAPISpec() []byte {
+	spec, err := newOpenAPISpec()
+	if err != nil {
+		panic(err)
+	}
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		panic(err)
+	}
+
+	return data
+}
+
+func (s *Server) getOpenAPISpec(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "application/json; charset=utf-8", s.openAPISpec)
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPICoversAllRoutes(t *testing.T) {
+	server := NewTestServer(t, nil)
+
+	spec, err := newOpenAPISpec()
+	require.NoError(t, err)
+
+	registered := make(map[string]bool)
+	for _, route := range server.router.Routes() {
+		key := route.Method + " " + route.Path
+		if internalRoutes[key] {
+			continue
+		}
+
+		registered[key] = true
+
+		item := spec.Paths.Value(openAPIPath(route.Path))
+		require.NotNil(t, item, "route %s has no OpenAPI path", key)
+		require.NotNil(t, item.GetOperation(route.Method), "route %s has no OpenAPI operation", key)
+	}
+
+	for _, op := range apiOperations {
+		key := op.Method + " " + op.Path
+		require.True(t, registered[key], "OpenAPI operation %s has no registered route", key)
+	}
+}
+
+func TestOpenAPISchemas(t *testing.T) {
+	spec, err := newOpenAPISpec()
+	require.NoError(t, err)
+
+	createUser := spec.Components.Schemas["CreateUserRequest"].Value
+	require.NotNil(t, createUser)
+	require.ElementsMatch(t, []string{"username", "password", "full_name", "email"}, createUser.Required)
+	require.Equal(t, "email", createUser.Properties["email"].Value.Format)
+	require.Equal(t, uint64(8), createUser.Properties["password"].Value.MinLength)
+
+	createTransfer := spec.Components.Schemas["CreateTransferRequest"].Value
+	require.NotNil(t, createTransfer)
+	require.True(t, createTransfer.Properties["amount"].Value.ExclusiveMin)
+	require.Equal(t, []any{"USD", "EUR"}, createTransfer.Properties["currency"].Value.Enum)
+
+	account := spec.Components.Schemas["Account"].Value
+	require.NotNil(t, account)
+	require.Equal(t, "date-time", account.Properties["created_at"].Value.Format)
+
+	fee := spec.Components.Schemas["Fee"].Value
+	require.NotNil(t, fee)
+	require.Equal(t, "date", fee.Properties["month"].Value.Format)
+
+	getAccount := spec.Paths.Value("/accounts/{id}").Get
+	require.NotNil(t, getAccount)
+	require.Len(t, getAccount.Parameters, 1)
+	require.Equal(t, openapi3.ParameterInPath, getAccount.Parameters[0].Value.In)
+	require.NotNil(t, getAccount.Security)
+	require.NotNil(t, getAccount.Responses.Status(http.StatusUnauthorized))
+}
+
+func TestServeOpenAPIDocs(t *testing.T) {
+	server := NewTestServer(t, nil)
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	loader := openapi3.NewLoader()
+	spec, err := loader.LoadFromData(recorder.Body.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, spec.Validate(context.Background()))
+
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, "/swagger/", nil)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.True(t, strings.Contains(recorder.Body.String(), "/openapi.json"))
+
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, "/swagger/swagger-ui-bundle.js", nil)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/token"
+	"github.com/shevgn/simplebank/util"
+	"github.com/shevgn/simplebank/worker"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var errIncorrectPassword = errors.New("incorrect password")
+
+// passwordResponse is returned once a password has been changed or reset.
+type passwordResponse struct {
+	User            userResponse `json:"user"`
+	BlockedSessions int64        `json:"blocked_sessions"`
+}
+
+// changePasswordRequest represents a request to change the caller's
+// password. Every session other than the one the access token was issued
+// for is blocked.
+type changePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required,min=8"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+func (s *Server) changePassword(ctx *gin.Context) {
+	var req changePasswordRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := s.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err := util.CheckPassword(req.OldPassword, user.HashedPassword); err != nil {
+		s.recordFailedLogin(ctx, user.Username)
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errIncorrectPassword))
+		return
+	}
+
+	hashedPassword, err := util.HashPassword(req.NewPassword)
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	result, err := s.store.ChangePasswordTx(ctx, db.ChangePasswordTxParams{
+		Username:       user.Username,
+		HashedPassword: hashedPassword,
+		KeepSessionID:  authPayload.SessionID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, passwordResponse{
+		User:            newUserResponse(result.User),
+		BlockedSessions: result.BlockedSessions,
+	})
+}
+
+// requestPasswordResetRequest represents a request to mail a password reset
+// link.
+type requestPasswordResetRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// requestPasswordResetResponse is the same whether or not the email belongs
+// to a user, so the endpoint cannot be used to discover accounts.
+type requestPasswordResetResponse struct {
+	Message string `json:"message"`
+}
+
+const passwordResetRequestedMessage = "if the email belongs to an active user, a reset link will be sent"
+
+// requestPasswordReset queues the reset email and answers right away, so
+// neither the response nor its timing depend on whether the email belongs
+// to a user. A task that cannot be queued is logged; the user can ask again.
+func (s *Server) requestPasswordReset(ctx *gin.Context) {
+	var req requestPasswordResetRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	task, err := worker.NewSendPasswordResetTask(req.Email)
+	if err == nil {
+		err = s.distributor.Enqueue(ctx, task)
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Cannot queue password reset email", slog.Any("error", err))
+	}
+
+	ctx.JSON(http.StatusAccepted, requestPasswordResetResponse{Message: passwordResetRequestedMessage})
+}
+
+// resetPasswordRequest represents a request to redeem a password reset
+// token.
+type resetPasswordRequest struct {
+	Token       string `json:"token"        binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+func (s *Server) resetPassword(ctx *gin.Context) {
+	var req resetPasswordRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hashedPassword, err := util.HashPassword(req.NewPassword)
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	result, err := s.store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		TokenHash:      token.HashOpaqueToken(req.Token),
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidResetToken) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, passwordResponse{
+		User:            newUserResponse(result.User),
+		BlockedSessions: result.BlockedSessions,
+	})
+}
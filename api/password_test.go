@@ -0,0 +1,347 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	mockmail "github.com/shevgn/simplebank/mail/mock"
+	"github.com/shevgn/simplebank/token"
+	"github.com/shevgn/simplebank/util"
+	"github.com/shevgn/simplebank/worker"
+	mockworker "github.com/shevgn/simplebank/worker/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestChangePasswordAPI(t *testing.T) {
+	user, password := randomUser(t)
+	sessionID := uuid.New()
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"old_password": password,
+				"new_password": "new-secret-password",
+				// A session named by the client is ignored.
+				"session_id": uuid.NewString(),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				accessToken, _, err := tokenMaker.CreateSessionToken(user.Username, sessionID, time.Minute)
+				require.NoError(t, err)
+
+				request.Header.Set(authorizationHeaderKey, authorizationTypeBearer+" "+accessToken)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ChangePasswordTxParams) (db.PasswordTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, sessionID, arg.KeepSessionID)
+						require.NoError(t, util.CheckPassword("new-secret-password", arg.HashedPassword))
+
+						return db.PasswordTxResult{User: user, BlockedSessions: 2}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response passwordResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, user.Username, response.User.Username)
+				require.Equal(t, int64(2), response.BlockedSessions)
+			},
+		},
+		{
+			name: "IncorrectPassword",
+			body: gin.H{
+				"old_password": "wrong-password",
+				"new_password": "new-secret-password",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					RecordFailedLogin(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: gin.H{
+				"old_password": password,
+				"new_password": "new-secret-password",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, "/users/password", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRequestPasswordResetAPI(t *testing.T) {
+	email := util.RandomEmail()
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(distributor *mockworker.MockDistributor)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"email": email},
+			buildStubs: func(distributor *mockworker.MockDistributor) {
+				task, err := worker.NewSendPasswordResetTask(email)
+				require.NoError(t, err)
+
+				distributor.EXPECT().
+					Enqueue(gomock.Any(), gomock.Eq(task)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name: "QueueError",
+			body: gin.H{"email": email},
+			buildStubs: func(distributor *mockworker.MockDistributor) {
+				distributor.EXPECT().
+					Enqueue(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidEmail",
+			body: gin.H{"email": "invalid"},
+			buildStubs: func(distributor *mockworker.MockDistributor) {
+				distributor.EXPECT().
+					Enqueue(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			distributor := mockworker.NewMockDistributor(ctrl)
+			tc.buildStubs(distributor)
+
+			server := NewTestServer(t, store)
+			server.distributor = distributor
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/password/reset_request", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestRequestPasswordResetIndistinguishable checks that a known email whose
+// mail cannot be delivered gets the same response as an unknown email.
+func TestRequestPasswordResetIndistinguishable(t *testing.T) {
+	user, _ := randomUser(t)
+	unknownEmail := util.RandomEmail()
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	mailer := mockmail.NewMockSender(ctrl)
+	distributor := mockworker.NewMockDistributor(ctrl)
+
+	store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).AnyTimes().Return(user, nil)
+	store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(unknownEmail)).AnyTimes().Return(db.User{}, db.ErrRecordNotFound)
+	store.EXPECT().CreatePasswordResetToken(gomock.Any(), gomock.Any()).AnyTimes().Return(db.PasswordResetToken{}, nil)
+	mailer.EXPECT().Send(gomock.Any(), gomock.Any()).AnyTimes().Return(errors.New("mail server unavailable"))
+
+	var tasks []worker.Task
+	distributor.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Times(2).
+		DoAndReturn(func(_ context.Context, task worker.Task) error {
+			tasks = append(tasks, task)
+			return nil
+		})
+
+	server := NewTestServer(t, store)
+	server.distributor = distributor
+
+	request := func(email string) *httptest.ResponseRecorder {
+		data, err := json.Marshal(gin.H{"email": email})
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, "/users/password/reset_request", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	known := request(user.Email)
+	unknown := request(unknownEmail)
+
+	require.Equal(t, http.StatusAccepted, known.Code)
+	require.Equal(t, unknown.Code, known.Code)
+	require.Equal(t, unknown.Body.String(), known.Body.String())
+
+	// The failed delivery only shows up in the task, which is retried.
+	handlers := worker.NewTaskHandlers(server.config, store, mailer).Handlers()
+	require.Len(t, tasks, 2)
+	require.Error(t, handlers[tasks[0].Type](context.Background(), tasks[0].Payload))
+	require.NoError(t, handlers[tasks[1].Type](context.Background(), tasks[1].Payload))
+}
+
+func TestResetPasswordAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	resetToken := util.RandomString(43)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"token": resetToken, "new_password": "new-secret-password"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ResetPasswordTxParams) (db.PasswordTxResult, error) {
+						require.Equal(t, token.HashOpaqueToken(resetToken), arg.TokenHash)
+						require.NoError(t, util.CheckPassword("new-secret-password", arg.HashedPassword))
+
+						return db.PasswordTxResult{User: user, BlockedSessions: 1}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidToken",
+			body: gin.H{"token": resetToken, "new_password": "new-secret-password"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PasswordTxResult{}, db.ErrInvalidResetToken)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ShortPassword",
+			body: gin.H{"token": resetToken, "new_password": "short"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/password/reset", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
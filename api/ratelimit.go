@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shevgn/simplebank/metrics"
+	"github.com/shevgn/simplebank/ratelimit"
+)
+
+var errTooManyRequests = errors.New("too many requests")
+
+// loginRateLimits limits login attempts per client IP and per username, so
+// neither a single client nor a distributed attack against one user can try
+// passwords at will.
+func (s *Server) loginRateLimits() gin.HandlerFunc {
+	perIP := ratelimit.Rate{Limit: s.config.LoginRateLimitPerIP, Period: s.config.LoginRateLimitPeriod}
+	perUsername := ratelimit.Rate{Limit: s.config.LoginRateLimitPerUser, Period: s.config.LoginRateLimitPeriod}
+
+	return func(ctx *gin.Context) {
+		if !s.allowRequest(ctx, "login:ip:"+ctx.ClientIP(), perIP) {
+			return
+		}
+
+		if username := peekUsername(ctx.Request); username != "" {
+			if !s.allowRequest(ctx, "login:username:"+username, perUsername) {
+				return
+			}
+		}
+
+		ctx.Next()
+	}
+}
+
+// allowRequest takes a token for key and aborts with 429 Too Many Requests
+// if none is left. When the limiter fails the request is let through, so an
+// outage of the rate limit store does not lock everyone out.
+func (s *Server) allowRequest(ctx *gin.Context, key string, rate ratelimit.Rate) bool {
+	result, err := s.limiter.Allow(ctx, key, rate)
+	if err != nil {
+		slog.WarnContext(ctx, "Rate limiter failed", slog.Any("error", err))
+		return true
+	}
+
+	ctx.Header("X-RateLimit-Limit", strconv.Itoa(rate.Limit))
+	ctx.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+
+	if !result.Allowed {
+		metrics.ObserveFailedLogin(metrics.ReasonRateLimited)
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+		ctx.AbortWithStatusJSON(http.StatusTooManyRequests, errorResponse(errTooManyRequests))
+
+		return false
+	}
+
+	return true
+}
+
+// peekUsername reads the username from a JSON request body without
+// consuming it.
+func peekUsername(req *http.Request) string {
+	if req.Body == nil {
+		return ""
+	}
+
+	var body struct {
+		Username string `json:"username"`
+	}
+	if err := json.Unmarshal(peekBody(req), &body); err != nil {
+		return ""
+	}
+
+	return body.Username
+}
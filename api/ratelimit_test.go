@@ -0,0 +1,139 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/health"
+	"github.com/shevgn/simplebank/notify"
+	"github.com/shevgn/simplebank/ratelimit"
+	"github.com/shevgn/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLoginRateLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	config := &util.Config{
+		TokenSymmetricKey:     util.RandomString(32),
+		AccessTokenDuration:   time.Minute,
+		LoginRateLimitPerIP:   4,
+		LoginRateLimitPerUser: 2,
+		LoginRateLimitPeriod:  time.Minute,
+	}
+	server := NewServer(config, store, health.NewChecker(), ratelimit.NewMemoryLimiter(), newTestDistributor(t),
+		notify.NewHub())
+
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Any()).
+		Times(3).
+		Return(db.User{}, db.ErrRecordNotFound)
+
+	login := func(username string) *httptest.ResponseRecorder {
+		data, err := json.Marshal(map[string]any{
+			"username": username,
+			"password": "secret-password",
+		})
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	// The per-username limit applies first.
+	require.Equal(t, http.StatusNotFound, login("alice").Code)
+	require.Equal(t, http.StatusNotFound, login("alice").Code)
+
+	recorder := login("alice")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "30", recorder.Header().Get("Retry-After"))
+
+	// Rejected attempts still count against the IP, whose limit is shared
+	// by every username.
+	require.Equal(t, http.StatusNotFound, login("bob").Code)
+
+	recorder = login("carol")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "0", recorder.Header().Get("X-RateLimit-Remaining"))
+}
+
+func TestLoginRateLimitsForwardedFor(t *testing.T) {
+	testCases := []struct {
+		name           string
+		trustedProxies string
+		checkResponse  func(t *testing.T, codes []int)
+	}{
+		{
+			name: "UntrustedPeer",
+			checkResponse: func(t *testing.T, codes []int) {
+				// A client cannot escape the per-IP limit by making up a
+				// new X-Forwarded-For header for every attempt.
+				require.Equal(t, []int{http.StatusNotFound, http.StatusNotFound, http.StatusTooManyRequests}, codes)
+			},
+		},
+		{
+			name:           "TrustedProxy",
+			trustedProxies: "192.0.2.0/24",
+			checkResponse: func(t *testing.T, codes []int) {
+				require.Equal(t, []int{http.StatusNotFound, http.StatusNotFound, http.StatusNotFound}, codes)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			config := &util.Config{
+				TokenSymmetricKey:     util.RandomString(32),
+				AccessTokenDuration:   time.Minute,
+				LoginRateLimitPerIP:   2,
+				LoginRateLimitPerUser: 2,
+				LoginRateLimitPeriod:  time.Minute,
+				TrustedProxies:        tc.trustedProxies,
+			}
+			server := NewServer(config, store, health.NewChecker(), ratelimit.NewMemoryLimiter(), newTestDistributor(t),
+				notify.NewHub())
+
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Any()).
+				AnyTimes().
+				Return(db.User{}, db.ErrRecordNotFound)
+
+			codes := make([]int, 0, 3)
+			for i, username := range []string{"alice", "bob", "carol"} {
+				data, err := json.Marshal(map[string]any{
+					"username": username,
+					"password": "secret-password",
+				})
+				require.NoError(t, err)
+
+				request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(data))
+				require.NoError(t, err)
+				request.RemoteAddr = "192.0.2.1:54321"
+				request.Header.Set("X-Forwarded-For", fmt.Sprintf("203.0.113.%d", i+1))
+
+				recorder := httptest.NewRecorder()
+				server.router.ServeHTTP(recorder, request)
+				codes = append(codes, recorder.Code)
+			}
+
+			tc.checkResponse(t, codes)
+		})
+	}
+}
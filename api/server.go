@@ -2,34 +2,75 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/health"
+	"github.com/shevgn/simplebank/metrics"
+	"github.com/shevgn/simplebank/notify"
+	"github.com/shevgn/simplebank/ratelimit"
+	"github.com/shevgn/simplebank/telemetry"
 	"github.com/shevgn/simplebank/token"
 	"github.com/shevgn/simplebank/util"
+	"github.com/shevgn/simplebank/webhook"
+	"github.com/shevgn/simplebank/worker"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 // Server represents the API server.
 type Server struct {
-	store      db.Store
-	tokenMaker token.Maker
-	config     *util.Config
-	router     *gin.Engine
+	store       db.Store
+	tokenMaker  token.Maker
+	config      *util.Config
+	health      *health.Checker
+	limiter     ratelimit.Limiter
+	distributor worker.Distributor
+	webhooks    *webhook.Dispatcher
+	hub         *notify.Hub
+	router      *gin.Engine
+	httpServer  *http.Server
+
+	// streams is canceled on shutdown to end long-lived streams, which
+	// would otherwise keep the server from draining.
+	streams     context.Context
+	stopStreams context.CancelFunc
+
+	openAPISpec []byte
 }
 
 // NewServer creates a new API server.
-func NewServer(config *util.Config, store db.Store) *Server {
+func NewServer(
+	config *util.Config,
+	store db.Store,
+	checker *health.Checker,
+	limiter ratelimit.Limiter,
+	distributor worker.Distributor,
+	hub *notify.Hub,
+) *Server {
 	maker, err := token.NewJWTMaker(config.TokenSymmetricKey)
 	if err != nil {
 		panic(err)
 	}
 
 	s := &Server{
-		store:      store,
-		tokenMaker: maker,
-		config:     config,
-		router:     gin.Default(),
+		store:       store,
+		tokenMaker:  maker,
+		config:      config,
+		health:      checker,
+		limiter:     limiter,
+		distributor: distributor,
+		webhooks:    webhook.NewDispatcher(store, config.WebhookTimeout, webhook.NewGuard(config.WebhookNetworks())),
+		hub:         hub,
+		router:      newRouter(config),
+
+		openAPISpec: mustMarshalOpenAPISpec(),
 	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
@@ -37,32 +78,149 @@ func NewServer(config *util.Config, store db.Store) *Server {
 		if err != nil {
 			panic(err)
 		}
+
+		err = v.RegisterValidation("account_type", validAccountType)
+		if err != nil {
+			panic(err)
+		}
+
+		err = v.RegisterValidation("webhook_event", validWebhookEvent)
+		if err != nil {
+			panic(err)
+		}
+
+		err = v.RegisterValidation("statement_format", validStatementFormat)
+		if err != nil {
+			panic(err)
+		}
 	}
 
+	s.streams, s.stopStreams = context.WithCancel(context.Background())
+
 	s.registerRoutes()
+	s.httpServer = NewHTTPServer(config, config.ServerAddress, s.router)
+	s.httpServer.RegisterOnShutdown(s.stopStreams)
 
 	return s
 }
 
+// quietPaths are polled by the orchestrator and the metrics scraper, and are
+// left out of the request log and traces.
+var quietPaths = []string{"/healthz", "/readyz", "/metrics"}
+
+// newRouter creates a gin engine like gin.Default, except that requests are
+// logged as structured JSON, probes and scrapes are not logged, and every
+// request is measured and traced. Client IPs are taken from X-Forwarded-For
+// only when the request comes from one of the configured trusted proxies.
+func newRouter(config *util.Config) *gin.Engine {
+	router := gin.New()
+	if err := router.SetTrustedProxies(trustedProxies(config)); err != nil {
+		panic(err)
+	}
+	// Let handlers pass *gin.Context to the store while keeping the request
+	// context, and with it the active span, as the parent of database spans.
+	router.ContextWithFallback = true
+	router.Use(
+		requestIDMiddleware(),
+		loggerMiddleware(),
+		recoveryMiddleware(),
+		otelgin.Middleware(telemetry.ServiceName, otelgin.WithFilter(isTraced)),
+		metrics.GinMiddleware(),
+	)
+
+	return router
+}
+
+// trustedProxies lists TRUSTED_PROXIES in the form gin expects. An empty
+// list trusts no proxy, so the client IP is always the peer address.
+func trustedProxies(config *util.Config) []string {
+	networks := config.TrustedProxyNetworks()
+
+	proxies := make([]string, 0, len(networks))
+	for _, network := range networks {
+		proxies = append(proxies, network.String())
+	}
+
+	return proxies
+}
+
+func isQuiet(path string) bool {
+	return slices.Contains(quietPaths, path)
+}
+
+func isTraced(req *http.Request) bool {
+	return !isQuiet(req.URL.Path)
+}
+
 func (s *Server) registerRoutes() {
+	s.router.GET("/healthz", s.healthz)
+	s.router.GET("/readyz", s.readyz)
+	s.router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
 	s.router.POST("/users", s.createUser)
-	s.router.POST("/users/login", s.loginUser)
+	s.router.POST("/users/login", s.loginRateLimits(), s.loginUser)
+	s.router.POST("/users/password/reset_request", s.loginRateLimits(), s.requestPasswordReset)
+	s.router.POST("/users/password/reset", s.loginRateLimits(), s.resetPassword)
+	s.router.GET("/users/verify_email", s.verifyEmail)
 	s.router.POST("/tokens/renew_access", s.renewAccessToken)
 
+	s.router.GET("/openapi.json", s.getOpenAPISpec)
+	s.router.GET("/swagger/*filepath", s.serveSwaggerUI)
+
 	authRoutes := s.router.Group("/").Use(authMiddleware(s.tokenMaker))
 
+	authRoutes.PUT("/users/password", s.changePassword)
+	authRoutes.POST("/users/verify_email/resend", s.resendVerifyEmail)
+
+	authRoutes.GET("/accounts/stream", s.streamAccounts)
 	authRoutes.GET("/accounts/:id", s.getAccount)
+	authRoutes.GET("/accounts/:id/statement", s.getStatement)
 	authRoutes.GET("/accounts", s.listAccounts)
 	authRoutes.POST("/accounts", s.createAccount)
-	authRoutes.PUT("/accounts", s.updateAccount)
 	authRoutes.DELETE("/accounts/:id", s.deleteAccount)
+	authRoutes.POST("/accounts/:id/close", s.closeAccount)
+	authRoutes.POST("/accounts/:id/reopen", s.reopenAccount)
 
 	authRoutes.POST("/transfers", s.createTransfer)
+	authRoutes.POST("/transfers/quote", s.quoteTransfer)
+	authRoutes.POST("/transfers/bulk", s.createBulkTransfer)
+
+	authRoutes.POST("/webhooks", s.createWebhookEndpoint)
+	authRoutes.GET("/webhooks", s.listWebhookEndpoints)
+	authRoutes.DELETE("/webhooks/:id", s.deleteWebhookEndpoint)
+	authRoutes.GET("/webhooks/:id/deliveries", s.listWebhookDeliveries)
+	authRoutes.POST("/webhooks/:id/test", s.testWebhookEndpoint)
 }
 
-// Run starts the API server.
-func (s *Server) Run(address string) error {
-	return s.router.Run(address)
+// Start serves HTTP requests on address until Shutdown is called.
+func (s *Server) Start(address string) error {
+	s.httpServer.Addr = address
+
+	err := s.httpServer.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests to
+// finish or for ctx to expire, whichever comes first.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
+
+// NewHTTPServer creates an http.Server with the timeouts from config, so that
+// slow or idle clients cannot hold connections open indefinitely.
+func NewHTTPServer(config *util.Config, address string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              address,
+		Handler:           handler,
+		ReadHeaderTimeout: config.HTTPReadTimeout,
+		ReadTimeout:       config.HTTPReadTimeout,
+		WriteTimeout:      config.HTTPWriteTimeout,
+		IdleTimeout:       config.HTTPIdleTimeout,
+	}
 }
 
 func errorResponse(err error) gin.H {
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServerShutdown(t *testing.T) {
+	server := NewTestServer(t, nil)
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.Start("127.0.0.1:0")
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.Eventually(t, func() bool {
+		return server.Shutdown(ctx) == nil
+	}, time.Second, 10*time.Millisecond)
+
+	select {
+	case err := <-errs:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("server did not stop after shutdown")
+	}
+}
+
+func TestNewHTTPServerTimeouts(t *testing.T) {
+	server := NewTestServer(t, nil)
+	server.config.HTTPReadTimeout = 5 * time.Second
+	server.config.HTTPWriteTimeout = 10 * time.Second
+	server.config.HTTPIdleTimeout = time.Minute
+
+	httpServer := NewHTTPServer(server.config, "127.0.0.1:0", http.NotFoundHandler())
+	require.Equal(t, 5*time.Second, httpServer.ReadHeaderTimeout)
+	require.Equal(t, 5*time.Second, httpServer.ReadTimeout)
+	require.Equal(t, 10*time.Second, httpServer.WriteTimeout)
+	require.Equal(t, time.Minute, httpServer.IdleTimeout)
+}
+
+func TestRequestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, _ int64) (db.Account, error) {
+			// The store must see the request span so database spans nest under it.
+			span := trace.SpanFromContext(ctx)
+			require.Equal(t, traceID, span.SpanContext().TraceID().String())
+
+			return account, nil
+		})
+
+	server := NewTestServer(t, store)
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d", account.ID), nil)
+	require.NoError(t, err)
+	request.Header.Set("traceparent", fmt.Sprintf("00-%s-00f067aa0ba902b7-01", traceID))
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+	server.router.ServeHTTP(httptest.NewRecorder(), request)
+
+	names := make(map[string]string)
+	for _, span := range recorder.Ended() {
+		names[span.Name()] = span.SpanContext().TraceID().String()
+	}
+
+	require.Equal(t, traceID, names["authMiddleware"])
+	require.Equal(t, traceID, names["GET /accounts/:id"])
+}
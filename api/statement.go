@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/statement"
+	"github.com/shevgn/simplebank/token"
+)
+
+// maxStatementDays bounds the period of one statement.
+const maxStatementDays = 366
+
+type statementRequest struct {
+	From   string `form:"from"   binding:"required,datetime=2006-01-02"`
+	To     string `form:"to"     binding:"required,datetime=2006-01-02"`
+	Format string `form:"format" binding:"omitempty,statement_format"`
+}
+
+func statementContentTypes() []string {
+	names := statement.FormatNames()
+	contentTypes := make([]string, len(names))
+	for i, name := range names {
+		format, _ := statement.LookupFormat(name)
+		contentTypes[i] = format.ContentType
+	}
+
+	return contentTypes
+}
+
+func (s *Server) getStatement(ctx *gin.Context) {
+	var uri GetAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req statementRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// The binding has already checked the layout of both dates.
+	from, _ := time.Parse(statement.DateLayout, req.From)
+	to, _ := time.Parse(statement.DateLayout, req.To)
+
+	if to.Before(from) {
+		err := errors.New("to must not be before from")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if to.Sub(from) >= maxStatementDays*24*time.Hour {
+		err := fmt.Errorf("a statement covers at most %d days", maxStatementDays)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.Format == "" {
+		req.Format = "json"
+	}
+	format, _ := statement.LookupFormat(req.Format)
+
+	account, err := s.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if account.Owner != authPayload.Username {
+		err := errors.New("account does not belong to the user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	filename := fmt.Sprintf("statement-%d-%s-%s.%s", account.ID, req.From, req.To, format.Extension)
+	ctx.Header("Content-Type", format.ContentType)
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	err = statement.Generate(ctx, s.store, account, from, to, format.NewWriter(ctx.Writer))
+	if err != nil {
+		if !ctx.Writer.Written() {
+			ctx.Header("Content-Type", "")
+			ctx.Header("Content-Disposition", "")
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		// The status line is gone; the client sees a truncated file.
+		slog.ErrorContext(ctx, "Cannot stream statement",
+			slog.Int64("account_id", account.ID),
+			slog.Any("error", err),
+		)
+	}
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetStatementAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	entries := []db.ListStatementEntriesRow{
+		{ID: 1, Amount: 500, TransferID: 7, CounterpartyAccountID: 9, CounterpartyOwner: "bob"},
+		{ID: 2, Amount: -200, TransferID: 8, CounterpartyAccountID: 9, CounterpartyOwner: "bob"},
+	}
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "CSV",
+			query:    url.Values{"from": {"2026-09-01"}, "to": {"2026-09-30"}, "format": {"csv"}},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetOpeningBalance(gomock.Any(), gomock.Any()).Times(1).Return(int64(1000), nil)
+				store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Any()).Times(1).Return(entries, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+				require.Equal(t,
+					fmt.Sprintf(`attachment; filename="statement-%d-2026-09-01-2026-09-30.csv"`, account.ID),
+					recorder.Header().Get("Content-Disposition"))
+
+				records, err := csv.NewReader(recorder.Body).ReadAll()
+				require.NoError(t, err)
+				require.Len(t, records, 5)
+				require.Equal(t, "15.00", records[2][7])
+				require.Equal(t, "13.00", records[4][7])
+			},
+		},
+		{
+			name:     "DefaultJSON",
+			query:    url.Values{"from": {"2026-09-01"}, "to": {"2026-09-01"}},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetOpeningBalance(gomock.Any(), gomock.Any()).Times(1).Return(int64(1000), nil)
+				store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Any()).Times(1).Return(entries, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+
+				var body struct {
+					ClosingBalance int64             `json:"closing_balance"`
+					Entries        []json.RawMessage `json:"entries"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.Equal(t, int64(1300), body.ClosingBalance)
+				require.Len(t, body.Entries, 2)
+			},
+		},
+		{
+			name:     "Unauthorized",
+			query:    url.Values{"from": {"2026-09-01"}, "to": {"2026-09-30"}},
+			username: "wrong_username",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetOpeningBalance(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			query:    url.Values{"from": {"2026-09-01"}, "to": {"2026-09-30"}},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalServerError",
+			query:    url.Values{"from": {"2026-09-01"}, "to": {"2026-09-30"}, "format": {"pdf"}},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetOpeningBalance(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+				require.Empty(t, recorder.Header().Get("Content-Disposition"))
+			},
+		},
+		{
+			name:     "InvalidFormat",
+			query:    url.Values{"from": {"2026-09-01"}, "to": {"2026-09-30"}, "format": {"xls"}},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidDate",
+			query:    url.Values{"from": {"01/09/2026"}, "to": {"2026-09-30"}},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "ToBeforeFrom",
+			query:    url.Values{"from": {"2026-09-30"}, "to": {"2026-09-01"}},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "PeriodTooLong",
+			query:    url.Values{"from": {"2024-01-01"}, "to": {"2025-01-01"}},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/statement?%s", account.ID, tc.query.Encode())
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
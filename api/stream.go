@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/notify"
+	"github.com/shevgn/simplebank/token"
+)
+
+const (
+	// streamHeartbeatInterval keeps idle streams from being closed by
+	// proxies and detects clients that went away
+	streamHeartbeatInterval = 15 * time.Second
+	// streamWriteTimeout bounds a single WebSocket write
+	streamWriteTimeout = 10 * time.Second
+)
+
+// websocketProtocol is the subprotocol spoken on account streams.
+const websocketProtocol = "simplebank.v1"
+
+// websocketTokenPrefix marks the subprotocol carrying the access token.
+// Browsers cannot set headers on WebSocket requests, so they offer the
+// token as a second subprotocol, "bearer.<token>", instead.
+const websocketTokenPrefix = "bearer."
+
+// accountEventResponse is sent when a committed transfer changes the balance
+// of one of the caller's accounts. Type is AccountDebited or AccountCredited
+// and Amount is negative for debits.
+type accountEventResponse struct {
+	Type       string    `json:"type"`
+	AccountID  int64     `json:"account_id"`
+	EntryID    int64     `json:"entry_id"`
+	TransferID int64     `json:"transfer_id"`
+	Amount     int64     `json:"amount"`
+	Balance    int64     `json:"balance"`
+	Currency   string    `json:"currency"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func newAccountEventResponse(n db.AccountNotification) accountEventResponse {
+	return accountEventResponse{
+		Type:       n.Type,
+		AccountID:  n.AccountID,
+		EntryID:    n.EntryID,
+		TransferID: n.TransferID,
+		Amount:     n.Amount,
+		Balance:    n.Balance,
+		Currency:   n.Currency,
+		CreatedAt:  n.CreatedAt,
+	}
+}
+
+// streamAccounts pushes balance changes of the caller's accounts as they
+// commit, as Server-Sent Events or, for WebSocket upgrade requests, as JSON
+// text messages. The stream ends when the client falls too far behind or
+// updates may have been missed; clients should then reload balances and
+// reconnect.
+func (s *Server) streamAccounts(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	// Streams outlive the server's read and write timeouts.
+	rc := http.NewResponseController(ctx.Writer)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	sub := s.hub.Subscribe(authPayload.Username)
+	defer sub.Close()
+
+	if isWebSocketUpgrade(ctx.Request) {
+		s.streamWebSocket(ctx, sub)
+		return
+	}
+
+	s.streamEvents(ctx, sub)
+}
+
+func (s *Server) streamEvents(ctx *gin.Context, sub *notify.Subscription) {
+	header := ctx.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	// Keep reverse proxies such as nginx from buffering the stream.
+	header.Set("X-Accel-Buffering", "no")
+
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case <-s.streams.Done():
+			return
+		case n, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+
+			ctx.Render(-1, sse.Event{
+				Id:    strconv.FormatInt(n.EntryID, 10),
+				Event: n.Type,
+				Data:  newAccountEventResponse(n),
+			})
+		case <-heartbeat.C:
+			if _, err := ctx.Writer.WriteString(": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+
+		ctx.Writer.Flush()
+	}
+}
+
+func (s *Server) streamWebSocket(ctx *gin.Context, sub *notify.Subscription) {
+	conn, err := websocket.Accept(ctx.Writer, ctx.Request, &websocket.AcceptOptions{
+		Subprotocols: []string{websocketProtocol},
+		// Streams are authorized by bearer token rather than cookies, so
+		// pages on other origins cannot use a visitor's session.
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		// Accept has written the error response.
+		return
+	}
+	defer conn.CloseNow()
+
+	// Clients only send control frames; CloseRead handles them and cancels
+	// the context once the client closes the connection.
+	streamCtx := conn.CloseRead(ctx.Request.Context())
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-streamCtx.Done():
+			return
+		case <-s.streams.Done():
+			conn.Close(websocket.StatusGoingAway, "server shutting down")
+			return
+		case n, ok := <-sub.Events():
+			if !ok {
+				conn.Close(websocket.StatusTryAgainLater, "stream interrupted, reload balances and reconnect")
+				return
+			}
+
+			if err := writeWebSocketJSON(streamCtx, conn, newAccountEventResponse(n)); err != nil {
+				slog.DebugContext(ctx, "Cannot write to account stream", slog.Any("error", err))
+				return
+			}
+		case <-heartbeat.C:
+			pingCtx, cancel := context.WithTimeout(streamCtx, streamWriteTimeout)
+			err := conn.Ping(pingCtx)
+			cancel()
+
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeWebSocketJSON(ctx context.Context, conn *websocket.Conn, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, streamWriteTimeout)
+	defer cancel()
+
+	return conn.Write(ctx, websocket.MessageText, data)
+}
+
+func isWebSocketUpgrade(req *http.Request) bool {
+	return strings.EqualFold(req.Header.Get("Upgrade"), "websocket")
+}
+
+// websocketToken returns the access token offered as a subprotocol by a
+// WebSocket upgrade request.
+func websocketToken(req *http.Request) (string, bool) {
+	if !isWebSocketUpgrade(req) {
+		return "", false
+	}
+
+	for _, header := range req.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(header, ",") {
+			if accessToken, ok := strings.CutPrefix(strings.TrimSpace(protocol), websocketTokenPrefix); ok {
+				return accessToken, true
+			}
+		}
+	}
+
+	return "", false
+}
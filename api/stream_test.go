@@ -0,0 +1,155 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func randomAccountNotification(owner string) db.AccountNotification {
+	return db.AccountNotification{
+		Type:  db.EventAccountCredited,
+		Owner: owner,
+		AccountEntryPayload: db.AccountEntryPayload{
+			AccountID:  1,
+			EntryID:    7,
+			TransferID: 3,
+			Amount:     10,
+			Balance:    110,
+			Currency:   "USD",
+			CreatedAt:  time.Now().UTC().Truncate(time.Second),
+		},
+	}
+}
+
+// The handlers subscribe before sending the response headers, so events
+// published once a client has the headers reach it.
+
+func TestStreamAccountsSSE(t *testing.T) {
+	user, _ := randomUser(t)
+
+	server := NewTestServer(t, mockdb.NewMockStore(gomock.NewController(t)))
+	httpServer := httptest.NewServer(server.router)
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL+"/accounts/stream", nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	other := randomAccountNotification("someone_else")
+	other.EntryID = 1
+	server.hub.Publish(other)
+
+	n := randomAccountNotification(user.Username)
+	server.hub.Publish(n)
+
+	reader := bufio.NewReader(response.Body)
+
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			break
+		}
+
+		lines = append(lines, line)
+	}
+
+	require.Len(t, lines, 3)
+	require.Equal(t, "id:7", lines[0])
+	require.Equal(t, "event:"+db.EventAccountCredited, lines[1])
+
+	var event accountEventResponse
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(lines[2], "data:")), &event))
+	require.Equal(t, newAccountEventResponse(n), event)
+
+	// The stream ends when updates may have been missed.
+	server.hub.Reset()
+
+	_, err = reader.ReadString('\n')
+	require.Error(t, err)
+}
+
+func TestStreamAccountsWebSocket(t *testing.T) {
+	user, _ := randomUser(t)
+
+	server := NewTestServer(t, mockdb.NewMockStore(gomock.NewController(t)))
+	httpServer := httptest.NewServer(server.router)
+	defer httpServer.Close()
+
+	accessToken, _, err := server.tokenMaker.CreateToken(user.Username, time.Minute)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, response, err := websocket.Dial(ctx, httpServer.URL+"/accounts/stream", &websocket.DialOptions{
+		Subprotocols: []string{websocketProtocol, websocketTokenPrefix + accessToken},
+	})
+	require.NoError(t, err)
+	defer conn.CloseNow()
+
+	require.Equal(t, websocketProtocol, response.Header.Get("Sec-WebSocket-Protocol"))
+
+	n := randomAccountNotification(user.Username)
+	server.hub.Publish(n)
+
+	messageType, data, err := conn.Read(ctx)
+	require.NoError(t, err)
+	require.Equal(t, websocket.MessageText, messageType)
+
+	var event accountEventResponse
+	require.NoError(t, json.Unmarshal(data, &event))
+	require.Equal(t, newAccountEventResponse(n), event)
+
+	server.hub.Reset()
+
+	_, _, err = conn.Read(ctx)
+	require.Equal(t, websocket.StatusTryAgainLater, websocket.CloseStatus(err))
+}
+
+func TestStreamAccountsUnauthorized(t *testing.T) {
+	server := NewTestServer(t, mockdb.NewMockStore(gomock.NewController(t)))
+	httpServer := httptest.NewServer(server.router)
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, response, err := websocket.Dial(ctx, httpServer.URL+"/accounts/stream", &websocket.DialOptions{
+		Subprotocols: []string{websocketProtocol, websocketTokenPrefix + "invalid"},
+	})
+	require.Error(t, err)
+	require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/accounts/stream", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// swaggerIndex replaces the stock Swagger UI page so that it loads our
+// /openapi.json instead of the demo petstore document.
+//
+//go:embed swagger/index.html
+var swaggerIndex []byte
+
+func (s *Server) serveSwaggerUI(ctx *gin.Context) {
+	file := ctx.Param("filepath")
+	if file == "/" || file == "/index.html" {
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", swaggerIndex)
+		return
+	}
+
+	ctx.FileFromFS(file, http.FS(swaggerFiles.FS))
+}
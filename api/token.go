@@ -1,12 +1,14 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/metrics"
 	"github.com/shevgn/simplebank/token"
 )
 
@@ -20,6 +22,10 @@ type renewAccessTokenResponse struct {
 }
 
 func (s *Server) renewAccessToken(ctx *gin.Context) {
+	defer func() {
+		metrics.ObserveTokenRenewal(ctx.Writer.Status() == http.StatusOK)
+	}()
+
 	var req renewAccessTokenRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -40,7 +46,7 @@ func (s *Server) renewAccessToken(ctx *gin.Context) {
 
 	session, err := s.store.GetSession(ctx, refreshPayload.ID)
 	if err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, db.ErrRecordNotFound) {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
 			return
 		}
@@ -73,8 +79,9 @@ func (s *Server) renewAccessToken(ctx *gin.Context) {
 		return
 	}
 
-	accessToken, accessPayload, err := s.tokenMaker.CreateToken(
+	accessToken, accessPayload, err := s.tokenMaker.CreateSessionToken(
 		refreshPayload.Username,
+		session.ID,
 		s.config.AccessTokenDuration,
 	)
 	if err != nil {
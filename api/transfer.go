@@ -6,15 +6,15 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx"
 	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/metrics"
 	"github.com/shevgn/simplebank/token"
 )
 
 func (s *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
 	account, err := s.store.GetAccount(ctx, accountID)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, db.ErrRecordNotFound) {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
 			return account, false
 		}
@@ -29,6 +29,11 @@ func (s *Server) validAccount(ctx *gin.Context, accountID int64, currency string
 		return account, false
 	}
 
+	if err := db.CheckActive(account); err != nil {
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return account, false
+	}
+
 	return account, true
 }
 
@@ -40,27 +45,50 @@ type CreateTransferRequest struct {
 	Currency      string `json:"currency"        binding:"required,currency"`
 }
 
-func (s *Server) createTransfer(ctx *gin.Context) {
+// bindTransfer reads a transfer request and checks both of its accounts,
+// writing the error response when it returns false.
+func (s *Server) bindTransfer(ctx *gin.Context) (CreateTransferRequest, bool) {
 	var req CreateTransferRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+		return req, false
 	}
 
 	fromAccount, valid := s.validAccount(ctx, req.FromAccountID, req.Currency)
 	if !valid {
-		return
+		return req, false
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	if fromAccount.Owner != authPayload.Username {
 		err := errors.New("from account does not belong to the user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return
+		return req, false
+	}
+
+	toAccount, valid := s.validAccount(ctx, req.ToAccountID, req.Currency)
+	if !valid {
+		return req, false
 	}
 
-	_, valid = s.validAccount(ctx, req.ToAccountID, req.Currency)
+	isSystem, err := s.store.IsSystemAccount(ctx, toAccount.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return req, false
+	}
+
+	if isSystem {
+		err := fmt.Errorf("account %d: %w", toAccount.ID, db.ErrSystemAccount)
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return req, false
+	}
+
+	return req, true
+}
+
+func (s *Server) createTransfer(ctx *gin.Context) {
+	req, valid := s.bindTransfer(ctx)
 	if !valid {
 		return
 	}
@@ -73,9 +101,42 @@ func (s *Server) createTransfer(ctx *gin.Context) {
 
 	result, err := s.store.TransferTx(ctx, arg)
 	if err != nil {
+		if errors.Is(err, db.ErrAccountFrozen) || errors.Is(err, db.ErrAccountClosed) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+
+		if errors.Is(err, db.ErrEmailNotVerified) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
+	metrics.ObserveTransfer(req.Currency, req.Amount)
+
 	ctx.JSON(http.StatusOK, result)
 }
+
+// quoteTransfer returns the fee a transfer would be charged, so clients can
+// show it before the user confirms the transfer.
+func (s *Server) quoteTransfer(ctx *gin.Context) {
+	req, valid := s.bindTransfer(ctx)
+	if !valid {
+		return
+	}
+
+	quote, err := s.store.QuoteTransfer(ctx, db.TransferTxParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quote)
+}
@@ -2,13 +2,13 @@ package api
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
-	"github.com/jackc/pgx"
 	mockdb "github.com/shevgn/simplebank/db/mock"
 	db "github.com/shevgn/simplebank/db/sqlc"
 	"github.com/shevgn/simplebank/token"
@@ -60,6 +60,10 @@ func TestTransferAPI(t *testing.T) {
 					GetAccount(gomock.Any(), gomock.Eq(accountTo.ID)).
 					Times(1).
 					Return(accountTo, nil)
+				store.EXPECT().
+					IsSystemAccount(gomock.Any(), gomock.Eq(accountTo.ID)).
+					Times(1).
+					Return(false, nil)
 
 				arg := db.TransferTxParams{
 					FromAccountID: accountFrom.ID,
@@ -90,7 +94,7 @@ func TestTransferAPI(t *testing.T) {
 				store.EXPECT().
 					GetAccount(gomock.Any(), gomock.Eq(accountFrom.ID)).
 					Times(1).
-					Return(db.Account{}, pgx.ErrNoRows)
+					Return(db.Account{}, db.ErrRecordNotFound)
 				store.EXPECT().
 					GetAccount(gomock.Any(), gomock.Eq(accountTo.ID)).
 					Times(0)
@@ -102,6 +106,169 @@ func TestTransferAPI(t *testing.T) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
 			},
 		},
+		{
+			name: "ToAccountFrozen",
+			requestBody: CreateTransferRequest{
+				FromAccountID: accountFrom.ID,
+				ToAccountID:   accountTo.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, userFrom.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				frozen := accountTo
+				frozen.Status = db.AccountStatusFrozen
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accountFrom.ID)).
+					Times(1).
+					Return(accountFrom, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accountTo.ID)).
+					Times(1).
+					Return(frozen, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "ToAccountClosed",
+			requestBody: CreateTransferRequest{
+				FromAccountID: accountFrom.ID,
+				ToAccountID:   accountTo.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, userFrom.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				closed := accountTo
+				closed.Status = db.AccountStatusClosed
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accountFrom.ID)).
+					Times(1).
+					Return(accountFrom, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accountTo.ID)).
+					Times(1).
+					Return(closed, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "FrozenDuringTransfer",
+			requestBody: CreateTransferRequest{
+				FromAccountID: accountFrom.ID,
+				ToAccountID:   accountTo.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, userFrom.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accountFrom.ID)).
+					Times(1).
+					Return(accountFrom, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accountTo.ID)).
+					Times(1).
+					Return(accountTo, nil)
+				store.EXPECT().
+					IsSystemAccount(gomock.Any(), gomock.Eq(accountTo.ID)).
+					Times(1).
+					Return(false, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrAccountFrozen)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "EmailNotVerified",
+			requestBody: CreateTransferRequest{
+				FromAccountID: accountFrom.ID,
+				ToAccountID:   accountTo.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, userFrom.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accountFrom.ID)).
+					Times(1).
+					Return(accountFrom, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accountTo.ID)).
+					Times(1).
+					Return(accountTo, nil)
+				store.EXPECT().
+					IsSystemAccount(gomock.Any(), gomock.Eq(accountTo.ID)).
+					Times(1).
+					Return(false, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrEmailNotVerified)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "ToSystemAccount",
+			requestBody: CreateTransferRequest{
+				FromAccountID: accountFrom.ID,
+				ToAccountID:   accountTo.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, authorizationTypeBearer, userFrom.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				system := accountTo
+				system.Owner = db.BankUsername
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accountFrom.ID)).
+					Times(1).
+					Return(accountFrom, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accountTo.ID)).
+					Times(1).
+					Return(system, nil)
+				store.EXPECT().
+					IsSystemAccount(gomock.Any(), gomock.Eq(accountTo.ID)).
+					Times(1).
+					Return(true, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "ToAccountNotFound",
 			requestBody: CreateTransferRequest{
@@ -121,7 +288,7 @@ func TestTransferAPI(t *testing.T) {
 				store.EXPECT().
 					GetAccount(gomock.Any(), gomock.Eq(accountTo.ID)).
 					Times(1).
-					Return(db.Account{}, pgx.ErrNoRows)
+					Return(db.Account{}, db.ErrRecordNotFound)
 				store.EXPECT().
 					TransferTx(gomock.Any(), gomock.Any()).
 					Times(0)
@@ -252,7 +419,7 @@ func TestTransferAPI(t *testing.T) {
 				store.EXPECT().
 					GetAccount(gomock.Any(), gomock.Eq(accountFrom.ID)).
 					Times(1).
-					Return(db.Account{}, pgx.ErrClosedPool)
+					Return(db.Account{}, sql.ErrConnDone)
 				store.EXPECT().
 					GetAccount(gomock.Any(), gomock.Eq(accountTo.ID)).
 					Times(0)
@@ -284,10 +451,14 @@ func TestTransferAPI(t *testing.T) {
 					GetAccount(gomock.Any(), gomock.Eq(accountTo.ID)).
 					Times(1).
 					Return(accountTo, nil)
+				store.EXPECT().
+					IsSystemAccount(gomock.Any(), gomock.Eq(accountTo.ID)).
+					Times(1).
+					Return(false, nil)
 				store.EXPECT().
 					TransferTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.TransferTxResult{}, pgx.ErrClosedPool)
+					Return(db.TransferTxResult{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -321,3 +492,119 @@ func TestTransferAPI(t *testing.T) {
 		})
 	}
 }
+
+func TestQuoteTransferAPI(t *testing.T) {
+	userFrom, _ := randomUser(t)
+	userTo, _ := randomUser(t)
+
+	accountFrom := randomAccount(userFrom.Username)
+	accountTo := randomAccount(userTo.Username)
+	accountFrom.Currency = util.USD
+	accountTo.Currency = util.USD
+
+	requestBody := CreateTransferRequest{
+		FromAccountID: accountFrom.ID,
+		ToAccountID:   accountTo.ID,
+		Amount:        10000,
+		Currency:      util.USD,
+	}
+
+	quote := db.TransferQuote{Amount: 10000, Fee: 50, Total: 10050, FeeRuleID: 3}
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: userFrom.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(accountFrom.ID)).Times(1).Return(accountFrom, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(accountTo.ID)).Times(1).Return(accountTo, nil)
+				store.EXPECT().IsSystemAccount(gomock.Any(), gomock.Eq(accountTo.ID)).Times(1).Return(false, nil)
+				store.EXPECT().
+					QuoteTransfer(gomock.Any(), gomock.Eq(db.TransferTxParams{
+						FromAccountID: accountFrom.ID,
+						ToAccountID:   accountTo.ID,
+						Amount:        10000,
+					})).
+					Times(1).
+					Return(quote, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.TransferQuote
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, quote, got)
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			username: userTo.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(accountFrom.ID)).Times(1).Return(accountFrom, nil)
+				store.EXPECT().QuoteTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "ToAccountClosed",
+			username: userFrom.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				closed := accountTo
+				closed.Status = db.AccountStatusClosed
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(accountFrom.ID)).Times(1).Return(accountFrom, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(accountTo.ID)).Times(1).Return(closed, nil)
+				store.EXPECT().QuoteTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			username: userFrom.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(accountFrom.ID)).Times(1).Return(accountFrom, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(accountTo.ID)).Times(1).Return(accountTo, nil)
+				store.EXPECT().IsSystemAccount(gomock.Any(), gomock.Eq(accountTo.ID)).Times(1).Return(false, nil)
+				store.EXPECT().
+					QuoteTransfer(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferQuote{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(requestBody)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers/quote", bytes.NewReader(body))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -2,16 +2,20 @@ package api
 
 import (
 	"errors"
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/lib/pq"
 	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/metrics"
 	"github.com/shevgn/simplebank/util"
+	"github.com/shevgn/simplebank/worker"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -28,6 +32,7 @@ type userResponse struct {
 	Username          string    `json:"username"`
 	FullName          string    `json:"full_name"`
 	Email             string    `json:"email"`
+	IsEmailVerified   bool      `json:"is_email_verified"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 }
@@ -37,6 +42,7 @@ func newUserResponse(user db.User) userResponse {
 		Username:          user.Username,
 		FullName:          user.FullName,
 		Email:             user.Email,
+		IsEmailVerified:   user.IsEmailVerified,
 		PasswordChangedAt: user.PasswordChangedAt.Time,
 		CreatedAt:         user.CreatedAt.Time,
 	}
@@ -78,11 +84,45 @@ func (s *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
+	s.sendVerifyEmail(ctx, user.Username)
+
 	response := newUserResponse(user)
 
 	ctx.JSON(http.StatusOK, response)
 }
 
+// sendVerifyEmail queues the verification email for username. The user
+// exists either way, so a failure is logged rather than returned; the user
+// can ask for the email again.
+func (s *Server) sendVerifyEmail(ctx *gin.Context, username string) {
+	task, err := worker.NewSendVerifyEmailTask(username)
+	if err == nil {
+		err = s.distributor.Enqueue(ctx, task)
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Cannot queue verification email",
+			slog.String("username", username),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// recordFailedLogin counts a wrong password and locks the user once the
+// configured number of attempts is reached. Every further failure doubles
+// the lockout, up to the configured maximum.
+func (s *Server) recordFailedLogin(ctx *gin.Context, username string) {
+	_, err := s.store.RecordFailedLogin(ctx, db.RecordFailedLoginParams{
+		Username:          username,
+		MaxAttempts:       int32(s.config.LoginMaxFailedAttempts),
+		LockoutSeconds:    s.config.LoginLockoutDuration.Seconds(),
+		MaxLockoutSeconds: s.config.LoginMaxLockoutDuration.Seconds(),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Cannot record failed login", slog.Any("error", err))
+	}
+}
+
 // loginUserRequest represents a request to login a user.
 type loginUserRequest struct {
 	Username string `json:"username" binding:"required,alphanum,min=1,max=100"`
@@ -108,7 +148,8 @@ func (s *Server) loginUser(ctx *gin.Context) {
 
 	user, err := s.store.GetUser(ctx, req.Username)
 	if err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			metrics.ObserveFailedLogin(metrics.ReasonUserNotFound)
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
 			return
 		}
@@ -117,24 +158,49 @@ func (s *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
+	if lockedFor := time.Until(user.LockedUntil.Time); lockedFor > 0 {
+		metrics.ObserveFailedLogin(metrics.ReasonAccountLocked)
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockedFor.Seconds()))))
+		ctx.JSON(http.StatusLocked, errorResponse(db.ErrUserLocked))
+		return
+	}
+
 	err = util.CheckPassword(req.Password, user.HashedPassword)
 	if err != nil {
+		metrics.ObserveFailedLogin(metrics.ReasonIncorrectPassword)
+		s.recordFailedLogin(ctx, user.Username)
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	accessToken, accessPayload, err := s.tokenMaker.CreateToken(
+	if user.IsDisabled {
+		metrics.ObserveFailedLogin(metrics.ReasonUserDisabled)
+		ctx.JSON(http.StatusForbidden, errorResponse(db.ErrUserDisabled))
+		return
+	}
+
+	if user.FailedLoginAttempts > 0 {
+		user, err = s.store.ResetFailedLogins(ctx, user.Username)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(
 		user.Username,
-		s.config.AccessTokenDuration,
+		s.config.RefreshTokenDuration,
 	)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(
+	// The refresh token's ID doubles as the session ID.
+	accessToken, accessPayload, err := s.tokenMaker.CreateSessionToken(
 		user.Username,
-		s.config.RefreshTokenDuration,
+		refreshPayload.ID,
+		s.config.AccessTokenDuration,
 	)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,7 +11,6 @@ import (
 	"reflect"
 	"testing"
 
-	"github.com/jackc/pgx"
 	mockdb "github.com/shevgn/simplebank/db/mock"
 	db "github.com/shevgn/simplebank/db/sqlc"
 	"github.com/shevgn/simplebank/util"
@@ -123,7 +123,7 @@ func TestCreateUserAPI(t *testing.T) {
 				store.EXPECT().
 					CreateUser(gomock.Any(), EqCreateUserParams(arg, password)).
 					Times(1).
-					Return(db.User{}, pgx.ErrClosedPool)
+					Return(db.User{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -1,8 +1,13 @@
 package api
 
 import (
+	"slices"
+
 	"github.com/go-playground/validator/v10"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/statement"
 	"github.com/shevgn/simplebank/util"
+	"github.com/shevgn/simplebank/webhook"
 )
 
 var validCurrency validator.Func = func(fl validator.FieldLevel) bool {
@@ -12,3 +17,28 @@ var validCurrency validator.Func = func(fl validator.FieldLevel) bool {
 
 	return false
 }
+
+var validAccountType validator.Func = func(fl validator.FieldLevel) bool {
+	if accountType, ok := fl.Field().Interface().(string); ok {
+		return slices.Contains(db.AccountTypes, accountType)
+	}
+
+	return false
+}
+
+var validWebhookEvent validator.Func = func(fl validator.FieldLevel) bool {
+	if eventType, ok := fl.Field().Interface().(string); ok {
+		return webhook.ValidEventType(eventType)
+	}
+
+	return false
+}
+
+var validStatementFormat validator.Func = func(fl validator.FieldLevel) bool {
+	if format, ok := fl.Field().Interface().(string); ok {
+		_, supported := statement.LookupFormat(format)
+		return supported
+	}
+
+	return false
+}
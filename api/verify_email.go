@@ -0,0 +1,61 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/token"
+)
+
+var errEmailAlreadyVerified = errors.New("email is already verified")
+
+// verifyEmailRequest carries the token from the link in the verification
+// email.
+type verifyEmailRequest struct {
+	Token string `form:"token" binding:"required"`
+}
+
+func (s *Server) verifyEmail(ctx *gin.Context) {
+	var req verifyEmailRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := s.store.VerifyEmailTx(ctx, token.HashOpaqueToken(req.Token))
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidVerifyToken) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// resendVerifyEmail queues a new verification email for the caller. Earlier
+// links stay valid until they expire.
+func (s *Server) resendVerifyEmail(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := s.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if user.IsEmailVerified {
+		ctx.JSON(http.StatusConflict, errorResponse(errEmailAlreadyVerified))
+		return
+	}
+
+	s.sendVerifyEmail(ctx, user.Username)
+
+	ctx.Status(http.StatusAccepted)
+}
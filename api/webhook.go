@@ -0,0 +1,268 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/token"
+	"github.com/shevgn/simplebank/webhook"
+)
+
+var errWebhookNotFound = errors.New("webhook endpoint not found")
+
+// webhookEndpointResponse describes an endpoint without its secret, which is
+// only returned when the endpoint is created.
+type webhookEndpointResponse struct {
+	ID         int64     `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func newWebhookEndpointResponse(endpoint db.WebhookEndpoint) webhookEndpointResponse {
+	return webhookEndpointResponse{
+		ID:         endpoint.ID,
+		URL:        endpoint.Url,
+		EventTypes: endpoint.EventTypes,
+		CreatedAt:  endpoint.CreatedAt.Time,
+	}
+}
+
+// webhookDeliveryResponse is an entry of the delivery log. Payload is the
+// body sent to the endpoint.
+type webhookDeliveryResponse struct {
+	ID             int64           `json:"id"`
+	EndpointID     int64           `json:"endpoint_id"`
+	EventID        int64           `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	ResponseStatus int32           `json:"response_status"`
+	LastError      string          `json:"last_error"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+func newWebhookDeliveryResponse(delivery db.WebhookDelivery) webhookDeliveryResponse {
+	return webhookDeliveryResponse{
+		ID:             delivery.ID,
+		EndpointID:     delivery.EndpointID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Payload:        delivery.Payload,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt.Time,
+		UpdatedAt:      delivery.UpdatedAt.Time,
+	}
+}
+
+// createWebhookEndpointRequest registers an endpoint for the given event
+// types, or for all of them when EventTypes is empty.
+type createWebhookEndpointRequest struct {
+	URL        string   `json:"url"         binding:"required,http_url,max=2048"`
+	EventTypes []string `json:"event_types" binding:"max=10,dive,webhook_event"`
+}
+
+// createWebhookEndpointResponse includes the secret deliveries are signed
+// with. It cannot be retrieved again.
+type createWebhookEndpointResponse struct {
+	ID         int64     `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (s *Server) createWebhookEndpoint(ctx *gin.Context) {
+	var req createWebhookEndpointRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := s.webhooks.CheckURL(req.URL); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if req.EventTypes == nil {
+		req.EventTypes = []string{}
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	endpoint, err := s.store.CreateWebhookEndpoint(ctx, db.CreateWebhookEndpointParams{
+		Owner:      authPayload.Username,
+		Url:        req.URL,
+		Secret:     secret,
+		EventTypes: req.EventTypes,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, createWebhookEndpointResponse{
+		ID:         endpoint.ID,
+		URL:        endpoint.Url,
+		EventTypes: endpoint.EventTypes,
+		Secret:     endpoint.Secret,
+		CreatedAt:  endpoint.CreatedAt.Time,
+	})
+}
+
+func (s *Server) listWebhookEndpoints(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	endpoints, err := s.store.ListWebhookEndpoints(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]webhookEndpointResponse, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		response = append(response, newWebhookEndpointResponse(endpoint))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// webhookEndpointRequest identifies one of the caller's endpoints.
+type webhookEndpointRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getOwnedWebhookEndpoint binds the endpoint ID from the path and loads the
+// endpoint. It writes the error response and returns false unless the
+// endpoint exists and belongs to the caller; endpoints of other users are
+// reported as not found.
+func (s *Server) getOwnedWebhookEndpoint(ctx *gin.Context) (db.WebhookEndpoint, bool) {
+	var req webhookEndpointRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.WebhookEndpoint{}, false
+	}
+
+	endpoint, err := s.store.GetWebhookEndpoint(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(errWebhookNotFound))
+			return endpoint, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return endpoint, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if endpoint.Owner != authPayload.Username {
+		ctx.JSON(http.StatusNotFound, errorResponse(errWebhookNotFound))
+		return endpoint, false
+	}
+
+	return endpoint, true
+}
+
+func (s *Server) deleteWebhookEndpoint(ctx *gin.Context) {
+	endpoint, ok := s.getOwnedWebhookEndpoint(ctx)
+	if !ok {
+		return
+	}
+
+	if err := s.store.DeleteWebhookEndpoint(ctx, endpoint.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// listWebhookDeliveriesRequest pages through an endpoint's delivery log,
+// newest first.
+type listWebhookDeliveriesRequest struct {
+	PageID   int32 `form:"page_id"   binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+func (s *Server) listWebhookDeliveries(ctx *gin.Context) {
+	endpoint, ok := s.getOwnedWebhookEndpoint(ctx)
+	if !ok {
+		return
+	}
+
+	var req listWebhookDeliveriesRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	deliveries, err := s.store.ListWebhookDeliveries(ctx, db.ListWebhookDeliveriesParams{
+		EndpointID: endpoint.ID,
+		Limit:      req.PageSize,
+		Offset:     (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]webhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		response = append(response, newWebhookDeliveryResponse(delivery))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// testWebhookEndpoint sends a test event to the endpoint right away and
+// returns the delivery with the outcome. Test deliveries are not retried.
+func (s *Server) testWebhookEndpoint(ctx *gin.Context) {
+	endpoint, ok := s.getOwnedWebhookEndpoint(ctx)
+	if !ok {
+		return
+	}
+
+	payload, err := webhook.NewTestPayload(endpoint, time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	delivery, err := s.store.CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+		EndpointID: endpoint.ID,
+		EventType:  webhook.EventTest,
+		Payload:    payload,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// A delivery the endpoint rejects is still a successful test; the
+	// outcome is in the returned delivery.
+	delivery, err = s.webhooks.Deliver(ctx, endpoint, delivery, true)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newWebhookDeliveryResponse(delivery))
+}
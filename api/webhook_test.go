@@ -0,0 +1,342 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/util"
+	"github.com/shevgn/simplebank/webhook"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func randomWebhookEndpoint(t *testing.T, owner, url string) db.WebhookEndpoint {
+	secret, err := webhook.NewSecret()
+	require.NoError(t, err)
+
+	return db.WebhookEndpoint{
+		ID:         util.RandomInt(1, 1000),
+		Owner:      owner,
+		Url:        url,
+		Secret:     secret,
+		EventTypes: []string{db.EventAccountCredited},
+		CreatedAt:  pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+}
+
+func TestCreateWebhookEndpointAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          map[string]any
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: map[string]any{
+				"url":         "https://example.com/hooks",
+				"event_types": []string{db.EventAccountCredited, db.EventAccountDebited},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookEndpoint(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateWebhookEndpointParams) (db.WebhookEndpoint, error) {
+						require.Equal(t, user.Username, arg.Owner)
+						require.Equal(t, "https://example.com/hooks", arg.Url)
+						require.True(t, strings.HasPrefix(arg.Secret, "whsec_"))
+
+						return db.WebhookEndpoint{ID: 1, Owner: arg.Owner, Url: arg.Url, Secret: arg.Secret, EventTypes: arg.EventTypes}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response createWebhookEndpointResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.NotEmpty(t, response.Secret)
+				require.Equal(t, []string{db.EventAccountCredited, db.EventAccountDebited}, response.EventTypes)
+			},
+		},
+		{
+			name: "AllEvents",
+			body: map[string]any{"url": "https://example.com/hooks"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookEndpoint(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateWebhookEndpointParams) (db.WebhookEndpoint, error) {
+						require.NotNil(t, arg.EventTypes)
+						require.Empty(t, arg.EventTypes)
+
+						return db.WebhookEndpoint{ID: 1, EventTypes: arg.EventTypes}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidEventType",
+			body: map[string]any{
+				"url":         "https://example.com/hooks",
+				"event_types": []string{"AccountDeleted"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookEndpoint(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "PrivateAddress",
+			body: map[string]any{"url": "http://169.254.169.254/latest/meta-data"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookEndpoint(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidURL",
+			body: map[string]any{"url": "ftp://example.com/hooks"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookEndpoint(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(data))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeleteWebhookEndpointAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	endpoint := randomWebhookEndpoint(t, user.Username, "https://example.com/hooks")
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(endpoint, nil)
+				store.EXPECT().DeleteWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:     "NotOwner",
+			username: "someone_else",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(endpoint, nil)
+				store.EXPECT().DeleteWebhookEndpoint(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).
+					Times(1).
+					Return(db.WebhookEndpoint{}, db.ErrRecordNotFound)
+				store.EXPECT().DeleteWebhookEndpoint(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/webhooks/%d", endpoint.ID), nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListWebhookDeliveriesAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	endpoint := randomWebhookEndpoint(t, user.Username, "https://example.com/hooks")
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(endpoint, nil)
+	store.EXPECT().
+		ListWebhookDeliveries(gomock.Any(), gomock.Eq(db.ListWebhookDeliveriesParams{
+			EndpointID: endpoint.ID,
+			Limit:      5,
+			Offset:     5,
+		})).
+		Times(1).
+		Return([]db.WebhookDelivery{{
+			ID:         1,
+			EndpointID: endpoint.ID,
+			EventType:  db.EventAccountCredited,
+			Payload:    []byte(`{"id":7}`),
+			Status:     db.WebhookDeliverySucceeded,
+		}}, nil)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	url := fmt.Sprintf("/webhooks/%d/deliveries?page_id=2&page_size=5", endpoint.ID)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response []webhookDeliveryResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.Len(t, response, 1)
+	require.JSONEq(t, `{"id":7}`, string(response[0].Payload))
+}
+
+func TestTestWebhookEndpointAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name       string
+		status     int
+		wantStatus string
+	}{
+		{name: "Accepted", status: http.StatusNoContent, wantStatus: db.WebhookDeliverySucceeded},
+		{name: "Rejected", status: http.StatusInternalServerError, wantStatus: db.WebhookDeliveryFailed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var endpoint db.WebhookEndpoint
+
+			received := make(chan struct{}, 1)
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+
+				err = webhook.Verify(endpoint.Secret,
+					r.Header.Get(webhook.SignatureHeader), r.Header.Get(webhook.TimestampHeader),
+					body, time.Now(), time.Minute)
+				require.NoError(t, err)
+				require.Equal(t, webhook.EventTest, r.Header.Get(webhook.EventHeader))
+
+				received <- struct{}{}
+				w.WriteHeader(tc.status)
+			}))
+			defer receiver.Close()
+
+			endpoint = randomWebhookEndpoint(t, user.Username, receiver.URL)
+
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			store.EXPECT().GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(endpoint, nil)
+			store.EXPECT().
+				CreateWebhookDelivery(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.CreateWebhookDeliveryParams) (db.WebhookDelivery, error) {
+					require.Equal(t, endpoint.ID, arg.EndpointID)
+					require.Zero(t, arg.EventID)
+					require.Equal(t, webhook.EventTest, arg.EventType)
+
+					return db.WebhookDelivery{
+						ID:         1,
+						EndpointID: arg.EndpointID,
+						EventType:  arg.EventType,
+						Payload:    arg.Payload,
+						Status:     db.WebhookDeliveryPending,
+					}, nil
+				})
+			store.EXPECT().
+				RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.RecordWebhookDeliveryAttemptParams) (db.WebhookDelivery, error) {
+					require.Equal(t, tc.wantStatus, arg.Status)
+					require.Equal(t, int32(tc.status), arg.ResponseStatus)
+
+					return db.WebhookDelivery{
+						ID:             arg.ID,
+						Status:         arg.Status,
+						Attempts:       1,
+						ResponseStatus: arg.ResponseStatus,
+						LastError:      arg.LastError,
+					}, nil
+				})
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/webhooks/%d/test", endpoint.ID), nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+			require.Len(t, received, 1)
+
+			var response webhookDeliveryResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			require.Equal(t, tc.wantStatus, response.Status)
+		})
+	}
+}
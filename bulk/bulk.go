@@ -0,0 +1,374 @@
+// Package bulk executes batches of transfers imported from a file, such as
+// a payroll run.
+//
+// Every transfer in a batch is validated before any of them is executed.
+// An atomic batch is rejected when a transfer is invalid and books the rest
+// in a single transaction, so they all happen or none does. A best effort
+// batch skips invalid transfers and books every other one on its own.
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/metrics"
+	"github.com/shevgn/simplebank/util"
+)
+
+// MaxTransfers is the most transfers one batch may hold.
+const MaxTransfers = 1000
+
+// Mode says what happens to a batch when some of its transfers fail.
+type Mode string
+
+// Batch modes
+const (
+	ModeAtomic     Mode = "atomic"
+	ModeBestEffort Mode = "best_effort"
+)
+
+// File formats a batch can be imported from
+const (
+	FormatCSV     = "csv"
+	FormatPain001 = "pain.001"
+)
+
+// Statuses of a single transfer in a batch
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusInvalid   = "invalid"
+	// StatusRolledBack marks the other transfers of an atomic batch in
+	// which one failed.
+	StatusRolledBack = "rolled_back"
+	// StatusNotExecuted marks the valid transfers of a rejected batch.
+	StatusNotExecuted = "not_executed"
+)
+
+// Statuses of a batch
+const (
+	BatchCompleted          = "completed"
+	BatchPartiallyCompleted = "partially_completed"
+	BatchFailed             = "failed"
+	// BatchRejected means validation failed and nothing was executed.
+	BatchRejected = "rejected"
+)
+
+// ErrUnknownFormat is returned by Parse for a format it cannot read.
+var ErrUnknownFormat = errors.New("unknown bulk transfer format")
+
+// Instruction is a transfer read from a file. Number is its position in the
+// file, from 1.
+type Instruction struct {
+	Number        int
+	FromAccountID int64
+	ToAccountID   int64
+	Amount        int64
+	Currency      string
+	Reference     string
+
+	// err is why a field of the transfer could not be read.
+	err error
+}
+
+// Result is the outcome of one transfer of a batch.
+type Result struct {
+	Number        int          `json:"number"`
+	FromAccountID int64        `json:"from_account_id"`
+	ToAccountID   int64        `json:"to_account_id"`
+	Amount        int64        `json:"amount"`
+	Currency      string       `json:"currency"`
+	Reference     string       `json:"reference"`
+	Status        string       `json:"status"`
+	Error         string       `json:"error,omitempty"`
+	Transfer      *db.Transfer `json:"transfer,omitempty"`
+}
+
+// Batch is the outcome of a batch.
+type Batch struct {
+	Mode      Mode     `json:"mode"`
+	Status    string   `json:"status"`
+	Succeeded int      `json:"succeeded"`
+	Failed    int      `json:"failed"`
+	Results   []Result `json:"results"`
+}
+
+// Store is the subset of db.Store used to execute batches.
+type Store interface {
+	GetAccount(ctx context.Context, id int64) (db.Account, error)
+	IsSystemAccount(ctx context.Context, accountID int64) (bool, error)
+	TransferTx(ctx context.Context, args db.TransferTxParams) (db.TransferTxResult, error)
+	BulkTransferTx(ctx context.Context, args []db.TransferTxParams) ([]db.TransferTxResult, error)
+}
+
+// Parse reads the transfers of a file in format.
+func Parse(format string, r io.Reader) ([]Instruction, error) {
+	switch format {
+	case FormatCSV:
+		return ParseCSV(r)
+	case FormatPain001:
+		return ParsePain001(r)
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+	}
+}
+
+// Execute validates instructions on behalf of owner, who must own every
+// source account, and then executes them in mode. The returned error is
+// only set when the batch could not be processed at all.
+func Execute(ctx context.Context, store Store, owner string, instructions []Instruction, mode Mode) (Batch, error) {
+	batch := Batch{
+		Mode:    mode,
+		Results: make([]Result, len(instructions)),
+	}
+
+	v := validator{
+		store:    store,
+		owner:    owner,
+		accounts: make(map[int64]db.Account),
+		system:   make(map[int64]bool),
+	}
+	var valid []int
+
+	for i, instruction := range instructions {
+		result := &batch.Results[i]
+		*result = Result{
+			Number:        instruction.Number,
+			FromAccountID: instruction.FromAccountID,
+			ToAccountID:   instruction.ToAccountID,
+			Amount:        instruction.Amount,
+			Currency:      instruction.Currency,
+			Reference:     instruction.Reference,
+		}
+
+		problem, err := v.validate(ctx, instruction)
+		if err != nil {
+			return batch, err
+		}
+
+		if problem != nil {
+			result.Status = StatusInvalid
+			result.Error = problem.Error()
+			batch.Failed++
+			continue
+		}
+
+		valid = append(valid, i)
+	}
+
+	switch {
+	case mode == ModeAtomic && batch.Failed > 0:
+		for _, i := range valid {
+			batch.Results[i].Status = StatusNotExecuted
+		}
+
+		batch.Status = BatchRejected
+
+		return batch, nil
+	case mode == ModeAtomic:
+		if err := executeAtomic(ctx, store, &batch, valid); err != nil {
+			return batch, err
+		}
+	default:
+		executeBestEffort(ctx, store, &batch, valid)
+	}
+
+	switch {
+	case batch.Failed == 0:
+		batch.Status = BatchCompleted
+	case batch.Succeeded == 0:
+		batch.Status = BatchFailed
+	default:
+		batch.Status = BatchPartiallyCompleted
+	}
+
+	return batch, nil
+}
+
+func executeAtomic(ctx context.Context, store Store, batch *Batch, valid []int) error {
+	args := make([]db.TransferTxParams, len(valid))
+	for j, i := range valid {
+		args[j] = transferParams(batch.Results[i])
+	}
+
+	results, err := store.BulkTransferTx(ctx, args)
+	if err != nil {
+		var transferErr *db.BulkTransferError
+		if !errors.As(err, &transferErr) {
+			return fmt.Errorf("cannot execute batch: %w", err)
+		}
+
+		for j, i := range valid {
+			if j == transferErr.Index {
+				batch.Results[i].Status = StatusFailed
+				batch.Results[i].Error = transferErr.Err.Error()
+			} else {
+				batch.Results[i].Status = StatusRolledBack
+			}
+		}
+
+		batch.Failed = len(batch.Results)
+
+		return nil
+	}
+
+	for j, i := range valid {
+		succeed(batch, i, results[j])
+	}
+
+	return nil
+}
+
+func executeBestEffort(ctx context.Context, store Store, batch *Batch, valid []int) {
+	// Once started, the batch runs to the end even if the client goes away,
+	// rather than stopping at a point the client cannot know.
+	ctx = context.WithoutCancel(ctx)
+
+	for _, i := range valid {
+		result, err := store.TransferTx(ctx, transferParams(batch.Results[i]))
+		if err != nil {
+			batch.Results[i].Status = StatusFailed
+			batch.Results[i].Error = err.Error()
+			batch.Failed++
+			continue
+		}
+
+		succeed(batch, i, result)
+	}
+}
+
+func succeed(batch *Batch, i int, result db.TransferTxResult) {
+	batch.Results[i].Status = StatusSucceeded
+	batch.Results[i].Transfer = &result.Transfer
+	batch.Succeeded++
+
+	metrics.ObserveTransfer(batch.Results[i].Currency, batch.Results[i].Amount)
+}
+
+func transferParams(result Result) db.TransferTxParams {
+	return db.TransferTxParams{
+		FromAccountID: result.FromAccountID,
+		ToAccountID:   result.ToAccountID,
+		Amount:        result.Amount,
+	}
+}
+
+// validator checks instructions against the accounts they name, loading
+// each account once per batch.
+type validator struct {
+	store    Store
+	owner    string
+	accounts map[int64]db.Account
+	// system tells which target accounts are system accounts
+	system map[int64]bool
+}
+
+// validate returns what is wrong with instruction, or an error when the
+// accounts cannot be loaded.
+func (v *validator) validate(ctx context.Context, instruction Instruction) (problem error, err error) {
+	switch {
+	case instruction.err != nil:
+		return instruction.err, nil
+	case instruction.Amount <= 0:
+		return errors.New("amount must be greater than 0"), nil
+	case !util.IsSupportedCurrency(instruction.Currency):
+		return fmt.Errorf("currency %q is not supported", instruction.Currency), nil
+	case instruction.FromAccountID == instruction.ToAccountID:
+		return errors.New("cannot transfer to the same account"), nil
+	}
+
+	for _, id := range []int64{instruction.FromAccountID, instruction.ToAccountID} {
+		account, found, err := v.account(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case !found:
+			return fmt.Errorf("account %d not found", id), nil
+		case id == instruction.FromAccountID && account.Owner != v.owner:
+			return fmt.Errorf("account %d does not belong to the user", id), nil
+		case account.Currency != instruction.Currency:
+			return fmt.Errorf("account %d has currency %s, but %s was requested",
+				id, account.Currency, instruction.Currency), nil
+		}
+
+		if problem := db.CheckActive(account); problem != nil {
+			return problem, nil
+		}
+	}
+
+	isSystem, err := v.isSystemAccount(ctx, instruction.ToAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if isSystem {
+		return fmt.Errorf("account %d: %w", instruction.ToAccountID, db.ErrSystemAccount), nil
+	}
+
+	return nil, nil
+}
+
+func (v *validator) account(ctx context.Context, id int64) (db.Account, bool, error) {
+	if account, ok := v.accounts[id]; ok {
+		return account, true, nil
+	}
+
+	account, err := v.store.GetAccount(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return account, false, nil
+		}
+
+		return account, false, fmt.Errorf("cannot get account %d: %w", id, err)
+	}
+
+	v.accounts[id] = account
+
+	return account, true, nil
+}
+
+func (v *validator) isSystemAccount(ctx context.Context, id int64) (bool, error) {
+	if isSystem, ok := v.system[id]; ok {
+		return isSystem, nil
+	}
+
+	isSystem, err := v.store.IsSystemAccount(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("cannot check account %d: %w", id, err)
+	}
+
+	v.system[id] = isSystem
+
+	return isSystem, nil
+}
+
+// parseAmount parses a decimal amount of major units with at most two
+// significant decimal places, such as "1250.50", into minor units.
+func parseAmount(s string) (int64, error) {
+	units, cents, hasCents := strings.Cut(s, ".")
+	if len(cents) > 2 && strings.Trim(cents[2:], "0") == "" {
+		cents = cents[:2]
+	}
+
+	if units == "" || (hasCents && (cents == "" || len(cents) > 2)) {
+		return 0, fmt.Errorf("amount %q is not a decimal with at most two decimal places", s)
+	}
+
+	for len(cents) < 2 {
+		cents += "0"
+	}
+
+	amount, err := strconv.ParseUint(units+cents, 10, 63)
+	if err != nil {
+		return 0, fmt.Errorf("amount %q is not a decimal with at most two decimal places", s)
+	}
+
+	return int64(amount), nil
+}
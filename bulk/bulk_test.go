@@ -0,0 +1,188 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	payer    = db.Account{ID: 1, Owner: "acme", Currency: "USD", Status: db.AccountStatusActive}
+	alice    = db.Account{ID: 2, Owner: "alice", Currency: "USD", Status: db.AccountStatusActive}
+	bob      = db.Account{ID: 3, Owner: "bob", Currency: "USD", Status: db.AccountStatusActive}
+	euros    = db.Account{ID: 4, Owner: "carol", Currency: "EUR", Status: db.AccountStatusActive}
+	fees     = db.Account{ID: 5, Owner: db.BankUsername, Currency: "USD", Status: db.AccountStatusActive}
+	accounts = []db.Account{payer, alice, bob, euros, fees}
+)
+
+func payroll() []Instruction {
+	return []Instruction{
+		{Number: 1, FromAccountID: 1, ToAccountID: 2, Amount: 1000, Currency: "USD", Reference: "ALICE"},
+		{Number: 2, FromAccountID: 1, ToAccountID: 3, Amount: 2000, Currency: "USD", Reference: "BOB"},
+	}
+}
+
+func newStore(t *testing.T) *mockdb.MockStore {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+
+	for _, account := range accounts {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).AnyTimes().Return(account, nil)
+	}
+
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).AnyTimes().Return(db.Account{}, db.ErrRecordNotFound)
+	store.EXPECT().IsSystemAccount(gomock.Any(), gomock.Eq(fees.ID)).AnyTimes().Return(true, nil)
+	store.EXPECT().IsSystemAccount(gomock.Any(), gomock.Any()).AnyTimes().Return(false, nil)
+
+	return store
+}
+
+func transferResult(arg db.TransferTxParams) db.TransferTxResult {
+	return db.TransferTxResult{Transfer: db.Transfer{
+		ID:            arg.ToAccountID * 10,
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+	}}
+}
+
+func statuses(batch Batch) []string {
+	statuses := make([]string, len(batch.Results))
+	for i, result := range batch.Results {
+		statuses[i] = result.Status
+	}
+
+	return statuses
+}
+
+func TestExecuteAtomic(t *testing.T) {
+	store := newStore(t)
+	store.EXPECT().
+		BulkTransferTx(gomock.Any(), gomock.Eq([]db.TransferTxParams{
+			{FromAccountID: 1, ToAccountID: 2, Amount: 1000},
+			{FromAccountID: 1, ToAccountID: 3, Amount: 2000},
+		})).
+		Times(1).
+		DoAndReturn(func(_ context.Context, args []db.TransferTxParams) ([]db.TransferTxResult, error) {
+			results := make([]db.TransferTxResult, len(args))
+			for i, arg := range args {
+				results[i] = transferResult(arg)
+			}
+
+			return results, nil
+		})
+
+	batch, err := Execute(context.Background(), store, payer.Owner, payroll(), ModeAtomic)
+	require.NoError(t, err)
+
+	require.Equal(t, BatchCompleted, batch.Status)
+	require.Equal(t, 2, batch.Succeeded)
+	require.Zero(t, batch.Failed)
+	require.Equal(t, []string{StatusSucceeded, StatusSucceeded}, statuses(batch))
+	require.Equal(t, int64(30), batch.Results[1].Transfer.ID)
+	require.Equal(t, "BOB", batch.Results[1].Reference)
+}
+
+func TestExecuteAtomicRejected(t *testing.T) {
+	instructions := append(payroll(),
+		Instruction{Number: 3, FromAccountID: 1, ToAccountID: 4, Amount: 500, Currency: "USD"},
+		Instruction{Number: 4, FromAccountID: 2, ToAccountID: 3, Amount: 500, Currency: "USD"},
+		Instruction{Number: 5, FromAccountID: 1, ToAccountID: 99, Amount: 500, Currency: "USD"},
+		Instruction{Number: 6, FromAccountID: 1, ToAccountID: 1, Amount: 500, Currency: "USD"},
+		Instruction{Number: 7, FromAccountID: 1, ToAccountID: 2, Amount: 0, Currency: "USD"},
+		Instruction{Number: 8, FromAccountID: 1, ToAccountID: 2, Amount: 500, Currency: "XYZ"},
+		Instruction{Number: 9, FromAccountID: 1, ToAccountID: 5, Amount: 500, Currency: "USD"},
+		Instruction{Number: 10, err: errors.New("amount \"x\" is not a decimal")},
+	)
+
+	store := newStore(t)
+	store.EXPECT().BulkTransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+	batch, err := Execute(context.Background(), store, payer.Owner, instructions, ModeAtomic)
+	require.NoError(t, err)
+
+	require.Equal(t, BatchRejected, batch.Status)
+	require.Equal(t, 8, batch.Failed)
+	require.Equal(t, StatusNotExecuted, batch.Results[0].Status)
+	require.Equal(t, StatusNotExecuted, batch.Results[1].Status)
+
+	wantErrors := []string{
+		"account 4 has currency EUR, but USD was requested",
+		"account 2 does not belong to the user",
+		"account 99 not found",
+		"cannot transfer to the same account",
+		"amount must be greater than 0",
+		`currency "XYZ" is not supported`,
+		"account 5: system accounts cannot receive transfers",
+		`amount "x" is not a decimal`,
+	}
+	for i, want := range wantErrors {
+		require.Equal(t, StatusInvalid, batch.Results[i+2].Status)
+		require.Equal(t, want, batch.Results[i+2].Error)
+	}
+}
+
+func TestExecuteAtomicRolledBack(t *testing.T) {
+	store := newStore(t)
+	store.EXPECT().
+		BulkTransferTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil, &db.BulkTransferError{Index: 1, Err: db.ErrAccountFrozen})
+
+	batch, err := Execute(context.Background(), store, payer.Owner, payroll(), ModeAtomic)
+	require.NoError(t, err)
+
+	require.Equal(t, BatchFailed, batch.Status)
+	require.Zero(t, batch.Succeeded)
+	require.Equal(t, 2, batch.Failed)
+	require.Equal(t, []string{StatusRolledBack, StatusFailed}, statuses(batch))
+	require.Equal(t, db.ErrAccountFrozen.Error(), batch.Results[1].Error)
+	require.Nil(t, batch.Results[0].Transfer)
+}
+
+func TestExecuteAtomicError(t *testing.T) {
+	store := newStore(t)
+	store.EXPECT().BulkTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(nil, context.DeadlineExceeded)
+
+	_, err := Execute(context.Background(), store, payer.Owner, payroll(), ModeAtomic)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestExecuteBestEffort(t *testing.T) {
+	instructions := append(payroll(),
+		Instruction{Number: 3, FromAccountID: 1, ToAccountID: 99, Amount: 500, Currency: "USD"},
+	)
+
+	store := newStore(t)
+	store.EXPECT().
+		TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{FromAccountID: 1, ToAccountID: 2, Amount: 1000})).
+		Times(1).
+		Return(db.TransferTxResult{}, db.ErrEmailNotVerified)
+	store.EXPECT().
+		TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{FromAccountID: 1, ToAccountID: 3, Amount: 2000})).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.TransferTxParams) (db.TransferTxResult, error) {
+			return transferResult(arg), nil
+		})
+
+	batch, err := Execute(context.Background(), store, payer.Owner, instructions, ModeBestEffort)
+	require.NoError(t, err)
+
+	require.Equal(t, BatchPartiallyCompleted, batch.Status)
+	require.Equal(t, 1, batch.Succeeded)
+	require.Equal(t, 2, batch.Failed)
+	require.Equal(t, []string{StatusFailed, StatusSucceeded, StatusInvalid}, statuses(batch))
+	require.Equal(t, db.ErrEmailNotVerified.Error(), batch.Results[0].Error)
+}
+
+func TestExecuteGetAccountError(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, context.Canceled)
+
+	_, err := Execute(context.Background(), store, payer.Owner, payroll(), ModeBestEffort)
+	require.ErrorIs(t, err, context.Canceled)
+}
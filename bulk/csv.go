@@ -0,0 +1,100 @@
+package bulk
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// csvColumns are the columns a CSV batch must have. A "reference" column,
+// echoed back in the results, is optional, and columns may come in any
+// order.
+var csvColumns = []string{"from_account_id", "to_account_id", "amount", "currency"}
+
+// ParseCSV reads a CSV batch: a header row naming the columns, then a row
+// per transfer with the amount as a decimal, such as "1250.50".
+func ParseCSV(r io.Reader) ([]Instruction, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("the file has no header row")
+		}
+
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range csvColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("the header row has no %s column", name)
+		}
+	}
+
+	var instructions []Instruction
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(instructions) == MaxTransfers {
+			return nil, fmt.Errorf("a batch holds at most %d transfers", MaxTransfers)
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok {
+				return ""
+			}
+
+			return strings.TrimSpace(record[i])
+		}
+
+		instruction := Instruction{
+			Number:    len(instructions) + 1,
+			Currency:  strings.ToUpper(field("currency")),
+			Reference: field("reference"),
+		}
+
+		instruction.FromAccountID, instruction.err = parseAccountID("from_account_id", field("from_account_id"))
+		if instruction.err == nil {
+			instruction.ToAccountID, instruction.err = parseAccountID("to_account_id", field("to_account_id"))
+		}
+
+		if instruction.err == nil {
+			instruction.Amount, instruction.err = parseAmount(field("amount"))
+		}
+
+		instructions = append(instructions, instruction)
+	}
+
+	if len(instructions) == 0 {
+		return nil, errors.New("the file has no transfers")
+	}
+
+	return instructions, nil
+}
+
+func parseAccountID(column, value string) (int64, error) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("%s %q is not an account id", column, value)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,124 @@
+package bulk
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// pain001Namespace prefixes the namespace of every version of pain.001.
+const pain001Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.001.001."
+
+// painDocument holds the parts of a pain.001 customer credit transfer
+// initiation that a batch uses. The paths are the same in versions 03 to 12.
+type painDocument struct {
+	XMLName    xml.Name `xml:"Document"`
+	Initiation struct {
+		NumberOfTransactions string `xml:"GrpHdr>NbOfTxs"`
+		ControlSum           string `xml:"GrpHdr>CtrlSum"`
+		Payments             []struct {
+			DebtorAccount painAccount `xml:"DbtrAcct"`
+			Transactions  []struct {
+				EndToEndID string `xml:"PmtId>EndToEndId"`
+				Amount     struct {
+					Value    string `xml:",chardata"`
+					Currency string `xml:"Ccy,attr"`
+				} `xml:"Amt>InstdAmt"`
+				CreditorAccount painAccount `xml:"CdtrAcct"`
+			} `xml:"CdtTrfTxInf"`
+		} `xml:"PmtInf"`
+	} `xml:"CstmrCdtTrfInitn"`
+}
+
+// painAccount identifies an account by its number in Othr/Id. Accounts
+// here have no IBAN.
+type painAccount struct {
+	ID   string `xml:"Id>Othr>Id"`
+	IBAN string `xml:"Id>IBAN"`
+}
+
+func (a painAccount) id(element string) (int64, error) {
+	if a.ID == "" && a.IBAN != "" {
+		return 0, fmt.Errorf("%s is an IBAN, but only account numbers in Othr/Id are supported", element)
+	}
+
+	return parseAccountID(element, strings.TrimSpace(a.ID))
+}
+
+// ParsePain001 reads an ISO 20022 pain.001 customer credit transfer
+// initiation. Every CdtTrfTxInf is a transfer from the DbtrAcct of its
+// PmtInf, with the EndToEndId as its reference. The NbOfTxs and CtrlSum of
+// the group header must match the transfers.
+func ParsePain001(r io.Reader) ([]Instruction, error) {
+	var document painDocument
+	if err := xml.NewDecoder(r).Decode(&document); err != nil {
+		return nil, fmt.Errorf("cannot read pain.001 document: %w", err)
+	}
+
+	if !strings.HasPrefix(document.XMLName.Space, pain001Namespace) {
+		return nil, fmt.Errorf("namespace %q is not a pain.001 namespace", document.XMLName.Space)
+	}
+
+	var instructions []Instruction
+	var controlSum int64
+	// The control sum is only checked when every amount could be read.
+	summed := true
+
+	for _, payment := range document.Initiation.Payments {
+		from, fromErr := payment.DebtorAccount.id("DbtrAcct")
+
+		for _, transaction := range payment.Transactions {
+			if len(instructions) == MaxTransfers {
+				return nil, fmt.Errorf("a batch holds at most %d transfers", MaxTransfers)
+			}
+
+			instruction := Instruction{
+				Number:        len(instructions) + 1,
+				FromAccountID: from,
+				Currency:      transaction.Amount.Currency,
+				Reference:     transaction.EndToEndID,
+				err:           fromErr,
+			}
+
+			if instruction.err == nil {
+				instruction.ToAccountID, instruction.err = transaction.CreditorAccount.id("CdtrAcct")
+			}
+
+			amount, err := parseAmount(strings.TrimSpace(transaction.Amount.Value))
+			if err == nil {
+				instruction.Amount = amount
+				controlSum += amount
+			} else {
+				summed = false
+				if instruction.err == nil {
+					instruction.err = err
+				}
+			}
+
+			instructions = append(instructions, instruction)
+		}
+	}
+
+	if len(instructions) == 0 {
+		return nil, errors.New("the document has no transfers")
+	}
+
+	count, err := strconv.Atoi(document.Initiation.NumberOfTransactions)
+	if err != nil || count != len(instructions) {
+		return nil, fmt.Errorf("NbOfTxs %q does not match the %d transfers",
+			document.Initiation.NumberOfTransactions, len(instructions))
+	}
+
+	if document.Initiation.ControlSum != "" && summed {
+		sum, err := parseAmount(strings.TrimSpace(document.Initiation.ControlSum))
+		if err != nil || sum != controlSum {
+			return nil, fmt.Errorf("CtrlSum %q does not match the sum of the transfers",
+				document.Initiation.ControlSum)
+		}
+	}
+
+	return instructions, nil
+}
@@ -0,0 +1,140 @@
+package bulk
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCSV(t *testing.T) {
+	file := "amount, to_account_id,from_account_id,currency,reference\n" +
+		"1250.5,2,1,usd,SALARY-ALICE\n" +
+		"\n" +
+		"12.345,3,1,USD,\n" +
+		"10,x,1,USD,\n"
+
+	instructions, err := ParseCSV(strings.NewReader(file))
+	require.NoError(t, err)
+	require.Len(t, instructions, 3)
+
+	require.Equal(t, Instruction{
+		Number:        1,
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        125050,
+		Currency:      "USD",
+		Reference:     "SALARY-ALICE",
+	}, instructions[0])
+
+	require.Equal(t, 2, instructions[1].Number)
+	require.ErrorContains(t, instructions[1].err, "at most two decimal places")
+	require.ErrorContains(t, instructions[2].err, `to_account_id "x" is not an account id`)
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		file string
+		want string
+	}{
+		{name: "Empty", file: "", want: "no header row"},
+		{name: "MissingColumn", file: "from_account_id,to_account_id,amount\n1,2,3\n", want: "no currency column"},
+		{name: "NoTransfers", file: "from_account_id,to_account_id,amount,currency\n", want: "no transfers"},
+		{name: "WrongFieldCount", file: "from_account_id,to_account_id,amount,currency\n1,2,3\n", want: "wrong number of fields"},
+		{
+			name: "TooMany",
+			file: "from_account_id,to_account_id,amount,currency\n" + strings.Repeat("1,2,3,USD\n", MaxTransfers+1),
+			want: "at most 1000 transfers",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseCSV(strings.NewReader(tc.file))
+			require.ErrorContains(t, err, tc.want)
+		})
+	}
+}
+
+func TestParsePain001(t *testing.T) {
+	file, err := os.Open("testdata/payroll.pain.001.xml")
+	require.NoError(t, err)
+	defer file.Close()
+
+	instructions, err := ParsePain001(file)
+	require.NoError(t, err)
+	require.Len(t, instructions, 3)
+
+	require.Equal(t, Instruction{
+		Number:        2,
+		FromAccountID: 1,
+		ToAccountID:   3,
+		Amount:        225125,
+		Currency:      "USD",
+		Reference:     "SALARY-BOB",
+	}, instructions[1])
+
+	require.ErrorContains(t, instructions[2].err, "only account numbers in Othr/Id are supported")
+}
+
+func TestParsePain001Errors(t *testing.T) {
+	data, err := os.ReadFile("testdata/payroll.pain.001.xml")
+	require.NoError(t, err)
+	document := string(data)
+
+	testCases := []struct {
+		name string
+		file string
+		want string
+	}{
+		{name: "NotXML", file: "from_account_id,to_account_id", want: "cannot read pain.001 document"},
+		{
+			name: "Namespace",
+			file: strings.Replace(document, "pain.001.001.03", "pain.008.001.02", 1),
+			want: "is not a pain.001 namespace",
+		},
+		{
+			name: "NumberOfTransactions",
+			file: strings.Replace(document, "<NbOfTxs>3</NbOfTxs>", "<NbOfTxs>2</NbOfTxs>", 1),
+			want: `NbOfTxs "2" does not match the 3 transfers`,
+		},
+		{
+			name: "ControlSum",
+			file: strings.Replace(document, "4751.25", "4751.20", 1),
+			want: `CtrlSum "4751.20" does not match`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParsePain001(strings.NewReader(tc.file))
+			require.ErrorContains(t, err, tc.want)
+		})
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	for input, want := range map[string]int64{
+		"0":       0,
+		"7":       700,
+		"12.3":    1230,
+		"12.34":   1234,
+		"12.3400": 1234,
+	} {
+		amount, err := parseAmount(input)
+		require.NoError(t, err, input)
+		require.Equal(t, want, amount, input)
+	}
+
+	for _, input := range []string{"", "-5", "1.", ".5", "1.234", "1,50", "1e3"} {
+		_, err := parseAmount(input)
+		require.Error(t, err, input)
+	}
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	_, err := Parse("xlsx", strings.NewReader(""))
+	require.ErrorIs(t, err, ErrUnknownFormat)
+}
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "sessions_username_idx";
+
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "accounts_status_check";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "status";
+
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "is_disabled";
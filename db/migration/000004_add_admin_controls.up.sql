@@ -0,0 +1,7 @@
+ALTER TABLE "users" ADD COLUMN "is_disabled" boolean NOT NULL DEFAULT false;
+
+ALTER TABLE "accounts" ADD COLUMN "status" varchar NOT NULL DEFAULT 'active';
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_status_check" CHECK ("status" IN ('active', 'frozen'));
+
+CREATE INDEX ON "sessions" ("username");
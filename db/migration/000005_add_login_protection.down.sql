@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "rate_limits";
+
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "locked_until";
+
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "failed_login_attempts";
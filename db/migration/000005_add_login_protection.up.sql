@@ -0,0 +1,14 @@
+ALTER TABLE "users" ADD COLUMN "failed_login_attempts" integer NOT NULL DEFAULT 0;
+
+ALTER TABLE "users" ADD COLUMN "locked_until" timestamp with time zone NOT NULL DEFAULT ('0001-01-01 00:00:00Z');
+
+CREATE TABLE "rate_limits" (
+  "key" varchar PRIMARY KEY,
+  "tokens" double precision NOT NULL,
+  "allowed" boolean NOT NULL,
+  "updated_at" timestamp with time zone NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "rate_limits" ("updated_at");
+
+COMMENT ON COLUMN "rate_limits"."allowed" IS 'Whether the last request was allowed';
@@ -0,0 +1,14 @@
+CREATE TABLE "password_reset_tokens" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "token_hash" varchar UNIQUE NOT NULL,
+  "is_used" boolean NOT NULL DEFAULT false,
+  "created_at" timestamp with time zone NOT NULL DEFAULT (now()),
+  "expires_at" timestamp with time zone NOT NULL
+);
+
+CREATE INDEX ON "password_reset_tokens" ("username");
+
+ALTER TABLE "password_reset_tokens" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+COMMENT ON COLUMN "password_reset_tokens"."token_hash" IS 'SHA-256 of the token mailed to the user; the token itself is never stored';
@@ -0,0 +1,20 @@
+ALTER TABLE "users" ADD COLUMN "is_email_verified" boolean NOT NULL DEFAULT false;
+
+-- Users created before verification existed keep their access to transfers.
+UPDATE "users" SET "is_email_verified" = true;
+
+CREATE TABLE "verify_emails" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "email" varchar NOT NULL,
+  "token_hash" varchar UNIQUE NOT NULL,
+  "is_used" boolean NOT NULL DEFAULT false,
+  "created_at" timestamp with time zone NOT NULL DEFAULT (now()),
+  "expires_at" timestamp with time zone NOT NULL
+);
+
+CREATE INDEX ON "verify_emails" ("username");
+
+ALTER TABLE "verify_emails" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+COMMENT ON COLUMN "verify_emails"."email" IS 'Address the token was sent to; it only verifies the user while their email is unchanged';
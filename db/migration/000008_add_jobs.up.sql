@@ -0,0 +1,22 @@
+CREATE TABLE "jobs" (
+  "id" bigserial PRIMARY KEY,
+  "type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "attempts" integer NOT NULL DEFAULT 0,
+  "max_attempts" integer NOT NULL,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "run_at" timestamp with time zone NOT NULL DEFAULT (now()),
+  "locked_at" timestamp with time zone NOT NULL DEFAULT ('0001-01-01 00:00:00Z'),
+  "created_at" timestamp with time zone NOT NULL DEFAULT (now()),
+  "updated_at" timestamp with time zone NOT NULL DEFAULT (now()),
+  CONSTRAINT "jobs_status_check" CHECK ("status" IN ('pending', 'running', 'done', 'dead'))
+);
+
+CREATE INDEX "jobs_pending_run_at_idx" ON "jobs" ("run_at") WHERE "status" = 'pending';
+
+CREATE INDEX ON "jobs" ("status", "updated_at");
+
+COMMENT ON COLUMN "jobs"."attempts" IS 'Number of times the job has been claimed, including the current run';
+
+COMMENT ON COLUMN "jobs"."locked_at" IS 'When a processor claimed the job; running jobs locked for too long are requeued';
@@ -0,0 +1,20 @@
+CREATE TABLE "outbox" (
+  "id" bigserial PRIMARY KEY,
+  "aggregate_type" varchar NOT NULL,
+  "aggregate_id" bigint NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "is_published" boolean NOT NULL DEFAULT false,
+  "attempts" integer NOT NULL DEFAULT 0,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "created_at" timestamp with time zone NOT NULL DEFAULT (now()),
+  "published_at" timestamp with time zone NOT NULL DEFAULT ('0001-01-01 00:00:00Z')
+);
+
+CREATE INDEX "outbox_unpublished_idx" ON "outbox" ("id") WHERE NOT "is_published";
+
+CREATE INDEX ON "outbox" ("aggregate_type", "aggregate_id");
+
+COMMENT ON TABLE "outbox" IS 'Domain events written in the transaction that caused them and relayed to sinks in id order';
+
+COMMENT ON COLUMN "outbox"."attempts" IS 'Number of failed publish attempts';
@@ -0,0 +1,41 @@
+CREATE TABLE "webhook_endpoints" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "url" varchar NOT NULL,
+  "secret" varchar NOT NULL,
+  "event_types" varchar[] NOT NULL DEFAULT '{}',
+  "created_at" timestamp with time zone NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "webhook_endpoints" ("owner");
+
+ALTER TABLE "webhook_endpoints" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+COMMENT ON COLUMN "webhook_endpoints"."secret" IS 'HMAC-SHA256 key for signing deliveries; kept in plain text as it is needed to sign';
+
+COMMENT ON COLUMN "webhook_endpoints"."event_types" IS 'Event types delivered to the endpoint; empty for all';
+
+CREATE TABLE "webhook_deliveries" (
+  "id" bigserial PRIMARY KEY,
+  "endpoint_id" bigint NOT NULL,
+  "event_id" bigint NOT NULL DEFAULT 0,
+  "event_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "attempts" integer NOT NULL DEFAULT 0,
+  "response_status" integer NOT NULL DEFAULT 0,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "created_at" timestamp with time zone NOT NULL DEFAULT (now()),
+  "updated_at" timestamp with time zone NOT NULL DEFAULT (now()),
+  CONSTRAINT "webhook_deliveries_status_check" CHECK ("status" IN ('pending', 'succeeded', 'failed'))
+);
+
+CREATE INDEX ON "webhook_deliveries" ("endpoint_id", "id");
+
+CREATE UNIQUE INDEX "webhook_deliveries_endpoint_event_idx" ON "webhook_deliveries" ("endpoint_id", "event_id") WHERE "event_id" > 0;
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("endpoint_id") REFERENCES "webhook_endpoints" ("id") ON DELETE CASCADE;
+
+COMMENT ON COLUMN "webhook_deliveries"."event_id" IS 'Outbox event delivered; 0 for test deliveries';
+
+COMMENT ON COLUMN "webhook_deliveries"."response_status" IS 'HTTP status of the last attempt; 0 if no response was received';
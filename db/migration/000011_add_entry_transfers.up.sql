@@ -0,0 +1,16 @@
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint NOT NULL DEFAULT 0;
+
+-- Entries written by TransferTx share the transfer's timestamp, as now() is
+-- fixed for the duration of a transaction.
+UPDATE "entries" AS e
+SET "transfer_id" = t."id"
+FROM "transfers" AS t
+WHERE e."created_at" = t."created_at"
+  AND (
+    (e."account_id" = t."from_account_id" AND e."amount" = -t."amount")
+    OR (e."account_id" = t."to_account_id" AND e."amount" = t."amount")
+  );
+
+CREATE INDEX ON "entries" ("account_id", "created_at");
+
+COMMENT ON COLUMN "entries"."transfer_id" IS 'Transfer that created the entry, 0 for other entries';
@@ -0,0 +1,9 @@
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "closed_at";
+
+-- Closed accounts have no balance and take no transfers, which is what a
+-- frozen account is to the previous version.
+UPDATE "accounts" SET "status" = 'frozen' WHERE "status" = 'closed';
+
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "accounts_status_check";
+
+ALTER TABLE IF EXISTS "accounts" ADD CONSTRAINT "accounts_status_check" CHECK ("status" IN ('active', 'frozen'));
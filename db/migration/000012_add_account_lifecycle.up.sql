@@ -0,0 +1,7 @@
+ALTER TABLE "accounts" DROP CONSTRAINT "accounts_status_check";
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_status_check" CHECK ("status" IN ('active', 'frozen', 'closed'));
+
+ALTER TABLE "accounts" ADD COLUMN "closed_at" timestamp with time zone NOT NULL DEFAULT ('0001-01-01 00:00:00Z');
+
+COMMENT ON COLUMN "accounts"."closed_at" IS 'When the account was closed, zero unless its status is closed';
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS "accounts_owner_nickname_idx";
+
+DROP INDEX IF EXISTS "accounts_owner_currency_idx";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "nickname";
+
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "accounts_type_check";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "type";
+
+-- Fails while a user has more than one account in a currency.
+ALTER TABLE IF EXISTS "accounts" ADD CONSTRAINT "owner_currency_key" UNIQUE ("owner", "currency");
@@ -0,0 +1,14 @@
+ALTER TABLE "accounts" DROP CONSTRAINT "owner_currency_key";
+
+ALTER TABLE "accounts" ADD COLUMN "type" varchar NOT NULL DEFAULT 'checking';
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_type_check" CHECK ("type" IN ('checking', 'savings', 'business'));
+
+ALTER TABLE "accounts" ADD COLUMN "nickname" varchar NOT NULL DEFAULT '';
+
+CREATE INDEX ON "accounts" ("owner", "currency");
+
+-- Nicknames tell the accounts of a user apart, so they are unique per owner.
+CREATE UNIQUE INDEX "accounts_owner_nickname_idx" ON "accounts" ("owner", "nickname") WHERE "nickname" <> '';
+
+COMMENT ON COLUMN "accounts"."nickname" IS 'Name the owner gave the account, empty if none';
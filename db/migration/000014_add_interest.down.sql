@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS "interest_postings";
+
+DROP TABLE IF EXISTS "interest_accruals";
+
+DROP TABLE IF EXISTS "bank_accounts";
+
+-- The accounts of the bank user stay if they have taken part in transfers.
+DELETE FROM "accounts"
+WHERE "owner" = '_bank'
+  AND NOT EXISTS (SELECT 1 FROM "entries" WHERE "entries"."account_id" = "accounts"."id");
+
+DELETE FROM "users"
+WHERE "username" = '_bank'
+  AND NOT EXISTS (SELECT 1 FROM "accounts" WHERE "accounts"."owner" = "users"."username");
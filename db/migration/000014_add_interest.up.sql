@@ -0,0 +1,62 @@
+-- The bank books its own income and expenses to accounts of this user. The
+-- API only accepts alphanumeric usernames, so it cannot be registered, and
+-- its password hash matches no password.
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email", "is_email_verified", "is_disabled")
+VALUES ('_bank', '!', 'SimpleBank', 'ledger@simplebank.invalid', true, true);
+
+CREATE TABLE "bank_accounts" (
+  "purpose" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "account_id" bigint UNIQUE NOT NULL,
+  PRIMARY KEY ("purpose", "currency")
+);
+
+ALTER TABLE "bank_accounts" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+WITH "created" AS (
+  INSERT INTO "accounts" ("owner", "balance", "currency", "type", "nickname")
+  VALUES
+    ('_bank', 0, 'USD', 'business', 'Interest expense USD'),
+    ('_bank', 0, 'EUR', 'business', 'Interest expense EUR')
+  RETURNING "id", "currency"
+)
+INSERT INTO "bank_accounts" ("purpose", "currency", "account_id")
+SELECT 'interest_expense', "currency", "id" FROM "created";
+
+CREATE TABLE "interest_accruals" (
+  "account_id" bigint NOT NULL,
+  "accrual_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "rate" varchar NOT NULL,
+  "amount_micros" bigint NOT NULL,
+  "posting_id" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamp with time zone NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "accrual_date")
+);
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+CREATE INDEX ON "interest_accruals" ("account_id") WHERE "posting_id" = 0;
+
+CREATE TABLE "interest_postings" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "month" date NOT NULL,
+  "accrued_micros" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "transfer_id" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamp with time zone NOT NULL DEFAULT (now()),
+  UNIQUE ("account_id", "month")
+);
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+COMMENT ON COLUMN "bank_accounts"."purpose" IS 'What the bank books to the account, such as interest_expense';
+COMMENT ON COLUMN "interest_accruals"."balance" IS 'Balance at the end of the accrual date';
+COMMENT ON COLUMN "interest_accruals"."rate" IS 'Annual rate as a decimal fraction, such as 0.035';
+COMMENT ON COLUMN "interest_accruals"."amount_micros" IS 'Interest for the day in millionths of the minor unit, rounded down';
+COMMENT ON COLUMN "interest_accruals"."posting_id" IS 'Posting that paid the accrual, 0 until it is paid';
+COMMENT ON COLUMN "interest_postings"."month" IS 'First day of the month the posting pays';
+COMMENT ON COLUMN "interest_postings"."accrued_micros" IS 'Sum of the accruals the posting paid';
+COMMENT ON COLUMN "interest_postings"."amount" IS 'Amount paid, in minor units; fractions of a unit carry over to the next posting';
+COMMENT ON COLUMN "interest_postings"."transfer_id" IS 'Transfer that paid the interest, 0 if the amount was 0';
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS "fees";
+
+DROP TABLE IF EXISTS "fee_rules";
+
+DELETE FROM "bank_accounts" WHERE "purpose" = 'fee_revenue';
+
+-- The fee revenue accounts stay if they have taken part in transfers.
+DELETE FROM "accounts"
+WHERE "owner" = '_bank'
+  AND "nickname" LIKE 'Fee revenue %'
+  AND NOT EXISTS (SELECT 1 FROM "entries" WHERE "entries"."account_id" = "accounts"."id");
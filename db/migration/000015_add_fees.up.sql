@@ -0,0 +1,60 @@
+CREATE TABLE "fee_rules" (
+  "id" bigserial PRIMARY KEY,
+  "event" varchar NOT NULL,
+  "currency" varchar NOT NULL DEFAULT '',
+  "account_type" varchar NOT NULL DEFAULT '',
+  "min_amount" bigint NOT NULL DEFAULT 0,
+  "flat" bigint NOT NULL DEFAULT 0,
+  "rate" varchar NOT NULL DEFAULT '0',
+  "min_fee" bigint NOT NULL DEFAULT 0,
+  "max_fee" bigint NOT NULL DEFAULT 0,
+  "description" varchar NOT NULL DEFAULT '',
+  "created_at" timestamp with time zone NOT NULL DEFAULT (now()),
+  CONSTRAINT "fee_rules_event_check" CHECK ("event" IN ('transfer', 'maintenance')),
+  CONSTRAINT "fee_rules_rate_check" CHECK ("rate" ~ '^[0-9]+(\.[0-9]+)?$' AND "rate"::numeric <= 1),
+  CONSTRAINT "fee_rules_amounts_check" CHECK (
+    "min_amount" >= 0 AND "flat" >= 0 AND "min_fee" >= 0 AND ("max_fee" = 0 OR "max_fee" >= "min_fee")
+  ),
+  UNIQUE ("event", "currency", "account_type", "min_amount")
+);
+
+CREATE TABLE "fees" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "event" varchar NOT NULL,
+  "rule_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "transfer_id" bigint NOT NULL DEFAULT 0,
+  "charged_transfer_id" bigint NOT NULL DEFAULT 0,
+  "month" date NOT NULL DEFAULT '0001-01-01',
+  "created_at" timestamp with time zone NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "fees" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+CREATE INDEX ON "fees" ("account_id");
+
+CREATE UNIQUE INDEX "fees_maintenance_month_idx" ON "fees" ("account_id", "month") WHERE "event" = 'maintenance';
+
+WITH "created" AS (
+  INSERT INTO "accounts" ("owner", "balance", "currency", "type", "nickname")
+  VALUES
+    ('_bank', 0, 'USD', 'business', 'Fee revenue USD'),
+    ('_bank', 0, 'EUR', 'business', 'Fee revenue EUR')
+  RETURNING "id", "currency"
+)
+INSERT INTO "bank_accounts" ("purpose", "currency", "account_id")
+SELECT 'fee_revenue', "currency", "id" FROM "created";
+
+COMMENT ON COLUMN "fee_rules"."event" IS 'What the fee is charged for: transfer or maintenance';
+COMMENT ON COLUMN "fee_rules"."currency" IS 'Currency the rule applies to, empty for any';
+COMMENT ON COLUMN "fee_rules"."account_type" IS 'Type of the charged account the rule applies to, empty for any';
+COMMENT ON COLUMN "fee_rules"."min_amount" IS 'Smallest transfer amount, or balance for maintenance, the rule applies to; rules with different minimums form tiers';
+COMMENT ON COLUMN "fee_rules"."flat" IS 'Fixed part of the fee, in minor units';
+COMMENT ON COLUMN "fee_rules"."rate" IS 'Part of the fee proportional to the amount, as a decimal fraction such as 0.005';
+COMMENT ON COLUMN "fee_rules"."min_fee" IS 'Smallest fee the rule charges';
+COMMENT ON COLUMN "fee_rules"."max_fee" IS 'Largest fee the rule charges, 0 for no limit';
+COMMENT ON COLUMN "fees"."rule_id" IS 'Rule that set the fee; rules may since have been removed';
+COMMENT ON COLUMN "fees"."transfer_id" IS 'Transfer that booked the fee to the bank, 0 if the amount was 0';
+COMMENT ON COLUMN "fees"."charged_transfer_id" IS 'Transfer the fee was charged for, 0 for maintenance fees';
+COMMENT ON COLUMN "fees"."month" IS 'First day of the month a maintenance fee was charged for';
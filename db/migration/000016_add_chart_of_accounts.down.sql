@@ -0,0 +1,21 @@
+ALTER TABLE "system_accounts" DROP CONSTRAINT IF EXISTS "system_accounts_code_fkey";
+
+-- Accounts for codes that had no purpose before stay if they have taken
+-- part in transfers, but are no longer the bank's.
+DELETE FROM "system_accounts" WHERE "code" NOT IN ('5100', '4100');
+
+DELETE FROM "accounts"
+WHERE "owner" = '_bank'
+  AND "nickname" IN ('Cash USD', 'Cash EUR', 'FX gains USD', 'FX gains EUR')
+  AND NOT EXISTS (SELECT 1 FROM "entries" WHERE "entries"."account_id" = "accounts"."id");
+
+UPDATE "system_accounts" SET "code" = 'interest_expense' WHERE "code" = '5100';
+UPDATE "system_accounts" SET "code" = 'fee_revenue' WHERE "code" = '4100';
+
+ALTER TABLE "system_accounts" RENAME CONSTRAINT "system_accounts_pkey" TO "bank_accounts_pkey";
+ALTER TABLE "system_accounts" RENAME CONSTRAINT "system_accounts_account_id_key" TO "bank_accounts_account_id_key";
+ALTER TABLE "system_accounts" RENAME CONSTRAINT "system_accounts_account_id_fkey" TO "bank_accounts_account_id_fkey";
+ALTER TABLE "system_accounts" RENAME COLUMN "code" TO "purpose";
+ALTER TABLE "system_accounts" RENAME TO "bank_accounts";
+
+DROP TABLE IF EXISTS "ledger_accounts";
@@ -0,0 +1,51 @@
+CREATE TABLE "ledger_accounts" (
+  "code" varchar PRIMARY KEY,
+  "name" varchar NOT NULL,
+  "type" varchar NOT NULL,
+  "parent_code" varchar NOT NULL DEFAULT '',
+  "created_at" timestamp with time zone NOT NULL DEFAULT (now()),
+  CONSTRAINT "ledger_accounts_type_check" CHECK ("type" IN ('asset', 'liability', 'equity', 'income', 'expense'))
+);
+
+INSERT INTO "ledger_accounts" ("code", "name", "type", "parent_code")
+VALUES
+  ('1000', 'Assets', 'asset', ''),
+  ('1100', 'Cash', 'asset', '1000'),
+  ('2000', 'Liabilities', 'liability', ''),
+  ('2100', 'Customer deposits', 'liability', '2000'),
+  ('3000', 'Equity', 'equity', ''),
+  ('3100', 'Opening balances', 'equity', '3000'),
+  ('4000', 'Income', 'income', ''),
+  ('4100', 'Fee revenue', 'income', '4000'),
+  ('4200', 'FX gains', 'income', '4000'),
+  ('5000', 'Expenses', 'expense', ''),
+  ('5100', 'Interest expense', 'expense', '5000');
+
+-- The bank's accounts are now identified by their code in the chart of
+-- accounts rather than by a purpose.
+ALTER TABLE "bank_accounts" RENAME TO "system_accounts";
+ALTER TABLE "system_accounts" RENAME COLUMN "purpose" TO "code";
+ALTER TABLE "system_accounts" RENAME CONSTRAINT "bank_accounts_pkey" TO "system_accounts_pkey";
+ALTER TABLE "system_accounts" RENAME CONSTRAINT "bank_accounts_account_id_key" TO "system_accounts_account_id_key";
+ALTER TABLE "system_accounts" RENAME CONSTRAINT "bank_accounts_account_id_fkey" TO "system_accounts_account_id_fkey";
+
+UPDATE "system_accounts" SET "code" = '5100' WHERE "code" = 'interest_expense';
+UPDATE "system_accounts" SET "code" = '4100' WHERE "code" = 'fee_revenue';
+
+ALTER TABLE "system_accounts" ADD FOREIGN KEY ("code") REFERENCES "ledger_accounts" ("code");
+
+WITH "created" AS (
+  INSERT INTO "accounts" ("owner", "balance", "currency", "type", "nickname")
+  VALUES
+    ('_bank', 0, 'USD', 'business', 'Cash USD'),
+    ('_bank', 0, 'EUR', 'business', 'Cash EUR'),
+    ('_bank', 0, 'USD', 'business', 'FX gains USD'),
+    ('_bank', 0, 'EUR', 'business', 'FX gains EUR')
+  RETURNING "id", "currency", "nickname"
+)
+INSERT INTO "system_accounts" ("code", "currency", "account_id")
+SELECT CASE WHEN "nickname" LIKE 'Cash %' THEN '1100' ELSE '4200' END, "currency", "id" FROM "created";
+
+COMMENT ON COLUMN "ledger_accounts"."code" IS 'General ledger code; the first digit is the type, 1 for assets to 5 for expenses';
+COMMENT ON COLUMN "ledger_accounts"."parent_code" IS 'Code of the group the account belongs to, empty for a top level group';
+COMMENT ON COLUMN "system_accounts"."code" IS 'Ledger account the bank books to the account';
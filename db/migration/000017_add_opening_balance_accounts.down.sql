@@ -0,0 +1,8 @@
+-- Accounts that have taken part in transfers stay, but are no longer the
+-- bank's.
+DELETE FROM "system_accounts" WHERE "code" = '3100';
+
+DELETE FROM "accounts"
+WHERE "owner" = '_bank'
+  AND "nickname" IN ('Opening balances USD', 'Opening balances EUR')
+  AND NOT EXISTS (SELECT 1 FROM "entries" WHERE "entries"."account_id" = "accounts"."id");
@@ -0,0 +1,11 @@
+-- Opening balances are booked against these, as deposits are booked
+-- against the cash accounts.
+WITH "created" AS (
+  INSERT INTO "accounts" ("owner", "balance", "currency", "type", "nickname")
+  VALUES
+    ('_bank', 0, 'USD', 'business', 'Opening balances USD'),
+    ('_bank', 0, 'EUR', 'business', 'Opening balances EUR')
+  RETURNING "id", "currency"
+)
+INSERT INTO "system_accounts" ("code", "currency", "account_id")
+SELECT '3100', "currency", "id" FROM "created";
@@ -0,0 +1,5 @@
+ALTER TABLE "jobs" ADD COLUMN "key" varchar NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX "jobs_key_idx" ON "jobs" ("key") WHERE "key" <> '';
+
+COMMENT ON COLUMN "jobs"."key" IS 'Names a scheduled run, such as a day of interest; a job is not enqueued while another with its key exists';
@@ -0,0 +1,111 @@
+package migration
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the "pgx" database/sql driver
+)
+
+// Migrator applies the embedded migrations to a database. Every operation
+// holds a PostgreSQL advisory lock, so replicas migrating on start up wait
+// for each other instead of racing.
+type Migrator struct {
+	migrate *migrate.Migrate
+	db      *sql.DB
+}
+
+// NewMigrator connects to the database at dbSource
+func NewMigrator(dbSource string) (*Migrator, error) {
+	db, err := sql.Open("pgx", dbSource)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open database: %w", err)
+	}
+
+	driver, err := pgx.WithInstance(db, &pgx.Config{})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("cannot create migration driver: %w", err)
+	}
+
+	source, err := iofs.New(FS, ".")
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("cannot read embedded migrations: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, "pgx5", driver)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("cannot create migrator: %w", err)
+	}
+
+	m.Log = logger{}
+
+	return &Migrator{migrate: m, db: db}, nil
+}
+
+// Up applies all pending migrations
+func (m *Migrator) Up() error {
+	return ignoreNoChange(m.migrate.Up())
+}
+
+// Down reverts the given number of migrations, or all of them when steps
+// is not positive
+func (m *Migrator) Down(steps int) error {
+	if steps <= 0 {
+		return ignoreNoChange(m.migrate.Down())
+	}
+
+	return ignoreNoChange(m.migrate.Steps(-steps))
+}
+
+// Version returns the current schema version and whether it is dirty. A
+// database without migrations is at version 0.
+func (m *Migrator) Version() (version uint, dirty bool, err error) {
+	version, dirty, err = m.migrate.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+
+	return version, dirty, err
+}
+
+// Force records version as the current one and clears the dirty flag
+// without running any migration, to recover from a failed migration that
+// was fixed by hand
+func (m *Migrator) Force(version int) error {
+	return m.migrate.Force(version)
+}
+
+// Close releases the database connection
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.migrate.Close()
+
+	return errors.Join(sourceErr, dbErr, m.db.Close())
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+
+	return err
+}
+
+// logger forwards golang-migrate progress messages to slog.
+type logger struct{}
+
+func (logger) Printf(format string, v ...any) {
+	slog.Info(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (logger) Verbose() bool {
+	return true
+}
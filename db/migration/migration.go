@@ -0,0 +1,85 @@
+// Package migration embeds the SQL migrations of the database schema.
+package migration
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// FS holds the *.up.sql and *.down.sql migration files
+//
+//go:embed *.sql
+var FS embed.FS
+
+// LatestVersion returns the version of the newest embedded migration
+func LatestVersion() (uint, error) {
+	entries, err := fs.ReadDir(FS, ".")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint
+	for _, entry := range entries {
+		prefix, _, ok := strings.Cut(entry.Name(), "_")
+		if !ok {
+			continue
+		}
+
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration file name %q: %w", entry.Name(), err)
+		}
+
+		latest = max(latest, uint(version))
+	}
+
+	return latest, nil
+}
+
+// DBTX is the subset of a connection needed to read the schema version
+type DBTX interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// CurrentVersion returns the schema version recorded by golang-migrate and
+// whether the last migration failed halfway
+func CurrentVersion(ctx context.Context, conn DBTX) (version uint, dirty bool, err error) {
+	var v int64
+
+	err = conn.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&v, &dirty)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return uint(v), dirty, nil
+}
+
+// CheckVersion returns an error unless the database schema is cleanly
+// migrated to the latest embedded version
+func CheckVersion(ctx context.Context, conn DBTX) error {
+	expected, err := LatestVersion()
+	if err != nil {
+		return err
+	}
+
+	current, dirty, err := CurrentVersion(ctx, conn)
+	if err != nil {
+		return fmt.Errorf("cannot read schema version: %w", err)
+	}
+
+	if dirty {
+		return fmt.Errorf("schema version %d is dirty", current)
+	}
+
+	if current != expected {
+		return fmt.Errorf("schema version is %d, expected %d", current, expected)
+	}
+
+	return nil
+}
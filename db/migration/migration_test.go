@@ -0,0 +1,47 @@
+package migration
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestVersion(t *testing.T) {
+	files, err := fs.Glob(FS, "*.up.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	version, err := LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, uint(len(files)), version)
+}
+
+func TestEmbeddedMigrationsArePaired(t *testing.T) {
+	source, err := iofs.New(FS, ".")
+	require.NoError(t, err)
+	defer source.Close()
+
+	ups, err := fs.Glob(FS, "*.up.sql")
+	require.NoError(t, err)
+
+	for _, up := range ups {
+		down := strings.TrimSuffix(up, ".up.sql") + ".down.sql"
+		_, err := fs.Stat(FS, down)
+		require.NoError(t, err, "missing down migration for %s", up)
+	}
+
+	version, err := source.First()
+	require.NoError(t, err)
+	require.Equal(t, uint(1), version)
+
+	latest, err := LatestVersion()
+	require.NoError(t, err)
+
+	for version != latest {
+		version, err = source.Next(version)
+		require.NoError(t, err)
+	}
+}
@@ -1,9 +1,9 @@
 -- name: CreateAccount :one
 INSERT INTO accounts (
-    owner, balance, currency
+    owner, balance, currency, type, nickname
 ) 
 VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5
 ) 
 RETURNING *;
 
@@ -17,11 +17,14 @@ WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE;
 
 -- name: ListAccounts :many
+-- An empty type or currency matches every account.
 SELECT * FROM accounts
-WHERE owner = $1
+WHERE owner = sqlc.arg(owner)
+    AND (sqlc.arg(type)::varchar = '' OR type = sqlc.arg(type))
+    AND (sqlc.arg(currency)::varchar = '' OR currency = sqlc.arg(currency))
 ORDER BY id
-LIMIT $2
-OFFSET $3;
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
 
 -- name: UpdateAccount :one
 UPDATE accounts
@@ -38,3 +41,41 @@ RETURNING *;
 -- name: DeleteAccount :exec
 DELETE FROM accounts
 WHERE id = $1;
+
+-- name: ListAllAccounts :many
+SELECT * FROM accounts
+ORDER BY id
+LIMIT $1
+OFFSET $2;
+
+-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET
+    status = sqlc.arg(status),
+    closed_at = CASE
+        WHEN sqlc.arg(status)::varchar = 'closed' THEN now()
+        ELSE '0001-01-01 00:00:00Z'
+    END
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListUnreconciledAccounts :many
+SELECT
+    a.id,
+    a.owner,
+    a.currency,
+    a.balance,
+    COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id;
+
+-- name: LockAccounts :many
+-- Locks accounts in id order, the order every transaction takes them in,
+-- so transactions locking several accounts do not deadlock.
+SELECT id FROM accounts
+WHERE id = ANY(sqlc.arg(ids)::bigint[])
+ORDER BY id
+FOR NO KEY UPDATE;
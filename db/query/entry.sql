@@ -1,9 +1,9 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
-    account_id, amount
+    account_id, amount, transfer_id
 ) 
 VALUES (
-    $1, $2
+    $1, $2, $3
 ) 
 RETURNING *;
 
@@ -17,3 +17,38 @@ WHERE account_id = $1
 ORDER BY id
 LIMIT $2
 OFFSET $3;
+
+-- name: GetOpeningBalance :one
+-- The balance of an account before from_time: its current balance less
+-- every entry since. Entries are only ever added at the current time, so the
+-- result does not change as new ones arrive.
+SELECT (a.balance - COALESCE((
+    SELECT SUM(e.amount)
+    FROM entries e
+    WHERE e.account_id = a.id AND e.created_at >= sqlc.arg(from_time)
+), 0))::bigint AS opening_balance
+FROM accounts a
+WHERE a.id = sqlc.arg(account_id);
+
+-- name: ListStatementEntries :many
+-- A page of an account's entries in [from_time, to_time) after entry
+-- after_id, with the other account of the transfer that created each one.
+SELECT
+    e.id,
+    e.amount,
+    e.transfer_id,
+    e.created_at,
+    COALESCE(c.id, 0)::bigint AS counterparty_account_id,
+    COALESCE(c.owner, '')::varchar AS counterparty_owner
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN accounts c ON c.id = CASE
+    WHEN t.from_account_id = e.account_id THEN t.to_account_id
+    ELSE t.from_account_id
+END
+WHERE e.account_id = sqlc.arg(account_id)
+    AND e.created_at >= sqlc.arg(from_time)
+    AND e.created_at < sqlc.arg(to_time)
+    AND e.id > sqlc.arg(after_id)
+ORDER BY e.id
+LIMIT sqlc.arg(page_size);
@@ -0,0 +1,57 @@
+-- name: CreateFeeRule :one
+INSERT INTO fee_rules (
+    event, currency, account_type, min_amount, flat, rate, min_fee, max_fee, description
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+RETURNING *;
+
+-- name: ListFeeRules :many
+SELECT * FROM fee_rules
+ORDER BY event, currency, account_type, min_amount;
+
+-- name: DeleteFeeRule :execrows
+DELETE FROM fee_rules
+WHERE id = $1;
+
+-- name: MatchFeeRule :one
+-- The rule for an event that applies to an account of the given currency
+-- and type and an amount. Rules for the currency win over rules for any
+-- currency, then rules for the account type over rules for any type, and
+-- among those the highest tier the amount reaches applies.
+SELECT * FROM fee_rules
+WHERE event = sqlc.arg(event)
+    AND currency IN (sqlc.arg(currency)::varchar, '')
+    AND account_type IN (sqlc.arg(account_type)::varchar, '')
+    AND min_amount <= sqlc.arg(amount)::bigint
+ORDER BY currency <> '' DESC, account_type <> '' DESC, min_amount DESC
+LIMIT 1;
+
+-- name: CreateFee :one
+INSERT INTO fees (
+    account_id, event, rule_id, amount, transfer_id, charged_transfer_id, month
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: SetFeeTransfer :one
+UPDATE fees
+SET transfer_id = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: ListFees :many
+SELECT * FROM fees
+WHERE account_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListMaintenanceFeeAccounts :many
+-- Lists the open customer accounts that existed at month_end.
+SELECT a.id FROM accounts a
+WHERE a.status <> 'closed'
+    AND a.created_at < sqlc.arg(month_end)::timestamptz
+    AND NOT EXISTS (SELECT 1 FROM system_accounts s WHERE s.account_id = a.id)
+ORDER BY a.id;
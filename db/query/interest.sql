@@ -0,0 +1,71 @@
+-- name: ListInterestBearingAccounts :many
+-- Lists the open customer accounts of the given types that existed at
+-- day_end, with their balance at that time: entries made since are
+-- subtracted.
+SELECT
+    a.id,
+    a.type,
+    a.currency,
+    (a.balance - COALESCE(SUM(e.amount), 0))::bigint AS balance
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id AND e.created_at >= sqlc.arg(day_end)::timestamptz
+WHERE a.type = ANY(sqlc.arg(types)::varchar[])
+    AND a.status <> 'closed'
+    AND a.created_at < sqlc.arg(day_end)::timestamptz
+    AND NOT EXISTS (SELECT 1 FROM system_accounts s WHERE s.account_id = a.id)
+GROUP BY a.id
+ORDER BY a.id;
+
+-- name: CreateInterestAccrual :execrows
+-- Does nothing when the account already accrued interest for the date.
+INSERT INTO interest_accruals (
+    account_id, accrual_date, balance, rate, amount_micros
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (account_id, accrual_date) DO NOTHING;
+
+-- name: ListInterestAccruals :many
+SELECT * FROM interest_accruals
+WHERE account_id = $1
+ORDER BY accrual_date;
+
+-- name: ListAccountsWithUnpostedInterest :many
+SELECT DISTINCT account_id FROM interest_accruals
+WHERE posting_id = 0 AND accrual_date < sqlc.arg(before)
+ORDER BY account_id;
+
+-- name: SumUnpostedInterest :one
+SELECT COALESCE(SUM(amount_micros), 0)::bigint FROM interest_accruals
+WHERE account_id = sqlc.arg(account_id) AND posting_id = 0 AND accrual_date < sqlc.arg(before);
+
+-- name: MarkInterestPosted :execrows
+UPDATE interest_accruals
+SET posting_id = sqlc.arg(posting_id)
+WHERE account_id = sqlc.arg(account_id) AND posting_id = 0 AND accrual_date < sqlc.arg(before);
+
+-- name: GetInterestPostingTotals :one
+SELECT
+    COALESCE(SUM(accrued_micros), 0)::bigint AS accrued_micros,
+    COALESCE(SUM(amount), 0)::bigint AS amount
+FROM interest_postings
+WHERE account_id = $1;
+
+-- name: CreateInterestPosting :one
+INSERT INTO interest_postings (
+    account_id, month, accrued_micros, amount
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: SetInterestPostingTransfer :one
+UPDATE interest_postings
+SET transfer_id = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: ListInterestPostings :many
+SELECT * FROM interest_postings
+WHERE account_id = $1
+ORDER BY month;
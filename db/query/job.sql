@@ -0,0 +1,89 @@
+-- name: EnqueueJob :one
+-- A job with a key that is already taken is not inserted, and no row is
+-- returned.
+INSERT INTO jobs (
+    type,
+    payload,
+    max_attempts,
+    run_at,
+    key
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (key) WHERE key <> '' DO NOTHING
+RETURNING *;
+
+-- name: GetJob :one
+SELECT * FROM jobs
+WHERE id = $1 LIMIT 1;
+
+-- name: ListJobs :many
+SELECT * FROM jobs
+WHERE status = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ClaimJobs :many
+UPDATE jobs
+SET
+    status = 'running',
+    attempts = attempts + 1,
+    locked_at = now(),
+    updated_at = now()
+WHERE id IN (
+    SELECT id FROM jobs
+    WHERE status = 'pending' AND run_at <= now()
+    ORDER BY run_at
+    LIMIT sqlc.arg(max_jobs)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CompleteJob :exec
+UPDATE jobs
+SET
+    status = 'done',
+    last_error = '',
+    updated_at = now()
+WHERE id = $1;
+
+-- name: RetryJob :exec
+UPDATE jobs
+SET
+    status = 'pending',
+    last_error = $2,
+    run_at = $3,
+    updated_at = now()
+WHERE id = $1;
+
+-- name: KillJob :exec
+UPDATE jobs
+SET
+    status = 'dead',
+    last_error = $2,
+    updated_at = now()
+WHERE id = $1;
+
+-- name: RequeueStuckJobs :execrows
+UPDATE jobs
+SET
+    status = CASE WHEN attempts >= max_attempts THEN 'dead' ELSE 'pending' END,
+    last_error = 'processor stopped while running the job',
+    run_at = now(),
+    updated_at = now()
+WHERE status = 'running' AND locked_at < sqlc.arg(locked_before);
+
+-- name: ReviveJob :one
+UPDATE jobs
+SET
+    status = 'pending',
+    attempts = 0,
+    run_at = now(),
+    updated_at = now()
+WHERE id = $1 AND status = 'dead'
+RETURNING *;
+
+-- name: DeleteDoneJobs :execrows
+DELETE FROM jobs
+WHERE status = 'done' AND updated_at < sqlc.arg(updated_before);
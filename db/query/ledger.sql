@@ -0,0 +1,72 @@
+-- name: CreateLedgerAccount :one
+INSERT INTO ledger_accounts (
+    code, name, type, parent_code
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetLedgerAccount :one
+SELECT * FROM ledger_accounts
+WHERE code = $1 LIMIT 1;
+
+-- name: ListLedgerAccounts :many
+SELECT * FROM ledger_accounts
+ORDER BY code;
+
+-- name: CreateSystemAccount :one
+INSERT INTO system_accounts (
+    code, currency, account_id
+) VALUES (
+    $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetSystemAccount :one
+SELECT a.* FROM accounts a
+JOIN system_accounts s ON s.account_id = a.id
+WHERE s.code = $1 AND s.currency = $2;
+
+-- name: IsSystemAccount :one
+SELECT EXISTS (
+    SELECT 1 FROM system_accounts WHERE account_id = $1
+);
+
+-- name: ListSystemAccounts :many
+SELECT
+    s.code,
+    l.name AS ledger_name,
+    a.id,
+    a.currency,
+    a.balance
+FROM system_accounts s
+JOIN ledger_accounts l ON l.code = s.code
+JOIN accounts a ON a.id = s.account_id
+ORDER BY s.code, a.currency;
+
+-- name: ListTrialBalance :many
+-- The balance of every ledger account per currency, summed from the entries
+-- of its accounts. Customer accounts are booked to customer_code, every
+-- other account to its system account's code. Balances are credits when
+-- positive and debits when negative. account_balance sums the balances
+-- stored on the accounts instead, and mismatched counts the accounts whose
+-- entries do not add up to their balance.
+SELECT
+    l.code,
+    l.name,
+    l.type,
+    a.currency,
+    COUNT(*)::bigint AS accounts,
+    COALESCE(SUM(e.amount), 0)::bigint AS balance,
+    SUM(a.balance)::bigint AS account_balance,
+    COUNT(*) FILTER (WHERE a.balance <> COALESCE(e.amount, 0))::bigint AS mismatched
+FROM accounts a
+LEFT JOIN (
+    SELECT account_id, SUM(amount) AS amount
+    FROM entries
+    GROUP BY account_id
+) e ON e.account_id = a.id
+LEFT JOIN system_accounts s ON s.account_id = a.id
+JOIN ledger_accounts l ON l.code = COALESCE(s.code, sqlc.arg(customer_code)::varchar)
+GROUP BY a.currency, l.code, l.name, l.type
+ORDER BY a.currency, l.code;
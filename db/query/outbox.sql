@@ -0,0 +1,41 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox (
+    aggregate_type,
+    aggregate_id,
+    event_type,
+    payload
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ListOutboxEventsByAggregate :many
+SELECT * FROM outbox
+WHERE aggregate_type = $1 AND aggregate_id = $2
+ORDER BY id;
+
+-- name: TryAdvisoryXactLock :one
+SELECT pg_try_advisory_xact_lock(sqlc.arg(lock_id)::bigint);
+
+-- name: ListUnpublishedOutboxEvents :many
+SELECT * FROM outbox
+WHERE NOT is_published
+ORDER BY id
+LIMIT $1;
+
+-- name: MarkOutboxEventsPublished :exec
+UPDATE outbox
+SET
+    is_published = true,
+    published_at = now()
+WHERE id = ANY(sqlc.arg(ids)::bigint[]);
+
+-- name: RecordOutboxEventFailure :exec
+UPDATE outbox
+SET
+    attempts = attempts + 1,
+    last_error = $2
+WHERE id = $1;
+
+-- name: DeletePublishedOutboxEvents :execrows
+DELETE FROM outbox
+WHERE is_published AND published_at < sqlc.arg(published_before);
@@ -0,0 +1,19 @@
+-- name: CreatePasswordResetToken :one
+INSERT INTO password_reset_tokens (
+    username,
+    token_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: UsePasswordResetToken :one
+UPDATE password_reset_tokens
+SET is_used = true
+WHERE token_hash = $1 AND NOT is_used AND expires_at > now()
+RETURNING *;
+
+-- name: InvalidatePasswordResetTokens :execrows
+UPDATE password_reset_tokens
+SET is_used = true
+WHERE username = $1 AND NOT is_used;
@@ -0,0 +1,21 @@
+-- name: TakeRateLimitToken :one
+INSERT INTO rate_limits (
+    key, tokens, allowed, updated_at
+)
+VALUES (
+    sqlc.arg(key), sqlc.arg(burst)::double precision - 1, true, now()
+)
+ON CONFLICT (key) DO UPDATE
+SET
+    tokens = CASE
+        WHEN LEAST(sqlc.arg(burst)::double precision, rate_limits.tokens + EXTRACT(EPOCH FROM now() - rate_limits.updated_at) * sqlc.arg(refill_per_second)::double precision) >= 1
+        THEN LEAST(sqlc.arg(burst)::double precision, rate_limits.tokens + EXTRACT(EPOCH FROM now() - rate_limits.updated_at) * sqlc.arg(refill_per_second)::double precision) - 1
+        ELSE LEAST(sqlc.arg(burst)::double precision, rate_limits.tokens + EXTRACT(EPOCH FROM now() - rate_limits.updated_at) * sqlc.arg(refill_per_second)::double precision)
+    END,
+    allowed = LEAST(sqlc.arg(burst)::double precision, rate_limits.tokens + EXTRACT(EPOCH FROM now() - rate_limits.updated_at) * sqlc.arg(refill_per_second)::double precision) >= 1,
+    updated_at = now()
+RETURNING tokens, allowed;
+
+-- name: DeleteStaleRateLimits :execrows
+DELETE FROM rate_limits
+WHERE updated_at < sqlc.arg(before);
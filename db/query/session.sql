@@ -16,3 +16,24 @@ RETURNING *;
 -- name: GetSession :one
 SELECT * FROM sessions
 WHERE id = $1 LIMIT 1;
+
+-- name: ListUserSessions :many
+SELECT * FROM sessions
+WHERE username = $1
+ORDER BY created_at DESC;
+
+-- name: BlockSession :one
+UPDATE sessions
+SET is_blocked = true
+WHERE id = $1
+RETURNING *;
+
+-- name: BlockUserSessions :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE username = $1 AND NOT is_blocked;
+
+-- name: BlockOtherUserSessions :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE username = $1 AND id <> sqlc.arg(keep_id) AND NOT is_blocked;
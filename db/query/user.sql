@@ -11,3 +11,50 @@ INSERT INTO users (
 -- name: GetUser :one
 SELECT * FROM users 
 WHERE username = $1 LIMIT 1;
+
+-- name: UpdateUserDisabled :one
+UPDATE users
+SET is_disabled = $2
+WHERE username = $1
+RETURNING *;
+
+-- name: RecordFailedLogin :one
+UPDATE users
+SET
+    failed_login_attempts = failed_login_attempts + 1,
+    locked_until = CASE
+        WHEN failed_login_attempts + 1 >= sqlc.arg(max_attempts)::integer
+        THEN now() + make_interval(secs => LEAST(
+            sqlc.arg(lockout_seconds)::double precision * power(2, LEAST(failed_login_attempts + 1 - sqlc.arg(max_attempts)::integer, 30)),
+            sqlc.arg(max_lockout_seconds)::double precision
+        ))
+        ELSE locked_until
+    END
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: ResetFailedLogins :one
+UPDATE users
+SET
+    failed_login_attempts = 0,
+    locked_until = '0001-01-01 00:00:00Z'
+WHERE username = $1
+RETURNING *;
+
+-- name: GetUserByEmail :one
+SELECT * FROM users
+WHERE email = $1 LIMIT 1;
+
+-- name: UpdateUserPassword :one
+UPDATE users
+SET
+    hashed_password = $2,
+    password_changed_at = now()
+WHERE username = $1
+RETURNING *;
+
+-- name: VerifyUserEmail :one
+UPDATE users
+SET is_email_verified = true
+WHERE username = $1 AND email = $2
+RETURNING *;
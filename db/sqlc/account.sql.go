@@ -13,7 +13,7 @@ const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
 SET balance = balance + $1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, status, closed_at, type, nickname
 `
 
 type AddAccountBalanceParams struct {
@@ -30,28 +30,40 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Status,
+		&i.ClosedAt,
+		&i.Type,
+		&i.Nickname,
 	)
 	return i, err
 }
 
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (
-    owner, balance, currency
+    owner, balance, currency, type, nickname
 ) 
 VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5
 ) 
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, status, closed_at, type, nickname
 `
 
 type CreateAccountParams struct {
 	Owner    string `json:"owner"`
 	Balance  int64  `json:"balance"`
 	Currency string `json:"currency"`
+	Type     string `json:"type"`
+	Nickname string `json:"nickname"`
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRow(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRow(ctx, createAccount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.Type,
+		arg.Nickname,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -59,6 +71,10 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Status,
+		&i.ClosedAt,
+		&i.Type,
+		&i.Nickname,
 	)
 	return i, err
 }
@@ -74,7 +90,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts 
+SELECT id, owner, balance, currency, created_at, status, closed_at, type, nickname FROM accounts 
 WHERE id = $1 LIMIT 1
 `
 
@@ -87,12 +103,16 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Status,
+		&i.ClosedAt,
+		&i.Type,
+		&i.Nickname,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts 
+SELECT id, owner, balance, currency, created_at, status, closed_at, type, nickname FROM accounts 
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -106,26 +126,41 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Status,
+		&i.ClosedAt,
+		&i.Type,
+		&i.Nickname,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, status, closed_at, type, nickname FROM accounts
 WHERE owner = $1
+    AND ($2::varchar = '' OR type = $2)
+    AND ($3::varchar = '' OR currency = $3)
 ORDER BY id
-LIMIT $2
-OFFSET $3
+LIMIT $5
+OFFSET $4
 `
 
 type ListAccountsParams struct {
-	Owner  string `json:"owner"`
-	Limit  int32  `json:"limit"`
-	Offset int32  `json:"offset"`
+	Owner    string `json:"owner"`
+	Type     string `json:"type"`
+	Currency string `json:"currency"`
+	Offset   int32  `json:"offset"`
+	Limit    int32  `json:"limit"`
 }
 
+// An empty type or currency matches every account.
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.Query(ctx, listAccounts, arg.Owner, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listAccounts,
+		arg.Owner,
+		arg.Type,
+		arg.Currency,
+		arg.Offset,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -139,6 +174,10 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.Status,
+			&i.ClosedAt,
+			&i.Type,
+			&i.Nickname,
 		); err != nil {
 			return nil, err
 		}
@@ -150,11 +189,130 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 	return items, nil
 }
 
+const listAllAccounts = `-- name: ListAllAccounts :many
+SELECT id, owner, balance, currency, created_at, status, closed_at, type, nickname FROM accounts
+ORDER BY id
+LIMIT $1
+OFFSET $2
+`
+
+type ListAllAccountsParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) ListAllAccounts(ctx context.Context, arg ListAllAccountsParams) ([]Account, error) {
+	rows, err := q.db.Query(ctx, listAllAccounts, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.Status,
+			&i.ClosedAt,
+			&i.Type,
+			&i.Nickname,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnreconciledAccounts = `-- name: ListUnreconciledAccounts :many
+SELECT
+    a.id,
+    a.owner,
+    a.currency,
+    a.balance,
+    COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id
+`
+
+type ListUnreconciledAccountsRow struct {
+	ID           int64  `json:"id"`
+	Owner        string `json:"owner"`
+	Currency     string `json:"currency"`
+	Balance      int64  `json:"balance"`
+	EntriesTotal int64  `json:"entries_total"`
+}
+
+func (q *Queries) ListUnreconciledAccounts(ctx context.Context) ([]ListUnreconciledAccountsRow, error) {
+	rows, err := q.db.Query(ctx, listUnreconciledAccounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUnreconciledAccountsRow{}
+	for rows.Next() {
+		var i ListUnreconciledAccountsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Currency,
+			&i.Balance,
+			&i.EntriesTotal,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockAccounts = `-- name: LockAccounts :many
+SELECT id FROM accounts
+WHERE id = ANY($1::bigint[])
+ORDER BY id
+FOR NO KEY UPDATE
+`
+
+// Locks accounts in id order, the order every transaction takes them in,
+// so transactions locking several accounts do not deadlock.
+func (q *Queries) LockAccounts(ctx context.Context, ids []int64) ([]int64, error) {
+	rows, err := q.db.Query(ctx, lockAccounts, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts
 SET balance = $2
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, status, closed_at, type, nickname
 `
 
 type UpdateAccountParams struct {
@@ -171,6 +329,44 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Status,
+		&i.ClosedAt,
+		&i.Type,
+		&i.Nickname,
+	)
+	return i, err
+}
+
+const updateAccountStatus = `-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET
+    status = $1,
+    closed_at = CASE
+        WHEN $1::varchar = 'closed' THEN now()
+        ELSE '0001-01-01 00:00:00Z'
+    END
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, status, closed_at, type, nickname
+`
+
+type UpdateAccountStatusParams struct {
+	Status string `json:"status"`
+	ID     int64  `json:"id"`
+}
+
+func (q *Queries) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	row := q.db.QueryRow(ctx, updateAccountStatus, arg.Status, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Status,
+		&i.ClosedAt,
+		&i.Type,
+		&i.Nickname,
 	)
 	return i, err
 }
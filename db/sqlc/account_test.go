@@ -13,12 +13,13 @@ import (
 // createRandomAccount creates random account for testing
 func createRandomAccount(t *testing.T) Account {
 
-	user := createRandomUser(t)
+	user := createRandomVerifiedUser(t)
 
 	arg := CreateAccountParams{
 		Owner:    user.Username,
 		Balance:  util.RandomBalance(),
 		Currency: util.RandomCurrency(),
+		Type:     AccountTypeChecking,
 	}
 
 	account, err := testQueries.CreateAccount(context.Background(), arg)
@@ -27,6 +28,7 @@ func createRandomAccount(t *testing.T) Account {
 	require.Equal(t, arg.Owner, account.Owner)
 	require.Equal(t, arg.Balance, account.Balance)
 	require.Equal(t, arg.Currency, account.Currency)
+	require.Equal(t, AccountStatusActive, account.Status)
 
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
@@ -41,6 +43,7 @@ func accountsEqual(t *testing.T, a, b Account) {
 	require.Equal(t, a.Owner, b.Owner)
 	require.Equal(t, a.Balance, b.Balance)
 	require.Equal(t, a.Currency, b.Currency)
+	require.Equal(t, a.Status, b.Status)
 
 	require.WithinDuration(t, a.CreatedAt.Time, b.CreatedAt.Time, time.Second)
 }
@@ -126,6 +129,64 @@ func TestListAccounts(t *testing.T) {
 	}
 }
 
+func TestListAccountsByTypeAndCurrency(t *testing.T) {
+	ctx := context.Background()
+	user := createRandomVerifiedUser(t)
+
+	for _, arg := range []CreateAccountParams{
+		{Currency: util.USD, Type: AccountTypeChecking},
+		{Currency: util.USD, Type: AccountTypeSavings, Nickname: "Holidays"},
+		{Currency: util.EUR, Type: AccountTypeSavings, Nickname: "Rainy day"},
+	} {
+		arg.Owner = user.Username
+
+		account, err := testQueries.CreateAccount(ctx, arg)
+		require.NoError(t, err)
+		require.Equal(t, arg.Type, account.Type)
+		require.Equal(t, arg.Nickname, account.Nickname)
+	}
+
+	// A user may hold several accounts in one currency, but not two with
+	// the same nickname.
+	_, err := testQueries.CreateAccount(ctx, CreateAccountParams{
+		Owner:    user.Username,
+		Currency: util.USD,
+		Type:     AccountTypeChecking,
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.CreateAccount(ctx, CreateAccountParams{
+		Owner:    user.Username,
+		Currency: util.EUR,
+		Type:     AccountTypeBusiness,
+		Nickname: "Holidays",
+	})
+	require.Equal(t, UniqueViolation, ErrorCode(err))
+
+	testCases := []struct {
+		accountType string
+		currency    string
+		count       int
+	}{
+		{count: 4},
+		{accountType: AccountTypeSavings, count: 2},
+		{currency: util.USD, count: 3},
+		{accountType: AccountTypeSavings, currency: util.EUR, count: 1},
+		{accountType: AccountTypeBusiness, count: 0},
+	}
+
+	for _, tc := range testCases {
+		accounts, err := testQueries.ListAccounts(ctx, ListAccountsParams{
+			Owner:    user.Username,
+			Type:     tc.accountType,
+			Currency: tc.currency,
+			Limit:    10,
+		})
+		require.NoError(t, err)
+		require.Len(t, accounts, tc.count, "type %q currency %q", tc.accountType, tc.currency)
+	}
+}
+
 func TestAddAccountBalance(t *testing.T) {
 	accountNew := createRandomAccount(t)
 
@@ -142,3 +203,20 @@ func TestAddAccountBalance(t *testing.T) {
 
 	accountsEqual(t, accountNew, account)
 }
+
+func TestUpdateAccountStatus(t *testing.T) {
+	account := createRandomAccount(t)
+
+	frozen, err := testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:     account.ID,
+		Status: AccountStatusFrozen,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusFrozen, frozen.Status)
+
+	_, err = testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:     account.ID,
+		Status: "unknown",
+	})
+	require.Error(t, err)
+}
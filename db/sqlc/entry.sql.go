@@ -7,37 +7,41 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
-    account_id, amount
+    account_id, amount, transfer_id
 ) 
 VALUES (
-    $1, $2
+    $1, $2, $3
 ) 
-RETURNING id, account_id, amount, created_at
+RETURNING id, account_id, amount, created_at, transfer_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID  int64 `json:"account_id"`
+	Amount     int64 `json:"amount"`
+	TransferID int64 `json:"transfer_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRow(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRow(ctx, createEntry, arg.AccountID, arg.Amount, arg.TransferID)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries 
+SELECT id, account_id, amount, created_at, transfer_id FROM entries 
 WHERE id = $1 LIMIT 1
 `
 
@@ -49,12 +53,38 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
 	)
 	return i, err
 }
 
+const getOpeningBalance = `-- name: GetOpeningBalance :one
+SELECT (a.balance - COALESCE((
+    SELECT SUM(e.amount)
+    FROM entries e
+    WHERE e.account_id = a.id AND e.created_at >= $1
+), 0))::bigint AS opening_balance
+FROM accounts a
+WHERE a.id = $2
+`
+
+type GetOpeningBalanceParams struct {
+	FromTime  pgtype.Timestamp `json:"from_time"`
+	AccountID int64            `json:"account_id"`
+}
+
+// The balance of an account before from_time: its current balance less
+// every entry since. Entries are only ever added at the current time, so the
+// result does not change as new ones arrive.
+func (q *Queries) GetOpeningBalance(ctx context.Context, arg GetOpeningBalanceParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getOpeningBalance, arg.FromTime, arg.AccountID)
+	var opening_balance int64
+	err := row.Scan(&opening_balance)
+	return opening_balance, err
+}
+
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
@@ -81,6 +111,81 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStatementEntries = `-- name: ListStatementEntries :many
+SELECT
+    e.id,
+    e.amount,
+    e.transfer_id,
+    e.created_at,
+    COALESCE(c.id, 0)::bigint AS counterparty_account_id,
+    COALESCE(c.owner, '')::varchar AS counterparty_owner
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN accounts c ON c.id = CASE
+    WHEN t.from_account_id = e.account_id THEN t.to_account_id
+    ELSE t.from_account_id
+END
+WHERE e.account_id = $1
+    AND e.created_at >= $2
+    AND e.created_at < $3
+    AND e.id > $4
+ORDER BY e.id
+LIMIT $5
+`
+
+type ListStatementEntriesParams struct {
+	AccountID int64            `json:"account_id"`
+	FromTime  pgtype.Timestamp `json:"from_time"`
+	ToTime    pgtype.Timestamp `json:"to_time"`
+	AfterID   int64            `json:"after_id"`
+	PageSize  int32            `json:"page_size"`
+}
+
+type ListStatementEntriesRow struct {
+	ID                    int64            `json:"id"`
+	Amount                int64            `json:"amount"`
+	TransferID            int64            `json:"transfer_id"`
+	CreatedAt             pgtype.Timestamp `json:"created_at"`
+	CounterpartyAccountID int64            `json:"counterparty_account_id"`
+	CounterpartyOwner     string           `json:"counterparty_owner"`
+}
+
+// A page of an account's entries in [from_time, to_time) after entry
+// after_id, with the other account of the transfer that created each one.
+func (q *Queries) ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error) {
+	rows, err := q.db.Query(ctx, listStatementEntries,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListStatementEntriesRow{}
+	for rows.Next() {
+		var i ListStatementEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Amount,
+			&i.TransferID,
+			&i.CreatedAt,
+			&i.CounterpartyAccountID,
+			&i.CounterpartyOwner,
 		); err != nil {
 			return nil, err
 		}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 )
 
@@ -73,3 +74,58 @@ func TestListEntries(t *testing.T) {
 		require.NotEmpty(t, entry)
 	}
 }
+
+func TestListStatementEntries(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	from := pgtype.Timestamp{Time: time.Now().UTC().Add(-time.Minute), Valid: true}
+
+	opening, err := store.GetOpeningBalance(ctx, GetOpeningBalanceParams{
+		FromTime:  from,
+		AccountID: account1.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, opening)
+
+	for range 3 {
+		_, err := store.TransferTx(ctx, TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+		})
+		require.NoError(t, err)
+	}
+
+	opening, err = store.GetOpeningBalance(ctx, GetOpeningBalanceParams{
+		FromTime:  from,
+		AccountID: account1.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, opening)
+
+	arg := ListStatementEntriesParams{
+		AccountID: account1.ID,
+		FromTime:  from,
+		ToTime:    pgtype.Timestamp{Time: time.Now().UTC().Add(time.Minute), Valid: true},
+		PageSize:  2,
+	}
+
+	page, err := store.ListStatementEntries(ctx, arg)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+
+	arg.AfterID = page[1].ID
+	rest, err := store.ListStatementEntries(ctx, arg)
+	require.NoError(t, err)
+	require.Len(t, rest, 1)
+
+	for _, entry := range append(page, rest...) {
+		require.Equal(t, int64(-10), entry.Amount)
+		require.NotZero(t, entry.TransferID)
+		require.Equal(t, account2.ID, entry.CounterpartyAccountID)
+		require.Equal(t, account2.Owner, entry.CounterpartyOwner)
+	}
+}
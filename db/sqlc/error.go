@@ -0,0 +1,28 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgreSQL error codes returned by ErrorCode
+const (
+	ForeignKeyViolation = "23503"
+	UniqueViolation     = "23505"
+)
+
+// ErrRecordNotFound is returned by single-row queries that match nothing
+var ErrRecordNotFound = pgx.ErrNoRows
+
+// ErrorCode returns the PostgreSQL error code of err, or an empty string
+// if err did not originate from the database server
+func ErrorCode(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+
+	return ""
+}
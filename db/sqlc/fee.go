@@ -0,0 +1,234 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Events fees are charged for, stored in fee_rules.event and fees.event
+const (
+	FeeEventTransfer    = "transfer"
+	FeeEventMaintenance = "maintenance"
+)
+
+// ErrFeeAlreadyCharged is returned by ChargeMaintenanceFeeTx when the
+// account's maintenance fee for the month has already been charged.
+var ErrFeeAlreadyCharged = errors.New("maintenance fee has already been charged for the month")
+
+// Charge is the fee the rule sets for amount: flat plus rate times amount,
+// rounded down, then raised to min_fee and lowered to max_fee when that is
+// set.
+func (r FeeRule) Charge(amount int64) (int64, error) {
+	rate, ok := new(big.Rat).SetString(r.Rate)
+	if !ok {
+		return 0, fmt.Errorf("fee rule %d has invalid rate %q", r.ID, r.Rate)
+	}
+
+	variable := new(big.Int).Mul(big.NewInt(amount), rate.Num())
+	variable.Quo(variable, rate.Denom())
+
+	fee := r.Flat + variable.Int64()
+	fee = max(fee, r.MinFee)
+	if r.MaxFee > 0 {
+		fee = min(fee, r.MaxFee)
+	}
+
+	return fee, nil
+}
+
+// matchFee finds the rule for event that applies to account and amount,
+// and the fee it sets. The rule is zero and the fee 0 when none applies,
+// which is always the case for the bank's own accounts.
+func matchFee(ctx context.Context, q *Queries, event string, account Account, amount int64) (FeeRule, int64, error) {
+	if account.Owner == BankUsername {
+		return FeeRule{}, 0, nil
+	}
+
+	rule, err := q.MatchFeeRule(ctx, MatchFeeRuleParams{
+		Event:       event,
+		Currency:    account.Currency,
+		AccountType: account.Type,
+		Amount:      amount,
+	})
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return FeeRule{}, 0, nil
+		}
+
+		return rule, 0, err
+	}
+
+	fee, err := rule.Charge(amount)
+
+	return rule, fee, err
+}
+
+// chargeFee books fee from account to the bank's fee revenue account in its
+// currency.
+func chargeFee(ctx context.Context, q *Queries, account Account, fee int64) (TransferTxResult, error) {
+	revenue, err := q.GetSystemAccount(ctx, GetSystemAccountParams{
+		Code:     LedgerFeeRevenue,
+		Currency: account.Currency,
+	})
+	if err != nil {
+		return TransferTxResult{}, fmt.Errorf("cannot get fee revenue account for %s: %w", account.Currency, err)
+	}
+
+	return transfer(ctx, q, TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   revenue.ID,
+		Amount:        fee,
+	})
+}
+
+// transferWithFee books a customer's transfer like transfer and then
+// charges the sender the transfer fee, if a rule sets one, as a transfer of
+// its own. Unlike the bank's own transfers, it fails with
+// ErrEmailNotVerified unless the sender has verified their email.
+func transferWithFee(ctx context.Context, q *Queries, args TransferTxParams) (TransferTxResult, error) {
+	result, err := transfer(ctx, q, args)
+	if err != nil {
+		return result, err
+	}
+
+	if err := checkEmailVerified(ctx, q, result.FromAccount); err != nil {
+		return result, err
+	}
+
+	rule, amount, err := matchFee(ctx, q, FeeEventTransfer, result.FromAccount, args.Amount)
+	if err != nil || amount == 0 {
+		return result, err
+	}
+
+	charged, err := chargeFee(ctx, q, result.FromAccount, amount)
+	if err != nil {
+		return result, err
+	}
+
+	fee, err := q.CreateFee(ctx, CreateFeeParams{
+		AccountID:         args.FromAccountID,
+		Event:             FeeEventTransfer,
+		RuleID:            rule.ID,
+		Amount:            amount,
+		TransferID:        charged.Transfer.ID,
+		ChargedTransferID: result.Transfer.ID,
+		Month:             pgtype.Date{Valid: true},
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.FromAccount = charged.FromAccount
+	result.Fee = &fee
+	result.FeeEntry = &charged.FromEntry
+
+	return result, nil
+}
+
+// TransferQuote is what a transfer would cost the sender.
+type TransferQuote struct {
+	Amount int64 `json:"amount"`
+	Fee    int64 `json:"fee"`
+	// Total is the amount plus the fee.
+	Total int64 `json:"total"`
+	// FeeRuleID is the rule that set the fee, 0 if none applies.
+	FeeRuleID int64 `json:"fee_rule_id"`
+}
+
+// QuoteTransfer returns the fee TransferTx would charge for args under the
+// current rules, without booking anything.
+func (s *SQLStore) QuoteTransfer(ctx context.Context, args TransferTxParams) (TransferQuote, error) {
+	account, err := s.GetAccount(ctx, args.FromAccountID)
+	if err != nil {
+		return TransferQuote{}, err
+	}
+
+	rule, fee, err := matchFee(ctx, s.Queries, FeeEventTransfer, account, args.Amount)
+	if err != nil {
+		return TransferQuote{}, err
+	}
+
+	return TransferQuote{
+		Amount:    args.Amount,
+		Fee:       fee,
+		Total:     args.Amount + fee,
+		FeeRuleID: rule.ID,
+	}, nil
+}
+
+// ChargeMaintenanceFeeTxParams is a set of parameters for
+// ChargeMaintenanceFeeTx
+type ChargeMaintenanceFeeTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// Month is the first day of the month to charge, in UTC.
+	Month time.Time `json:"month"`
+}
+
+// ChargeMaintenanceFeeTx charges an account the maintenance fee for
+// args.Month set by the rule matching its balance at the end of the month.
+// The fee is recorded even when it is 0, so each month is charged at most
+// once per account: charging it again fails with ErrFeeAlreadyCharged. When
+// no rule applies, nothing is recorded and the returned fee is zero.
+func (s *SQLStore) ChargeMaintenanceFeeTx(ctx context.Context, args ChargeMaintenanceFeeTxParams) (Fee, error) {
+	var fee Fee
+
+	err := s.execTx(ctx, "ChargeMaintenanceFeeTx", func(ctx context.Context, q *Queries) error {
+		// Lock the account before reading its balance, so a transfer or
+		// status change cannot slip in between the read and the charge.
+		account, err := q.GetAccountForUpdate(ctx, args.AccountID)
+		if err != nil {
+			return err
+		}
+
+		balance, err := q.GetOpeningBalance(ctx, GetOpeningBalanceParams{
+			FromTime:  pgtype.Timestamp{Time: args.Month.AddDate(0, 1, 0), Valid: true},
+			AccountID: account.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		rule, amount, err := matchFee(ctx, q, FeeEventMaintenance, account, balance)
+		if err != nil || rule.ID == 0 {
+			return err
+		}
+
+		fee, err = q.CreateFee(ctx, CreateFeeParams{
+			AccountID: account.ID,
+			Event:     FeeEventMaintenance,
+			RuleID:    rule.ID,
+			Amount:    amount,
+			Month:     pgtype.Date{Time: args.Month, Valid: true},
+		})
+		if err != nil {
+			if ErrorCode(err) == UniqueViolation {
+				return fmt.Errorf("account %d, %s: %w", account.ID, args.Month.Format("2006-01"), ErrFeeAlreadyCharged)
+			}
+
+			return err
+		}
+
+		if amount == 0 {
+			return nil
+		}
+
+		charged, err := chargeFee(ctx, q, account, amount)
+		if err != nil {
+			return err
+		}
+
+		fee, err = q.SetFeeTransfer(ctx, SetFeeTransferParams{
+			ID:         fee.ID,
+			TransferID: charged.Transfer.ID,
+		})
+
+		return err
+	})
+
+	return fee, err
+}
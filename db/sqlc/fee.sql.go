@@ -0,0 +1,303 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: fee.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createFee = `-- name: CreateFee :one
+INSERT INTO fees (
+    account_id, event, rule_id, amount, transfer_id, charged_transfer_id, month
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING id, account_id, event, rule_id, amount, transfer_id, charged_transfer_id, month, created_at
+`
+
+type CreateFeeParams struct {
+	AccountID         int64       `json:"account_id"`
+	Event             string      `json:"event"`
+	RuleID            int64       `json:"rule_id"`
+	Amount            int64       `json:"amount"`
+	TransferID        int64       `json:"transfer_id"`
+	ChargedTransferID int64       `json:"charged_transfer_id"`
+	Month             pgtype.Date `json:"month"`
+}
+
+func (q *Queries) CreateFee(ctx context.Context, arg CreateFeeParams) (Fee, error) {
+	row := q.db.QueryRow(ctx, createFee,
+		arg.AccountID,
+		arg.Event,
+		arg.RuleID,
+		arg.Amount,
+		arg.TransferID,
+		arg.ChargedTransferID,
+		arg.Month,
+	)
+	var i Fee
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Event,
+		&i.RuleID,
+		&i.Amount,
+		&i.TransferID,
+		&i.ChargedTransferID,
+		&i.Month,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createFeeRule = `-- name: CreateFeeRule :one
+INSERT INTO fee_rules (
+    event, currency, account_type, min_amount, flat, rate, min_fee, max_fee, description
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+RETURNING id, event, currency, account_type, min_amount, flat, rate, min_fee, max_fee, description, created_at
+`
+
+type CreateFeeRuleParams struct {
+	Event       string `json:"event"`
+	Currency    string `json:"currency"`
+	AccountType string `json:"account_type"`
+	MinAmount   int64  `json:"min_amount"`
+	Flat        int64  `json:"flat"`
+	Rate        string `json:"rate"`
+	MinFee      int64  `json:"min_fee"`
+	MaxFee      int64  `json:"max_fee"`
+	Description string `json:"description"`
+}
+
+func (q *Queries) CreateFeeRule(ctx context.Context, arg CreateFeeRuleParams) (FeeRule, error) {
+	row := q.db.QueryRow(ctx, createFeeRule,
+		arg.Event,
+		arg.Currency,
+		arg.AccountType,
+		arg.MinAmount,
+		arg.Flat,
+		arg.Rate,
+		arg.MinFee,
+		arg.MaxFee,
+		arg.Description,
+	)
+	var i FeeRule
+	err := row.Scan(
+		&i.ID,
+		&i.Event,
+		&i.Currency,
+		&i.AccountType,
+		&i.MinAmount,
+		&i.Flat,
+		&i.Rate,
+		&i.MinFee,
+		&i.MaxFee,
+		&i.Description,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteFeeRule = `-- name: DeleteFeeRule :execrows
+DELETE FROM fee_rules
+WHERE id = $1
+`
+
+func (q *Queries) DeleteFeeRule(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteFeeRule, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const listFeeRules = `-- name: ListFeeRules :many
+SELECT id, event, currency, account_type, min_amount, flat, rate, min_fee, max_fee, description, created_at FROM fee_rules
+ORDER BY event, currency, account_type, min_amount
+`
+
+func (q *Queries) ListFeeRules(ctx context.Context) ([]FeeRule, error) {
+	rows, err := q.db.Query(ctx, listFeeRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FeeRule{}
+	for rows.Next() {
+		var i FeeRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Event,
+			&i.Currency,
+			&i.AccountType,
+			&i.MinAmount,
+			&i.Flat,
+			&i.Rate,
+			&i.MinFee,
+			&i.MaxFee,
+			&i.Description,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFees = `-- name: ListFees :many
+SELECT id, account_id, event, rule_id, amount, transfer_id, charged_transfer_id, month, created_at FROM fees
+WHERE account_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3
+`
+
+type ListFeesParams struct {
+	AccountID int64 `json:"account_id"`
+	Limit     int32 `json:"limit"`
+	Offset    int32 `json:"offset"`
+}
+
+func (q *Queries) ListFees(ctx context.Context, arg ListFeesParams) ([]Fee, error) {
+	rows, err := q.db.Query(ctx, listFees, arg.AccountID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Fee{}
+	for rows.Next() {
+		var i Fee
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Event,
+			&i.RuleID,
+			&i.Amount,
+			&i.TransferID,
+			&i.ChargedTransferID,
+			&i.Month,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMaintenanceFeeAccounts = `-- name: ListMaintenanceFeeAccounts :many
+SELECT a.id FROM accounts a
+WHERE a.status <> 'closed'
+    AND a.created_at < $1::timestamptz
+    AND NOT EXISTS (SELECT 1 FROM system_accounts s WHERE s.account_id = a.id)
+ORDER BY a.id
+`
+
+// Lists the open customer accounts that existed at month_end.
+func (q *Queries) ListMaintenanceFeeAccounts(ctx context.Context, monthEnd pgtype.Timestamptz) ([]int64, error) {
+	rows, err := q.db.Query(ctx, listMaintenanceFeeAccounts, monthEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const matchFeeRule = `-- name: MatchFeeRule :one
+SELECT id, event, currency, account_type, min_amount, flat, rate, min_fee, max_fee, description, created_at FROM fee_rules
+WHERE event = $1
+    AND currency IN ($2::varchar, '')
+    AND account_type IN ($3::varchar, '')
+    AND min_amount <= $4::bigint
+ORDER BY currency <> '' DESC, account_type <> '' DESC, min_amount DESC
+LIMIT 1
+`
+
+type MatchFeeRuleParams struct {
+	Event       string `json:"event"`
+	Currency    string `json:"currency"`
+	AccountType string `json:"account_type"`
+	Amount      int64  `json:"amount"`
+}
+
+// The rule for an event that applies to an account of the given currency
+// and type and an amount. Rules for the currency win over rules for any
+// currency, then rules for the account type over rules for any type, and
+// among those the highest tier the amount reaches applies.
+func (q *Queries) MatchFeeRule(ctx context.Context, arg MatchFeeRuleParams) (FeeRule, error) {
+	row := q.db.QueryRow(ctx, matchFeeRule,
+		arg.Event,
+		arg.Currency,
+		arg.AccountType,
+		arg.Amount,
+	)
+	var i FeeRule
+	err := row.Scan(
+		&i.ID,
+		&i.Event,
+		&i.Currency,
+		&i.AccountType,
+		&i.MinAmount,
+		&i.Flat,
+		&i.Rate,
+		&i.MinFee,
+		&i.MaxFee,
+		&i.Description,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const setFeeTransfer = `-- name: SetFeeTransfer :one
+UPDATE fees
+SET transfer_id = $2
+WHERE id = $1
+RETURNING id, account_id, event, rule_id, amount, transfer_id, charged_transfer_id, month, created_at
+`
+
+type SetFeeTransferParams struct {
+	ID         int64 `json:"id"`
+	TransferID int64 `json:"transfer_id"`
+}
+
+func (q *Queries) SetFeeTransfer(ctx context.Context, arg SetFeeTransferParams) (Fee, error) {
+	row := q.db.QueryRow(ctx, setFeeTransfer, arg.ID, arg.TransferID)
+	var i Fee
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Event,
+		&i.RuleID,
+		&i.Amount,
+		&i.TransferID,
+		&i.ChargedTransferID,
+		&i.Month,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shevgn/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// addFeeRule adds a rule for the duration of the test. Tests only add
+// rules for business accounts, so other tests' transfers are not charged.
+func addFeeRule(t *testing.T, arg CreateFeeRuleParams) FeeRule {
+	arg.AccountType = AccountTypeBusiness
+
+	rule, err := testQueries.CreateFeeRule(context.Background(), arg)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, err := testQueries.DeleteFeeRule(context.Background(), rule.ID)
+		require.NoError(t, err)
+	})
+
+	return rule
+}
+
+func TestTransferTxWithFee(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+
+	base := addFeeRule(t, CreateFeeRuleParams{Event: FeeEventTransfer, Currency: util.EUR, Flat: 10, Rate: "0.01"})
+	tier := addFeeRule(t, CreateFeeRuleParams{Event: FeeEventTransfer, Currency: util.EUR, MinAmount: 500, Rate: "0.005"})
+
+	from := createRandomAccountOfType(t, AccountTypeBusiness, util.EUR)
+	to := createRandomAccountOfType(t, AccountTypeBusiness, util.EUR)
+
+	revenue, err := store.GetSystemAccount(ctx, GetSystemAccountParams{Code: LedgerFeeRevenue, Currency: util.EUR})
+	require.NoError(t, err)
+
+	quote, err := store.QuoteTransfer(ctx, TransferTxParams{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 100})
+	require.NoError(t, err)
+	require.Equal(t, TransferQuote{Amount: 100, Fee: 11, Total: 111, FeeRuleID: base.ID}, quote)
+
+	result, err := store.TransferTx(ctx, TransferTxParams{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 100})
+	require.NoError(t, err)
+	require.NotNil(t, result.Fee)
+	require.Equal(t, int64(11), result.Fee.Amount)
+	require.Equal(t, base.ID, result.Fee.RuleID)
+	require.Equal(t, result.Transfer.ID, result.Fee.ChargedTransferID)
+	require.Equal(t, int64(-11), result.FeeEntry.Amount)
+	require.Equal(t, from.Balance-111, result.FromAccount.Balance)
+	require.Equal(t, to.Balance+100, result.ToAccount.Balance)
+
+	feeTransfer, err := store.GetTransfer(ctx, result.Fee.TransferID)
+	require.NoError(t, err)
+	require.Equal(t, from.ID, feeTransfer.FromAccountID)
+	require.Equal(t, revenue.ID, feeTransfer.ToAccountID)
+	require.Equal(t, int64(11), feeTransfer.Amount)
+
+	// The higher tier applies from 500 on.
+	result, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 600})
+	require.NoError(t, err)
+	require.Equal(t, tier.ID, result.Fee.RuleID)
+	require.Equal(t, int64(3), result.Fee.Amount)
+
+	// Transfers from other account types are not charged.
+	checking := createRandomAccountOfType(t, AccountTypeChecking, util.EUR)
+	result, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: checking.ID, ToAccountID: to.ID, Amount: 1})
+	require.NoError(t, err)
+	require.Nil(t, result.Fee)
+	require.Nil(t, result.FeeEntry)
+}
+
+func TestChargeMaintenanceFeeTx(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+
+	rule := addFeeRule(t, CreateFeeRuleParams{Event: FeeEventMaintenance, Currency: util.USD, Flat: 300})
+	waived := addFeeRule(t, CreateFeeRuleParams{Event: FeeEventMaintenance, Currency: util.USD, MinAmount: 1_000_000})
+
+	account := createRandomAccountOfType(t, AccountTypeBusiness, util.USD)
+	rich := createRandomAccountOfType(t, AccountTypeBusiness, util.USD)
+	rich, err := store.AddAccountBalance(ctx, AddAccountBalanceParams{ID: rich.ID, Amount: 1_000_000})
+	require.NoError(t, err)
+
+	month := time.Date(time.Now().Year()+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	fee, err := store.ChargeMaintenanceFeeTx(ctx, ChargeMaintenanceFeeTxParams{AccountID: account.ID, Month: month})
+	require.NoError(t, err)
+	require.Equal(t, rule.ID, fee.RuleID)
+	require.Equal(t, int64(300), fee.Amount)
+	require.NotZero(t, fee.TransferID)
+
+	_, err = store.ChargeMaintenanceFeeTx(ctx, ChargeMaintenanceFeeTxParams{AccountID: account.ID, Month: month})
+	require.ErrorIs(t, err, ErrFeeAlreadyCharged)
+
+	updated, err := store.GetAccount(ctx, account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance-300, updated.Balance)
+
+	fee, err = store.ChargeMaintenanceFeeTx(ctx, ChargeMaintenanceFeeTxParams{AccountID: rich.ID, Month: month})
+	require.NoError(t, err)
+	require.Equal(t, waived.ID, fee.RuleID)
+	require.Zero(t, fee.Amount)
+	require.Zero(t, fee.TransferID)
+}
+
+func TestChargeMaintenanceFeeTxUnverifiedOwner(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+
+	rule := addFeeRule(t, CreateFeeRuleParams{Event: FeeEventMaintenance, Currency: util.EUR, Flat: 300})
+
+	// The bank charges fees whether or not the owner has verified their
+	// email; only the owner's own transfers require it.
+	user := createRandomUser(t)
+	require.False(t, user.IsEmailVerified)
+
+	account, err := store.CreateAccount(ctx, CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  util.RandomBalance(),
+		Currency: util.EUR,
+		Type:     AccountTypeBusiness,
+	})
+	require.NoError(t, err)
+
+	month := time.Date(time.Now().Year()+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	fee, err := store.ChargeMaintenanceFeeTx(ctx, ChargeMaintenanceFeeTxParams{AccountID: account.ID, Month: month})
+	require.NoError(t, err)
+	require.Equal(t, rule.ID, fee.RuleID)
+	require.Equal(t, int64(300), fee.Amount)
+
+	to := createRandomAccountOfType(t, AccountTypeBusiness, util.EUR)
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account.ID, ToAccountID: to.ID, Amount: 1})
+	require.ErrorIs(t, err, ErrEmailNotVerified)
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// MicrosPerUnit is the number of interest_accruals.amount_micros in one
+// minor unit of a currency.
+const MicrosPerUnit = 1_000_000
+
+// ErrInterestAlreadyPosted is returned by PostInterestTx when the account's
+// interest for the month has already been posted.
+var ErrInterestAlreadyPosted = errors.New("interest has already been posted for the month")
+
+// PostInterestTxParams is a set of parameters for PostInterestTx
+type PostInterestTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// Month is the first day of the month to post, in UTC.
+	Month time.Time `json:"month"`
+}
+
+// PostInterestTx pays an account the interest it accrued up to the end of
+// args.Month and not yet paid, with a transfer from the bank's interest
+// expense account in its currency. Whole minor units are paid; the fraction
+// left over is paid by a later posting. Each month is posted at most once
+// per account: posting it again fails with ErrInterestAlreadyPosted.
+func (s *SQLStore) PostInterestTx(ctx context.Context, args PostInterestTxParams) (InterestPosting, error) {
+	var posting InterestPosting
+
+	month := pgtype.Date{Time: args.Month, Valid: true}
+	before := pgtype.Date{Time: args.Month.AddDate(0, 1, 0), Valid: true}
+
+	err := s.execTx(ctx, "PostInterestTx", func(ctx context.Context, q *Queries) error {
+		account, err := q.GetAccount(ctx, args.AccountID)
+		if err != nil {
+			return err
+		}
+
+		bank, err := q.GetSystemAccount(ctx, GetSystemAccountParams{
+			Code:     LedgerInterestExpense,
+			Currency: account.Currency,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot get interest expense account for %s: %w", account.Currency, err)
+		}
+
+		// Both accounts are locked before anything is read, in the order
+		// transfers lock them.
+		if _, err := q.LockAccounts(ctx, []int64{account.ID, bank.ID}); err != nil {
+			return err
+		}
+
+		unposted, err := q.SumUnpostedInterest(ctx, SumUnpostedInterestParams{
+			AccountID: account.ID,
+			Before:    before,
+		})
+		if err != nil {
+			return err
+		}
+
+		totals, err := q.GetInterestPostingTotals(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+
+		// Paying the whole units of everything accrued so far, less what was
+		// already paid, carries fractions over without storing them.
+		amount := (totals.AccruedMicros+unposted)/MicrosPerUnit - totals.Amount
+
+		posting, err = q.CreateInterestPosting(ctx, CreateInterestPostingParams{
+			AccountID:     account.ID,
+			Month:         month,
+			AccruedMicros: unposted,
+			Amount:        amount,
+		})
+		if err != nil {
+			if ErrorCode(err) == UniqueViolation {
+				return fmt.Errorf("account %d, %s: %w", account.ID, args.Month.Format("2006-01"), ErrInterestAlreadyPosted)
+			}
+
+			return err
+		}
+
+		if amount > 0 {
+			result, err := transfer(ctx, q, TransferTxParams{
+				FromAccountID: bank.ID,
+				ToAccountID:   account.ID,
+				Amount:        amount,
+			})
+			if err != nil {
+				return err
+			}
+
+			posting, err = q.SetInterestPostingTransfer(ctx, SetInterestPostingTransferParams{
+				ID:         posting.ID,
+				TransferID: result.Transfer.ID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = q.MarkInterestPosted(ctx, MarkInterestPostedParams{
+			PostingID: posting.ID,
+			AccountID: account.ID,
+			Before:    before,
+		})
+
+		return err
+	})
+
+	return posting, err
+}
@@ -0,0 +1,314 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: interest.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createInterestAccrual = `-- name: CreateInterestAccrual :execrows
+INSERT INTO interest_accruals (
+    account_id, accrual_date, balance, rate, amount_micros
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (account_id, accrual_date) DO NOTHING
+`
+
+type CreateInterestAccrualParams struct {
+	AccountID    int64       `json:"account_id"`
+	AccrualDate  pgtype.Date `json:"accrual_date"`
+	Balance      int64       `json:"balance"`
+	Rate         string      `json:"rate"`
+	AmountMicros int64       `json:"amount_micros"`
+}
+
+// Does nothing when the account already accrued interest for the date.
+func (q *Queries) CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (int64, error) {
+	result, err := q.db.Exec(ctx, createInterestAccrual,
+		arg.AccountID,
+		arg.AccrualDate,
+		arg.Balance,
+		arg.Rate,
+		arg.AmountMicros,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createInterestPosting = `-- name: CreateInterestPosting :one
+INSERT INTO interest_postings (
+    account_id, month, accrued_micros, amount
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING id, account_id, month, accrued_micros, amount, transfer_id, created_at
+`
+
+type CreateInterestPostingParams struct {
+	AccountID     int64       `json:"account_id"`
+	Month         pgtype.Date `json:"month"`
+	AccruedMicros int64       `json:"accrued_micros"`
+	Amount        int64       `json:"amount"`
+}
+
+func (q *Queries) CreateInterestPosting(ctx context.Context, arg CreateInterestPostingParams) (InterestPosting, error) {
+	row := q.db.QueryRow(ctx, createInterestPosting,
+		arg.AccountID,
+		arg.Month,
+		arg.AccruedMicros,
+		arg.Amount,
+	)
+	var i InterestPosting
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Month,
+		&i.AccruedMicros,
+		&i.Amount,
+		&i.TransferID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getInterestPostingTotals = `-- name: GetInterestPostingTotals :one
+SELECT
+    COALESCE(SUM(accrued_micros), 0)::bigint AS accrued_micros,
+    COALESCE(SUM(amount), 0)::bigint AS amount
+FROM interest_postings
+WHERE account_id = $1
+`
+
+type GetInterestPostingTotalsRow struct {
+	AccruedMicros int64 `json:"accrued_micros"`
+	Amount        int64 `json:"amount"`
+}
+
+func (q *Queries) GetInterestPostingTotals(ctx context.Context, accountID int64) (GetInterestPostingTotalsRow, error) {
+	row := q.db.QueryRow(ctx, getInterestPostingTotals, accountID)
+	var i GetInterestPostingTotalsRow
+	err := row.Scan(&i.AccruedMicros, &i.Amount)
+	return i, err
+}
+
+const listAccountsWithUnpostedInterest = `-- name: ListAccountsWithUnpostedInterest :many
+SELECT DISTINCT account_id FROM interest_accruals
+WHERE posting_id = 0 AND accrual_date < $1
+ORDER BY account_id
+`
+
+func (q *Queries) ListAccountsWithUnpostedInterest(ctx context.Context, before pgtype.Date) ([]int64, error) {
+	rows, err := q.db.Query(ctx, listAccountsWithUnpostedInterest, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var account_id int64
+		if err := rows.Scan(&account_id); err != nil {
+			return nil, err
+		}
+		items = append(items, account_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInterestAccruals = `-- name: ListInterestAccruals :many
+SELECT account_id, accrual_date, balance, rate, amount_micros, posting_id, created_at FROM interest_accruals
+WHERE account_id = $1
+ORDER BY accrual_date
+`
+
+func (q *Queries) ListInterestAccruals(ctx context.Context, accountID int64) ([]InterestAccrual, error) {
+	rows, err := q.db.Query(ctx, listInterestAccruals, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []InterestAccrual{}
+	for rows.Next() {
+		var i InterestAccrual
+		if err := rows.Scan(
+			&i.AccountID,
+			&i.AccrualDate,
+			&i.Balance,
+			&i.Rate,
+			&i.AmountMicros,
+			&i.PostingID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInterestBearingAccounts = `-- name: ListInterestBearingAccounts :many
+SELECT
+    a.id,
+    a.type,
+    a.currency,
+    (a.balance - COALESCE(SUM(e.amount), 0))::bigint AS balance
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id AND e.created_at >= $1::timestamptz
+WHERE a.type = ANY($2::varchar[])
+    AND a.status <> 'closed'
+    AND a.created_at < $1::timestamptz
+    AND NOT EXISTS (SELECT 1 FROM system_accounts s WHERE s.account_id = a.id)
+GROUP BY a.id
+ORDER BY a.id
+`
+
+type ListInterestBearingAccountsParams struct {
+	DayEnd pgtype.Timestamptz `json:"day_end"`
+	Types  []string           `json:"types"`
+}
+
+type ListInterestBearingAccountsRow struct {
+	ID       int64  `json:"id"`
+	Type     string `json:"type"`
+	Currency string `json:"currency"`
+	Balance  int64  `json:"balance"`
+}
+
+// Lists the open customer accounts of the given types that existed at
+// day_end, with their balance at that time: entries made since are
+// subtracted.
+func (q *Queries) ListInterestBearingAccounts(ctx context.Context, arg ListInterestBearingAccountsParams) ([]ListInterestBearingAccountsRow, error) {
+	rows, err := q.db.Query(ctx, listInterestBearingAccounts, arg.DayEnd, arg.Types)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListInterestBearingAccountsRow{}
+	for rows.Next() {
+		var i ListInterestBearingAccountsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Currency,
+			&i.Balance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInterestPostings = `-- name: ListInterestPostings :many
+SELECT id, account_id, month, accrued_micros, amount, transfer_id, created_at FROM interest_postings
+WHERE account_id = $1
+ORDER BY month
+`
+
+func (q *Queries) ListInterestPostings(ctx context.Context, accountID int64) ([]InterestPosting, error) {
+	rows, err := q.db.Query(ctx, listInterestPostings, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []InterestPosting{}
+	for rows.Next() {
+		var i InterestPosting
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Month,
+			&i.AccruedMicros,
+			&i.Amount,
+			&i.TransferID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markInterestPosted = `-- name: MarkInterestPosted :execrows
+UPDATE interest_accruals
+SET posting_id = $1
+WHERE account_id = $2 AND posting_id = 0 AND accrual_date < $3
+`
+
+type MarkInterestPostedParams struct {
+	PostingID int64       `json:"posting_id"`
+	AccountID int64       `json:"account_id"`
+	Before    pgtype.Date `json:"before"`
+}
+
+func (q *Queries) MarkInterestPosted(ctx context.Context, arg MarkInterestPostedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markInterestPosted, arg.PostingID, arg.AccountID, arg.Before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setInterestPostingTransfer = `-- name: SetInterestPostingTransfer :one
+UPDATE interest_postings
+SET transfer_id = $2
+WHERE id = $1
+RETURNING id, account_id, month, accrued_micros, amount, transfer_id, created_at
+`
+
+type SetInterestPostingTransferParams struct {
+	ID         int64 `json:"id"`
+	TransferID int64 `json:"transfer_id"`
+}
+
+func (q *Queries) SetInterestPostingTransfer(ctx context.Context, arg SetInterestPostingTransferParams) (InterestPosting, error) {
+	row := q.db.QueryRow(ctx, setInterestPostingTransfer, arg.ID, arg.TransferID)
+	var i InterestPosting
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Month,
+		&i.AccruedMicros,
+		&i.Amount,
+		&i.TransferID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const sumUnpostedInterest = `-- name: SumUnpostedInterest :one
+SELECT COALESCE(SUM(amount_micros), 0)::bigint FROM interest_accruals
+WHERE account_id = $1 AND posting_id = 0 AND accrual_date < $2
+`
+
+type SumUnpostedInterestParams struct {
+	AccountID int64       `json:"account_id"`
+	Before    pgtype.Date `json:"before"`
+}
+
+func (q *Queries) SumUnpostedInterest(ctx context.Context, arg SumUnpostedInterestParams) (int64, error) {
+	row := q.db.QueryRow(ctx, sumUnpostedInterest, arg.AccountID, arg.Before)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
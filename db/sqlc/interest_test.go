@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shevgn/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAccountOfType(t *testing.T, accountType, currency string) Account {
+	user := createRandomVerifiedUser(t)
+
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  util.RandomBalance(),
+		Currency: currency,
+		Type:     accountType,
+	})
+	require.NoError(t, err)
+
+	return account
+}
+
+func accrue(t *testing.T, account Account, date time.Time, micros int64) int64 {
+	rows, err := testQueries.CreateInterestAccrual(context.Background(), CreateInterestAccrualParams{
+		AccountID:    account.ID,
+		AccrualDate:  pgtype.Date{Time: date, Valid: true},
+		Balance:      account.Balance,
+		Rate:         "0.02",
+		AmountMicros: micros,
+	})
+	require.NoError(t, err)
+
+	return rows
+}
+
+func TestCreateInterestAccrualOncePerDate(t *testing.T) {
+	account := createRandomAccountOfType(t, AccountTypeSavings, util.USD)
+	date := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	require.Equal(t, int64(1), accrue(t, account, date, 500_000))
+	require.Equal(t, int64(0), accrue(t, account, date, 900_000))
+
+	accruals, err := testQueries.ListInterestAccruals(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, accruals, 1)
+	require.Equal(t, int64(500_000), accruals[0].AmountMicros)
+}
+
+func TestListInterestBearingAccounts(t *testing.T) {
+	account := createRandomAccountOfType(t, AccountTypeSavings, util.USD)
+	other := createRandomAccount(t)
+
+	dayEnd := time.Now().Add(time.Minute)
+
+	rows, err := testQueries.ListInterestBearingAccounts(context.Background(), ListInterestBearingAccountsParams{
+		DayEnd: pgtype.Timestamptz{Time: dayEnd, Valid: true},
+		Types:  []string{AccountTypeSavings},
+	})
+	require.NoError(t, err)
+
+	ids := make(map[int64]int64)
+	for _, row := range rows {
+		ids[row.ID] = row.Balance
+	}
+
+	require.Contains(t, ids, account.ID)
+	require.Equal(t, account.Balance, ids[account.ID])
+	require.NotContains(t, ids, other.ID)
+
+	// Accounts created after the end of the day are left out.
+	rows, err = testQueries.ListInterestBearingAccounts(context.Background(), ListInterestBearingAccountsParams{
+		DayEnd: pgtype.Timestamptz{Time: account.CreatedAt.Time.Add(-time.Second), Valid: true},
+		Types:  []string{AccountTypeSavings},
+	})
+	require.NoError(t, err)
+
+	for _, row := range rows {
+		require.NotEqual(t, account.ID, row.ID)
+	}
+}
+
+func TestPostInterestTx(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+
+	account := createRandomAccountOfType(t, AccountTypeSavings, util.USD)
+
+	bank, err := store.GetSystemAccount(ctx, GetSystemAccountParams{
+		Code:     LedgerInterestExpense,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
+
+	january := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	february := january.AddDate(0, 1, 0)
+
+	// 1.2 units in January and 0.9 in February: 1 is paid each month, as
+	// the 0.2 left over in January completes a unit in February.
+	accrue(t, account, january, 600_000)
+	accrue(t, account, january.AddDate(0, 0, 30), 600_000)
+	accrue(t, account, february, 900_000)
+
+	posting, err := store.PostInterestTx(ctx, PostInterestTxParams{AccountID: account.ID, Month: january})
+	require.NoError(t, err)
+	require.Equal(t, int64(1_200_000), posting.AccruedMicros)
+	require.Equal(t, int64(1), posting.Amount)
+	require.NotZero(t, posting.TransferID)
+
+	transfer, err := store.GetTransfer(ctx, posting.TransferID)
+	require.NoError(t, err)
+	require.Equal(t, bank.ID, transfer.FromAccountID)
+	require.Equal(t, account.ID, transfer.ToAccountID)
+
+	_, err = store.PostInterestTx(ctx, PostInterestTxParams{AccountID: account.ID, Month: january})
+	require.ErrorIs(t, err, ErrInterestAlreadyPosted)
+
+	posting, err = store.PostInterestTx(ctx, PostInterestTxParams{AccountID: account.ID, Month: february})
+	require.NoError(t, err)
+	require.Equal(t, int64(900_000), posting.AccruedMicros)
+	require.Equal(t, int64(1), posting.Amount)
+
+	updated, err := store.GetAccount(ctx, account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+2, updated.Balance)
+
+	accruals, err := store.ListInterestAccruals(ctx, account.ID)
+	require.NoError(t, err)
+	for _, accrual := range accruals {
+		require.NotZero(t, accrual.PostingID)
+	}
+
+	// Nothing accrued in March: the posting is recorded but pays nothing.
+	posting, err = store.PostInterestTx(ctx, PostInterestTxParams{AccountID: account.ID, Month: february.AddDate(0, 1, 0)})
+	require.NoError(t, err)
+	require.Zero(t, posting.Amount)
+	require.Zero(t, posting.TransferID)
+}
@@ -0,0 +1,300 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: job.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimJobs = `-- name: ClaimJobs :many
+UPDATE jobs
+SET
+    status = 'running',
+    attempts = attempts + 1,
+    locked_at = now(),
+    updated_at = now()
+WHERE id IN (
+    SELECT id FROM jobs
+    WHERE status = 'pending' AND run_at <= now()
+    ORDER BY run_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, type, payload, status, attempts, max_attempts, last_error, run_at, locked_at, created_at, updated_at, key
+`
+
+func (q *Queries) ClaimJobs(ctx context.Context, maxJobs int32) ([]Job, error) {
+	rows, err := q.db.Query(ctx, claimJobs, maxJobs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.MaxAttempts,
+			&i.LastError,
+			&i.RunAt,
+			&i.LockedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Key,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const completeJob = `-- name: CompleteJob :exec
+UPDATE jobs
+SET
+    status = 'done',
+    last_error = '',
+    updated_at = now()
+WHERE id = $1
+`
+
+func (q *Queries) CompleteJob(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, completeJob, id)
+	return err
+}
+
+const deleteDoneJobs = `-- name: DeleteDoneJobs :execrows
+DELETE FROM jobs
+WHERE status = 'done' AND updated_at < $1
+`
+
+func (q *Queries) DeleteDoneJobs(ctx context.Context, updatedBefore pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteDoneJobs, updatedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const enqueueJob = `-- name: EnqueueJob :one
+INSERT INTO jobs (
+    type,
+    payload,
+    max_attempts,
+    run_at,
+    key
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (key) WHERE key <> '' DO NOTHING
+RETURNING id, type, payload, status, attempts, max_attempts, last_error, run_at, locked_at, created_at, updated_at, key
+`
+
+type EnqueueJobParams struct {
+	Type        string             `json:"type"`
+	Payload     []byte             `json:"payload"`
+	MaxAttempts int32              `json:"max_attempts"`
+	RunAt       pgtype.Timestamptz `json:"run_at"`
+	Key         string             `json:"key"`
+}
+
+// A job with a key that is already taken is not inserted, and no row is
+// returned.
+func (q *Queries) EnqueueJob(ctx context.Context, arg EnqueueJobParams) (Job, error) {
+	row := q.db.QueryRow(ctx, enqueueJob,
+		arg.Type,
+		arg.Payload,
+		arg.MaxAttempts,
+		arg.RunAt,
+		arg.Key,
+	)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.LastError,
+		&i.RunAt,
+		&i.LockedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Key,
+	)
+	return i, err
+}
+
+const getJob = `-- name: GetJob :one
+SELECT id, type, payload, status, attempts, max_attempts, last_error, run_at, locked_at, created_at, updated_at, key FROM jobs
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetJob(ctx context.Context, id int64) (Job, error) {
+	row := q.db.QueryRow(ctx, getJob, id)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.LastError,
+		&i.RunAt,
+		&i.LockedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Key,
+	)
+	return i, err
+}
+
+const killJob = `-- name: KillJob :exec
+UPDATE jobs
+SET
+    status = 'dead',
+    last_error = $2,
+    updated_at = now()
+WHERE id = $1
+`
+
+type KillJobParams struct {
+	ID        int64  `json:"id"`
+	LastError string `json:"last_error"`
+}
+
+func (q *Queries) KillJob(ctx context.Context, arg KillJobParams) error {
+	_, err := q.db.Exec(ctx, killJob, arg.ID, arg.LastError)
+	return err
+}
+
+const listJobs = `-- name: ListJobs :many
+SELECT id, type, payload, status, attempts, max_attempts, last_error, run_at, locked_at, created_at, updated_at, key FROM jobs
+WHERE status = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3
+`
+
+type ListJobsParams struct {
+	Status string `json:"status"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+func (q *Queries) ListJobs(ctx context.Context, arg ListJobsParams) ([]Job, error) {
+	rows, err := q.db.Query(ctx, listJobs, arg.Status, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.MaxAttempts,
+			&i.LastError,
+			&i.RunAt,
+			&i.LockedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Key,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const requeueStuckJobs = `-- name: RequeueStuckJobs :execrows
+UPDATE jobs
+SET
+    status = CASE WHEN attempts >= max_attempts THEN 'dead' ELSE 'pending' END,
+    last_error = 'processor stopped while running the job',
+    run_at = now(),
+    updated_at = now()
+WHERE status = 'running' AND locked_at < $1
+`
+
+func (q *Queries) RequeueStuckJobs(ctx context.Context, lockedBefore pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, requeueStuckJobs, lockedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const retryJob = `-- name: RetryJob :exec
+UPDATE jobs
+SET
+    status = 'pending',
+    last_error = $2,
+    run_at = $3,
+    updated_at = now()
+WHERE id = $1
+`
+
+type RetryJobParams struct {
+	ID        int64              `json:"id"`
+	LastError string             `json:"last_error"`
+	RunAt     pgtype.Timestamptz `json:"run_at"`
+}
+
+func (q *Queries) RetryJob(ctx context.Context, arg RetryJobParams) error {
+	_, err := q.db.Exec(ctx, retryJob, arg.ID, arg.LastError, arg.RunAt)
+	return err
+}
+
+const reviveJob = `-- name: ReviveJob :one
+UPDATE jobs
+SET
+    status = 'pending',
+    attempts = 0,
+    run_at = now(),
+    updated_at = now()
+WHERE id = $1 AND status = 'dead'
+RETURNING id, type, payload, status, attempts, max_attempts, last_error, run_at, locked_at, created_at, updated_at, key
+`
+
+func (q *Queries) ReviveJob(ctx context.Context, id int64) (Job, error) {
+	row := q.db.QueryRow(ctx, reviveJob, id)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.LastError,
+		&i.RunAt,
+		&i.LockedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Key,
+	)
+	return i, err
+}
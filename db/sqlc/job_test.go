@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shevgn/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomJob(t *testing.T, runAt time.Time) Job {
+	arg := EnqueueJobParams{
+		Type:        util.RandomString(8),
+		Payload:     []byte(`{"username":"` + util.RandomOwner() + `"}`),
+		MaxAttempts: 2,
+		RunAt:       pgtype.Timestamptz{Time: runAt, Valid: true},
+	}
+
+	job, err := testQueries.EnqueueJob(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Type, job.Type)
+	require.JSONEq(t, string(arg.Payload), string(job.Payload))
+	require.Equal(t, JobStatusPending, job.Status)
+	require.Zero(t, job.Attempts)
+	require.Equal(t, arg.MaxAttempts, job.MaxAttempts)
+
+	return job
+}
+
+// claimJob claims due jobs until it gets job. Other tests may leave due jobs
+// behind, which are marked done so they do not pile up.
+func claimJob(t *testing.T, job Job) (Job, bool) {
+	for {
+		jobs, err := testQueries.ClaimJobs(context.Background(), 10)
+		require.NoError(t, err)
+
+		found, ok := Job{}, false
+		for _, claimed := range jobs {
+			if claimed.ID == job.ID {
+				found, ok = claimed, true
+				continue
+			}
+			require.NoError(t, testQueries.CompleteJob(context.Background(), claimed.ID))
+		}
+
+		if ok || len(jobs) == 0 {
+			return found, ok
+		}
+	}
+}
+
+func TestClaimJobs(t *testing.T) {
+	future := createRandomJob(t, time.Now().Add(time.Hour))
+	due := createRandomJob(t, time.Now().Add(-time.Second))
+
+	claimed, ok := claimJob(t, due)
+	require.True(t, ok)
+	require.Equal(t, JobStatusRunning, claimed.Status)
+	require.Equal(t, int32(1), claimed.Attempts)
+	require.WithinDuration(t, time.Now(), claimed.LockedAt.Time, time.Second)
+
+	// Running jobs and jobs that are not due yet are not claimed.
+	_, ok = claimJob(t, due)
+	require.False(t, ok)
+	_, ok = claimJob(t, future)
+	require.False(t, ok)
+}
+
+func TestRetryAndKillJob(t *testing.T) {
+	job := createRandomJob(t, time.Now())
+
+	_, ok := claimJob(t, job)
+	require.True(t, ok)
+
+	err := testQueries.RetryJob(context.Background(), RetryJobParams{
+		ID:        job.ID,
+		LastError: "failed",
+		RunAt:     pgtype.Timestamptz{Time: time.Now().Add(-time.Second), Valid: true},
+	})
+	require.NoError(t, err)
+
+	retried, ok := claimJob(t, job)
+	require.True(t, ok)
+	require.Equal(t, int32(2), retried.Attempts)
+	require.Equal(t, "failed", retried.LastError)
+
+	err = testQueries.KillJob(context.Background(), KillJobParams{ID: job.ID, LastError: "failed again"})
+	require.NoError(t, err)
+
+	dead, err := testQueries.GetJob(context.Background(), job.ID)
+	require.NoError(t, err)
+	require.Equal(t, JobStatusDead, dead.Status)
+	require.Equal(t, "failed again", dead.LastError)
+
+	revived, err := testQueries.ReviveJob(context.Background(), job.ID)
+	require.NoError(t, err)
+	require.Equal(t, JobStatusPending, revived.Status)
+	require.Zero(t, revived.Attempts)
+
+	// Only dead jobs can be revived.
+	_, err = testQueries.ReviveJob(context.Background(), job.ID)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestRequeueStuckJobs(t *testing.T) {
+	job := createRandomJob(t, time.Now())
+
+	_, ok := claimJob(t, job)
+	require.True(t, ok)
+
+	// A job locked just now is not stuck yet.
+	_, err := testQueries.RequeueStuckJobs(context.Background(),
+		pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true})
+	require.NoError(t, err)
+
+	running, err := testQueries.GetJob(context.Background(), job.ID)
+	require.NoError(t, err)
+	require.Equal(t, JobStatusRunning, running.Status)
+
+	requeued, err := testQueries.RequeueStuckJobs(context.Background(),
+		pgtype.Timestamptz{Time: time.Now().Add(time.Minute), Valid: true})
+	require.NoError(t, err)
+	require.Positive(t, requeued)
+
+	pending, err := testQueries.GetJob(context.Background(), job.ID)
+	require.NoError(t, err)
+	require.Equal(t, JobStatusPending, pending.Status)
+
+	// Out of attempts after the second claim, so the job is marked dead.
+	_, ok = claimJob(t, job)
+	require.True(t, ok)
+
+	_, err = testQueries.RequeueStuckJobs(context.Background(),
+		pgtype.Timestamptz{Time: time.Now().Add(time.Minute), Valid: true})
+	require.NoError(t, err)
+
+	dead, err := testQueries.GetJob(context.Background(), job.ID)
+	require.NoError(t, err)
+	require.Equal(t, JobStatusDead, dead.Status)
+}
@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// BankUsername owns the system accounts, the accounts the bank books its
+// own assets, income and expenses to. It is not a customer: it cannot log
+// in or be registered through the API.
+const BankUsername = "_bank"
+
+// Codes of the ledger accounts in the chart of accounts that the bank
+// books to
+const (
+	LedgerCash             = "1100"
+	LedgerCustomerDeposits = "2100"
+	LedgerOpeningBalances  = "3100"
+	LedgerFeeRevenue       = "4100"
+	LedgerFXGains          = "4200"
+	LedgerInterestExpense  = "5100"
+)
+
+// Ledger account types stored in ledger_accounts.type
+const (
+	LedgerTypeAsset     = "asset"
+	LedgerTypeLiability = "liability"
+	LedgerTypeEquity    = "equity"
+	LedgerTypeIncome    = "income"
+	LedgerTypeExpense   = "expense"
+)
+
+// LedgerTypes are the types of ledger accounts, in chart order
+var LedgerTypes = []string{LedgerTypeAsset, LedgerTypeLiability, LedgerTypeEquity, LedgerTypeIncome, LedgerTypeExpense}
+
+var (
+	// ErrNotPostingAccount is returned by CreateSystemAccountTx for a ledger
+	// account that groups others or holds the customers' accounts
+	ErrNotPostingAccount = errors.New("ledger account cannot hold system accounts")
+	// ErrSystemAccountExists is returned by CreateSystemAccountTx when the
+	// ledger account already has a system account in the currency
+	ErrSystemAccountExists = errors.New("system account already exists")
+	// ErrSystemAccount is returned when a customer names one of the bank's
+	// system accounts as the target of a transfer
+	ErrSystemAccount = errors.New("system accounts cannot receive transfers")
+)
+
+// CreateSystemAccountTxParams is a set of parameters for
+// CreateSystemAccountTx
+type CreateSystemAccountTxParams struct {
+	Code     string `json:"code"`
+	Currency string `json:"currency"`
+}
+
+// CreateSystemAccountTx opens an account owned by BankUsername and books it
+// to a ledger account of the chart. Top level groups and customer deposits
+// cannot hold system accounts, and each ledger account holds at most one
+// per currency.
+func (s *SQLStore) CreateSystemAccountTx(ctx context.Context, args CreateSystemAccountTxParams) (Account, error) {
+	var account Account
+
+	err := s.execTx(ctx, "CreateSystemAccountTx", func(ctx context.Context, q *Queries) error {
+		ledger, err := q.GetLedgerAccount(ctx, args.Code)
+		if err != nil {
+			return err
+		}
+
+		if ledger.ParentCode == "" || ledger.Code == LedgerCustomerDeposits {
+			return fmt.Errorf("%s %s: %w", ledger.Code, ledger.Name, ErrNotPostingAccount)
+		}
+
+		account, err = q.CreateAccount(ctx, CreateAccountParams{
+			Owner:    BankUsername,
+			Currency: args.Currency,
+			Type:     AccountTypeBusiness,
+			Nickname: ledger.Name + " " + args.Currency,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateSystemAccount(ctx, CreateSystemAccountParams{
+			Code:      ledger.Code,
+			Currency:  args.Currency,
+			AccountID: account.ID,
+		})
+		if ErrorCode(err) == UniqueViolation {
+			return fmt.Errorf("%s %s in %s: %w", ledger.Code, ledger.Name, args.Currency, ErrSystemAccountExists)
+		}
+
+		return err
+	})
+
+	return account, err
+}
+
+// PostLedgerTxParams is a set of parameters for PostLedgerTx
+type PostLedgerTxParams struct {
+	Code      string `json:"code"`
+	AccountID int64  `json:"account_id"`
+	Amount    int64  `json:"amount"`
+}
+
+// PostLedgerTx books money that reaches a customer's account from outside
+// the ledger, such as a cash deposit or a balance carried over from another
+// system, as a transfer from the system account of args.Code in the
+// account's currency. The system account's balance goes down by as much as
+// the customer's goes up, so the ledger stays balanced.
+func (s *SQLStore) PostLedgerTx(ctx context.Context, args PostLedgerTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := s.execTx(ctx, "PostLedgerTx", func(ctx context.Context, q *Queries) error {
+		account, err := q.GetAccount(ctx, args.AccountID)
+		if err != nil {
+			return err
+		}
+
+		isSystem, err := q.IsSystemAccount(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+
+		if isSystem {
+			return fmt.Errorf("account %d: %w", account.ID, ErrSystemAccount)
+		}
+
+		system, err := q.GetSystemAccount(ctx, GetSystemAccountParams{
+			Code:     args.Code,
+			Currency: account.Currency,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot get system account %s for %s: %w", args.Code, account.Currency, err)
+		}
+
+		result, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: system.ID,
+			ToAccountID:   account.ID,
+			Amount:        args.Amount,
+		})
+
+		return err
+	})
+
+	return result, err
+}
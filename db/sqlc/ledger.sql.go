@@ -0,0 +1,271 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: ledger.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createLedgerAccount = `-- name: CreateLedgerAccount :one
+INSERT INTO ledger_accounts (
+    code, name, type, parent_code
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING code, name, type, parent_code, created_at
+`
+
+type CreateLedgerAccountParams struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	ParentCode string `json:"parent_code"`
+}
+
+func (q *Queries) CreateLedgerAccount(ctx context.Context, arg CreateLedgerAccountParams) (LedgerAccount, error) {
+	row := q.db.QueryRow(ctx, createLedgerAccount,
+		arg.Code,
+		arg.Name,
+		arg.Type,
+		arg.ParentCode,
+	)
+	var i LedgerAccount
+	err := row.Scan(
+		&i.Code,
+		&i.Name,
+		&i.Type,
+		&i.ParentCode,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createSystemAccount = `-- name: CreateSystemAccount :one
+INSERT INTO system_accounts (
+    code, currency, account_id
+) VALUES (
+    $1, $2, $3
+)
+RETURNING code, currency, account_id
+`
+
+type CreateSystemAccountParams struct {
+	Code      string `json:"code"`
+	Currency  string `json:"currency"`
+	AccountID int64  `json:"account_id"`
+}
+
+func (q *Queries) CreateSystemAccount(ctx context.Context, arg CreateSystemAccountParams) (SystemAccount, error) {
+	row := q.db.QueryRow(ctx, createSystemAccount, arg.Code, arg.Currency, arg.AccountID)
+	var i SystemAccount
+	err := row.Scan(&i.Code, &i.Currency, &i.AccountID)
+	return i, err
+}
+
+const getLedgerAccount = `-- name: GetLedgerAccount :one
+SELECT code, name, type, parent_code, created_at FROM ledger_accounts
+WHERE code = $1 LIMIT 1
+`
+
+func (q *Queries) GetLedgerAccount(ctx context.Context, code string) (LedgerAccount, error) {
+	row := q.db.QueryRow(ctx, getLedgerAccount, code)
+	var i LedgerAccount
+	err := row.Scan(
+		&i.Code,
+		&i.Name,
+		&i.Type,
+		&i.ParentCode,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getSystemAccount = `-- name: GetSystemAccount :one
+SELECT a.id, a.owner, a.balance, a.currency, a.created_at, a.status, a.closed_at, a.type, a.nickname FROM accounts a
+JOIN system_accounts s ON s.account_id = a.id
+WHERE s.code = $1 AND s.currency = $2
+`
+
+type GetSystemAccountParams struct {
+	Code     string `json:"code"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) GetSystemAccount(ctx context.Context, arg GetSystemAccountParams) (Account, error) {
+	row := q.db.QueryRow(ctx, getSystemAccount, arg.Code, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Status,
+		&i.ClosedAt,
+		&i.Type,
+		&i.Nickname,
+	)
+	return i, err
+}
+
+const isSystemAccount = `-- name: IsSystemAccount :one
+SELECT EXISTS (
+    SELECT 1 FROM system_accounts WHERE account_id = $1
+)
+`
+
+func (q *Queries) IsSystemAccount(ctx context.Context, accountID int64) (bool, error) {
+	row := q.db.QueryRow(ctx, isSystemAccount, accountID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listLedgerAccounts = `-- name: ListLedgerAccounts :many
+SELECT code, name, type, parent_code, created_at FROM ledger_accounts
+ORDER BY code
+`
+
+func (q *Queries) ListLedgerAccounts(ctx context.Context) ([]LedgerAccount, error) {
+	rows, err := q.db.Query(ctx, listLedgerAccounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []LedgerAccount{}
+	for rows.Next() {
+		var i LedgerAccount
+		if err := rows.Scan(
+			&i.Code,
+			&i.Name,
+			&i.Type,
+			&i.ParentCode,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSystemAccounts = `-- name: ListSystemAccounts :many
+SELECT
+    s.code,
+    l.name AS ledger_name,
+    a.id,
+    a.currency,
+    a.balance
+FROM system_accounts s
+JOIN ledger_accounts l ON l.code = s.code
+JOIN accounts a ON a.id = s.account_id
+ORDER BY s.code, a.currency
+`
+
+type ListSystemAccountsRow struct {
+	Code       string `json:"code"`
+	LedgerName string `json:"ledger_name"`
+	ID         int64  `json:"id"`
+	Currency   string `json:"currency"`
+	Balance    int64  `json:"balance"`
+}
+
+func (q *Queries) ListSystemAccounts(ctx context.Context) ([]ListSystemAccountsRow, error) {
+	rows, err := q.db.Query(ctx, listSystemAccounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSystemAccountsRow{}
+	for rows.Next() {
+		var i ListSystemAccountsRow
+		if err := rows.Scan(
+			&i.Code,
+			&i.LedgerName,
+			&i.ID,
+			&i.Currency,
+			&i.Balance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTrialBalance = `-- name: ListTrialBalance :many
+SELECT
+    l.code,
+    l.name,
+    l.type,
+    a.currency,
+    COUNT(*)::bigint AS accounts,
+    COALESCE(SUM(e.amount), 0)::bigint AS balance,
+    SUM(a.balance)::bigint AS account_balance,
+    COUNT(*) FILTER (WHERE a.balance <> COALESCE(e.amount, 0))::bigint AS mismatched
+FROM accounts a
+LEFT JOIN (
+    SELECT account_id, SUM(amount) AS amount
+    FROM entries
+    GROUP BY account_id
+) e ON e.account_id = a.id
+LEFT JOIN system_accounts s ON s.account_id = a.id
+JOIN ledger_accounts l ON l.code = COALESCE(s.code, $1::varchar)
+GROUP BY a.currency, l.code, l.name, l.type
+ORDER BY a.currency, l.code
+`
+
+type ListTrialBalanceRow struct {
+	Code           string `json:"code"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	Currency       string `json:"currency"`
+	Accounts       int64  `json:"accounts"`
+	Balance        int64  `json:"balance"`
+	AccountBalance int64  `json:"account_balance"`
+	Mismatched     int64  `json:"mismatched"`
+}
+
+// The balance of every ledger account per currency, summed from the entries
+// of its accounts. Customer accounts are booked to customer_code, every
+// other account to its system account's code. Balances are credits when
+// positive and debits when negative. account_balance sums the balances
+// stored on the accounts instead, and mismatched counts the accounts whose
+// entries do not add up to their balance.
+func (q *Queries) ListTrialBalance(ctx context.Context, customerCode string) ([]ListTrialBalanceRow, error) {
+	rows, err := q.db.Query(ctx, listTrialBalance, customerCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTrialBalanceRow{}
+	for rows.Next() {
+		var i ListTrialBalanceRow
+		if err := rows.Scan(
+			&i.Code,
+			&i.Name,
+			&i.Type,
+			&i.Currency,
+			&i.Accounts,
+			&i.Balance,
+			&i.AccountBalance,
+			&i.Mismatched,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
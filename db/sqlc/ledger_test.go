@@ -0,0 +1,133 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shevgn/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemAccounts(t *testing.T) {
+	for _, code := range []string{LedgerCash, LedgerOpeningBalances, LedgerFeeRevenue, LedgerFXGains, LedgerInterestExpense} {
+		for _, currency := range util.SupportedCurrencies() {
+			account, err := testQueries.GetSystemAccount(context.Background(), GetSystemAccountParams{
+				Code:     code,
+				Currency: currency,
+			})
+			require.NoError(t, err, code)
+			require.Equal(t, BankUsername, account.Owner)
+			require.Equal(t, currency, account.Currency)
+
+			isSystem, err := testQueries.IsSystemAccount(context.Background(), account.ID)
+			require.NoError(t, err)
+			require.True(t, isSystem)
+		}
+	}
+
+	isSystem, err := testQueries.IsSystemAccount(context.Background(), createRandomAccount(t).ID)
+	require.NoError(t, err)
+	require.False(t, isSystem)
+}
+
+func TestCreateSystemAccountTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	for code, wantErr := range map[string]error{
+		"1000":                 ErrNotPostingAccount,
+		LedgerCustomerDeposits: ErrNotPostingAccount,
+		LedgerCash:             ErrSystemAccountExists,
+		"9999":                 ErrRecordNotFound,
+	} {
+		_, err := store.CreateSystemAccountTx(context.Background(), CreateSystemAccountTxParams{
+			Code:     code,
+			Currency: util.USD,
+		})
+		require.ErrorIs(t, err, wantErr, code)
+	}
+}
+
+func TestPostLedgerTx(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+
+	customer := createRandomAccountOfType(t, AccountTypeChecking, util.EUR)
+
+	opening, err := store.GetSystemAccount(ctx, GetSystemAccountParams{Code: LedgerOpeningBalances, Currency: util.EUR})
+	require.NoError(t, err)
+
+	result, err := store.PostLedgerTx(ctx, PostLedgerTxParams{Code: LedgerOpeningBalances, AccountID: customer.ID, Amount: 250})
+	require.NoError(t, err)
+	require.Equal(t, opening.ID, result.Transfer.FromAccountID)
+	require.Equal(t, customer.ID, result.Transfer.ToAccountID)
+	require.Equal(t, opening.Balance-250, result.FromAccount.Balance)
+	require.Equal(t, customer.Balance+250, result.ToAccount.Balance)
+
+	_, err = store.PostLedgerTx(ctx, PostLedgerTxParams{Code: LedgerCash, AccountID: opening.ID, Amount: 1})
+	require.ErrorIs(t, err, ErrSystemAccount)
+
+	_, err = store.PostLedgerTx(ctx, PostLedgerTxParams{Code: LedgerCustomerDeposits, AccountID: customer.ID, Amount: 1})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+type trialBalanceLine struct {
+	Balance    int64
+	Mismatched int64
+}
+
+func trialBalance(t *testing.T) map[string]map[string]trialBalanceLine {
+	rows, err := testQueries.ListTrialBalance(context.Background(), LedgerCustomerDeposits)
+	require.NoError(t, err)
+
+	balances := make(map[string]map[string]trialBalanceLine)
+	for _, row := range rows {
+		if balances[row.Currency] == nil {
+			balances[row.Currency] = make(map[string]trialBalanceLine)
+		}
+		balances[row.Currency][row.Code] = trialBalanceLine{Balance: row.Balance, Mismatched: row.Mismatched}
+	}
+
+	return balances
+}
+
+func TestListTrialBalance(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+
+	// Opened empty, the account matches its entries.
+	customer, err := store.CreateAccount(ctx, CreateAccountParams{
+		Owner:    createRandomVerifiedUser(t).Username,
+		Currency: util.USD,
+		Type:     AccountTypeChecking,
+	})
+	require.NoError(t, err)
+
+	before := trialBalance(t)
+
+	// A cash deposit moves money from cash to customer deposits.
+	_, err = store.PostLedgerTx(ctx, PostLedgerTxParams{Code: LedgerCash, AccountID: customer.ID, Amount: 500})
+	require.NoError(t, err)
+
+	after := trialBalance(t)
+	require.Equal(t, before[util.USD][LedgerCash].Balance-500, after[util.USD][LedgerCash].Balance)
+	require.Equal(t, before[util.USD][LedgerCustomerDeposits].Balance+500, after[util.USD][LedgerCustomerDeposits].Balance)
+
+	var total int64
+	for _, line := range before[util.USD] {
+		total += line.Balance
+	}
+	for _, line := range after[util.USD] {
+		total -= line.Balance
+	}
+	require.Zero(t, total)
+	require.Equal(t, before[util.USD][LedgerCustomerDeposits].Mismatched, after[util.USD][LedgerCustomerDeposits].Mismatched)
+
+	// Money put on an account without entries is not in the trial balance,
+	// but the account no longer matches its entries.
+	_, err = store.AddAccountBalance(ctx, AddAccountBalanceParams{ID: customer.ID, Amount: 1})
+	require.NoError(t, err)
+
+	unbooked := trialBalance(t)
+	require.Equal(t, after[util.USD][LedgerCustomerDeposits].Balance, unbooked[util.USD][LedgerCustomerDeposits].Balance)
+	require.Equal(t, after[util.USD][LedgerCustomerDeposits].Mismatched+1, unbooked[util.USD][LedgerCustomerDeposits].Mismatched)
+}
@@ -15,6 +15,12 @@ type Account struct {
 	Balance   int64            `json:"balance"`
 	Currency  string           `json:"currency"`
 	CreatedAt pgtype.Timestamp `json:"created_at"`
+	Status    string           `json:"status"`
+	// When the account was closed, zero unless its status is closed
+	ClosedAt pgtype.Timestamptz `json:"closed_at"`
+	Type     string             `json:"type"`
+	// Name the owner gave the account, empty if none
+	Nickname string `json:"nickname"`
 }
 
 type Entry struct {
@@ -22,6 +28,135 @@ type Entry struct {
 	AccountID int64            `json:"account_id"`
 	Amount    int64            `json:"amount"`
 	CreatedAt pgtype.Timestamp `json:"created_at"`
+	// Transfer that created the entry, 0 for other entries
+	TransferID int64 `json:"transfer_id"`
+}
+
+type Fee struct {
+	ID        int64  `json:"id"`
+	AccountID int64  `json:"account_id"`
+	Event     string `json:"event"`
+	// Rule that set the fee; rules may since have been removed
+	RuleID int64 `json:"rule_id"`
+	Amount int64 `json:"amount"`
+	// Transfer that booked the fee to the bank, 0 if the amount was 0
+	TransferID int64 `json:"transfer_id"`
+	// Transfer the fee was charged for, 0 for maintenance fees
+	ChargedTransferID int64 `json:"charged_transfer_id"`
+	// First day of the month a maintenance fee was charged for
+	Month     pgtype.Date        `json:"month"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type FeeRule struct {
+	ID int64 `json:"id"`
+	// What the fee is charged for: transfer or maintenance
+	Event string `json:"event"`
+	// Currency the rule applies to, empty for any
+	Currency string `json:"currency"`
+	// Type of the charged account the rule applies to, empty for any
+	AccountType string `json:"account_type"`
+	// Smallest transfer amount, or balance for maintenance, the rule applies to; rules with different minimums form tiers
+	MinAmount int64 `json:"min_amount"`
+	// Fixed part of the fee, in minor units
+	Flat int64 `json:"flat"`
+	// Part of the fee proportional to the amount, as a decimal fraction such as 0.005
+	Rate string `json:"rate"`
+	// Smallest fee the rule charges
+	MinFee int64 `json:"min_fee"`
+	// Largest fee the rule charges, 0 for no limit
+	MaxFee      int64              `json:"max_fee"`
+	Description string             `json:"description"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type InterestAccrual struct {
+	AccountID   int64       `json:"account_id"`
+	AccrualDate pgtype.Date `json:"accrual_date"`
+	// Balance at the end of the accrual date
+	Balance int64 `json:"balance"`
+	// Annual rate as a decimal fraction, such as 0.035
+	Rate string `json:"rate"`
+	// Interest for the day in millionths of the minor unit, rounded down
+	AmountMicros int64 `json:"amount_micros"`
+	// Posting that paid the accrual, 0 until it is paid
+	PostingID int64              `json:"posting_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type InterestPosting struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// First day of the month the posting pays
+	Month pgtype.Date `json:"month"`
+	// Sum of the accruals the posting paid
+	AccruedMicros int64 `json:"accrued_micros"`
+	// Amount paid, in minor units; fractions of a unit carry over to the next posting
+	Amount int64 `json:"amount"`
+	// Transfer that paid the interest, 0 if the amount was 0
+	TransferID int64              `json:"transfer_id"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type Job struct {
+	ID      int64  `json:"id"`
+	Type    string `json:"type"`
+	Payload []byte `json:"payload"`
+	Status  string `json:"status"`
+	// Number of times the job has been claimed, including the current run
+	Attempts    int32              `json:"attempts"`
+	MaxAttempts int32              `json:"max_attempts"`
+	LastError   string             `json:"last_error"`
+	RunAt       pgtype.Timestamptz `json:"run_at"`
+	// When a processor claimed the job; running jobs locked for too long are requeued
+	LockedAt  pgtype.Timestamptz `json:"locked_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	// Names a scheduled run, such as a day of interest; a job is not enqueued while another with its key exists
+	Key string `json:"key"`
+}
+
+type LedgerAccount struct {
+	// General ledger code; the first digit is the type, 1 for assets to 5 for expenses
+	Code string `json:"code"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Code of the group the account belongs to, empty for a top level group
+	ParentCode string             `json:"parent_code"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+// Domain events written in the transaction that caused them and relayed to sinks in id order
+type Outbox struct {
+	ID            int64  `json:"id"`
+	AggregateType string `json:"aggregate_type"`
+	AggregateID   int64  `json:"aggregate_id"`
+	EventType     string `json:"event_type"`
+	Payload       []byte `json:"payload"`
+	IsPublished   bool   `json:"is_published"`
+	// Number of failed publish attempts
+	Attempts    int32              `json:"attempts"`
+	LastError   string             `json:"last_error"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	PublishedAt pgtype.Timestamptz `json:"published_at"`
+}
+
+type PasswordResetToken struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// SHA-256 of the token mailed to the user; the token itself is never stored
+	TokenHash string             `json:"token_hash"`
+	IsUsed    bool               `json:"is_used"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+}
+
+type RateLimit struct {
+	Key    string  `json:"key"`
+	Tokens float64 `json:"tokens"`
+	// Whether the last request was allowed
+	Allowed   bool               `json:"allowed"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
 type Session struct {
@@ -35,6 +170,13 @@ type Session struct {
 	ExpiresAt    pgtype.Timestamptz `json:"expires_at"`
 }
 
+type SystemAccount struct {
+	// Ledger account the bank books to the account
+	Code      string `json:"code"`
+	Currency  string `json:"currency"`
+	AccountID int64  `json:"account_id"`
+}
+
 type Transfer struct {
 	ID            int64 `json:"id"`
 	ToAccountID   int64 `json:"to_account_id"`
@@ -45,10 +187,52 @@ type Transfer struct {
 }
 
 type User struct {
-	Username          string             `json:"username"`
-	HashedPassword    string             `json:"hashed_password"`
-	FullName          string             `json:"full_name"`
-	Email             string             `json:"email"`
-	PasswordChangedAt pgtype.Timestamptz `json:"password_changed_at"`
-	CreatedAt         pgtype.Timestamp   `json:"created_at"`
+	Username            string             `json:"username"`
+	HashedPassword      string             `json:"hashed_password"`
+	FullName            string             `json:"full_name"`
+	Email               string             `json:"email"`
+	PasswordChangedAt   pgtype.Timestamptz `json:"password_changed_at"`
+	CreatedAt           pgtype.Timestamp   `json:"created_at"`
+	IsDisabled          bool               `json:"is_disabled"`
+	FailedLoginAttempts int32              `json:"failed_login_attempts"`
+	LockedUntil         pgtype.Timestamptz `json:"locked_until"`
+	IsEmailVerified     bool               `json:"is_email_verified"`
+}
+
+type VerifyEmail struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// Address the token was sent to; it only verifies the user while their email is unchanged
+	Email     string             `json:"email"`
+	TokenHash string             `json:"token_hash"`
+	IsUsed    bool               `json:"is_used"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+}
+
+type WebhookDelivery struct {
+	ID         int64 `json:"id"`
+	EndpointID int64 `json:"endpoint_id"`
+	// Outbox event delivered; 0 for test deliveries
+	EventID   int64  `json:"event_id"`
+	EventType string `json:"event_type"`
+	Payload   []byte `json:"payload"`
+	Status    string `json:"status"`
+	Attempts  int32  `json:"attempts"`
+	// HTTP status of the last attempt; 0 if no response was received
+	ResponseStatus int32              `json:"response_status"`
+	LastError      string             `json:"last_error"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
+
+type WebhookEndpoint struct {
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	Url   string `json:"url"`
+	// HMAC-SHA256 key for signing deliveries; kept in plain text as it is needed to sign
+	Secret string `json:"secret"`
+	// Event types delivered to the endpoint; empty for all
+	EventTypes []string           `json:"event_types"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
 }
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// AccountEventsChannel is the Postgres notification channel on which balance
+// changes are announced. Postgres delivers notifications to every listening
+// connection when the transaction that sent them commits, and drops them
+// if it rolls back.
+const AccountEventsChannel = "account_events"
+
+// AccountNotification is the payload of a notification on
+// AccountEventsChannel. Type is EventAccountDebited or EventAccountCredited.
+type AccountNotification struct {
+	Type  string `json:"type"`
+	Owner string `json:"owner"`
+	AccountEntryPayload
+}
+
+// notifyTransfer announces the new balances of both accounts of a transfer.
+func notifyTransfer(ctx context.Context, q *Queries, result TransferTxResult) error {
+	for _, side := range []struct {
+		eventType string
+		account   Account
+		entry     Entry
+	}{
+		{EventAccountDebited, result.FromAccount, result.FromEntry},
+		{EventAccountCredited, result.ToAccount, result.ToEntry},
+	} {
+		err := notifyAccount(ctx, q, AccountNotification{
+			Type:                side.eventType,
+			Owner:               side.account.Owner,
+			AccountEntryPayload: newAccountEntryPayload(result.Transfer, side.account, side.entry),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// notifyAccount sends n as part of the transaction of q.
+func notifyAccount(ctx context.Context, q *Queries, n AccountNotification) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("cannot encode %s notification: %w", n.Type, err)
+	}
+
+	return q.Notify(ctx, NotifyParams{Channel: AccountEventsChannel, Payload: string(data)})
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferTxNotifies(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := testDB.Acquire(ctx)
+	require.NoError(t, err)
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{AccountEventsChannel}.Sanitize())
+	require.NoError(t, err)
+	defer func() {
+		_, _ = conn.Exec(context.Background(), "UNLISTEN *")
+	}()
+
+	store := NewStore(testDB)
+
+	accountFrom := createRandomAccount(t)
+	accountTo := createRandomAccount(t)
+
+	result, err := store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: accountFrom.ID,
+		ToAccountID:   accountTo.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	// Other tests may transfer at the same time; pick out this transfer.
+	got := make(map[string]AccountNotification)
+	for len(got) < 2 {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		require.NoError(t, err)
+
+		var n AccountNotification
+		require.NoError(t, json.Unmarshal([]byte(notification.Payload), &n))
+
+		if n.TransferID == result.Transfer.ID {
+			got[n.Type] = n
+		}
+	}
+
+	debited := got[EventAccountDebited]
+	require.Equal(t, accountFrom.Owner, debited.Owner)
+	require.Equal(t, result.FromAccount.Balance, debited.Balance)
+	require.Equal(t, int64(-10), debited.Amount)
+
+	credited := got[EventAccountCredited]
+	require.Equal(t, accountTo.Owner, credited.Owner)
+	require.Equal(t, result.ToAccount.Balance, credited.Balance)
+	require.Equal(t, result.ToEntry.ID, credited.EntryID)
+}
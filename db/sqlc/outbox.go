@@ -0,0 +1,209 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Aggregate types stored in outbox.aggregate_type
+const (
+	AggregateTransfer = "transfer"
+	AggregateAccount  = "account"
+)
+
+// Event types stored in outbox.event_type
+const (
+	EventTransferCreated = "TransferCreated"
+	EventAccountDebited  = "AccountDebited"
+	EventAccountCredited = "AccountCredited"
+)
+
+// outboxRelayLockID is the advisory lock held by the replica relaying the
+// outbox. A single relay at a time keeps events in id order.
+const outboxRelayLockID = 0x6f7574626f78 // "outbox"
+
+// TransferCreatedPayload is the payload of EventTransferCreated.
+type TransferCreatedPayload struct {
+	TransferID    int64     `json:"transfer_id"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// AccountEntryPayload is the payload of EventAccountDebited and
+// EventAccountCredited. Amount is negative for debits.
+type AccountEntryPayload struct {
+	AccountID  int64     `json:"account_id"`
+	EntryID    int64     `json:"entry_id"`
+	TransferID int64     `json:"transfer_id"`
+	Amount     int64     `json:"amount"`
+	Balance    int64     `json:"balance"`
+	Currency   string    `json:"currency"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func newAccountEntryPayload(transfer Transfer, account Account, entry Entry) AccountEntryPayload {
+	return AccountEntryPayload{
+		AccountID:  account.ID,
+		EntryID:    entry.ID,
+		TransferID: transfer.ID,
+		Amount:     entry.Amount,
+		Balance:    account.Balance,
+		Currency:   account.Currency,
+		CreatedAt:  entry.CreatedAt.Time,
+	}
+}
+
+// addOutboxEvent records an event in the outbox as part of the transaction
+// of q, so it is published if and only if the transaction commits.
+func addOutboxEvent(
+	ctx context.Context,
+	q *Queries,
+	aggregateType string,
+	aggregateID int64,
+	eventType string,
+	payload any,
+) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("cannot encode %s event: %w", eventType, err)
+	}
+
+	_, err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       data,
+	})
+
+	return err
+}
+
+// addTransferEvents records the events of a completed transfer.
+func addTransferEvents(ctx context.Context, q *Queries, result TransferTxResult) error {
+	transfer := result.Transfer
+
+	err := addOutboxEvent(ctx, q, AggregateTransfer, transfer.ID, EventTransferCreated, TransferCreatedPayload{
+		TransferID:    transfer.ID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		Currency:      result.FromAccount.Currency,
+		CreatedAt:     transfer.CreatedAt.Time,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, side := range []struct {
+		eventType string
+		account   Account
+		entry     Entry
+	}{
+		{EventAccountDebited, result.FromAccount, result.FromEntry},
+		{EventAccountCredited, result.ToAccount, result.ToEntry},
+	} {
+		payload := newAccountEntryPayload(transfer, side.account, side.entry)
+
+		err := addOutboxEvent(ctx, q, AggregateAccount, side.account.ID, side.eventType, payload)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RelayOutboxTxResult is a result of RelayOutboxTx
+type RelayOutboxTxResult struct {
+	// Busy is set when another replica holds the relay lock and nothing
+	// was relayed.
+	Busy      bool
+	Published int
+	Failed    int
+	// Held counts events not attempted because an earlier event of the
+	// same aggregate failed.
+	Held int
+}
+
+// RelayOutboxTx passes up to limit unpublished events to publish in id order
+// and marks those it accepts as published. Once an event fails, later
+// events of the same aggregate are held back until it succeeds, so each
+// aggregate's events are published in order. Failures are recorded on the
+// event and retried on the next call.
+//
+// Events are marked published only when the transaction commits, so an event
+// may be published again after a crash: delivery is at least once.
+func (s *SQLStore) RelayOutboxTx(
+	ctx context.Context,
+	limit int32,
+	publish func(ctx context.Context, event Outbox) error,
+) (RelayOutboxTxResult, error) {
+	var result RelayOutboxTxResult
+
+	err := s.execTx(ctx, "RelayOutboxTx", func(ctx context.Context, q *Queries) error {
+		result = RelayOutboxTxResult{}
+
+		locked, err := q.TryAdvisoryXactLock(ctx, outboxRelayLockID)
+		if err != nil {
+			return err
+		}
+		if !locked {
+			result.Busy = true
+			return nil
+		}
+
+		events, err := q.ListUnpublishedOutboxEvents(ctx, limit)
+		if err != nil {
+			return err
+		}
+
+		type aggregate struct {
+			typ string
+			id  int64
+		}
+
+		var (
+			published []int64
+			failed    = make(map[aggregate]bool)
+		)
+
+		for _, event := range events {
+			key := aggregate{event.AggregateType, event.AggregateID}
+			if failed[key] {
+				result.Held++
+				continue
+			}
+
+			if err := publish(ctx, event); err != nil {
+				failed[key] = true
+				result.Failed++
+
+				err = q.RecordOutboxEventFailure(ctx, RecordOutboxEventFailureParams{
+					ID:        event.ID,
+					LastError: err.Error(),
+				})
+				if err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			published = append(published, event.ID)
+		}
+
+		result.Published = len(published)
+		if len(published) == 0 {
+			return nil
+		}
+
+		return q.MarkOutboxEventsPublished(ctx, published)
+	})
+
+	return result, err
+}
@@ -0,0 +1,188 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: outbox.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createOutboxEvent = `-- name: CreateOutboxEvent :one
+INSERT INTO outbox (
+    aggregate_type,
+    aggregate_id,
+    event_type,
+    payload
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING id, aggregate_type, aggregate_id, event_type, payload, is_published, attempts, last_error, created_at, published_at
+`
+
+type CreateOutboxEventParams struct {
+	AggregateType string `json:"aggregate_type"`
+	AggregateID   int64  `json:"aggregate_id"`
+	EventType     string `json:"event_type"`
+	Payload       []byte `json:"payload"`
+}
+
+func (q *Queries) CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (Outbox, error) {
+	row := q.db.QueryRow(ctx, createOutboxEvent,
+		arg.AggregateType,
+		arg.AggregateID,
+		arg.EventType,
+		arg.Payload,
+	)
+	var i Outbox
+	err := row.Scan(
+		&i.ID,
+		&i.AggregateType,
+		&i.AggregateID,
+		&i.EventType,
+		&i.Payload,
+		&i.IsPublished,
+		&i.Attempts,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.PublishedAt,
+	)
+	return i, err
+}
+
+const deletePublishedOutboxEvents = `-- name: DeletePublishedOutboxEvents :execrows
+DELETE FROM outbox
+WHERE is_published AND published_at < $1
+`
+
+func (q *Queries) DeletePublishedOutboxEvents(ctx context.Context, publishedBefore pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deletePublishedOutboxEvents, publishedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const listOutboxEventsByAggregate = `-- name: ListOutboxEventsByAggregate :many
+SELECT id, aggregate_type, aggregate_id, event_type, payload, is_published, attempts, last_error, created_at, published_at FROM outbox
+WHERE aggregate_type = $1 AND aggregate_id = $2
+ORDER BY id
+`
+
+type ListOutboxEventsByAggregateParams struct {
+	AggregateType string `json:"aggregate_type"`
+	AggregateID   int64  `json:"aggregate_id"`
+}
+
+func (q *Queries) ListOutboxEventsByAggregate(ctx context.Context, arg ListOutboxEventsByAggregateParams) ([]Outbox, error) {
+	rows, err := q.db.Query(ctx, listOutboxEventsByAggregate, arg.AggregateType, arg.AggregateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Outbox{}
+	for rows.Next() {
+		var i Outbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.AggregateType,
+			&i.AggregateID,
+			&i.EventType,
+			&i.Payload,
+			&i.IsPublished,
+			&i.Attempts,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.PublishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnpublishedOutboxEvents = `-- name: ListUnpublishedOutboxEvents :many
+SELECT id, aggregate_type, aggregate_id, event_type, payload, is_published, attempts, last_error, created_at, published_at FROM outbox
+WHERE NOT is_published
+ORDER BY id
+LIMIT $1
+`
+
+func (q *Queries) ListUnpublishedOutboxEvents(ctx context.Context, limit int32) ([]Outbox, error) {
+	rows, err := q.db.Query(ctx, listUnpublishedOutboxEvents, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Outbox{}
+	for rows.Next() {
+		var i Outbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.AggregateType,
+			&i.AggregateID,
+			&i.EventType,
+			&i.Payload,
+			&i.IsPublished,
+			&i.Attempts,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.PublishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markOutboxEventsPublished = `-- name: MarkOutboxEventsPublished :exec
+UPDATE outbox
+SET
+    is_published = true,
+    published_at = now()
+WHERE id = ANY($1::bigint[])
+`
+
+func (q *Queries) MarkOutboxEventsPublished(ctx context.Context, ids []int64) error {
+	_, err := q.db.Exec(ctx, markOutboxEventsPublished, ids)
+	return err
+}
+
+const recordOutboxEventFailure = `-- name: RecordOutboxEventFailure :exec
+UPDATE outbox
+SET
+    attempts = attempts + 1,
+    last_error = $2
+WHERE id = $1
+`
+
+type RecordOutboxEventFailureParams struct {
+	ID        int64  `json:"id"`
+	LastError string `json:"last_error"`
+}
+
+func (q *Queries) RecordOutboxEventFailure(ctx context.Context, arg RecordOutboxEventFailureParams) error {
+	_, err := q.db.Exec(ctx, recordOutboxEventFailure, arg.ID, arg.LastError)
+	return err
+}
+
+const tryAdvisoryXactLock = `-- name: TryAdvisoryXactLock :one
+SELECT pg_try_advisory_xact_lock($1::bigint)
+`
+
+func (q *Queries) TryAdvisoryXactLock(ctx context.Context, lockID int64) (bool, error) {
+	row := q.db.QueryRow(ctx, tryAdvisoryXactLock, lockID)
+	var pg_try_advisory_xact_lock bool
+	err := row.Scan(&pg_try_advisory_xact_lock)
+	return pg_try_advisory_xact_lock, err
+}
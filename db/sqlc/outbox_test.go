@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func listOutboxEvents(t *testing.T, aggregateType string, aggregateID int64) []Outbox {
+	events, err := testQueries.ListOutboxEventsByAggregate(context.Background(), ListOutboxEventsByAggregateParams{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+	})
+	require.NoError(t, err)
+
+	return events
+}
+
+func TestTransferTxOutboxEvents(t *testing.T) {
+	store := NewStore(testDB)
+
+	accountFrom := createRandomAccount(t)
+	accountTo := createRandomAccount(t)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: accountFrom.ID,
+		ToAccountID:   accountTo.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	events := listOutboxEvents(t, AggregateTransfer, result.Transfer.ID)
+	require.Len(t, events, 1)
+	require.Equal(t, EventTransferCreated, events[0].EventType)
+	require.False(t, events[0].IsPublished)
+
+	var created TransferCreatedPayload
+	require.NoError(t, json.Unmarshal(events[0].Payload, &created))
+	require.Equal(t, result.Transfer.ID, created.TransferID)
+	require.Equal(t, int64(10), created.Amount)
+	require.Equal(t, accountFrom.Currency, created.Currency)
+
+	for _, tc := range []struct {
+		account   Account
+		eventType string
+		amount    int64
+	}{
+		{result.FromAccount, EventAccountDebited, -10},
+		{result.ToAccount, EventAccountCredited, 10},
+	} {
+		events := listOutboxEvents(t, AggregateAccount, tc.account.ID)
+		require.Len(t, events, 1)
+		require.Equal(t, tc.eventType, events[0].EventType)
+
+		var entry AccountEntryPayload
+		require.NoError(t, json.Unmarshal(events[0].Payload, &entry))
+		require.Equal(t, result.Transfer.ID, entry.TransferID)
+		require.Equal(t, tc.amount, entry.Amount)
+		require.Equal(t, tc.account.Balance, entry.Balance)
+	}
+}
+
+func TestRelayOutboxTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	accountFrom := createRandomAccount(t)
+	accountTo := createRandomAccount(t)
+
+	var transfers []TransferTxResult
+	for range 2 {
+		result, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: accountFrom.ID,
+			ToAccountID:   accountTo.ID,
+			Amount:        10,
+		})
+		require.NoError(t, err)
+		transfers = append(transfers, result)
+	}
+
+	// Events of the source account fail, everything else is accepted.
+	var publishedIDs []int64
+	publish := func(_ context.Context, event Outbox) error {
+		if event.AggregateType == AggregateAccount && event.AggregateID == accountFrom.ID {
+			return errors.New("sink unavailable")
+		}
+		publishedIDs = append(publishedIDs, event.ID)
+
+		return nil
+	}
+
+	// Other tests may have left events behind; relay until only failing
+	// events remain.
+	for {
+		result, err := store.RelayOutboxTx(context.Background(), 100, publish)
+		require.NoError(t, err)
+		require.False(t, result.Busy)
+
+		if result.Published == 0 {
+			break
+		}
+	}
+
+	require.IsIncreasing(t, publishedIDs)
+
+	for _, transfer := range transfers {
+		events := listOutboxEvents(t, AggregateTransfer, transfer.Transfer.ID)
+		require.True(t, events[0].IsPublished)
+	}
+
+	for _, event := range listOutboxEvents(t, AggregateAccount, accountTo.ID) {
+		require.True(t, event.IsPublished)
+	}
+
+	// The first debit failed and held back the second, which was never
+	// attempted.
+	debits := listOutboxEvents(t, AggregateAccount, accountFrom.ID)
+	require.Len(t, debits, 2)
+	require.False(t, debits[0].IsPublished)
+	require.Positive(t, debits[0].Attempts)
+	require.Equal(t, "sink unavailable", debits[0].LastError)
+	require.False(t, debits[1].IsPublished)
+	require.Zero(t, debits[1].Attempts)
+}
@@ -0,0 +1,77 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: password_reset.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createPasswordResetToken = `-- name: CreatePasswordResetToken :one
+INSERT INTO password_reset_tokens (
+    username,
+    token_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) RETURNING id, username, token_hash, is_used, created_at, expires_at
+`
+
+type CreatePasswordResetTokenParams struct {
+	Username  string             `json:"username"`
+	TokenHash string             `json:"token_hash"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+}
+
+func (q *Queries) CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) (PasswordResetToken, error) {
+	row := q.db.QueryRow(ctx, createPasswordResetToken, arg.Username, arg.TokenHash, arg.ExpiresAt)
+	var i PasswordResetToken
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.TokenHash,
+		&i.IsUsed,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const invalidatePasswordResetTokens = `-- name: InvalidatePasswordResetTokens :execrows
+UPDATE password_reset_tokens
+SET is_used = true
+WHERE username = $1 AND NOT is_used
+`
+
+func (q *Queries) InvalidatePasswordResetTokens(ctx context.Context, username string) (int64, error) {
+	result, err := q.db.Exec(ctx, invalidatePasswordResetTokens, username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const usePasswordResetToken = `-- name: UsePasswordResetToken :one
+UPDATE password_reset_tokens
+SET is_used = true
+WHERE token_hash = $1 AND NOT is_used AND expires_at > now()
+RETURNING id, username, token_hash, is_used, created_at, expires_at
+`
+
+func (q *Queries) UsePasswordResetToken(ctx context.Context, tokenHash string) (PasswordResetToken, error) {
+	row := q.db.QueryRow(ctx, usePasswordResetToken, tokenHash)
+	var i PasswordResetToken
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.TokenHash,
+		&i.IsUsed,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
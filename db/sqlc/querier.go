@@ -8,26 +8,128 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	BlockOtherUserSessions(ctx context.Context, arg BlockOtherUserSessionsParams) (int64, error)
+	BlockSession(ctx context.Context, id uuid.UUID) (Session, error)
+	BlockUserSessions(ctx context.Context, username string) (int64, error)
+	ClaimJobs(ctx context.Context, maxJobs int32) ([]Job, error)
+	CompleteJob(ctx context.Context, id int64) error
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateFee(ctx context.Context, arg CreateFeeParams) (Fee, error)
+	CreateFeeRule(ctx context.Context, arg CreateFeeRuleParams) (FeeRule, error)
+	// Does nothing when the account already accrued interest for the date.
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (int64, error)
+	CreateInterestPosting(ctx context.Context, arg CreateInterestPostingParams) (InterestPosting, error)
+	CreateLedgerAccount(ctx context.Context, arg CreateLedgerAccountParams) (LedgerAccount, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (Outbox, error)
+	CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) (PasswordResetToken, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateSystemAccount(ctx context.Context, arg CreateSystemAccountParams) (SystemAccount, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateVerifyEmail(ctx context.Context, arg CreateVerifyEmailParams) (VerifyEmail, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error)
+	CreateWebhookEndpoint(ctx context.Context, arg CreateWebhookEndpointParams) (WebhookEndpoint, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteDoneJobs(ctx context.Context, updatedBefore pgtype.Timestamptz) (int64, error)
+	DeleteFeeRule(ctx context.Context, id int64) (int64, error)
+	DeletePublishedOutboxEvents(ctx context.Context, publishedBefore pgtype.Timestamptz) (int64, error)
+	DeleteStaleRateLimits(ctx context.Context, before pgtype.Timestamptz) (int64, error)
+	DeleteWebhookEndpoint(ctx context.Context, id int64) error
+	// A job with a key that is already taken is not inserted, and no row is
+	// returned.
+	EnqueueJob(ctx context.Context, arg EnqueueJobParams) (Job, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetInterestPostingTotals(ctx context.Context, accountID int64) (GetInterestPostingTotalsRow, error)
+	GetJob(ctx context.Context, id int64) (Job, error)
+	GetLedgerAccount(ctx context.Context, code string) (LedgerAccount, error)
+	// The balance of an account before from_time: its current balance less
+	// every entry since. Entries are only ever added at the current time, so the
+	// result does not change as new ones arrive.
+	GetOpeningBalance(ctx context.Context, arg GetOpeningBalanceParams) (int64, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetSystemAccount(ctx context.Context, arg GetSystemAccountParams) (Account, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	GetWebhookEndpoint(ctx context.Context, id int64) (WebhookEndpoint, error)
+	InvalidatePasswordResetTokens(ctx context.Context, username string) (int64, error)
+	IsSystemAccount(ctx context.Context, accountID int64) (bool, error)
+	KillJob(ctx context.Context, arg KillJobParams) error
+	// An empty type or currency matches every account.
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAccountsWithUnpostedInterest(ctx context.Context, before pgtype.Date) ([]int64, error)
+	ListAllAccounts(ctx context.Context, arg ListAllAccountsParams) ([]Account, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListFeeRules(ctx context.Context) ([]FeeRule, error)
+	ListFees(ctx context.Context, arg ListFeesParams) ([]Fee, error)
+	ListInterestAccruals(ctx context.Context, accountID int64) ([]InterestAccrual, error)
+	// Lists the open customer accounts of the given types that existed at
+	// day_end, with their balance at that time: entries made since are
+	// subtracted.
+	ListInterestBearingAccounts(ctx context.Context, arg ListInterestBearingAccountsParams) ([]ListInterestBearingAccountsRow, error)
+	ListInterestPostings(ctx context.Context, accountID int64) ([]InterestPosting, error)
+	ListJobs(ctx context.Context, arg ListJobsParams) ([]Job, error)
+	ListLedgerAccounts(ctx context.Context) ([]LedgerAccount, error)
+	// Lists the open customer accounts that existed at month_end.
+	ListMaintenanceFeeAccounts(ctx context.Context, monthEnd pgtype.Timestamptz) ([]int64, error)
+	ListOutboxEventsByAggregate(ctx context.Context, arg ListOutboxEventsByAggregateParams) ([]Outbox, error)
+	// A page of an account's entries in [from_time, to_time) after entry
+	// after_id, with the other account of the transfer that created each one.
+	ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error)
+	ListSystemAccounts(ctx context.Context) ([]ListSystemAccountsRow, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	// The balance of every ledger account per currency, summed from the entries
+	// of its accounts. Customer accounts are booked to customer_code, every
+	// other account to its system account's code. Balances are credits when
+	// positive and debits when negative. account_balance sums the balances
+	// stored on the accounts instead, and mismatched counts the accounts whose
+	// entries do not add up to their balance.
+	ListTrialBalance(ctx context.Context, customerCode string) ([]ListTrialBalanceRow, error)
+	ListUnpublishedOutboxEvents(ctx context.Context, limit int32) ([]Outbox, error)
+	ListUnreconciledAccounts(ctx context.Context) ([]ListUnreconciledAccountsRow, error)
+	ListUserSessions(ctx context.Context, username string) ([]Session, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhookEndpoints(ctx context.Context, owner string) ([]WebhookEndpoint, error)
+	ListWebhookEndpointsForEvent(ctx context.Context, arg ListWebhookEndpointsForEventParams) ([]WebhookEndpoint, error)
+	// Locks accounts in id order, the order every transaction takes them in,
+	// so transactions locking several accounts do not deadlock.
+	LockAccounts(ctx context.Context, ids []int64) ([]int64, error)
+	MarkInterestPosted(ctx context.Context, arg MarkInterestPostedParams) (int64, error)
+	MarkOutboxEventsPublished(ctx context.Context, ids []int64) error
+	// The rule for an event that applies to an account of the given currency
+	// and type and an amount. Rules for the currency win over rules for any
+	// currency, then rules for the account type over rules for any type, and
+	// among those the highest tier the amount reaches applies.
+	MatchFeeRule(ctx context.Context, arg MatchFeeRuleParams) (FeeRule, error)
+	Notify(ctx context.Context, arg NotifyParams) error
+	RecordFailedLogin(ctx context.Context, arg RecordFailedLoginParams) (User, error)
+	RecordOutboxEventFailure(ctx context.Context, arg RecordOutboxEventFailureParams) error
+	RecordWebhookDeliveryAttempt(ctx context.Context, arg RecordWebhookDeliveryAttemptParams) (WebhookDelivery, error)
+	RequeueStuckJobs(ctx context.Context, lockedBefore pgtype.Timestamptz) (int64, error)
+	ResetFailedLogins(ctx context.Context, username string) (User, error)
+	RetryJob(ctx context.Context, arg RetryJobParams) error
+	ReviveJob(ctx context.Context, id int64) (Job, error)
+	SetFeeTransfer(ctx context.Context, arg SetFeeTransferParams) (Fee, error)
+	SetInterestPostingTransfer(ctx context.Context, arg SetInterestPostingTransferParams) (InterestPosting, error)
+	SumUnpostedInterest(ctx context.Context, arg SumUnpostedInterestParams) (int64, error)
+	TakeRateLimitToken(ctx context.Context, arg TakeRateLimitTokenParams) (TakeRateLimitTokenRow, error)
+	TryAdvisoryXactLock(ctx context.Context, lockID int64) (bool, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	UpdateUserDisabled(ctx context.Context, arg UpdateUserDisabledParams) (User, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UsePasswordResetToken(ctx context.Context, tokenHash string) (PasswordResetToken, error)
+	UseVerifyEmail(ctx context.Context, tokenHash string) (VerifyEmail, error)
+	VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,62 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: rate_limit.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const deleteStaleRateLimits = `-- name: DeleteStaleRateLimits :execrows
+DELETE FROM rate_limits
+WHERE updated_at < $1
+`
+
+func (q *Queries) DeleteStaleRateLimits(ctx context.Context, before pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteStaleRateLimits, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const takeRateLimitToken = `-- name: TakeRateLimitToken :one
+INSERT INTO rate_limits (
+    key, tokens, allowed, updated_at
+)
+VALUES (
+    $1, $2::double precision - 1, true, now()
+)
+ON CONFLICT (key) DO UPDATE
+SET
+    tokens = CASE
+        WHEN LEAST($2::double precision, rate_limits.tokens + EXTRACT(EPOCH FROM now() - rate_limits.updated_at) * $3::double precision) >= 1
+        THEN LEAST($2::double precision, rate_limits.tokens + EXTRACT(EPOCH FROM now() - rate_limits.updated_at) * $3::double precision) - 1
+        ELSE LEAST($2::double precision, rate_limits.tokens + EXTRACT(EPOCH FROM now() - rate_limits.updated_at) * $3::double precision)
+    END,
+    allowed = LEAST($2::double precision, rate_limits.tokens + EXTRACT(EPOCH FROM now() - rate_limits.updated_at) * $3::double precision) >= 1,
+    updated_at = now()
+RETURNING tokens, allowed
+`
+
+type TakeRateLimitTokenParams struct {
+	Key             string  `json:"key"`
+	Burst           float64 `json:"burst"`
+	RefillPerSecond float64 `json:"refill_per_second"`
+}
+
+type TakeRateLimitTokenRow struct {
+	Tokens  float64 `json:"tokens"`
+	Allowed bool    `json:"allowed"`
+}
+
+func (q *Queries) TakeRateLimitToken(ctx context.Context, arg TakeRateLimitTokenParams) (TakeRateLimitTokenRow, error) {
+	row := q.db.QueryRow(ctx, takeRateLimitToken, arg.Key, arg.Burst, arg.RefillPerSecond)
+	var i TakeRateLimitTokenRow
+	err := row.Scan(&i.Tokens, &i.Allowed)
+	return i, err
+}
@@ -0,0 +1,28 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shevgn/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTakeRateLimitToken(t *testing.T) {
+	arg := TakeRateLimitTokenParams{
+		Key:             util.RandomString(12),
+		Burst:           2,
+		RefillPerSecond: 0.001,
+	}
+
+	for range 2 {
+		row, err := testQueries.TakeRateLimitToken(context.Background(), arg)
+		require.NoError(t, err)
+		require.True(t, row.Allowed)
+	}
+
+	row, err := testQueries.TakeRateLimitToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, row.Allowed)
+	require.Less(t, row.Tokens, 1.0)
+}
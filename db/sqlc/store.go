@@ -2,16 +2,97 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// Account statuses stored in accounts.status
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+// Account types stored in accounts.type
+const (
+	AccountTypeChecking = "checking"
+	AccountTypeSavings  = "savings"
+	AccountTypeBusiness = "business"
+)
+
+// AccountTypes are the types an account can be opened as
+var AccountTypes = []string{AccountTypeChecking, AccountTypeSavings, AccountTypeBusiness}
+
+// accountTransitions lists the statuses an account in each status may move
+// to. Frozen accounts must be unfrozen before they can be closed, and closed
+// accounts can only be reopened.
+var accountTransitions = map[string][]string{
+	AccountStatusActive: {AccountStatusFrozen, AccountStatusClosed},
+	AccountStatusFrozen: {AccountStatusActive},
+	AccountStatusClosed: {AccountStatusActive},
+}
+
+// Job statuses stored in jobs.status
+const (
+	JobStatusPending = "pending"
+	JobStatusRunning = "running"
+	JobStatusDone    = "done"
+	JobStatusDead    = "dead"
+)
+
+var (
+	// ErrAccountFrozen is returned by TransferTx when either account is frozen
+	ErrAccountFrozen = errors.New("account is frozen")
+	// ErrAccountClosed is returned by TransferTx when either account is closed
+	ErrAccountClosed = errors.New("account is closed")
+	// ErrAccountNotEmpty is returned by UpdateAccountStatusTx when closing
+	// an account whose balance is not zero
+	ErrAccountNotEmpty = errors.New("account balance is not zero")
+	// ErrInvalidStatusTransition is returned by UpdateAccountStatusTx when
+	// the account cannot move from its current status to the requested one
+	ErrInvalidStatusTransition = errors.New("account status change is not allowed")
+	// ErrUserDisabled is returned when a disabled user tries to log in
+	ErrUserDisabled = errors.New("user is disabled")
+	// ErrUserLocked is returned when a user tries to log in while locked out
+	// after too many failed attempts
+	ErrUserLocked = errors.New("user is locked after too many failed login attempts")
+	// ErrInvalidResetToken is returned by ResetPasswordTx when the token is
+	// unknown, expired or already used
+	ErrInvalidResetToken = errors.New("password reset token is invalid or expired")
+	// ErrInvalidVerifyToken is returned by VerifyEmailTx when the token is
+	// unknown, expired, already used or was sent to a previous email
+	ErrInvalidVerifyToken = errors.New("email verification token is invalid or expired")
+	// ErrEmailNotVerified is returned by TransferTx when the owner of the
+	// source account has not verified their email
+	ErrEmailNotVerified = errors.New("email is not verified")
+)
+
 // Store is a database store interface
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, args TransferTxParams) (TransferTxResult, error)
+	BulkTransferTx(ctx context.Context, args []TransferTxParams) ([]TransferTxResult, error)
+	QuoteTransfer(ctx context.Context, args TransferTxParams) (TransferQuote, error)
+	UpdateAccountStatusTx(ctx context.Context, args UpdateAccountStatusTxParams) (Account, error)
+	PostInterestTx(ctx context.Context, args PostInterestTxParams) (InterestPosting, error)
+	ChargeMaintenanceFeeTx(ctx context.Context, args ChargeMaintenanceFeeTxParams) (Fee, error)
+	CreateSystemAccountTx(ctx context.Context, args CreateSystemAccountTxParams) (Account, error)
+	PostLedgerTx(ctx context.Context, args PostLedgerTxParams) (TransferTxResult, error)
+	DisableUserTx(ctx context.Context, username string) (DisableUserTxResult, error)
+	ChangePasswordTx(ctx context.Context, args ChangePasswordTxParams) (PasswordTxResult, error)
+	ResetPasswordTx(ctx context.Context, args ResetPasswordTxParams) (PasswordTxResult, error)
+	VerifyEmailTx(ctx context.Context, tokenHash string) (User, error)
+	RelayOutboxTx(
+		ctx context.Context,
+		limit int32,
+		publish func(ctx context.Context, event Outbox) error,
+	) (RelayOutboxTxResult, error)
+	EnqueueWebhookDeliveriesTx(ctx context.Context, args EnqueueWebhookDeliveriesTxParams) ([]WebhookDelivery, error)
 }
 
 // SQLStore is a database store
@@ -23,24 +104,34 @@ type SQLStore struct {
 // NewStore creates a new Store
 func NewStore(db *pgxpool.Pool) Store {
 	return &SQLStore{
-		Queries: New(db),
+		Queries: New(newTracedDBTX(db)),
 		db:      db,
 	}
 }
 
-func (s *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
+// execTx runs fn inside a database transaction traced as a single span
+// named after the transaction, with each statement as a child span.
+func (s *SQLStore) execTx(ctx context.Context, name string, fn func(context.Context, *Queries) error) (err error) {
+	ctx, span := tracer.Start(ctx, "db."+name)
+	defer func() {
+		recordError(span, err)
+		span.End()
+	}()
+
 	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return err
 	}
 
-	q := New(tx)
-	err = fn(q)
+	q := New(newTracedDBTX(tx))
+	err = fn(ctx, q)
 	if err != nil {
 		rbErr := tx.Rollback(ctx)
 		if rbErr != nil {
 			return fmt.Errorf("tx error: %w, rollback error: %w", err, rbErr)
 		}
+
+		return err
 	}
 
 	return tx.Commit(ctx)
@@ -60,67 +151,376 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// Fee is what the sender was charged for the transfer, booked as a
+	// transfer of its own to the bank's fee revenue account, and FeeEntry
+	// its entry on the sender's account. Both are nil when no fee applies;
+	// otherwise FromAccount is the sender's account after the fee.
+	Fee      *Fee   `json:"fee,omitempty"`
+	FeeEntry *Entry `json:"fee_entry,omitempty"`
 }
 
+// TransferTx books a transfer and the fee its sender is charged for it in
+// one transaction.
 func (s *SQLStore) TransferTx(ctx context.Context, args TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
-	err := s.execTx(ctx, func(q *Queries) error {
+	err := s.execTx(ctx, "TransferTx", func(ctx context.Context, q *Queries) error {
 		var err error
+		result, err = transferWithFee(ctx, q, args)
+
+		return err
+	})
+
+	return result, err
+}
+
+// BulkTransferError is returned by BulkTransferTx when one of the transfers
+// fails. Index is its position in the batch.
+type BulkTransferError struct {
+	Index int
+	Err   error
+}
 
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams(args))
+func (e *BulkTransferError) Error() string {
+	return fmt.Sprintf("transfer %d: %s", e.Index+1, e.Err)
+}
+
+func (e *BulkTransferError) Unwrap() error {
+	return e.Err
+}
+
+// BulkTransferTx books every transfer in one transaction, so either all of
+// them happen or, when one fails with a *BulkTransferError, none does. The
+// accounts are locked up front so batches do not deadlock each other.
+func (s *SQLStore) BulkTransferTx(ctx context.Context, args []TransferTxParams) ([]TransferTxResult, error) {
+	results := make([]TransferTxResult, 0, len(args))
+
+	err := s.execTx(ctx, "BulkTransferTx", func(ctx context.Context, q *Queries) error {
+		ids := make([]int64, 0, 2*len(args))
+		for _, arg := range args {
+			ids = append(ids, arg.FromAccountID, arg.ToAccountID)
+		}
+
+		if _, err := q.LockAccounts(ctx, ids); err != nil {
+			return err
+		}
+
+		for i, arg := range args {
+			result, err := transferWithFee(ctx, q, arg)
+			if err != nil {
+				return &BulkTransferError{Index: i, Err: err}
+			}
+
+			results = append(results, result)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// transfer books a transfer with its two entries and balance updates
+// inside the transaction of q. It charges no fee, so the bank's own
+// transfers use it directly.
+func transfer(ctx context.Context, q *Queries, args TransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+	var err error
+
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams(args))
+	if err != nil {
+		return result, err
+	}
+
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  args.FromAccountID,
+		Amount:     -args.Amount,
+		TransferID: result.Transfer.ID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  args.ToAccountID,
+		Amount:     args.Amount,
+		TransferID: result.Transfer.ID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if args.FromAccountID < args.ToAccountID {
+		result.FromAccount, result.ToAccount, err = addBalance(
+			ctx,
+			q,
+			args.FromAccountID,
+			-args.Amount,
+			args.ToAccountID,
+			args.Amount,
+		)
+		if err != nil {
+			return result, err
+		}
+	} else {
+		result.ToAccount, result.FromAccount, err = addBalance(
+			ctx,
+			q,
+			args.ToAccountID,
+			args.Amount,
+			args.FromAccountID,
+			-args.Amount,
+		)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if err := CheckActive(result.FromAccount, result.ToAccount); err != nil {
+		return result, err
+	}
+
+	if err := addTransferEvents(ctx, q, result); err != nil {
+		return result, err
+	}
+
+	return result, notifyTransfer(ctx, q, result)
+}
+
+// CheckActive fails with ErrAccountFrozen or ErrAccountClosed unless every
+// account is active. TransferTx calls it after the balance updates, so it
+// sees the status under the same row locks.
+func CheckActive(accounts ...Account) error {
+	for _, account := range accounts {
+		switch account.Status {
+		case AccountStatusActive:
+		case AccountStatusClosed:
+			return fmt.Errorf("account %d: %w", account.ID, ErrAccountClosed)
+		default:
+			return fmt.Errorf("account %d: %w", account.ID, ErrAccountFrozen)
+		}
+	}
+
+	return nil
+}
+
+// UpdateAccountStatusTxParams is a set of parameters for
+// UpdateAccountStatusTx
+type UpdateAccountStatusTxParams struct {
+	ID int64 `json:"id"`
+	// From is the status the account must be in, so unfreezing cannot
+	// reopen a closed account and reopening cannot unfreeze a frozen one
+	From   string `json:"from"`
+	Status string `json:"status"`
+}
+
+// UpdateAccountStatusTx moves an account from one status to another under a
+// row lock, so no transfer can change its balance in between. It fails with
+// ErrInvalidStatusTransition unless the account is in args.From and
+// accountTransitions allows the move, and with ErrAccountNotEmpty when
+// closing an account that still holds money. Closed accounts keep their
+// entries and transfers.
+func (s *SQLStore) UpdateAccountStatusTx(ctx context.Context, args UpdateAccountStatusTxParams) (Account, error) {
+	var account Account
+
+	err := s.execTx(ctx, "UpdateAccountStatusTx", func(ctx context.Context, q *Queries) error {
+		var err error
+
+		account, err = q.GetAccountForUpdate(ctx, args.ID)
 		if err != nil {
 			return err
 		}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: args.FromAccountID,
-			Amount:    -args.Amount,
+		if account.Status != args.From || !slices.Contains(accountTransitions[account.Status], args.Status) {
+			return fmt.Errorf("%w: account %d is %s and cannot become %s",
+				ErrInvalidStatusTransition, account.ID, account.Status, args.Status)
+		}
+
+		if args.Status == AccountStatusClosed && account.Balance != 0 {
+			return fmt.Errorf("account %d: %w", account.ID, ErrAccountNotEmpty)
+		}
+
+		account, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			ID:     args.ID,
+			Status: args.Status,
+		})
+
+		return err
+	})
+
+	return account, err
+}
+
+// checkEmailVerified fails unless the owner of account has verified their
+// email, so unverified users cannot move money out.
+func checkEmailVerified(ctx context.Context, q *Queries, account Account) error {
+	owner, err := q.GetUser(ctx, account.Owner)
+	if err != nil {
+		return err
+	}
+
+	if !owner.IsEmailVerified {
+		return fmt.Errorf("user %s: %w", owner.Username, ErrEmailNotVerified)
+	}
+
+	return nil
+}
+
+// DisableUserTxResult is a result of DisableUserTx
+type DisableUserTxResult struct {
+	User            User  `json:"user"`
+	BlockedSessions int64 `json:"blocked_sessions"`
+}
+
+// DisableUserTx disables a user and blocks all of their sessions, so no new
+// access tokens can be issued to them
+func (s *SQLStore) DisableUserTx(ctx context.Context, username string) (DisableUserTxResult, error) {
+	var result DisableUserTxResult
+
+	err := s.execTx(ctx, "DisableUserTx", func(ctx context.Context, q *Queries) error {
+		var err error
+
+		result.User, err = q.UpdateUserDisabled(ctx, UpdateUserDisabledParams{
+			Username:   username,
+			IsDisabled: true,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.BlockedSessions, err = q.BlockUserSessions(ctx, username)
+
+		return err
+	})
+
+	return result, err
+}
+
+// ChangePasswordTxParams is a set of parameters for ChangePasswordTx
+type ChangePasswordTxParams struct {
+	Username       string    `json:"username"`
+	HashedPassword string    `json:"hashed_password"`
+	KeepSessionID  uuid.UUID `json:"keep_session_id"`
+}
+
+// PasswordTxResult is a result of ChangePasswordTx and ResetPasswordTx
+type PasswordTxResult struct {
+	User            User  `json:"user"`
+	BlockedSessions int64 `json:"blocked_sessions"`
+}
+
+// ChangePasswordTx sets a new password and blocks every session of the user
+// except KeepSessionID, which may be uuid.Nil to block them all
+func (s *SQLStore) ChangePasswordTx(ctx context.Context, args ChangePasswordTxParams) (PasswordTxResult, error) {
+	var result PasswordTxResult
+
+	err := s.execTx(ctx, "ChangePasswordTx", func(ctx context.Context, q *Queries) error {
+		var err error
+
+		result.User, err = q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+			Username:       args.Username,
+			HashedPassword: args.HashedPassword,
 		})
 		if err != nil {
 			return err
 		}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: args.ToAccountID,
-			Amount:    args.Amount,
+		result.BlockedSessions, err = q.BlockOtherUserSessions(ctx, BlockOtherUserSessionsParams{
+			Username: args.Username,
+			KeepID:   args.KeepSessionID,
 		})
 		if err != nil {
 			return err
 		}
 
-		if args.FromAccountID < args.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addBalance(
-				ctx,
-				q,
-				args.FromAccountID,
-				-args.Amount,
-				args.ToAccountID,
-				args.Amount,
-			)
-			if err != nil {
-				return err
-			}
-		} else {
-			result.ToAccount, result.FromAccount, err = addBalance(
-				ctx,
-				q,
-				args.ToAccountID,
-				args.Amount,
-				args.FromAccountID,
-				-args.Amount,
-			)
-			if err != nil {
-				return err
+		_, err = q.InvalidatePasswordResetTokens(ctx, args.Username)
+
+		return err
+	})
+
+	return result, err
+}
+
+// ResetPasswordTxParams is a set of parameters for ResetPasswordTx
+type ResetPasswordTxParams struct {
+	TokenHash      string `json:"token_hash"`
+	HashedPassword string `json:"hashed_password"`
+}
+
+// ResetPasswordTx redeems a password reset token: it sets the new password,
+// invalidates every other reset token of the user, lifts any login lockout
+// and blocks all sessions
+func (s *SQLStore) ResetPasswordTx(ctx context.Context, args ResetPasswordTxParams) (PasswordTxResult, error) {
+	var result PasswordTxResult
+
+	err := s.execTx(ctx, "ResetPasswordTx", func(ctx context.Context, q *Queries) error {
+		resetToken, err := q.UsePasswordResetToken(ctx, args.TokenHash)
+		if err != nil {
+			if errors.Is(err, ErrRecordNotFound) {
+				return ErrInvalidResetToken
 			}
+
+			return err
 		}
 
-		return nil
+		_, err = q.InvalidatePasswordResetTokens(ctx, resetToken.Username)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+			Username:       resetToken.Username,
+			HashedPassword: args.HashedPassword,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.User, err = q.ResetFailedLogins(ctx, resetToken.Username)
+		if err != nil {
+			return err
+		}
+
+		result.BlockedSessions, err = q.BlockUserSessions(ctx, resetToken.Username)
+
+		return err
 	})
 
 	return result, err
 }
 
+// VerifyEmailTx redeems an email verification token and marks the email it
+// was sent to as verified
+func (s *SQLStore) VerifyEmailTx(ctx context.Context, tokenHash string) (User, error) {
+	var user User
+
+	err := s.execTx(ctx, "VerifyEmailTx", func(ctx context.Context, q *Queries) error {
+		verifyEmail, err := q.UseVerifyEmail(ctx, tokenHash)
+		if err != nil {
+			if errors.Is(err, ErrRecordNotFound) {
+				return ErrInvalidVerifyToken
+			}
+
+			return err
+		}
+
+		user, err = q.VerifyUserEmail(ctx, VerifyUserEmailParams{
+			Username: verifyEmail.Username,
+			Email:    verifyEmail.Email,
+		})
+		if errors.Is(err, ErrRecordNotFound) {
+			return ErrInvalidVerifyToken
+		}
+
+		return err
+	})
+
+	return user, err
+}
+
 func addBalance(
 	ctx context.Context,
 	q *Queries,
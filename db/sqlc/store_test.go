@@ -4,7 +4,11 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shevgn/simplebank/util"
 	"github.com/stretchr/testify/require"
 )
 
@@ -191,3 +195,306 @@ func TestTransferTxDeadlock(t *testing.T) {
 // 		})
 // 	}
 // }
+
+func TestTransferTxFrozenAccount(t *testing.T) {
+	store := NewStore(testDB)
+
+	accountFrom := createRandomAccount(t)
+	accountTo := createRandomAccount(t)
+
+	_, err := testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:     accountTo.ID,
+		Status: AccountStatusFrozen,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: accountFrom.ID,
+		ToAccountID:   accountTo.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	// The transaction was rolled back, so no money moved.
+	updatedFrom, err := testQueries.GetAccount(context.Background(), accountFrom.ID)
+	require.NoError(t, err)
+	require.Equal(t, accountFrom.Balance, updatedFrom.Balance)
+
+	// Nor were any events recorded.
+	events, err := testQueries.ListOutboxEventsByAggregate(context.Background(), ListOutboxEventsByAggregateParams{
+		AggregateType: AggregateAccount,
+		AggregateID:   accountFrom.ID,
+	})
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func TestUpdateAccountStatusTx(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+
+	account := createRandomAccount(t)
+	other := createRandomAccount(t)
+
+	_, err := store.UpdateAccountStatusTx(ctx, UpdateAccountStatusTxParams{
+		ID:     account.ID,
+		From:   AccountStatusActive,
+		Status: AccountStatusClosed,
+	})
+	require.ErrorIs(t, err, ErrAccountNotEmpty)
+
+	// Unfreezing an active account is not a transition.
+	_, err = store.UpdateAccountStatusTx(ctx, UpdateAccountStatusTxParams{
+		ID:     account.ID,
+		From:   AccountStatusFrozen,
+		Status: AccountStatusActive,
+	})
+	require.ErrorIs(t, err, ErrInvalidStatusTransition)
+
+	_, err = store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   other.ID,
+		Amount:        account.Balance,
+	})
+	require.NoError(t, err)
+
+	closed, err := store.UpdateAccountStatusTx(ctx, UpdateAccountStatusTxParams{
+		ID:     account.ID,
+		From:   AccountStatusActive,
+		Status: AccountStatusClosed,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusClosed, closed.Status)
+	require.WithinDuration(t, time.Now(), closed.ClosedAt.Time, time.Second)
+
+	// A closed account takes no transfers, and its history is kept.
+	_, err = store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: other.ID,
+		ToAccountID:   account.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrAccountClosed)
+
+	entries, err := testQueries.ListEntries(ctx, ListEntriesParams{
+		AccountID: account.ID,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// Freezing is only for active accounts.
+	_, err = store.UpdateAccountStatusTx(ctx, UpdateAccountStatusTxParams{
+		ID:     account.ID,
+		From:   AccountStatusActive,
+		Status: AccountStatusFrozen,
+	})
+	require.ErrorIs(t, err, ErrInvalidStatusTransition)
+
+	reopened, err := store.UpdateAccountStatusTx(ctx, UpdateAccountStatusTxParams{
+		ID:     account.ID,
+		From:   AccountStatusClosed,
+		Status: AccountStatusActive,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusActive, reopened.Status)
+	require.True(t, reopened.ClosedAt.Time.IsZero())
+
+	_, err = store.UpdateAccountStatusTx(ctx, UpdateAccountStatusTxParams{
+		ID:     0,
+		From:   AccountStatusActive,
+		Status: AccountStatusClosed,
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestBulkTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+
+	payer := createRandomAccount(t)
+	payee1 := createRandomAccount(t)
+	payee2 := createRandomAccount(t)
+
+	results, err := store.BulkTransferTx(ctx, []TransferTxParams{
+		{FromAccountID: payer.ID, ToAccountID: payee1.ID, Amount: 10},
+		{FromAccountID: payer.ID, ToAccountID: payee2.ID, Amount: 20},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, payer.Balance-30, results[1].FromAccount.Balance)
+	require.Equal(t, payee2.Balance+20, results[1].ToAccount.Balance)
+
+	_, err = testQueries.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+		ID:     payee2.ID,
+		Status: AccountStatusFrozen,
+	})
+	require.NoError(t, err)
+
+	_, err = store.BulkTransferTx(ctx, []TransferTxParams{
+		{FromAccountID: payer.ID, ToAccountID: payee1.ID, Amount: 10},
+		{FromAccountID: payer.ID, ToAccountID: payee2.ID, Amount: 20},
+	})
+
+	var transferErr *BulkTransferError
+	require.ErrorAs(t, err, &transferErr)
+	require.Equal(t, 1, transferErr.Index)
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	// The first transfer was rolled back with the second.
+	updatedPayee1, err := testQueries.GetAccount(ctx, payee1.ID)
+	require.NoError(t, err)
+	require.Equal(t, payee1.Balance+10, updatedPayee1.Balance)
+}
+
+func TestDisableUserTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	for range 2 {
+		_, err := testQueries.CreateSession(context.Background(), CreateSessionParams{
+			ID:           uuid.New(),
+			Username:     user.Username,
+			RefreshToken: "token",
+			ExpiresAt:    pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+		})
+		require.NoError(t, err)
+	}
+
+	result, err := store.DisableUserTx(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.True(t, result.User.IsDisabled)
+	require.Equal(t, int64(2), result.BlockedSessions)
+
+	sessions, err := testQueries.ListUserSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+
+	for _, session := range sessions {
+		require.True(t, session.IsBlocked)
+	}
+}
+
+func TestChangePasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	sessionIDs := []uuid.UUID{uuid.New(), uuid.New()}
+	for _, id := range sessionIDs {
+		_, err := testQueries.CreateSession(context.Background(), CreateSessionParams{
+			ID:           id,
+			Username:     user.Username,
+			RefreshToken: "token",
+			ExpiresAt:    pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+		})
+		require.NoError(t, err)
+	}
+
+	result, err := store.ChangePasswordTx(context.Background(), ChangePasswordTxParams{
+		Username:       user.Username,
+		HashedPassword: "new-hash",
+		KeepSessionID:  sessionIDs[0],
+	})
+	require.NoError(t, err)
+	require.Equal(t, "new-hash", result.User.HashedPassword)
+	require.WithinDuration(t, time.Now(), result.User.PasswordChangedAt.Time, 5*time.Second)
+	require.Equal(t, int64(1), result.BlockedSessions)
+
+	kept, err := testQueries.GetSession(context.Background(), sessionIDs[0])
+	require.NoError(t, err)
+	require.False(t, kept.IsBlocked)
+
+	blocked, err := testQueries.GetSession(context.Background(), sessionIDs[1])
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+}
+
+func TestResetPasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	resetToken, err := testQueries.CreatePasswordResetToken(context.Background(), CreatePasswordResetTokenParams{
+		Username:  user.Username,
+		TokenHash: util.RandomString(64),
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+	})
+	require.NoError(t, err)
+
+	expired, err := testQueries.CreatePasswordResetToken(context.Background(), CreatePasswordResetTokenParams{
+		Username:  user.Username,
+		TokenHash: util.RandomString(64),
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		TokenHash:      expired.TokenHash,
+		HashedPassword: "new-hash",
+	})
+	require.ErrorIs(t, err, ErrInvalidResetToken)
+
+	result, err := store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		TokenHash:      resetToken.TokenHash,
+		HashedPassword: "new-hash",
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.Username, result.User.Username)
+	require.Equal(t, "new-hash", result.User.HashedPassword)
+
+	// Tokens are single-use.
+	_, err = store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		TokenHash:      resetToken.TokenHash,
+		HashedPassword: "another-hash",
+	})
+	require.ErrorIs(t, err, ErrInvalidResetToken)
+}
+
+func TestTransferTxEmailNotVerified(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	accountFrom, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  100,
+		Currency: util.USD,
+		Type:     AccountTypeChecking,
+	})
+	require.NoError(t, err)
+
+	accountTo := createRandomAccount(t)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: accountFrom.ID,
+		ToAccountID:   accountTo.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrEmailNotVerified)
+
+	updatedFrom, err := testQueries.GetAccount(context.Background(), accountFrom.ID)
+	require.NoError(t, err)
+	require.Equal(t, accountFrom.Balance, updatedFrom.Balance)
+}
+
+func TestVerifyEmailTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	verifyEmail, err := testQueries.CreateVerifyEmail(context.Background(), CreateVerifyEmailParams{
+		Username:  user.Username,
+		Email:     user.Email,
+		TokenHash: util.RandomString(64),
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = store.VerifyEmailTx(context.Background(), util.RandomString(64))
+	require.ErrorIs(t, err, ErrInvalidVerifyToken)
+
+	verified, err := store.VerifyEmailTx(context.Background(), verifyEmail.TokenHash)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, verified.Username)
+	require.True(t, verified.IsEmailVerified)
+
+	// Tokens are single-use.
+	_, err = store.VerifyEmailTx(context.Background(), verifyEmail.TokenHash)
+	require.ErrorIs(t, err, ErrInvalidVerifyToken)
+}
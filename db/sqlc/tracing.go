@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/shevgn/simplebank/db")
+
+// tracedDBTX starts a span for every statement sent through the wrapped DBTX.
+// Spans are named after the sqlc query, so each Querier call shows up as
+// its own span, e.g. "db.GetAccount".
+type tracedDBTX struct {
+	db DBTX
+}
+
+func newTracedDBTX(db DBTX) DBTX {
+	return &tracedDBTX{db: db}
+}
+
+func (t *tracedDBTX) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	ctx, span := startQuerySpan(ctx, sql)
+	defer span.End()
+
+	tag, err := t.db.Exec(ctx, sql, args...)
+	recordError(span, err)
+
+	return tag, err
+}
+
+func (t *tracedDBTX) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	ctx, span := startQuerySpan(ctx, sql)
+
+	rows, err := t.db.Query(ctx, sql, args...)
+	if err != nil {
+		recordError(span, err)
+		span.End()
+
+		return nil, err
+	}
+
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+func (t *tracedDBTX) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	ctx, span := startQuerySpan(ctx, sql)
+
+	return &tracedRow{row: t.db.QueryRow(ctx, sql, args...), span: span}
+}
+
+// tracedRow ends its span once the row has been scanned.
+type tracedRow struct {
+	row  pgx.Row
+	span trace.Span
+}
+
+func (r *tracedRow) Scan(dest ...any) error {
+	defer r.span.End()
+
+	err := r.row.Scan(dest...)
+	recordError(r.span, err)
+
+	return err
+}
+
+// tracedRows ends its span when the result set is closed.
+type tracedRows struct {
+	pgx.Rows
+	span trace.Span
+}
+
+func (r *tracedRows) Close() {
+	r.Rows.Close()
+	recordError(r.span, r.Rows.Err())
+	r.span.End()
+}
+
+func startQuerySpan(ctx context.Context, sql string) (context.Context, trace.Span) {
+	name := queryName(sql)
+
+	return tracer.Start(ctx, "db."+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(name),
+			semconv.DBQueryText(sql),
+		),
+	)
+}
+
+// queryName extracts the query name from the "-- name: X :kind" header sqlc
+// puts in front of every generated statement.
+func queryName(sql string) string {
+	const prefix = "-- name: "
+
+	if !strings.HasPrefix(sql, prefix) {
+		return "query"
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(sql, prefix))
+	if len(fields) == 0 {
+		return "query"
+	}
+
+	return fields[0]
+}
+
+// recordError marks the span as failed. A missing row is an expected outcome
+// of lookups, so it is not treated as an error.
+func recordError(span trace.Span, err error) {
+	if err == nil || errors.Is(err, pgx.ErrNoRows) {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
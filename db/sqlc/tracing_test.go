@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestQueryName(t *testing.T) {
+	require.Equal(t, "GetAccount", queryName(getAccount))
+	require.Equal(t, "ListAccounts", queryName(listAccounts))
+	require.Equal(t, "query", queryName("SELECT 1"))
+}
+
+// rowDBTX answers every QueryRow with the same row.
+type rowDBTX struct {
+	DBTX
+	row pgx.Row
+}
+
+func (d rowDBTX) QueryRow(context.Context, string, ...any) pgx.Row {
+	return d.row
+}
+
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...any) error {
+	return r.err
+}
+
+func TestTracedDBTX(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	queries := New(newTracedDBTX(rowDBTX{row: errRow{err: pgx.ErrNoRows}}))
+	_, err := queries.GetAccount(context.Background(), 1)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	queries = New(newTracedDBTX(rowDBTX{row: errRow{err: &pgconn.PgError{Code: UniqueViolation}}}))
+	_, err = queries.GetUser(context.Background(), "user")
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	require.Equal(t, "db.GetAccount", spans[0].Name())
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	require.Equal(t, "db.GetUser", spans[1].Name())
+	require.Equal(t, codes.Error, spans[1].Status().Code)
+}
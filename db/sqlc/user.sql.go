@@ -17,7 +17,7 @@ INSERT INTO users (
     email
 ) VALUES (
     $1, $2, $3, $4
-) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at
+) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, is_disabled, failed_login_attempts, locked_until, is_email_verified
 `
 
 type CreateUserParams struct {
@@ -42,12 +42,16 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.IsDisabled,
+		&i.FailedLoginAttempts,
+		&i.LockedUntil,
+		&i.IsEmailVerified,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT username, hashed_password, full_name, email, password_changed_at, created_at FROM users 
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, is_disabled, failed_login_attempts, locked_until, is_email_verified FROM users 
 WHERE username = $1 LIMIT 1
 `
 
@@ -61,6 +65,198 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.IsDisabled,
+		&i.FailedLoginAttempts,
+		&i.LockedUntil,
+		&i.IsEmailVerified,
+	)
+	return i, err
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, is_disabled, failed_login_attempts, locked_until, is_email_verified FROM users
+WHERE email = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.IsDisabled,
+		&i.FailedLoginAttempts,
+		&i.LockedUntil,
+		&i.IsEmailVerified,
+	)
+	return i, err
+}
+
+const recordFailedLogin = `-- name: RecordFailedLogin :one
+UPDATE users
+SET
+    failed_login_attempts = failed_login_attempts + 1,
+    locked_until = CASE
+        WHEN failed_login_attempts + 1 >= $1::integer
+        THEN now() + make_interval(secs => LEAST(
+            $2::double precision * power(2, LEAST(failed_login_attempts + 1 - $1::integer, 30)),
+            $3::double precision
+        ))
+        ELSE locked_until
+    END
+WHERE username = $4
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, is_disabled, failed_login_attempts, locked_until, is_email_verified
+`
+
+type RecordFailedLoginParams struct {
+	MaxAttempts       int32   `json:"max_attempts"`
+	LockoutSeconds    float64 `json:"lockout_seconds"`
+	MaxLockoutSeconds float64 `json:"max_lockout_seconds"`
+	Username          string  `json:"username"`
+}
+
+func (q *Queries) RecordFailedLogin(ctx context.Context, arg RecordFailedLoginParams) (User, error) {
+	row := q.db.QueryRow(ctx, recordFailedLogin,
+		arg.MaxAttempts,
+		arg.LockoutSeconds,
+		arg.MaxLockoutSeconds,
+		arg.Username,
+	)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.IsDisabled,
+		&i.FailedLoginAttempts,
+		&i.LockedUntil,
+		&i.IsEmailVerified,
+	)
+	return i, err
+}
+
+const resetFailedLogins = `-- name: ResetFailedLogins :one
+UPDATE users
+SET
+    failed_login_attempts = 0,
+    locked_until = '0001-01-01 00:00:00Z'
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, is_disabled, failed_login_attempts, locked_until, is_email_verified
+`
+
+func (q *Queries) ResetFailedLogins(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRow(ctx, resetFailedLogins, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.IsDisabled,
+		&i.FailedLoginAttempts,
+		&i.LockedUntil,
+		&i.IsEmailVerified,
+	)
+	return i, err
+}
+
+const updateUserDisabled = `-- name: UpdateUserDisabled :one
+UPDATE users
+SET is_disabled = $2
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, is_disabled, failed_login_attempts, locked_until, is_email_verified
+`
+
+type UpdateUserDisabledParams struct {
+	Username   string `json:"username"`
+	IsDisabled bool   `json:"is_disabled"`
+}
+
+func (q *Queries) UpdateUserDisabled(ctx context.Context, arg UpdateUserDisabledParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserDisabled, arg.Username, arg.IsDisabled)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.IsDisabled,
+		&i.FailedLoginAttempts,
+		&i.LockedUntil,
+		&i.IsEmailVerified,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE users
+SET
+    hashed_password = $2,
+    password_changed_at = now()
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, is_disabled, failed_login_attempts, locked_until, is_email_verified
+`
+
+type UpdateUserPasswordParams struct {
+	Username       string `json:"username"`
+	HashedPassword string `json:"hashed_password"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserPassword, arg.Username, arg.HashedPassword)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.IsDisabled,
+		&i.FailedLoginAttempts,
+		&i.LockedUntil,
+		&i.IsEmailVerified,
+	)
+	return i, err
+}
+
+const verifyUserEmail = `-- name: VerifyUserEmail :one
+UPDATE users
+SET is_email_verified = true
+WHERE username = $1 AND email = $2
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, is_disabled, failed_login_attempts, locked_until, is_email_verified
+`
+
+type VerifyUserEmailParams struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+func (q *Queries) VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (User, error) {
+	row := q.db.QueryRow(ctx, verifyUserEmail, arg.Username, arg.Email)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.IsDisabled,
+		&i.FailedLoginAttempts,
+		&i.LockedUntil,
+		&i.IsEmailVerified,
 	)
 	return i, err
 }
@@ -32,6 +32,22 @@ func createRandomUser(t *testing.T) User {
 
 	require.NotZero(t, user.CreatedAt)
 	require.True(t, user.PasswordChangedAt.Time.IsZero())
+	require.False(t, user.IsEmailVerified)
+
+	return user
+}
+
+// createRandomVerifiedUser creates a random user whose email is verified,
+// as TransferTx requires of the source account owner
+func createRandomVerifiedUser(t *testing.T) User {
+	user := createRandomUser(t)
+
+	user, err := testQueries.VerifyUserEmail(context.Background(), VerifyUserEmailParams{
+		Username: user.Username,
+		Email:    user.Email,
+	})
+	require.NoError(t, err)
+	require.True(t, user.IsEmailVerified)
 
 	return user
 }
@@ -55,3 +71,54 @@ func TestGetUser(t *testing.T) {
 	require.WithinDuration(t, user.CreatedAt.Time, got.CreatedAt.Time, time.Second)
 	require.WithinDuration(t, user.PasswordChangedAt.Time, got.PasswordChangedAt.Time, time.Second)
 }
+
+func TestRecordFailedLogin(t *testing.T) {
+	user := createRandomUser(t)
+
+	arg := RecordFailedLoginParams{
+		Username:          user.Username,
+		MaxAttempts:       2,
+		LockoutSeconds:    60,
+		MaxLockoutSeconds: 90,
+	}
+
+	user1, err := testQueries.RecordFailedLogin(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), user1.FailedLoginAttempts)
+	require.True(t, user1.LockedUntil.Time.Before(time.Now()))
+
+	user2, err := testQueries.RecordFailedLogin(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), user2.FailedLoginAttempts)
+	require.WithinDuration(t, time.Now().Add(time.Minute), user2.LockedUntil.Time, 5*time.Second)
+
+	// The lockout doubles, up to the maximum.
+	user3, err := testQueries.RecordFailedLogin(context.Background(), arg)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(90*time.Second), user3.LockedUntil.Time, 5*time.Second)
+
+	user4, err := testQueries.ResetFailedLogins(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Zero(t, user4.FailedLoginAttempts)
+	require.True(t, user4.LockedUntil.Time.Before(time.Now()))
+}
+
+func TestRecordFailedLoginManyAttempts(t *testing.T) {
+	user := createRandomUser(t)
+
+	// Enough failures that doubling the lockout for each of them would
+	// overflow, unless the exponent is capped.
+	_, err := testDB.Exec(context.Background(),
+		"UPDATE users SET failed_login_attempts = 5000 WHERE username = $1", user.Username)
+	require.NoError(t, err)
+
+	user1, err := testQueries.RecordFailedLogin(context.Background(), RecordFailedLoginParams{
+		Username:          user.Username,
+		MaxAttempts:       5,
+		LockoutSeconds:    60,
+		MaxLockoutSeconds: 3600,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(5001), user1.FailedLoginAttempts)
+	require.WithinDuration(t, time.Now().Add(time.Hour), user1.LockedUntil.Time, 5*time.Second)
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"errors"
+)
+
+// Webhook delivery statuses stored in webhook_deliveries.status
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// EnqueueWebhookDeliveriesTxParams is a set of parameters for
+// EnqueueWebhookDeliveriesTx
+type EnqueueWebhookDeliveriesTxParams struct {
+	Endpoints []WebhookEndpoint
+	EventID   int64
+	EventType string
+	Payload   []byte
+	// NewJob returns the job that delivers a newly created delivery.
+	NewJob func(delivery WebhookDelivery) (EnqueueJobParams, error)
+}
+
+// EnqueueWebhookDeliveriesTx creates a delivery of an event to each endpoint
+// together with the job that sends it, so no delivery is left without a job.
+// Endpoints that already have a delivery of the event are skipped, which
+// makes redelivered outbox events harmless. It returns the new deliveries.
+func (s *SQLStore) EnqueueWebhookDeliveriesTx(
+	ctx context.Context,
+	args EnqueueWebhookDeliveriesTxParams,
+) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+
+	err := s.execTx(ctx, "EnqueueWebhookDeliveriesTx", func(ctx context.Context, q *Queries) error {
+		deliveries = nil
+
+		for _, endpoint := range args.Endpoints {
+			delivery, err := q.CreateWebhookDelivery(ctx, CreateWebhookDeliveryParams{
+				EndpointID: endpoint.ID,
+				EventID:    args.EventID,
+				EventType:  args.EventType,
+				Payload:    args.Payload,
+			})
+			if errors.Is(err, ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			job, err := args.NewJob(delivery)
+			if err != nil {
+				return err
+			}
+
+			if _, err := q.EnqueueJob(ctx, job); err != nil {
+				return err
+			}
+
+			deliveries = append(deliveries, delivery)
+		}
+
+		return nil
+	})
+
+	return deliveries, err
+}
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shevgn/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomWebhookEndpoint(t *testing.T, owner string, eventTypes []string) WebhookEndpoint {
+	arg := CreateWebhookEndpointParams{
+		Owner:      owner,
+		Url:        "https://example.com/" + util.RandomString(8),
+		Secret:     util.RandomString(32),
+		EventTypes: eventTypes,
+	}
+
+	endpoint, err := testQueries.CreateWebhookEndpoint(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Owner, endpoint.Owner)
+	require.Equal(t, arg.Url, endpoint.Url)
+	require.Equal(t, arg.EventTypes, endpoint.EventTypes)
+
+	return endpoint
+}
+
+func TestListWebhookEndpointsForEvent(t *testing.T) {
+	user := createRandomUser(t)
+	all := createRandomWebhookEndpoint(t, user.Username, []string{})
+	credits := createRandomWebhookEndpoint(t, user.Username, []string{EventAccountCredited})
+
+	endpoints, err := testQueries.ListWebhookEndpointsForEvent(context.Background(), ListWebhookEndpointsForEventParams{
+		Owners:    []string{user.Username},
+		EventType: EventAccountDebited,
+	})
+	require.NoError(t, err)
+	require.Len(t, endpoints, 1)
+	require.Equal(t, all.ID, endpoints[0].ID)
+
+	endpoints, err = testQueries.ListWebhookEndpointsForEvent(context.Background(), ListWebhookEndpointsForEventParams{
+		Owners:    []string{user.Username},
+		EventType: EventAccountCredited,
+	})
+	require.NoError(t, err)
+	require.Len(t, endpoints, 2)
+	require.Equal(t, credits.ID, endpoints[1].ID)
+}
+
+func TestEnqueueWebhookDeliveriesTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	endpoints := []WebhookEndpoint{
+		createRandomWebhookEndpoint(t, user.Username, []string{}),
+		createRandomWebhookEndpoint(t, user.Username, []string{}),
+	}
+
+	arg := EnqueueWebhookDeliveriesTxParams{
+		Endpoints: endpoints,
+		EventID:   util.RandomInt(1, 1<<40),
+		EventType: EventAccountCredited,
+		Payload:   []byte(`{"type":"AccountCredited"}`),
+		NewJob: func(WebhookDelivery) (EnqueueJobParams, error) {
+			return EnqueueJobParams{
+				Type:        "deliver_webhook",
+				Payload:     []byte(`{}`),
+				MaxAttempts: 3,
+				RunAt:       pgtype.Timestamptz{Time: time.Now(), Valid: true},
+			}, nil
+		},
+	}
+
+	deliveries, err := store.EnqueueWebhookDeliveriesTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+
+	for i, delivery := range deliveries {
+		require.Equal(t, endpoints[i].ID, delivery.EndpointID)
+		require.Equal(t, WebhookDeliveryPending, delivery.Status)
+	}
+
+	// Redelivering the event creates nothing.
+	deliveries, err = store.EnqueueWebhookDeliveriesTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, deliveries)
+
+	delivery, err := testQueries.RecordWebhookDeliveryAttempt(context.Background(), RecordWebhookDeliveryAttemptParams{
+		ID:             latestWebhookDelivery(t, endpoints[0].ID).ID,
+		Status:         WebhookDeliverySucceeded,
+		ResponseStatus: 200,
+	})
+	require.NoError(t, err)
+	require.Equal(t, WebhookDeliverySucceeded, delivery.Status)
+	require.Equal(t, int32(1), delivery.Attempts)
+
+	// Deleting the endpoint removes its delivery log.
+	require.NoError(t, testQueries.DeleteWebhookEndpoint(context.Background(), endpoints[0].ID))
+	_, err = testQueries.GetWebhookDelivery(context.Background(), delivery.ID)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+// latestWebhookDelivery returns the newest delivery to an endpoint.
+func latestWebhookDelivery(t *testing.T, endpointID int64) WebhookDelivery {
+	deliveries, err := testQueries.ListWebhookDeliveries(context.Background(), ListWebhookDeliveriesParams{
+		EndpointID: endpointID,
+		Limit:      1,
+	})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+
+	return deliveries[0]
+}
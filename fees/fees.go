@@ -0,0 +1,95 @@
+// Package fees manages the rules fees are charged by and charges the
+// monthly account maintenance fee.
+//
+// A rule charges a flat amount plus a rate of the transfer amount, or of
+// the balance for maintenance, within an optional minimum and maximum.
+// Rules may be limited to a currency and an account type, and rules with
+// different minimum amounts form tiers; db.FeeRule.Charge and MatchFeeRule
+// describe how one is picked. Transfer fees are charged by db.TransferTx.
+package fees
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/util"
+)
+
+// Store is the subset of db.Store used to charge maintenance fees.
+type Store interface {
+	ListMaintenanceFeeAccounts(ctx context.Context, monthEnd pgtype.Timestamptz) ([]int64, error)
+	ChargeMaintenanceFeeTx(ctx context.Context, args db.ChargeMaintenanceFeeTxParams) (db.Fee, error)
+}
+
+// ValidateRule checks a rule before it is created.
+func ValidateRule(rule db.CreateFeeRuleParams) error {
+	switch {
+	case rule.Event != db.FeeEventTransfer && rule.Event != db.FeeEventMaintenance:
+		return fmt.Errorf("event must be %s or %s", db.FeeEventTransfer, db.FeeEventMaintenance)
+	case rule.Currency != "" && !util.IsSupportedCurrency(rule.Currency):
+		return fmt.Errorf("currency %q is not supported", rule.Currency)
+	case rule.AccountType != "" && !slices.Contains(db.AccountTypes, rule.AccountType):
+		return fmt.Errorf("%q is not an account type", rule.AccountType)
+	case rule.MinAmount < 0 || rule.Flat < 0 || rule.MinFee < 0 || rule.MaxFee < 0:
+		return errors.New("amounts cannot be negative")
+	case rule.MaxFee > 0 && rule.MaxFee < rule.MinFee:
+		return errors.New("maximum fee is below the minimum fee")
+	}
+
+	if _, err := util.ParseRate(rule.Rate); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Failure is an account whose maintenance fee could not be charged.
+type Failure struct {
+	AccountID int64  `json:"account_id"`
+	Error     string `json:"error"`
+}
+
+// Maintenance is the outcome of charging maintenance fees for a month.
+type Maintenance struct {
+	Month time.Time `json:"month"`
+	// Charged is the number of accounts a rule applied to, including those
+	// whose fee was 0.
+	Charged int `json:"charged"`
+	// Skipped is the number of accounts already charged for the month.
+	Skipped  int       `json:"skipped"`
+	Failures []Failure `json:"failures"`
+}
+
+// ChargeMaintenance charges every open customer account the maintenance fee
+// for the UTC month of month. An account that fails, for example because
+// it is frozen, is reported and left for a later run; the others are still
+// charged. The returned error is only set when the accounts cannot be
+// listed.
+func ChargeMaintenance(ctx context.Context, store Store, month time.Time) (Maintenance, error) {
+	month = util.StartOfMonth(month)
+	maintenance := Maintenance{Month: month}
+
+	ids, err := store.ListMaintenanceFeeAccounts(ctx, pgtype.Timestamptz{Time: month.AddDate(0, 1, 0), Valid: true})
+	if err != nil {
+		return maintenance, fmt.Errorf("cannot list accounts: %w", err)
+	}
+
+	for _, id := range ids {
+		fee, err := store.ChargeMaintenanceFeeTx(ctx, db.ChargeMaintenanceFeeTxParams{AccountID: id, Month: month})
+		switch {
+		case errors.Is(err, db.ErrFeeAlreadyCharged):
+			maintenance.Skipped++
+		case err != nil:
+			maintenance.Failures = append(maintenance.Failures, Failure{AccountID: id, Error: err.Error()})
+		case fee.ID != 0:
+			maintenance.Charged++
+		}
+	}
+
+	return maintenance, nil
+}
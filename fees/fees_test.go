@@ -0,0 +1,132 @@
+package fees
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCharge(t *testing.T) {
+	testCases := []struct {
+		name   string
+		rule   db.FeeRule
+		amount int64
+		fee    int64
+	}{
+		{name: "Flat", rule: db.FeeRule{Flat: 50, Rate: "0"}, amount: 100000, fee: 50},
+		// 0.5% of 12345 is 61.725
+		{name: "Percentage", rule: db.FeeRule{Rate: "0.005"}, amount: 12345, fee: 61},
+		{name: "FlatAndPercentage", rule: db.FeeRule{Flat: 25, Rate: "0.01"}, amount: 1000, fee: 35},
+		{name: "MinFee", rule: db.FeeRule{Rate: "0.01", MinFee: 100}, amount: 1000, fee: 100},
+		{name: "MaxFee", rule: db.FeeRule{Rate: "0.01", MaxFee: 500}, amount: 1000000, fee: 500},
+		{name: "NoMaxFee", rule: db.FeeRule{Rate: "0.01"}, amount: 1000000, fee: 10000},
+		{name: "Waived", rule: db.FeeRule{Rate: "0"}, amount: 1000000, fee: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fee, err := tc.rule.Charge(tc.amount)
+			require.NoError(t, err)
+			require.Equal(t, tc.fee, fee)
+		})
+	}
+
+	_, err := db.FeeRule{Rate: "one percent"}.Charge(1000)
+	require.Error(t, err)
+}
+
+func TestValidateRule(t *testing.T) {
+	valid := db.CreateFeeRuleParams{
+		Event:       db.FeeEventTransfer,
+		Currency:    util.USD,
+		AccountType: db.AccountTypeChecking,
+		MinAmount:   100000,
+		Flat:        25,
+		Rate:        "0.001",
+		MinFee:      25,
+		MaxFee:      1000,
+	}
+	require.NoError(t, ValidateRule(valid))
+
+	anyAccount := valid
+	anyAccount.Currency = ""
+	anyAccount.AccountType = ""
+	require.NoError(t, ValidateRule(anyAccount))
+
+	for name, change := range map[string]func(*db.CreateFeeRuleParams){
+		"Event":       func(r *db.CreateFeeRuleParams) { r.Event = "withdrawal" },
+		"Currency":    func(r *db.CreateFeeRuleParams) { r.Currency = "GBP" },
+		"AccountType": func(r *db.CreateFeeRuleParams) { r.AccountType = "brokerage" },
+		"Flat":        func(r *db.CreateFeeRuleParams) { r.Flat = -1 },
+		"Rate":        func(r *db.CreateFeeRuleParams) { r.Rate = "1%" },
+		"MaxFee":      func(r *db.CreateFeeRuleParams) { r.MaxFee = 10 },
+	} {
+		t.Run(name, func(t *testing.T) {
+			rule := valid
+			change(&rule)
+			require.Error(t, ValidateRule(rule))
+		})
+	}
+}
+
+func TestChargeMaintenance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	month := time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)
+
+	store.EXPECT().
+		ListMaintenanceFeeAccounts(gomock.Any(), gomock.Eq(pgtype.Timestamptz{
+			Time:  time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+			Valid: true,
+		})).
+		Times(1).
+		Return([]int64{1, 2, 3, 4}, nil)
+
+	for id, result := range map[int64]struct {
+		fee db.Fee
+		err error
+	}{
+		1: {fee: db.Fee{ID: 10, AccountID: 1, Amount: 500}},
+		2: {err: db.ErrFeeAlreadyCharged},
+		3: {err: db.ErrAccountFrozen},
+		// No rule applies to account 4.
+		4: {},
+	} {
+		store.EXPECT().
+			ChargeMaintenanceFeeTx(gomock.Any(), gomock.Eq(db.ChargeMaintenanceFeeTxParams{AccountID: id, Month: month})).
+			Times(1).
+			Return(result.fee, result.err)
+	}
+
+	maintenance, err := ChargeMaintenance(context.Background(), store, time.Date(2025, time.February, 20, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, Maintenance{
+		Month:    month,
+		Charged:  1,
+		Skipped:  1,
+		Failures: []Failure{{AccountID: 3, Error: db.ErrAccountFrozen.Error()}},
+	}, maintenance)
+}
+
+func TestChargeMaintenanceListFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		ListMaintenanceFeeAccounts(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil, errors.New("connection reset"))
+	store.EXPECT().ChargeMaintenanceFeeTx(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := ChargeMaintenance(context.Background(), store, time.Now())
+	require.Error(t, err)
+}
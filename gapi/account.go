@@ -0,0 +1,212 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// getOwnedAccount loads an account and makes sure it belongs to the caller.
+func (s *Server) getOwnedAccount(ctx context.Context, id int64) (db.Account, error) {
+	account, err := s.store.GetAccount(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return account, status.Errorf(codes.NotFound, "account %d not found", id)
+		}
+
+		return account, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	if account.Owner != authPayload(ctx).Username {
+		return account, status.Errorf(codes.PermissionDenied, "account does not belong to the user")
+	}
+
+	return account, nil
+}
+
+// CreateAccount opens a new account for the caller.
+func (s *Server) CreateAccount(
+	ctx context.Context,
+	req *pb.CreateAccountRequest,
+) (*pb.CreateAccountResponse, error) {
+	if violations := validateCreateAccountRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	accountType := req.GetType()
+	if accountType == "" {
+		accountType = db.AccountTypeChecking
+	}
+
+	arg := db.CreateAccountParams{
+		Owner:    authPayload(ctx).Username,
+		Currency: req.GetCurrency(),
+		Balance:  0,
+		Type:     accountType,
+		Nickname: req.GetNickname(),
+	}
+
+	account, err := s.store.CreateAccount(ctx, arg)
+	if err != nil {
+		switch db.ErrorCode(err) {
+		case db.UniqueViolation:
+			return nil, status.Errorf(codes.AlreadyExists, "an account named %q already exists", req.GetNickname())
+		case db.ForeignKeyViolation:
+			return nil, status.Errorf(codes.FailedPrecondition, "owner does not exist: %s", err)
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to create account: %s", err)
+	}
+
+	return &pb.CreateAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func validateCreateAccountRequest(req *pb.CreateAccountRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	if req.GetType() != "" {
+		if err := validateAccountType(req.GetType()); err != nil {
+			violations = append(violations, fieldViolation("type", err))
+		}
+	}
+
+	if err := validateNickname(req.GetNickname()); err != nil {
+		violations = append(violations, fieldViolation("nickname", err))
+	}
+
+	return violations
+}
+
+// GetAccount returns one of the caller's accounts.
+func (s *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	if err := validateID(req.GetId()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("id", err)})
+	}
+
+	account, err := s.getOwnedAccount(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetAccountResponse{Account: convertAccount(account)}, nil
+}
+
+// ListAccounts returns a page of the caller's accounts.
+func (s *Server) ListAccounts(
+	ctx context.Context,
+	req *pb.ListAccountsRequest,
+) (*pb.ListAccountsResponse, error) {
+	if violations := validateListAccountsRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	arg := db.ListAccountsParams{
+		Owner:    authPayload(ctx).Username,
+		Type:     req.GetType(),
+		Currency: req.GetCurrency(),
+		Limit:    req.GetPageSize(),
+		Offset:   (req.GetPageId() - 1) * req.GetPageSize(),
+	}
+
+	accounts, err := s.store.ListAccounts(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list accounts: %s", err)
+	}
+
+	response := &pb.ListAccountsResponse{Accounts: make([]*pb.Account, 0, len(accounts))}
+	for _, account := range accounts {
+		response.Accounts = append(response.Accounts, convertAccount(account))
+	}
+
+	return response, nil
+}
+
+func validateListAccountsRequest(req *pb.ListAccountsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetPageId() < 1 {
+		violations = append(violations, fieldViolation("page_id", errors.New("must be at least 1")))
+	}
+
+	if req.GetPageSize() < 5 || req.GetPageSize() > 10 {
+		violations = append(violations, fieldViolation("page_size", errors.New("must be between 5 and 10")))
+	}
+
+	if req.GetType() != "" {
+		if err := validateAccountType(req.GetType()); err != nil {
+			violations = append(violations, fieldViolation("type", err))
+		}
+	}
+
+	if req.GetCurrency() != "" {
+		if err := validateCurrency(req.GetCurrency()); err != nil {
+			violations = append(violations, fieldViolation("currency", err))
+		}
+	}
+
+	return violations
+}
+
+// DeleteAccount closes one of the caller's accounts. The account and its
+// history are kept.
+func (s *Server) DeleteAccount(ctx context.Context, req *pb.DeleteAccountRequest) (*emptypb.Empty, error) {
+	if _, err := s.changeAccountStatus(ctx, req.GetId(), db.AccountStatusActive, db.AccountStatusClosed); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// CloseAccount closes one of the caller's accounts, which must be active and
+// empty.
+func (s *Server) CloseAccount(ctx context.Context, req *pb.CloseAccountRequest) (*pb.CloseAccountResponse, error) {
+	account, err := s.changeAccountStatus(ctx, req.GetId(), db.AccountStatusActive, db.AccountStatusClosed)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CloseAccountResponse{Account: convertAccount(account)}, nil
+}
+
+// ReopenAccount makes one of the caller's closed accounts active again.
+func (s *Server) ReopenAccount(ctx context.Context, req *pb.ReopenAccountRequest) (*pb.ReopenAccountResponse, error) {
+	account, err := s.changeAccountStatus(ctx, req.GetId(), db.AccountStatusClosed, db.AccountStatusActive)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ReopenAccountResponse{Account: convertAccount(account)}, nil
+}
+
+// changeAccountStatus moves one of the caller's accounts from one status to
+// another. Freezing is left to the admin CLI.
+func (s *Server) changeAccountStatus(ctx context.Context, id int64, from, to string) (db.Account, error) {
+	if err := validateID(id); err != nil {
+		return db.Account{}, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("id", err)})
+	}
+
+	if _, err := s.getOwnedAccount(ctx, id); err != nil {
+		return db.Account{}, err
+	}
+
+	account, err := s.store.UpdateAccountStatusTx(ctx, db.UpdateAccountStatusTxParams{
+		ID:     id,
+		From:   from,
+		Status: to,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidStatusTransition) || errors.Is(err, db.ErrAccountNotEmpty) {
+			return account, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+
+		return account, status.Errorf(codes.Internal, "failed to change account status: %s", err)
+	}
+
+	return account, nil
+}
@@ -0,0 +1,237 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/pb"
+	"github.com/shevgn/simplebank/token"
+	"github.com/shevgn/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateAccount(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username, util.USD)
+
+	testCases := []struct {
+		name          string
+		req           *pb.CreateAccountRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.CreateAccountResponse, err error)
+	}{
+		{
+			name: "DefaultType",
+			req:  &pb.CreateAccountRequest{Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Eq(db.CreateAccountParams{
+						Owner:    user.Username,
+						Currency: util.USD,
+						Type:     db.AccountTypeChecking,
+					})).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, db.AccountTypeChecking, res.GetAccount().GetType())
+			},
+		},
+		{
+			name: "SavingsWithNickname",
+			req:  &pb.CreateAccountRequest{Currency: util.USD, Type: db.AccountTypeSavings, Nickname: "Holidays"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Eq(db.CreateAccountParams{
+						Owner:    user.Username,
+						Currency: util.USD,
+						Type:     db.AccountTypeSavings,
+						Nickname: "Holidays",
+					})).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "NicknameTaken",
+			req:  &pb.CreateAccountRequest{Currency: util.USD, Nickname: "Holidays"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, &pgconn.PgError{Code: db.UniqueViolation})
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				require.Equal(t, codes.AlreadyExists, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidType",
+			req:  &pb.CreateAccountRequest{Currency: util.USD, Type: "brokerage"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, time.Minute)
+
+			res, err := invoke(ctx, server, pb.SimpleBank_CreateAccount_FullMethodName, tc.req, server.CreateAccount)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestCloseAccount(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+
+	account := randomAccount(user.Username, util.USD)
+	account.Balance = 0
+
+	closed := account
+	closed.Status = db.AccountStatusClosed
+
+	closeParams := db.UpdateAccountStatusTxParams{
+		ID:     account.ID,
+		From:   db.AccountStatusActive,
+		Status: db.AccountStatusClosed,
+	}
+
+	testCases := []struct {
+		name          string
+		req           *pb.CloseAccountRequest
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.CloseAccountResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.CloseAccountRequest{Id: account.ID},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Eq(closeParams)).
+					Times(1).
+					Return(closed, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.CloseAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, db.AccountStatusClosed, res.GetAccount().GetStatus())
+			},
+		},
+		{
+			name: "NotEmpty",
+			req:  &pb.CloseAccountRequest{Id: account.ID},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Eq(closeParams)).
+					Times(1).
+					Return(db.Account{}, db.ErrAccountNotEmpty)
+			},
+			checkResponse: func(t *testing.T, res *pb.CloseAccountResponse, err error) {
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "NotOwner",
+			req:  &pb.CloseAccountRequest{Id: account.ID},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, other.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpdateAccountStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CloseAccountResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidID",
+			req:  &pb.CloseAccountRequest{Id: 0},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CloseAccountResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := tc.buildContext(t, server.tokenMaker)
+
+			res, err := invoke(ctx, server, pb.SimpleBank_CloseAccount_FullMethodName, tc.req, server.CloseAccount)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestReopenAccount(t *testing.T) {
+	user, _ := randomUser(t)
+
+	account := randomAccount(user.Username, util.USD)
+	account.Balance = 0
+
+	closed := account
+	closed.Status = db.AccountStatusClosed
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(closed, nil)
+	store.EXPECT().
+		UpdateAccountStatusTx(gomock.Any(), gomock.Eq(db.UpdateAccountStatusTxParams{
+			ID:     account.ID,
+			From:   db.AccountStatusClosed,
+			Status: db.AccountStatusActive,
+		})).
+		Times(1).
+		Return(account, nil)
+
+	server := newTestServer(t, store)
+	ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, time.Minute)
+
+	res, err := invoke(ctx, server, pb.SimpleBank_ReopenAccount_FullMethodName,
+		&pb.ReopenAccountRequest{Id: account.ID}, server.ReopenAccount)
+	require.NoError(t, err)
+	require.Equal(t, db.AccountStatusActive, res.GetAccount().GetStatus())
+}
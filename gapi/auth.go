@@ -0,0 +1,136 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/shevgn/simplebank/pb"
+	"github.com/shevgn/simplebank/token"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	authorizationHeaderKey  = "authorization"
+	authorizationTypeBearer = "bearer"
+)
+
+// publicMethods can be called without an access token.
+var publicMethods = map[string]bool{
+	pb.SimpleBank_CreateUser_FullMethodName:           true,
+	pb.SimpleBank_LoginUser_FullMethodName:            true,
+	pb.SimpleBank_RequestPasswordReset_FullMethodName: true,
+	pb.SimpleBank_ResetPassword_FullMethodName:        true,
+	pb.SimpleBank_VerifyEmail_FullMethodName:          true,
+	pb.SimpleBank_RenewAccessToken_FullMethodName:     true,
+}
+
+var tracer = otel.Tracer("github.com/shevgn/simplebank/gapi")
+
+type authPayloadKey struct{}
+
+// AuthInterceptor verifies the bearer access token of every non-public call
+// and stores its payload in the request context. Requests coming through the
+// HTTP gateway carry the Authorization header as metadata, so both transports
+// share this check.
+func (s *Server) AuthInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	if publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	_, span := tracer.Start(ctx, "AuthInterceptor")
+
+	payload, err := s.authorizeUser(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
+
+		return nil, unauthenticatedError(err)
+	}
+
+	span.SetAttributes(semconv.EnduserID(payload.Username))
+	span.End()
+
+	ctx = setLogUsername(ctx, payload.Username)
+
+	return handler(context.WithValue(ctx, authPayloadKey{}, payload), req)
+}
+
+// StreamAuthInterceptor does for streaming calls what AuthInterceptor does
+// for unary ones.
+func (s *Server) StreamAuthInterceptor(
+	srv any,
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	if publicMethods[info.FullMethod] {
+		return handler(srv, stream)
+	}
+
+	ctx := stream.Context()
+
+	payload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return unauthenticatedError(err)
+	}
+
+	return handler(srv, &authorizedStream{
+		ServerStream: stream,
+		ctx:          context.WithValue(ctx, authPayloadKey{}, payload),
+	})
+}
+
+// authorizedStream replaces the context of a stream with one that carries
+// the auth payload.
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *Server) authorizeUser(ctx context.Context) (*token.Payload, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("missing metadata")
+	}
+
+	values := md.Get(authorizationHeaderKey)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("missing authorization header")
+	}
+
+	fields := strings.Fields(values[0])
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("invalid authorization header")
+	}
+
+	if !strings.EqualFold(fields[0], authorizationTypeBearer) {
+		return nil, fmt.Errorf("invalid authorization type")
+	}
+
+	payload, err := s.tokenMaker.VerifyToken(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid access token: %w", err)
+	}
+
+	return payload, nil
+}
+
+// authPayload returns the payload stored by AuthInterceptor.
+func authPayload(ctx context.Context) *token.Payload {
+	return ctx.Value(authPayloadKey{}).(*token.Payload)
+}
@@ -0,0 +1,94 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shevgn/simplebank/pb"
+	"github.com/shevgn/simplebank/token"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthInterceptor(t *testing.T) {
+	testCases := []struct {
+		name         string
+		method       string
+		buildContext func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResult  func(t *testing.T, payload *token.Payload, err error)
+	}{
+		{
+			name:   "OK",
+			method: pb.SimpleBank_GetAccount_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, "user", time.Minute)
+			},
+			checkResult: func(t *testing.T, payload *token.Payload, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "user", payload.Username)
+			},
+		},
+		{
+			name:   "PublicMethod",
+			method: pb.SimpleBank_LoginUser_FullMethodName,
+			buildContext: func(_ *testing.T, _ token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResult: func(t *testing.T, payload *token.Payload, err error) {
+				require.NoError(t, err)
+				require.Nil(t, payload)
+			},
+		},
+		{
+			name:   "NoAuthorization",
+			method: pb.SimpleBank_GetAccount_FullMethodName,
+			buildContext: func(_ *testing.T, _ token.Maker) context.Context {
+				return metadata.NewIncomingContext(context.Background(), metadata.MD{})
+			},
+			checkResult: func(t *testing.T, _ *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:   "UnsupportedAuthorizationType",
+			method: pb.SimpleBank_GetAccount_FullMethodName,
+			buildContext: func(_ *testing.T, _ token.Maker) context.Context {
+				md := metadata.Pairs(authorizationHeaderKey, "basic dXNlcjpwYXNz")
+				return metadata.NewIncomingContext(context.Background(), md)
+			},
+			checkResult: func(t *testing.T, _ *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:   "ExpiredToken",
+			method: pb.SimpleBank_GetAccount_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, "user", -time.Minute)
+			},
+			checkResult: func(t *testing.T, _ *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil)
+
+			var payload *token.Payload
+			handler := func(ctx context.Context, _ any) (any, error) {
+				payload, _ = ctx.Value(authPayloadKey{}).(*token.Payload)
+				return nil, nil
+			}
+
+			ctx := tc.buildContext(t, server.tokenMaker)
+			_, err := server.AuthInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+			tc.checkResult(t, payload, err)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package gapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/shevgn/simplebank/bulk"
+	"github.com/shevgn/simplebank/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateBulkTransfer executes a batch of transfers from a CSV or pain.001
+// file and reports the outcome of each.
+func (s *Server) CreateBulkTransfer(
+	ctx context.Context,
+	req *pb.CreateBulkTransferRequest,
+) (*pb.CreateBulkTransferResponse, error) {
+	if violations := validateCreateBulkTransferRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	mode := bulk.Mode(req.GetMode())
+	if mode == "" {
+		mode = bulk.ModeAtomic
+	}
+
+	instructions, err := bulk.Parse(req.GetFormat(), bytes.NewReader(req.GetFile()))
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("file", err)})
+	}
+
+	batch, err := bulk.Execute(ctx, s.store, authPayload(ctx).Username, instructions, mode)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to execute batch: %s", err)
+	}
+
+	return convertBulkTransferBatch(batch), nil
+}
+
+func validateCreateBulkTransferRequest(
+	req *pb.CreateBulkTransferRequest,
+) (violations []*errdetails.BadRequest_FieldViolation) {
+	if len(req.GetFile()) == 0 {
+		violations = append(violations, fieldViolation("file", errors.New("must not be empty")))
+	}
+
+	switch req.GetFormat() {
+	case bulk.FormatCSV, bulk.FormatPain001:
+	default:
+		violations = append(violations, fieldViolation("format", errors.New("must be csv or pain.001")))
+	}
+
+	switch bulk.Mode(req.GetMode()) {
+	case "", bulk.ModeAtomic, bulk.ModeBestEffort:
+	default:
+		violations = append(violations, fieldViolation("mode", errors.New("must be atomic or best_effort")))
+	}
+
+	return violations
+}
@@ -0,0 +1,96 @@
+package gapi
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/shevgn/simplebank/bulk"
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/pb"
+	"github.com/shevgn/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateBulkTransfer(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username, util.USD)
+	account2 := randomAccount(user2.Username, util.USD)
+
+	file := fmt.Sprintf("from_account_id,to_account_id,amount,currency\n%d,%d,12.50,USD\n", account1.ID, account2.ID)
+
+	testCases := []struct {
+		name          string
+		req           *pb.CreateBulkTransferRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.CreateBulkTransferResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.CreateBulkTransferRequest{File: []byte(file), Format: bulk.FormatCSV},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().IsSystemAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(false, nil)
+
+				arg := []db.TransferTxParams{{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 1250}}
+				store.EXPECT().
+					BulkTransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.TransferTxResult{{Transfer: db.Transfer{ID: 7, Amount: 1250}}}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateBulkTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, string(bulk.ModeAtomic), res.GetMode())
+				require.Equal(t, bulk.BatchCompleted, res.GetStatus())
+				require.Len(t, res.GetResults(), 1)
+				require.Equal(t, int64(7), res.GetResults()[0].GetTransfer().GetId())
+			},
+		},
+		{
+			name: "InvalidFile",
+			req:  &pb.CreateBulkTransferRequest{File: []byte("amount\n1\n"), Format: bulk.FormatCSV},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *pb.CreateBulkTransferResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidArguments",
+			req:  &pb.CreateBulkTransferRequest{Format: "xlsx", Mode: "eventually"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *pb.CreateBulkTransferResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+				details := status.Convert(err).Details()
+				require.Len(t, details, 1)
+				require.Len(t, details[0].(*errdetails.BadRequest).GetFieldViolations(), 3)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, user1.Username, time.Minute)
+
+			res, err := invoke(ctx, server, pb.SimpleBank_CreateBulkTransfer_FullMethodName, tc.req, server.CreateBulkTransfer)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package gapi
+
+import (
+	"github.com/shevgn/simplebank/bulk"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func convertUser(user db.User) *pb.User {
+	return &pb.User{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		IsEmailVerified:   user.IsEmailVerified,
+		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt.Time),
+		CreatedAt:         timestamppb.New(user.CreatedAt.Time),
+	}
+}
+
+func convertAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Id:        account.ID,
+		Owner:     account.Owner,
+		Balance:   account.Balance,
+		Currency:  account.Currency,
+		CreatedAt: timestamppb.New(account.CreatedAt.Time),
+		Status:    account.Status,
+		ClosedAt:  timestamppb.New(account.ClosedAt.Time),
+		Type:      account.Type,
+		Nickname:  account.Nickname,
+	}
+}
+
+func convertAccountEvent(n db.AccountNotification) *pb.AccountEvent {
+	return &pb.AccountEvent{
+		Type:       n.Type,
+		AccountId:  n.AccountID,
+		EntryId:    n.EntryID,
+		TransferId: n.TransferID,
+		Amount:     n.Amount,
+		Balance:    n.Balance,
+		Currency:   n.Currency,
+		CreatedAt:  timestamppb.New(n.CreatedAt),
+	}
+}
+
+func convertTransfer(transfer db.Transfer) *pb.Transfer {
+	return &pb.Transfer{
+		Id:            transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt.Time),
+	}
+}
+
+func convertEntry(entry db.Entry) *pb.Entry {
+	return &pb.Entry{
+		Id:        entry.ID,
+		AccountId: entry.AccountID,
+		Amount:    entry.Amount,
+		CreatedAt: timestamppb.New(entry.CreatedAt.Time),
+	}
+}
+
+func convertFee(fee db.Fee) *pb.Fee {
+	return &pb.Fee{
+		Id:                fee.ID,
+		AccountId:         fee.AccountID,
+		Event:             fee.Event,
+		RuleId:            fee.RuleID,
+		Amount:            fee.Amount,
+		TransferId:        fee.TransferID,
+		ChargedTransferId: fee.ChargedTransferID,
+		CreatedAt:         timestamppb.New(fee.CreatedAt.Time),
+	}
+}
+
+func convertWebhookEndpoint(endpoint db.WebhookEndpoint) *pb.WebhookEndpoint {
+	return &pb.WebhookEndpoint{
+		Id:         endpoint.ID,
+		Url:        endpoint.Url,
+		EventTypes: endpoint.EventTypes,
+		CreatedAt:  timestamppb.New(endpoint.CreatedAt.Time),
+	}
+}
+
+func convertWebhookDelivery(delivery db.WebhookDelivery) *pb.WebhookDelivery {
+	return &pb.WebhookDelivery{
+		Id:             delivery.ID,
+		EndpointId:     delivery.EndpointID,
+		EventId:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Payload:        string(delivery.Payload),
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		CreatedAt:      timestamppb.New(delivery.CreatedAt.Time),
+		UpdatedAt:      timestamppb.New(delivery.UpdatedAt.Time),
+	}
+}
+
+func convertBulkTransferBatch(batch bulk.Batch) *pb.CreateBulkTransferResponse {
+	results := make([]*pb.BulkTransferResult, len(batch.Results))
+	for i, result := range batch.Results {
+		results[i] = &pb.BulkTransferResult{
+			Number:        int32(result.Number),
+			FromAccountId: result.FromAccountID,
+			ToAccountId:   result.ToAccountID,
+			Amount:        result.Amount,
+			Currency:      result.Currency,
+			Reference:     result.Reference,
+			Status:        result.Status,
+			Error:         result.Error,
+		}
+
+		if result.Transfer != nil {
+			results[i].Transfer = convertTransfer(*result.Transfer)
+		}
+	}
+
+	return &pb.CreateBulkTransferResponse{
+		Mode:      string(batch.Mode),
+		Status:    batch.Status,
+		Succeeded: int32(batch.Succeeded),
+		Failed:    int32(batch.Failed),
+		Results:   results,
+	}
+}
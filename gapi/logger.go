@@ -0,0 +1,88 @@
+package gapi
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/shevgn/simplebank/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDMetadataKey is the metadata form of logging.RequestIDHeader.
+var requestIDMetadataKey = strings.ToLower(logging.RequestIDHeader)
+
+type requestLogKey struct{}
+
+// requestLog collects details learned while serving a call, such as the
+// authenticated user, for the access log line written once it completes.
+type requestLog struct {
+	username string
+}
+
+// LoggerInterceptor assigns each call a request ID, taken from the
+// x-request-id metadata when present, returns it in the response header and
+// writes one structured log line per call.
+func LoggerInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDMetadataKey); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+
+	requestID = logging.RequestIDOrNew(requestID)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadataKey, requestID))
+
+	entry := &requestLog{}
+	ctx = logging.WithRequestID(ctx, requestID)
+	ctx = context.WithValue(ctx, requestLogKey{}, entry)
+
+	start := time.Now()
+	res, err := handler(ctx, req)
+
+	code := status.Code(err)
+	attrs := []slog.Attr{
+		slog.String("method", info.FullMethod),
+		slog.String("status_code", code.String()),
+		slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+	}
+	if entry.username != "" {
+		attrs = append(attrs, slog.String("username", entry.username))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+	}
+
+	level := slog.LevelInfo
+	switch code {
+	case codes.OK:
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		level = slog.LevelError
+	default:
+		level = slog.LevelWarn
+	}
+
+	slog.Default().LogAttrs(ctx, level, "grpc request", attrs...)
+
+	return res, err
+}
+
+// setLogUsername records the authenticated user for the access log and for
+// every record logged with ctx.
+func setLogUsername(ctx context.Context, username string) context.Context {
+	if entry, ok := ctx.Value(requestLogKey{}).(*requestLog); ok {
+		entry.username = username
+	}
+
+	return logging.WithUsername(ctx, username)
+}
@@ -0,0 +1,50 @@
+package gapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/shevgn/simplebank/logging"
+	"github.com/shevgn/simplebank/pb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestLoggerInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&buf, slog.LevelInfo))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	server := newTestServer(t, nil)
+
+	ctx := newContextWithBearerToken(t, server.tokenMaker, "alice", time.Minute)
+	md, _ := metadata.FromIncomingContext(ctx)
+	md = metadata.Join(md, metadata.Pairs(requestIDMetadataKey, "req-7"))
+	ctx = metadata.NewIncomingContext(ctx, md)
+
+	info := &grpc.UnaryServerInfo{FullMethod: pb.SimpleBank_GetAccount_FullMethodName}
+	handler := func(ctx context.Context, req any) (any, error) {
+		require.Equal(t, "req-7", logging.RequestID(ctx))
+		require.Equal(t, "alice", logging.Username(ctx))
+
+		return nil, nil
+	}
+
+	_, err := LoggerInterceptor(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+		return server.AuthInterceptor(ctx, req, info, handler)
+	})
+	require.NoError(t, err)
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Equal(t, "req-7", line["request_id"])
+	require.Equal(t, "alice", line["username"])
+	require.Equal(t, "OK", line["status_code"])
+	require.Equal(t, pb.SimpleBank_GetAccount_FullMethodName, line["method"])
+}
@@ -0,0 +1,86 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/notify"
+	"github.com/shevgn/simplebank/ratelimit"
+	"github.com/shevgn/simplebank/token"
+	"github.com/shevgn/simplebank/util"
+	"github.com/shevgn/simplebank/worker"
+	mockworker "github.com/shevgn/simplebank/worker/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	config := &util.Config{
+		TokenSymmetricKey:       util.RandomString(32),
+		AccessTokenDuration:     time.Minute,
+		RefreshTokenDuration:    time.Hour,
+		LoginRateLimitPerIP:     100,
+		LoginRateLimitPerUser:   100,
+		LoginRateLimitPeriod:    time.Minute,
+		LoginMaxFailedAttempts:  5,
+		LoginLockoutDuration:    time.Minute,
+		LoginMaxLockoutDuration: time.Hour,
+		PasswordResetURL:        "http://localhost:8080/reset_password",
+		PasswordResetDuration:   time.Hour,
+	}
+
+	server, err := NewServer(config, store, ratelimit.NewMemoryLimiter(), newTestDistributor(t), notify.NewHub())
+	require.NoError(t, err)
+
+	return server
+}
+
+// newTestDistributor accepts every task without running it.
+func newTestDistributor(t *testing.T) worker.Distributor {
+	distributor := mockworker.NewMockDistributor(gomock.NewController(t))
+	distributor.EXPECT().Enqueue(gomock.Any(), gomock.Any()).AnyTimes()
+
+	return distributor
+}
+
+func newContextWithBearerToken(
+	t *testing.T,
+	tokenMaker token.Maker,
+	username string,
+	duration time.Duration,
+) context.Context {
+	accessToken, _, err := tokenMaker.CreateToken(username, duration)
+	require.NoError(t, err)
+
+	md := metadata.MD{
+		authorizationHeaderKey: []string{fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken)},
+	}
+
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+// invoke runs call behind the auth interceptor, as the gRPC server would.
+func invoke[Req, Res any](
+	ctx context.Context,
+	server *Server,
+	method string,
+	req Req,
+	call func(context.Context, Req) (Res, error),
+) (Res, error) {
+	handler := func(ctx context.Context, req any) (any, error) {
+		return call(ctx, req.(Req))
+	}
+
+	res, err := server.AuthInterceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	if err != nil {
+		var zero Res
+		return zero, err
+	}
+
+	return res.(Res), nil
+}
@@ -0,0 +1,141 @@
+package gapi
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"net"
+	"net/netip"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	grpcGatewayUserAgentHeader = "grpcgateway-user-agent"
+	userAgentHeader            = "user-agent"
+	xForwardedForHeader        = "x-forwarded-for"
+	gatewayKeyHeader           = "x-simplebank-gateway-key"
+)
+
+// gatewayKey is sent by the HTTP gateway running in this process, so the
+// server can tell its calls apart from anyone else's. It is never shared
+// with clients, which therefore cannot pass themselves off as the gateway.
+var gatewayKey = rand.Text()
+
+// Metadata describes the client that issued a request.
+type Metadata struct {
+	UserAgent string
+	ClientIP  string
+}
+
+// extractMetadata reads the client details from either a direct gRPC call
+// or a call forwarded by the HTTP gateway. The client IP is the address of
+// the connection's peer, unless the peer is the gateway or a trusted proxy;
+// only then is X-Forwarded-For believed.
+func (s *Server) extractMetadata(ctx context.Context) *Metadata {
+	mtdt := &Metadata{}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if userAgents := md.Get(grpcGatewayUserAgentHeader); len(userAgents) > 0 {
+		mtdt.UserAgent = userAgents[0]
+	}
+
+	if userAgents := md.Get(userAgentHeader); len(userAgents) > 0 && mtdt.UserAgent == "" {
+		mtdt.UserAgent = userAgents[0]
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		mtdt.ClientIP = clientHost(p.Addr.String())
+	}
+
+	if isGatewayCall(md) || s.isTrustedProxy(mtdt.ClientIP) {
+		if clientIP := s.forwardedClientIP(md.Get(xForwardedForHeader)); clientIP != "" {
+			mtdt.ClientIP = clientIP
+		}
+	}
+
+	return mtdt
+}
+
+// forwardedClientIP walks X-Forwarded-For from the right, past the trusted
+// proxies that appended to it, and returns the first address they did not
+// vouch for. Entries further left were written by the client and may be
+// made up.
+func (s *Server) forwardedClientIP(values []string) string {
+	var addresses []string
+	for _, value := range values {
+		for address := range strings.SplitSeq(value, ",") {
+			addresses = append(addresses, strings.TrimSpace(address))
+		}
+	}
+
+	for i := len(addresses) - 1; i >= 0; i-- {
+		if i == 0 || !s.isTrustedProxy(addresses[i]) {
+			return addresses[i]
+		}
+	}
+
+	return ""
+}
+
+// isTrustedProxy reports whether address is in TRUSTED_PROXIES.
+func (s *Server) isTrustedProxy(address string) bool {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+	for _, network := range s.trustedProxies {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isGatewayCall reports whether the call was made by the HTTP gateway
+// running in this process.
+func isGatewayCall(md metadata.MD) bool {
+	for _, key := range md.Get(gatewayKeyHeader) {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(gatewayKey)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GatewayDialOption makes the HTTP gateway identify itself to the gRPC
+// server in this process, so the client addresses it forwards are trusted.
+func GatewayDialOption() grpc.DialOption {
+	return grpc.WithPerRPCCredentials(gatewayCredentials{})
+}
+
+// gatewayCredentials attaches gatewayKey to every call.
+type gatewayCredentials struct{}
+
+func (gatewayCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{gatewayKeyHeader: gatewayKey}, nil
+}
+
+// RequireTransportSecurity allows the key over the gateway's plaintext
+// connection to the gRPC server.
+func (gatewayCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// clientHost drops the port from a peer address, so every connection from
+// the same client shares a bucket.
+func clientHost(address string) string {
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		return host
+	}
+
+	return address
+}
@@ -0,0 +1,81 @@
+package gapi
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestExtractMetadataClientIP(t *testing.T) {
+	testCases := []struct {
+		name     string
+		peer     string
+		md       metadata.MD
+		clientIP string
+	}{
+		{
+			name:     "DirectCall",
+			peer:     "203.0.113.7:40000",
+			clientIP: "203.0.113.7",
+		},
+		{
+			name:     "SpoofedForwardedFor",
+			peer:     "203.0.113.7:40000",
+			md:       metadata.Pairs(xForwardedForHeader, "198.51.100.1"),
+			clientIP: "203.0.113.7",
+		},
+		{
+			name: "WrongGatewayKey",
+			peer: "127.0.0.1:40000",
+			md: metadata.Pairs(
+				xForwardedForHeader, "198.51.100.1",
+				gatewayKeyHeader, "guess",
+			),
+			clientIP: "127.0.0.1",
+		},
+		{
+			name: "Gateway",
+			peer: "127.0.0.1:40000",
+			md: metadata.Pairs(
+				xForwardedForHeader, "198.51.100.1, 203.0.113.7",
+				gatewayKeyHeader, gatewayKey,
+			),
+			clientIP: "203.0.113.7",
+		},
+		{
+			name: "GatewayBehindTrustedProxy",
+			peer: "127.0.0.1:40000",
+			md: metadata.Pairs(
+				xForwardedForHeader, "198.51.100.1, 203.0.113.7, 10.0.0.2",
+				gatewayKeyHeader, gatewayKey,
+			),
+			clientIP: "203.0.113.7",
+		},
+		{
+			name:     "TrustedProxy",
+			peer:     "10.0.0.2:40000",
+			md:       metadata.Pairs(xForwardedForHeader, "198.51.100.1, 203.0.113.7"),
+			clientIP: "203.0.113.7",
+		},
+	}
+
+	server := newTestServer(t, nil)
+	server.trustedProxies = []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			addr, err := net.ResolveTCPAddr("tcp", tc.peer)
+			require.NoError(t, err)
+
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+			ctx = metadata.NewIncomingContext(ctx, tc.md)
+
+			require.Equal(t, tc.clientIP, server.extractMetadata(ctx).ClientIP)
+		})
+	}
+}
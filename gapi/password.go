@@ -0,0 +1,141 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/pb"
+	"github.com/shevgn/simplebank/token"
+	"github.com/shevgn/simplebank/util"
+	"github.com/shevgn/simplebank/worker"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const passwordResetRequestedMessage = "if the email belongs to an active user, a reset link will be sent"
+
+// ChangePassword changes the caller's password after checking the current
+// one, and blocks their other sessions. The session the caller's access
+// token was issued for stays active.
+func (s *Server) ChangePassword(
+	ctx context.Context,
+	req *pb.ChangePasswordRequest,
+) (*pb.ChangePasswordResponse, error) {
+	if violations := validateChangePasswordRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	payload := authPayload(ctx)
+
+	user, err := s.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find user: %s", err)
+	}
+
+	if err := util.CheckPassword(req.GetOldPassword(), user.HashedPassword); err != nil {
+		s.recordFailedLogin(ctx, user.Username)
+		return nil, status.Error(codes.Unauthenticated, "incorrect password")
+	}
+
+	hashedPassword, err := util.HashPassword(req.GetNewPassword())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to hash password: %s", err)
+	}
+
+	result, err := s.store.ChangePasswordTx(ctx, db.ChangePasswordTxParams{
+		Username:       user.Username,
+		HashedPassword: hashedPassword,
+		KeepSessionID:  payload.SessionID,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to change password: %s", err)
+	}
+
+	return &pb.ChangePasswordResponse{
+		User:            convertUser(result.User),
+		BlockedSessions: result.BlockedSessions,
+	}, nil
+}
+
+func validateChangePasswordRequest(req *pb.ChangePasswordRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validatePassword(req.GetOldPassword()); err != nil {
+		violations = append(violations, fieldViolation("old_password", err))
+	}
+
+	if err := validatePassword(req.GetNewPassword()); err != nil {
+		violations = append(violations, fieldViolation("new_password", err))
+	}
+
+	return violations
+}
+
+// RequestPasswordReset queues a single-use reset link for the user with the
+// given email and answers right away. Neither the response nor its timing
+// reveal whether such a user exists.
+func (s *Server) RequestPasswordReset(
+	ctx context.Context,
+	req *pb.RequestPasswordResetRequest,
+) (*pb.RequestPasswordResetResponse, error) {
+	if err := validateEmail(req.GetEmail()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("email", err)})
+	}
+
+	task, err := worker.NewSendPasswordResetTask(req.GetEmail())
+	if err == nil {
+		err = s.distributor.Enqueue(ctx, task)
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Cannot queue password reset email", slog.Any("error", err))
+	}
+
+	return &pb.RequestPasswordResetResponse{Message: passwordResetRequestedMessage}, nil
+}
+
+// ResetPassword redeems a password reset token and blocks all sessions of
+// its user.
+func (s *Server) ResetPassword(
+	ctx context.Context,
+	req *pb.ResetPasswordRequest,
+) (*pb.ResetPasswordResponse, error) {
+	if violations := validateResetPasswordRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	hashedPassword, err := util.HashPassword(req.GetNewPassword())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to hash password: %s", err)
+	}
+
+	result, err := s.store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		TokenHash:      token.HashOpaqueToken(req.GetToken()),
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidResetToken) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to reset password: %s", err)
+	}
+
+	return &pb.ResetPasswordResponse{
+		User:            convertUser(result.User),
+		BlockedSessions: result.BlockedSessions,
+	}, nil
+}
+
+func validateResetPasswordRequest(req *pb.ResetPasswordRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetToken() == "" {
+		violations = append(violations, fieldViolation("token", errors.New("must be set")))
+	}
+
+	if err := validatePassword(req.GetNewPassword()); err != nil {
+		violations = append(violations, fieldViolation("new_password", err))
+	}
+
+	return violations
+}
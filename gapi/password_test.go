@@ -0,0 +1,179 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/pb"
+	"github.com/shevgn/simplebank/token"
+	"github.com/shevgn/simplebank/util"
+	"github.com/shevgn/simplebank/worker"
+	mockworker "github.com/shevgn/simplebank/worker/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestChangePassword(t *testing.T) {
+	user, password := randomUser(t)
+	sessionID := uuid.New()
+
+	testCases := []struct {
+		name          string
+		req           *pb.ChangePasswordRequest
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.ChangePasswordResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.ChangePasswordRequest{OldPassword: password, NewPassword: "new-secret-password"},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				accessToken, _, err := tokenMaker.CreateSessionToken(user.Username, sessionID, time.Minute)
+				require.NoError(t, err)
+
+				md := metadata.MD{
+					authorizationHeaderKey: []string{fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken)},
+				}
+
+				return metadata.NewIncomingContext(context.Background(), md)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ChangePasswordTxParams) (db.PasswordTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, sessionID, arg.KeepSessionID)
+						require.NoError(t, util.CheckPassword("new-secret-password", arg.HashedPassword))
+
+						return db.PasswordTxResult{User: user, BlockedSessions: 3}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.ChangePasswordResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(3), res.GetBlockedSessions())
+			},
+		},
+		{
+			name: "TokenWithoutSession",
+			req:  &pb.ChangePasswordRequest{OldPassword: password, NewPassword: "new-secret-password"},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ChangePasswordTxParams) (db.PasswordTxResult, error) {
+						require.Zero(t, arg.KeepSessionID)
+
+						return db.PasswordTxResult{User: user, BlockedSessions: 4}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.ChangePasswordResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(4), res.GetBlockedSessions())
+			},
+		},
+		{
+			name: "IncorrectPassword",
+			req:  &pb.ChangePasswordRequest{OldPassword: "wrong-password", NewPassword: "new-secret-password"},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().RecordFailedLogin(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().ChangePasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *pb.ChangePasswordResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			req:  &pb.ChangePasswordRequest{OldPassword: password, NewPassword: "new-secret-password"},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ChangePasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *pb.ChangePasswordResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := tc.buildContext(t, server.tokenMaker)
+
+			res, err := invoke(ctx, server, pb.SimpleBank_ChangePassword_FullMethodName, tc.req, server.ChangePassword)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	email := util.RandomEmail()
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	distributor := mockworker.NewMockDistributor(ctrl)
+
+	server := newTestServer(t, store)
+	server.distributor = distributor
+
+	task, err := worker.NewSendPasswordResetTask(email)
+	require.NoError(t, err)
+
+	distributor.EXPECT().Enqueue(gomock.Any(), gomock.Eq(task)).Times(1).Return(nil)
+
+	res, err := server.RequestPasswordReset(context.Background(), &pb.RequestPasswordResetRequest{Email: email})
+	require.NoError(t, err)
+	require.Equal(t, passwordResetRequestedMessage, res.GetMessage())
+
+	// A task that cannot be queued gets the same answer.
+	distributor.EXPECT().Enqueue(gomock.Any(), gomock.Any()).Times(1).Return(sql.ErrConnDone)
+
+	res, err = server.RequestPasswordReset(context.Background(), &pb.RequestPasswordResetRequest{Email: email})
+	require.NoError(t, err)
+	require.Equal(t, passwordResetRequestedMessage, res.GetMessage())
+}
+
+func TestResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	store.EXPECT().
+		ResetPasswordTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.PasswordTxResult{}, db.ErrInvalidResetToken)
+
+	_, err := server.ResetPassword(context.Background(), &pb.ResetPasswordRequest{
+		Token:       "expired",
+		NewPassword: "new-secret-password",
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.ResetPassword(context.Background(), &pb.ResetPasswordRequest{NewPassword: "new-secret-password"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
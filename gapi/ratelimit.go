@@ -0,0 +1,83 @@
+package gapi
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/shevgn/simplebank/metrics"
+	"github.com/shevgn/simplebank/pb"
+	"github.com/shevgn/simplebank/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const retryAfterHeader = "retry-after"
+
+// ipRateLimitedMethods can be called without an access token and are limited
+// per client IP, sharing the login budget.
+var ipRateLimitedMethods = map[string]bool{
+	pb.SimpleBank_LoginUser_FullMethodName:            true,
+	pb.SimpleBank_RequestPasswordReset_FullMethodName: true,
+	pb.SimpleBank_ResetPassword_FullMethodName:        true,
+}
+
+// RateLimitInterceptor limits LoginUser and the password reset calls per
+// client IP, and LoginUser also per username. The buckets are shared with
+// the HTTP API when both use the same limiter.
+func (s *Server) RateLimitInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	if !ipRateLimitedMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	perIP := ratelimit.Rate{Limit: s.config.LoginRateLimitPerIP, Period: s.config.LoginRateLimitPeriod}
+	if err := s.allowRequest(ctx, "login:ip:"+s.extractMetadata(ctx).ClientIP, perIP); err != nil {
+		return nil, err
+	}
+
+	if loginReq, ok := req.(*pb.LoginUserRequest); ok && loginReq.GetUsername() != "" {
+		perUsername := ratelimit.Rate{Limit: s.config.LoginRateLimitPerUser, Period: s.config.LoginRateLimitPeriod}
+		if err := s.allowRequest(ctx, "login:username:"+loginReq.GetUsername(), perUsername); err != nil {
+			return nil, err
+		}
+	}
+
+	return handler(ctx, req)
+}
+
+// allowRequest takes a token for key and returns a ResourceExhausted error
+// if none is left. Limiter failures let the request through.
+func (s *Server) allowRequest(ctx context.Context, key string, rate ratelimit.Rate) error {
+	result, err := s.limiter.Allow(ctx, key, rate)
+	if err != nil {
+		slog.WarnContext(ctx, "Rate limiter failed", slog.Any("error", err))
+		return nil
+	}
+
+	if !result.Allowed {
+		metrics.ObserveFailedLogin(metrics.ReasonRateLimited)
+		setRetryAfter(ctx, result.RetryAfter)
+
+		return status.Error(codes.ResourceExhausted, "too many requests")
+	}
+
+	return nil
+}
+
+// setRetryAfter tells the client, in whole seconds, when to try again. The
+// gateway forwards it as the Grpc-Metadata-Retry-After header.
+func setRetryAfter(ctx context.Context, d time.Duration) {
+	seconds := strconv.Itoa(int(math.Ceil(d.Seconds())))
+	if err := grpc.SetHeader(ctx, metadata.Pairs(retryAfterHeader, seconds)); err != nil {
+		slog.DebugContext(ctx, "Cannot set retry-after header", slog.Any("error", err))
+	}
+}
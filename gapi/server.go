@@ -0,0 +1,73 @@
+// Package gapi provides the gRPC API for the simplebank application.
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/notify"
+	"github.com/shevgn/simplebank/pb"
+	"github.com/shevgn/simplebank/ratelimit"
+	"github.com/shevgn/simplebank/token"
+	"github.com/shevgn/simplebank/util"
+	"github.com/shevgn/simplebank/webhook"
+	"github.com/shevgn/simplebank/worker"
+)
+
+// Server serves gRPC requests for the banking service.
+type Server struct {
+	pb.UnimplementedSimpleBankServer
+	store       db.Store
+	tokenMaker  token.Maker
+	config      *util.Config
+	limiter     ratelimit.Limiter
+	distributor worker.Distributor
+	webhooks    *webhook.Dispatcher
+	hub         *notify.Hub
+
+	// trustedProxies may report a client's address in X-Forwarded-For.
+	trustedProxies []netip.Prefix
+
+	// streams is canceled by StopStreams to end long-lived streams, which
+	// would otherwise keep a graceful stop waiting.
+	streams     context.Context
+	stopStreams context.CancelFunc
+}
+
+// NewServer creates a new gRPC server.
+func NewServer(
+	config *util.Config,
+	store db.Store,
+	limiter ratelimit.Limiter,
+	distributor worker.Distributor,
+	hub *notify.Hub,
+) (*Server, error) {
+	maker, err := token.NewJWTMaker(config.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	s := &Server{
+		store:       store,
+		tokenMaker:  maker,
+		config:      config,
+		limiter:     limiter,
+		distributor: distributor,
+		webhooks:    webhook.NewDispatcher(store, config.WebhookTimeout, webhook.NewGuard(config.WebhookNetworks())),
+		hub:         hub,
+
+		trustedProxies: config.TrustedProxyNetworks(),
+	}
+
+	s.streams, s.stopStreams = context.WithCancel(context.Background())
+
+	return s, nil
+}
+
+// StopStreams ends every open stream. Call it before stopping the gRPC
+// server gracefully.
+func (s *Server) StopStreams() {
+	s.stopStreams()
+}
@@ -0,0 +1,42 @@
+package gapi
+
+import (
+	"github.com/shevgn/simplebank/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StreamAccountEvents pushes balance changes of the caller's accounts as
+// they commit.
+func (s *Server) StreamAccountEvents(
+	_ *pb.StreamAccountEventsRequest,
+	stream grpc.ServerStreamingServer[pb.AccountEvent],
+) error {
+	ctx := stream.Context()
+
+	sub := s.hub.Subscribe(authPayload(ctx).Username)
+	defer sub.Close()
+
+	// Send the headers right away so the client knows it is subscribed.
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-s.streams.Done():
+			return status.Errorf(codes.Unavailable, "server is shutting down")
+		case n, ok := <-sub.Events():
+			if !ok {
+				return status.Errorf(codes.Unavailable, "stream interrupted, reload balances and reconnect")
+			}
+
+			if err := stream.Send(convertAccountEvent(n)); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/pb"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeAccountEventStream is the server side of a StreamAccountEvents call.
+type fakeAccountEventStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header chan struct{}
+	events chan *pb.AccountEvent
+}
+
+func (s *fakeAccountEventStream) Context() context.Context { return s.ctx }
+
+func (s *fakeAccountEventStream) SendHeader(metadata.MD) error {
+	close(s.header)
+	return nil
+}
+
+func (s *fakeAccountEventStream) SendMsg(m any) error {
+	s.events <- m.(*pb.AccountEvent)
+	return nil
+}
+
+func TestStreamAccountEvents(t *testing.T) {
+	user, _ := randomUser(t)
+
+	server := newTestServer(t, mockdb.NewMockStore(gomock.NewController(t)))
+
+	testCases := []struct {
+		name      string
+		buildCtx  func(t *testing.T) context.Context
+		interrupt func()
+		code      codes.Code
+	}{
+		{
+			name: "Interrupted",
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithBearerToken(t, server.tokenMaker, user.Username, time.Minute)
+			},
+			interrupt: server.hub.Reset,
+			code:      codes.Unavailable,
+		},
+		{
+			name: "NoAuthorization",
+			buildCtx: func(*testing.T) context.Context {
+				return context.Background()
+			},
+			code: codes.Unauthenticated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stream := &fakeAccountEventStream{
+				ctx:    tc.buildCtx(t),
+				header: make(chan struct{}),
+				events: make(chan *pb.AccountEvent, 1),
+			}
+
+			errs := make(chan error, 1)
+			go func() {
+				errs <- server.StreamAuthInterceptor(nil, stream,
+					&grpc.StreamServerInfo{FullMethod: pb.SimpleBank_StreamAccountEvents_FullMethodName},
+					func(_ any, stream grpc.ServerStream) error {
+						return server.StreamAccountEvents(&pb.StreamAccountEventsRequest{},
+							&grpc.GenericServerStream[pb.StreamAccountEventsRequest, pb.AccountEvent]{ServerStream: stream})
+					})
+			}()
+
+			if tc.interrupt != nil {
+				<-stream.header
+
+				server.hub.Publish(db.AccountNotification{
+					Type:                db.EventAccountDebited,
+					Owner:               user.Username,
+					AccountEntryPayload: db.AccountEntryPayload{AccountID: 1, EntryID: 2, Amount: -5, Balance: 95},
+				})
+
+				event := <-stream.events
+				require.Equal(t, db.EventAccountDebited, event.GetType())
+				require.Equal(t, int64(95), event.GetBalance())
+
+				tc.interrupt()
+			}
+
+			require.Equal(t, tc.code, status.Code(<-errs))
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/metrics"
+	"github.com/shevgn/simplebank/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// RenewAccessToken issues a new access token for a valid refresh token.
+func (s *Server) RenewAccessToken(
+	ctx context.Context,
+	req *pb.RenewAccessTokenRequest,
+) (res *pb.RenewAccessTokenResponse, err error) {
+	defer func() {
+		metrics.ObserveTokenRenewal(err == nil)
+	}()
+
+	if req.GetRefreshToken() == "" {
+		err := fmt.Errorf("must not be empty")
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("refresh_token", err)})
+	}
+
+	refreshPayload, err := s.tokenMaker.VerifyToken(req.GetRefreshToken())
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	session, err := s.store.GetSession(ctx, refreshPayload.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "session not found")
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to find session: %s", err)
+	}
+
+	if session.IsBlocked {
+		return nil, unauthenticatedError(fmt.Errorf("session is blocked"))
+	}
+
+	if session.Username != refreshPayload.Username {
+		return nil, unauthenticatedError(fmt.Errorf("incorrect session user"))
+	}
+
+	if session.RefreshToken != req.GetRefreshToken() {
+		return nil, unauthenticatedError(fmt.Errorf("refresh token does not match"))
+	}
+
+	if time.Now().After(session.ExpiresAt.Time) {
+		return nil, unauthenticatedError(fmt.Errorf("session expired"))
+	}
+
+	accessToken, accessPayload, err := s.tokenMaker.CreateSessionToken(
+		refreshPayload.Username,
+		session.ID,
+		s.config.AccessTokenDuration,
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create access token: %s", err)
+	}
+
+	response := &pb.RenewAccessTokenResponse{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: timestamppb.New(accessPayload.ExpiredAt),
+	}
+
+	return response, nil
+}
@@ -0,0 +1,173 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/metrics"
+	"github.com/shevgn/simplebank/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) validAccount(ctx context.Context, accountID int64, currency string) (db.Account, error) {
+	account, err := s.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return account, status.Errorf(codes.NotFound, "account %d not found", accountID)
+		}
+
+		return account, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	if account.Currency != currency {
+		return account, status.Errorf(
+			codes.InvalidArgument,
+			"account %d has currency %s, but %s was requested",
+			accountID,
+			account.Currency,
+			currency,
+		)
+	}
+
+	if err := db.CheckActive(account); err != nil {
+		return account, status.Errorf(codes.FailedPrecondition, "%s", err)
+	}
+
+	return account, nil
+}
+
+// transferRequest is what CreateTransferRequest and QuoteTransferRequest
+// have in common.
+type transferRequest interface {
+	GetFromAccountId() int64
+	GetToAccountId() int64
+	GetAmount() int64
+	GetCurrency() string
+}
+
+// checkTransfer validates a transfer request and checks both of its
+// accounts.
+func (s *Server) checkTransfer(ctx context.Context, req transferRequest) error {
+	if violations := validateTransferRequest(req); violations != nil {
+		return invalidArgumentError(violations)
+	}
+
+	fromAccount, err := s.validAccount(ctx, req.GetFromAccountId(), req.GetCurrency())
+	if err != nil {
+		return err
+	}
+
+	if fromAccount.Owner != authPayload(ctx).Username {
+		return status.Errorf(codes.PermissionDenied, "from account does not belong to the user")
+	}
+
+	toAccount, err := s.validAccount(ctx, req.GetToAccountId(), req.GetCurrency())
+	if err != nil {
+		return err
+	}
+
+	isSystem, err := s.store.IsSystemAccount(ctx, toAccount.ID)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to check account: %s", err)
+	}
+
+	if isSystem {
+		return status.Errorf(codes.PermissionDenied, "account %d: %s", toAccount.ID, db.ErrSystemAccount)
+	}
+
+	return nil
+}
+
+// CreateTransfer moves money between two accounts of the same currency.
+func (s *Server) CreateTransfer(
+	ctx context.Context,
+	req *pb.CreateTransferRequest,
+) (*pb.CreateTransferResponse, error) {
+	if err := s.checkTransfer(ctx, req); err != nil {
+		return nil, err
+	}
+
+	result, err := s.store.TransferTx(ctx, db.TransferTxParams{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        req.GetAmount(),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrAccountFrozen) ||
+			errors.Is(err, db.ErrAccountClosed) ||
+			errors.Is(err, db.ErrEmailNotVerified) {
+			return nil, status.Errorf(codes.FailedPrecondition, "failed to create transfer: %s", err)
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to create transfer: %s", err)
+	}
+
+	metrics.ObserveTransfer(req.GetCurrency(), req.GetAmount())
+
+	response := &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+		ToEntry:     convertEntry(result.ToEntry),
+	}
+
+	if result.Fee != nil {
+		response.Fee = convertFee(*result.Fee)
+		response.FeeEntry = convertEntry(*result.FeeEntry)
+	}
+
+	return response, nil
+}
+
+// QuoteTransfer returns the fee a transfer would be charged, so clients can
+// show it before the user confirms the transfer.
+func (s *Server) QuoteTransfer(
+	ctx context.Context,
+	req *pb.QuoteTransferRequest,
+) (*pb.QuoteTransferResponse, error) {
+	if err := s.checkTransfer(ctx, req); err != nil {
+		return nil, err
+	}
+
+	quote, err := s.store.QuoteTransfer(ctx, db.TransferTxParams{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        req.GetAmount(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to quote transfer: %s", err)
+	}
+
+	response := &pb.QuoteTransferResponse{
+		Amount:    quote.Amount,
+		Fee:       quote.Fee,
+		Total:     quote.Total,
+		FeeRuleId: quote.FeeRuleID,
+	}
+
+	return response, nil
+}
+
+func validateTransferRequest(req transferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validateID(req.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+
+	if err := validateID(req.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	}
+
+	if req.GetAmount() <= 0 {
+		violations = append(violations, fieldViolation("amount", errors.New("must be greater than 0")))
+	}
+
+	if err := validateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	return violations
+}
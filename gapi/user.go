@@ -0,0 +1,182 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/metrics"
+	"github.com/shevgn/simplebank/pb"
+	"github.com/shevgn/simplebank/util"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// CreateUser registers a new user.
+func (s *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	if violations := validateCreateUserRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	hashedPassword, err := util.HashPassword(req.GetPassword())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to hash password: %s", err)
+	}
+
+	arg := db.CreateUserParams{
+		Username:       req.GetUsername(),
+		HashedPassword: hashedPassword,
+		FullName:       req.GetFullName(),
+		Email:          req.GetEmail(),
+	}
+
+	user, err := s.store.CreateUser(ctx, arg)
+	if err != nil {
+		if db.ErrorCode(err) == db.UniqueViolation {
+			return nil, status.Errorf(codes.AlreadyExists, "user already exists: %s", err)
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to create user: %s", err)
+	}
+
+	s.sendVerifyEmail(ctx, user.Username)
+
+	return &pb.CreateUserResponse{User: convertUser(user)}, nil
+}
+
+func validateCreateUserRequest(req *pb.CreateUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validateUsername(req.GetUsername()); err != nil {
+		violations = append(violations, fieldViolation("username", err))
+	}
+
+	if err := validatePassword(req.GetPassword()); err != nil {
+		violations = append(violations, fieldViolation("password", err))
+	}
+
+	if err := validateFullName(req.GetFullName()); err != nil {
+		violations = append(violations, fieldViolation("full_name", err))
+	}
+
+	if err := validateEmail(req.GetEmail()); err != nil {
+		violations = append(violations, fieldViolation("email", err))
+	}
+
+	return violations
+}
+
+// LoginUser checks the user's credentials and opens a new session.
+func (s *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
+	if violations := validateLoginUserRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	user, err := s.store.GetUser(ctx, req.GetUsername())
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			metrics.ObserveFailedLogin(metrics.ReasonUserNotFound)
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to find user: %s", err)
+	}
+
+	if lockedFor := time.Until(user.LockedUntil.Time); lockedFor > 0 {
+		metrics.ObserveFailedLogin(metrics.ReasonAccountLocked)
+		setRetryAfter(ctx, lockedFor)
+		return nil, status.Error(codes.PermissionDenied, db.ErrUserLocked.Error())
+	}
+
+	err = util.CheckPassword(req.GetPassword(), user.HashedPassword)
+	if err != nil {
+		metrics.ObserveFailedLogin(metrics.ReasonIncorrectPassword)
+		s.recordFailedLogin(ctx, user.Username)
+		return nil, status.Errorf(codes.Unauthenticated, "incorrect password")
+	}
+
+	if user.IsDisabled {
+		metrics.ObserveFailedLogin(metrics.ReasonUserDisabled)
+		return nil, status.Error(codes.PermissionDenied, db.ErrUserDisabled.Error())
+	}
+
+	if user.FailedLoginAttempts > 0 {
+		user, err = s.store.ResetFailedLogins(ctx, user.Username)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to reset failed logins: %s", err)
+		}
+	}
+
+	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(
+		user.Username,
+		s.config.RefreshTokenDuration,
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create refresh token: %s", err)
+	}
+
+	// The refresh token's ID doubles as the session ID.
+	accessToken, accessPayload, err := s.tokenMaker.CreateSessionToken(
+		user.Username,
+		refreshPayload.ID,
+		s.config.AccessTokenDuration,
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create access token: %s", err)
+	}
+
+	mtdt := s.extractMetadata(ctx)
+
+	session, err := s.store.CreateSession(ctx, db.CreateSessionParams{
+		ID:           refreshPayload.ID,
+		Username:     user.Username,
+		RefreshToken: refreshToken,
+		UserAgent:    mtdt.UserAgent,
+		ClientIp:     mtdt.ClientIP,
+		IsBlocked:    false,
+		ExpiresAt:    pgtype.Timestamptz{Time: refreshPayload.ExpiredAt, Valid: true},
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create session: %s", err)
+	}
+
+	response := &pb.LoginUserResponse{
+		SessionId:             session.ID.String(),
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  timestamppb.New(accessPayload.ExpiredAt),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: timestamppb.New(refreshPayload.ExpiredAt),
+		User:                  convertUser(user),
+	}
+
+	return response, nil
+}
+
+// recordFailedLogin counts a wrong password and locks the user once the
+// configured number of attempts is reached.
+func (s *Server) recordFailedLogin(ctx context.Context, username string) {
+	_, err := s.store.RecordFailedLogin(ctx, db.RecordFailedLoginParams{
+		Username:          username,
+		MaxAttempts:       int32(s.config.LoginMaxFailedAttempts),
+		LockoutSeconds:    s.config.LoginLockoutDuration.Seconds(),
+		MaxLockoutSeconds: s.config.LoginMaxLockoutDuration.Seconds(),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Cannot record failed login", slog.Any("error", err))
+	}
+}
+
+func validateLoginUserRequest(req *pb.LoginUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validateUsername(req.GetUsername()); err != nil {
+		violations = append(violations, fieldViolation("username", err))
+	}
+
+	if err := validatePassword(req.GetPassword()); err != nil {
+		violations = append(violations, fieldViolation("password", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,111 @@
+package gapi
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/util"
+	"github.com/shevgn/simplebank/webhook"
+)
+
+var isValidUsername = regexp.MustCompile(`^[a-zA-Z0-9]+$`).MatchString
+
+func validateString(value string, minLength int, maxLength int) error {
+	n := len(value)
+	if n < minLength || n > maxLength {
+		return fmt.Errorf("must contain from %d-%d characters", minLength, maxLength)
+	}
+
+	return nil
+}
+
+func validateUsername(value string) error {
+	if err := validateString(value, 1, 100); err != nil {
+		return err
+	}
+
+	if !isValidUsername(value) {
+		return fmt.Errorf("must contain only letters or digits")
+	}
+
+	return nil
+}
+
+func validatePassword(value string) error {
+	return validateString(value, 8, 72)
+}
+
+func validateFullName(value string) error {
+	return validateString(value, 1, 200)
+}
+
+func validateEmail(value string) error {
+	if err := validateString(value, 3, 200); err != nil {
+		return err
+	}
+
+	if _, err := mail.ParseAddress(value); err != nil {
+		return fmt.Errorf("is not a valid email address")
+	}
+
+	return nil
+}
+
+func validateCurrency(value string) error {
+	if !util.IsSupportedCurrency(value) {
+		return fmt.Errorf("unsupported currency %q", value)
+	}
+
+	return nil
+}
+
+func validateAccountType(value string) error {
+	if !slices.Contains(db.AccountTypes, value) {
+		return fmt.Errorf("unknown account type %q", value)
+	}
+
+	return nil
+}
+
+func validateNickname(value string) error {
+	return validateString(value, 0, 64)
+}
+
+func validateID(value int64) error {
+	if value < 1 {
+		return fmt.Errorf("must be a positive integer")
+	}
+
+	return nil
+}
+
+func validateWebhookURL(value string) error {
+	if err := validateString(value, 1, 2048); err != nil {
+		return err
+	}
+
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("must be an http or https URL")
+	}
+
+	return nil
+}
+
+func validateWebhookEventTypes(values []string) error {
+	if len(values) > 10 {
+		return fmt.Errorf("must contain at most 10 event types")
+	}
+
+	for _, value := range values {
+		if !webhook.ValidEventType(value) {
+			return fmt.Errorf("unsupported event type %q", value)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/pb"
+	"github.com/shevgn/simplebank/token"
+	"github.com/shevgn/simplebank/worker"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// VerifyEmail redeems the token from a verification email.
+func (s *Server) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+	if req.GetToken() == "" {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{
+			fieldViolation("token", errors.New("must be set")),
+		})
+	}
+
+	user, err := s.store.VerifyEmailTx(ctx, token.HashOpaqueToken(req.GetToken()))
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidVerifyToken) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to verify email: %s", err)
+	}
+
+	return &pb.VerifyEmailResponse{User: convertUser(user)}, nil
+}
+
+// ResendVerifyEmail queues a new verification email for the caller.
+func (s *Server) ResendVerifyEmail(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	payload := authPayload(ctx)
+
+	user, err := s.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find user: %s", err)
+	}
+
+	if user.IsEmailVerified {
+		return nil, status.Error(codes.FailedPrecondition, "email is already verified")
+	}
+
+	s.sendVerifyEmail(ctx, user.Username)
+
+	return &emptypb.Empty{}, nil
+}
+
+// sendVerifyEmail queues the verification email for username, logging
+// rather than returning failures since the user can ask again.
+func (s *Server) sendVerifyEmail(ctx context.Context, username string) {
+	task, err := worker.NewSendVerifyEmailTask(username)
+	if err == nil {
+		err = s.distributor.Enqueue(ctx, task)
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Cannot queue verification email",
+			slog.String("username", username),
+			slog.Any("error", err),
+		)
+	}
+}
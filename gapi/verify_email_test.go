@@ -0,0 +1,68 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/pb"
+	"github.com/shevgn/simplebank/token"
+	"github.com/shevgn/simplebank/worker"
+	mockworker "github.com/shevgn/simplebank/worker/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestVerifyEmail(t *testing.T) {
+	user, _ := randomUser(t)
+	user.IsEmailVerified = true
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	store.EXPECT().
+		VerifyEmailTx(gomock.Any(), gomock.Eq(token.HashOpaqueToken("good"))).
+		Times(1).
+		Return(user, nil)
+	store.EXPECT().
+		VerifyEmailTx(gomock.Any(), gomock.Eq(token.HashOpaqueToken("bad"))).
+		Times(1).
+		Return(db.User{}, db.ErrInvalidVerifyToken)
+
+	res, err := server.VerifyEmail(context.Background(), &pb.VerifyEmailRequest{Token: "good"})
+	require.NoError(t, err)
+	require.True(t, res.GetUser().GetIsEmailVerified())
+
+	_, err = server.VerifyEmail(context.Background(), &pb.VerifyEmailRequest{Token: "bad"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.VerifyEmail(context.Background(), &pb.VerifyEmailRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestResendVerifyEmail(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	distributor := mockworker.NewMockDistributor(ctrl)
+
+	server := newTestServer(t, store)
+	server.distributor = distributor
+
+	task, err := worker.NewSendVerifyEmailTask(user.Username)
+	require.NoError(t, err)
+
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+	distributor.EXPECT().Enqueue(gomock.Any(), gomock.Eq(task)).Times(1).Return(nil)
+
+	ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, time.Minute)
+	_, err = invoke(ctx, server, pb.SimpleBank_ResendVerifyEmail_FullMethodName, &emptypb.Empty{}, server.ResendVerifyEmail)
+	require.NoError(t, err)
+}
@@ -0,0 +1,207 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/pb"
+	"github.com/shevgn/simplebank/webhook"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// getOwnedWebhookEndpoint loads an endpoint and makes sure it belongs to the
+// caller. Endpoints of other users are reported as not found.
+func (s *Server) getOwnedWebhookEndpoint(ctx context.Context, id int64) (db.WebhookEndpoint, error) {
+	endpoint, err := s.store.GetWebhookEndpoint(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return endpoint, status.Errorf(codes.NotFound, "webhook endpoint %d not found", id)
+		}
+
+		return endpoint, status.Errorf(codes.Internal, "failed to get webhook endpoint: %s", err)
+	}
+
+	if endpoint.Owner != authPayload(ctx).Username {
+		return endpoint, status.Errorf(codes.NotFound, "webhook endpoint %d not found", id)
+	}
+
+	return endpoint, nil
+}
+
+// CreateWebhookEndpoint registers an endpoint for the caller's account
+// activity and returns its signing secret.
+func (s *Server) CreateWebhookEndpoint(
+	ctx context.Context,
+	req *pb.CreateWebhookEndpointRequest,
+) (*pb.CreateWebhookEndpointResponse, error) {
+	var violations []*errdetails.BadRequest_FieldViolation
+	if err := validateWebhookURL(req.GetUrl()); err != nil {
+		violations = append(violations, fieldViolation("url", err))
+	} else if err := s.webhooks.CheckURL(req.GetUrl()); err != nil {
+		violations = append(violations, fieldViolation("url", err))
+	}
+
+	if err := validateWebhookEventTypes(req.GetEventTypes()); err != nil {
+		violations = append(violations, fieldViolation("event_types", err))
+	}
+
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate secret: %s", err)
+	}
+
+	eventTypes := req.GetEventTypes()
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	endpoint, err := s.store.CreateWebhookEndpoint(ctx, db.CreateWebhookEndpointParams{
+		Owner:      authPayload(ctx).Username,
+		Url:        req.GetUrl(),
+		Secret:     secret,
+		EventTypes: eventTypes,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create webhook endpoint: %s", err)
+	}
+
+	return &pb.CreateWebhookEndpointResponse{
+		Endpoint: convertWebhookEndpoint(endpoint),
+		Secret:   endpoint.Secret,
+	}, nil
+}
+
+// ListWebhookEndpoints returns the caller's endpoints.
+func (s *Server) ListWebhookEndpoints(
+	ctx context.Context,
+	_ *pb.ListWebhookEndpointsRequest,
+) (*pb.ListWebhookEndpointsResponse, error) {
+	endpoints, err := s.store.ListWebhookEndpoints(ctx, authPayload(ctx).Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list webhook endpoints: %s", err)
+	}
+
+	response := &pb.ListWebhookEndpointsResponse{Endpoints: make([]*pb.WebhookEndpoint, 0, len(endpoints))}
+	for _, endpoint := range endpoints {
+		response.Endpoints = append(response.Endpoints, convertWebhookEndpoint(endpoint))
+	}
+
+	return response, nil
+}
+
+// DeleteWebhookEndpoint removes one of the caller's endpoints with its
+// delivery log.
+func (s *Server) DeleteWebhookEndpoint(
+	ctx context.Context,
+	req *pb.DeleteWebhookEndpointRequest,
+) (*emptypb.Empty, error) {
+	if err := validateID(req.GetId()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("id", err)})
+	}
+
+	if _, err := s.getOwnedWebhookEndpoint(ctx, req.GetId()); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.DeleteWebhookEndpoint(ctx, req.GetId()); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete webhook endpoint: %s", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// ListWebhookDeliveries returns a page of an endpoint's delivery log, newest
+// first.
+func (s *Server) ListWebhookDeliveries(
+	ctx context.Context,
+	req *pb.ListWebhookDeliveriesRequest,
+) (*pb.ListWebhookDeliveriesResponse, error) {
+	if violations := validateListWebhookDeliveriesRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	endpoint, err := s.getOwnedWebhookEndpoint(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.store.ListWebhookDeliveries(ctx, db.ListWebhookDeliveriesParams{
+		EndpointID: endpoint.ID,
+		Limit:      req.GetPageSize(),
+		Offset:     (req.GetPageId() - 1) * req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list webhook deliveries: %s", err)
+	}
+
+	response := &pb.ListWebhookDeliveriesResponse{Deliveries: make([]*pb.WebhookDelivery, 0, len(deliveries))}
+	for _, delivery := range deliveries {
+		response.Deliveries = append(response.Deliveries, convertWebhookDelivery(delivery))
+	}
+
+	return response, nil
+}
+
+func validateListWebhookDeliveriesRequest(
+	req *pb.ListWebhookDeliveriesRequest,
+) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validateID(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	if req.GetPageId() < 1 {
+		violations = append(violations, fieldViolation("page_id", errors.New("must be at least 1")))
+	}
+
+	if req.GetPageSize() < 5 || req.GetPageSize() > 50 {
+		violations = append(violations, fieldViolation("page_size", errors.New("must be between 5 and 50")))
+	}
+
+	return violations
+}
+
+// TestWebhookEndpoint sends a test event to the endpoint right away and
+// returns the delivery with the outcome. Test deliveries are not retried.
+func (s *Server) TestWebhookEndpoint(
+	ctx context.Context,
+	req *pb.TestWebhookEndpointRequest,
+) (*pb.TestWebhookEndpointResponse, error) {
+	if err := validateID(req.GetId()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("id", err)})
+	}
+
+	endpoint, err := s.getOwnedWebhookEndpoint(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := webhook.NewTestPayload(endpoint, time.Now())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to build test event: %s", err)
+	}
+
+	delivery, err := s.store.CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+		EndpointID: endpoint.ID,
+		EventType:  webhook.EventTest,
+		Payload:    payload,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create webhook delivery: %s", err)
+	}
+
+	delivery, err = s.webhooks.Deliver(ctx, endpoint, delivery, true)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to record webhook delivery: %s", err)
+	}
+
+	return &pb.TestWebhookEndpointResponse{Delivery: convertWebhookDelivery(delivery)}, nil
+}
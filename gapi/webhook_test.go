@@ -0,0 +1,143 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/pb"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateWebhookEndpoint(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		req           *pb.CreateWebhookEndpointRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.CreateWebhookEndpointResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.CreateWebhookEndpointRequest{
+				Url:        "https://example.com/hooks",
+				EventTypes: []string{db.EventTransferCreated},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookEndpoint(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateWebhookEndpointParams) (db.WebhookEndpoint, error) {
+						require.Equal(t, user.Username, arg.Owner)
+
+						return db.WebhookEndpoint{ID: 1, Owner: arg.Owner, Url: arg.Url, Secret: arg.Secret, EventTypes: arg.EventTypes}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateWebhookEndpointResponse, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, res.GetSecret())
+				require.Equal(t, "https://example.com/hooks", res.GetEndpoint().GetUrl())
+			},
+		},
+		{
+			name: "InvalidURL",
+			req:  &pb.CreateWebhookEndpointRequest{Url: "example.com/hooks"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookEndpoint(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *pb.CreateWebhookEndpointResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "Localhost",
+			req:  &pb.CreateWebhookEndpointRequest{Url: "http://localhost:6379/"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookEndpoint(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *pb.CreateWebhookEndpointResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidEventType",
+			req: &pb.CreateWebhookEndpointRequest{
+				Url:        "https://example.com/hooks",
+				EventTypes: []string{"AccountDeleted"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookEndpoint(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *pb.CreateWebhookEndpointResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, time.Minute)
+
+			res, err := invoke(ctx, server, pb.SimpleBank_CreateWebhookEndpoint_FullMethodName, tc.req,
+				server.CreateWebhookEndpoint)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestDeleteWebhookEndpoint(t *testing.T) {
+	user, _ := randomUser(t)
+	endpoint := db.WebhookEndpoint{ID: 7, Owner: user.Username, Url: "https://example.com/hooks"}
+
+	testCases := []struct {
+		name       string
+		username   string
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(endpoint, nil)
+				store.EXPECT().DeleteWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name:     "NotOwner",
+			username: "someone",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(endpoint, nil)
+				store.EXPECT().DeleteWebhookEndpoint(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.NotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, tc.username, time.Minute)
+
+			_, err := invoke(ctx, server, pb.SimpleBank_DeleteWebhookEndpoint_FullMethodName,
+				&pb.DeleteWebhookEndpointRequest{Id: endpoint.ID}, server.DeleteWebhookEndpoint)
+			require.Equal(t, tc.code, status.Code(err))
+		})
+	}
+}
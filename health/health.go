@@ -0,0 +1,133 @@
+// Package health reports whether the service is alive and ready to serve
+// traffic.
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Check statuses reported by Checker
+const (
+	StatusOK           = "ok"
+	StatusFailed       = "failed"
+	StatusShuttingDown = "shutting_down"
+)
+
+const defaultCheckTimeout = 2 * time.Second
+
+// ErrShuttingDown is reported by readiness once shutdown has begun
+var ErrShuttingDown = errors.New("service is shutting down")
+
+// CheckFunc returns an error if the dependency it checks is not usable
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult is the outcome of a single check
+type CheckResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Report is the outcome of all registered checks
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type namedCheck struct {
+	name  string
+	check CheckFunc
+}
+
+// Checker runs named readiness checks
+type Checker struct {
+	mu           sync.RWMutex
+	checks       []namedCheck
+	timeout      time.Duration
+	shuttingDown bool
+}
+
+// NewChecker creates a Checker with no checks registered
+func NewChecker() *Checker {
+	return &Checker{timeout: defaultCheckTimeout}
+}
+
+// Register adds a check that must pass for the service to be ready
+func (c *Checker) Register(name string, check CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// SetShuttingDown makes every following readiness check fail, so that load
+// balancers stop routing new traffic while in-flight requests drain.
+func (c *Checker) SetShuttingDown() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.shuttingDown = true
+}
+
+// Check runs all registered checks concurrently, each bounded by a timeout
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := c.checks
+	shuttingDown := c.shuttingDown
+	c.mu.RUnlock()
+
+	if shuttingDown {
+		return Report{Status: StatusShuttingDown}
+	}
+
+	results := make([]CheckResult, len(checks))
+
+	var wg sync.WaitGroup
+	for i, nc := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			results[i] = c.run(ctx, nc.check)
+		}()
+	}
+
+	wg.Wait()
+
+	report := Report{
+		Status: StatusOK,
+		Checks: make(map[string]CheckResult, len(checks)),
+	}
+
+	for i, nc := range checks {
+		report.Checks[nc.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFailed
+		}
+	}
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check CheckFunc) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+
+	result := CheckResult{
+		Status:     StatusOK,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+
+	if err != nil {
+		result.Status = StatusFailed
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,66 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecker(t *testing.T) {
+	checker := NewChecker()
+	checker.Register("ok", func(_ context.Context) error { return nil })
+
+	report := checker.Check(context.Background())
+	require.Equal(t, StatusOK, report.Status)
+	require.Equal(t, StatusOK, report.Checks["ok"].Status)
+
+	checker.Register("broken", func(_ context.Context) error { return errors.New("connection refused") })
+
+	report = checker.Check(context.Background())
+	require.Equal(t, StatusFailed, report.Status)
+	require.Equal(t, StatusOK, report.Checks["ok"].Status)
+	require.Equal(t, StatusFailed, report.Checks["broken"].Status)
+	require.Equal(t, "connection refused", report.Checks["broken"].Error)
+}
+
+func TestCheckerTimeout(t *testing.T) {
+	checker := NewChecker()
+	checker.timeout = 10 * time.Millisecond
+	checker.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report := checker.Check(context.Background())
+	require.Equal(t, StatusFailed, report.Status)
+	require.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+
+func TestCheckerShuttingDown(t *testing.T) {
+	checker := NewChecker()
+	checker.Register("ok", func(_ context.Context) error { return nil })
+	checker.SetShuttingDown()
+
+	report := checker.Check(context.Background())
+	require.Equal(t, StatusShuttingDown, report.Status)
+	require.Empty(t, report.Checks)
+}
+
+func TestWorkers(t *testing.T) {
+	workers := NewWorkers()
+	require.NoError(t, workers.Check(context.Background()))
+
+	workers.Register("relay")
+	workers.Register("mailer")
+	require.EqualError(t, workers.Check(context.Background()), "not running: mailer, relay")
+
+	workers.Started("relay")
+	workers.Started("mailer")
+	require.NoError(t, workers.Check(context.Background()))
+
+	workers.Stopped("relay")
+	require.EqualError(t, workers.Check(context.Background()), "not running: relay")
+}
@@ -0,0 +1,67 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Workers tracks whether long-running background components are up
+type Workers struct {
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+// NewWorkers creates an empty worker registry
+func NewWorkers() *Workers {
+	return &Workers{running: make(map[string]bool)}
+}
+
+// Register adds a component that is expected to be running; it counts as
+// stopped until Started is called
+func (w *Workers) Register(name string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.running[name]; !ok {
+		w.running[name] = false
+	}
+}
+
+// Started marks the named component as running
+func (w *Workers) Started(name string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.running[name] = true
+}
+
+// Stopped marks the named component as no longer running
+func (w *Workers) Stopped(name string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.running[name] = false
+}
+
+// Check fails if any registered component has stopped
+func (w *Workers) Check(_ context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var stopped []string
+	for name, running := range w.running {
+		if !running {
+			stopped = append(stopped, name)
+		}
+	}
+
+	if len(stopped) > 0 {
+		slices.Sort(stopped)
+		return fmt.Errorf("not running: %s", strings.Join(stopped, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+// Package interest accrues interest on savings products every day and pays
+// it out once a month.
+//
+// Accrual records each account's interest for a date in millionths of a
+// minor unit, computed exactly from the balance at the end of the day and
+// the annual rate of the account's type. Posting pays the whole units
+// accrued up to the end of a month with a transfer from the bank's interest
+// expense account. Both are recorded once per account and date or month, so
+// running either again does nothing more.
+package interest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/util"
+)
+
+// Store is the subset of db.Store used to accrue and post interest.
+type Store interface {
+	ListInterestBearingAccounts(ctx context.Context, arg db.ListInterestBearingAccountsParams) ([]db.ListInterestBearingAccountsRow, error)
+	CreateInterestAccrual(ctx context.Context, arg db.CreateInterestAccrualParams) (int64, error)
+	ListAccountsWithUnpostedInterest(ctx context.Context, before pgtype.Date) ([]int64, error)
+	PostInterestTx(ctx context.Context, args db.PostInterestTxParams) (db.InterestPosting, error)
+}
+
+// Rates are annual interest rates by account type.
+type Rates map[string]*big.Rat
+
+// ParseRates parses rates written as in the INTEREST_RATES setting, such
+// as "savings=0.035", and checks that they name account types.
+func ParseRates(s string) (Rates, error) {
+	rates, err := util.ParseRates(s)
+	if err != nil {
+		return nil, err
+	}
+
+	for accountType := range rates {
+		if !slices.Contains(db.AccountTypes, accountType) {
+			return nil, fmt.Errorf("%q is not an account type", accountType)
+		}
+	}
+
+	return rates, nil
+}
+
+// types returns the account types that earn interest, in order.
+func (r Rates) types() []string {
+	var types []string
+	for accountType, rate := range r {
+		if rate.Sign() > 0 {
+			types = append(types, accountType)
+		}
+	}
+
+	slices.Sort(types)
+
+	return types
+}
+
+// DailyMicros is the interest a balance earns over date at an annual rate,
+// in millionths of a minor unit and rounded down. A year is the actual
+// number of days in the year of date, 365 or 366. Balances of 0 or less
+// earn nothing.
+func DailyMicros(balance int64, rate *big.Rat, date time.Time) int64 {
+	if balance <= 0 || rate.Sign() <= 0 {
+		return 0
+	}
+
+	num := new(big.Int).Mul(big.NewInt(balance), big.NewInt(db.MicrosPerUnit))
+	num.Mul(num, rate.Num())
+
+	den := new(big.Int).Mul(rate.Denom(), big.NewInt(int64(daysInYear(date.Year()))))
+
+	return num.Quo(num, den).Int64()
+}
+
+func daysInYear(year int) int {
+	return time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+}
+
+// Day returns the UTC date t falls on, at midnight.
+func Day(t time.Time) time.Time {
+	return util.StartOfDay(t)
+}
+
+// Month returns the first day of the UTC month t falls in, at midnight.
+func Month(t time.Time) time.Time {
+	return util.StartOfMonth(t)
+}
+
+// Accrual is the outcome of accruing interest for a date.
+type Accrual struct {
+	Date time.Time `json:"date"`
+	// Accounts is the number of accounts that earned interest.
+	Accounts int `json:"accounts"`
+	// Created is how many of them had not accrued for the date before.
+	Created int `json:"created"`
+	// Micros is the interest the created accruals add up to.
+	Micros int64 `json:"micros"`
+}
+
+// Accrue records the interest every account earning rates accrued over the
+// UTC date of date, using its balance at the end of that day. Accounts that
+// already accrued for the date are skipped, so a failed run can be repeated.
+func Accrue(ctx context.Context, store Store, rates Rates, date time.Time) (Accrual, error) {
+	date = Day(date)
+	accrual := Accrual{Date: date}
+
+	types := rates.types()
+	if len(types) == 0 {
+		return accrual, nil
+	}
+
+	accounts, err := store.ListInterestBearingAccounts(ctx, db.ListInterestBearingAccountsParams{
+		DayEnd: pgtype.Timestamptz{Time: date.AddDate(0, 0, 1), Valid: true},
+		Types:  types,
+	})
+	if err != nil {
+		return accrual, fmt.Errorf("cannot list accounts: %w", err)
+	}
+
+	for _, account := range accounts {
+		if account.Balance <= 0 {
+			continue
+		}
+
+		rate := rates[account.Type]
+		micros := DailyMicros(account.Balance, rate, date)
+
+		created, err := store.CreateInterestAccrual(ctx, db.CreateInterestAccrualParams{
+			AccountID:    account.ID,
+			AccrualDate:  pgtype.Date{Time: date, Valid: true},
+			Balance:      account.Balance,
+			Rate:         formatRate(rate),
+			AmountMicros: micros,
+		})
+		if err != nil {
+			return accrual, fmt.Errorf("cannot accrue interest on account %d: %w", account.ID, err)
+		}
+
+		accrual.Accounts++
+		if created > 0 {
+			accrual.Created++
+			accrual.Micros += micros
+		}
+	}
+
+	return accrual, nil
+}
+
+// formatRate writes rate as the decimal fraction it was parsed from.
+func formatRate(rate *big.Rat) string {
+	ten := big.NewInt(10)
+	pow := big.NewInt(1)
+	rem := new(big.Int)
+
+	for prec := 0; ; prec++ {
+		if rem.Mod(pow, rate.Denom()).Sign() == 0 {
+			return rate.FloatString(prec)
+		}
+
+		pow.Mul(pow, ten)
+	}
+}
+
+// Failure is an account whose interest could not be posted.
+type Failure struct {
+	AccountID int64  `json:"account_id"`
+	Error     string `json:"error"`
+}
+
+// Posting is the outcome of posting interest for a month.
+type Posting struct {
+	Month time.Time `json:"month"`
+	// Posted is the number of accounts whose interest was posted.
+	Posted int `json:"posted"`
+	// Skipped is the number of accounts already posted for the month.
+	Skipped  int       `json:"skipped"`
+	Failures []Failure `json:"failures"`
+}
+
+// Post pays every account the interest it accrued up to the end of the UTC
+// month of month and has not been paid. An account that fails, for example
+// because it is frozen, is reported and left for a later run; the others are
+// still posted. The returned error is only set when the accounts cannot be
+// listed.
+func Post(ctx context.Context, store Store, month time.Time) (Posting, error) {
+	month = Month(month)
+	posting := Posting{Month: month}
+
+	ids, err := store.ListAccountsWithUnpostedInterest(ctx, pgtype.Date{Time: month.AddDate(0, 1, 0), Valid: true})
+	if err != nil {
+		return posting, fmt.Errorf("cannot list accounts: %w", err)
+	}
+
+	for _, id := range ids {
+		_, err := store.PostInterestTx(ctx, db.PostInterestTxParams{AccountID: id, Month: month})
+		switch {
+		case errors.Is(err, db.ErrInterestAlreadyPosted):
+			posting.Skipped++
+		case err != nil:
+			posting.Failures = append(posting.Failures, Failure{AccountID: id, Error: err.Error()})
+		default:
+			posting.Posted++
+		}
+	}
+
+	return posting, nil
+}
@@ -0,0 +1,175 @@
+package interest
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDailyMicros(t *testing.T) {
+	rate := big.NewRat(35, 1000)
+
+	testCases := []struct {
+		name    string
+		balance int64
+		date    time.Time
+		micros  int64
+	}{
+		// 100000 * 0.035 / 365 = 9.589041... units
+		{name: "CommonYear", balance: 100000, date: date(2025, time.March, 1), micros: 9589041},
+		// 100000 * 0.035 / 366 = 9.562841... units
+		{name: "LeapYear", balance: 100000, date: date(2024, time.March, 1), micros: 9562841},
+		{name: "OneUnit", balance: 1, date: date(2025, time.March, 1), micros: 95},
+		{name: "Zero", balance: 0, date: date(2025, time.March, 1), micros: 0},
+		{name: "Negative", balance: -100000, date: date(2025, time.March, 1), micros: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.micros, DailyMicros(tc.balance, rate, tc.date))
+		})
+	}
+}
+
+func TestDailyMicrosLargeBalance(t *testing.T) {
+	// balance * 10^6 * 3 overflows int64, the quotient does not.
+	balance := int64(1) << 50
+	micros := DailyMicros(balance, big.NewRat(3, 100), date(2025, time.June, 1))
+
+	want := new(big.Int).Mul(big.NewInt(balance), big.NewInt(3*db.MicrosPerUnit))
+	want.Quo(want, big.NewInt(100*365))
+	require.True(t, want.IsInt64())
+	require.Equal(t, want.Int64(), micros)
+}
+
+func TestParseRates(t *testing.T) {
+	rates, err := ParseRates("savings=0.035,checking=0")
+	require.NoError(t, err)
+	require.Equal(t, []string{db.AccountTypeSavings}, rates.types())
+
+	_, err = ParseRates("brokerage=0.01")
+	require.Error(t, err)
+}
+
+func TestFormatRate(t *testing.T) {
+	for _, s := range []string{"0", "0.035", "0.0001", "1"} {
+		rates, err := ParseRates(db.AccountTypeSavings + "=" + s)
+		require.NoError(t, err)
+		require.Equal(t, s, formatRate(rates[db.AccountTypeSavings]))
+	}
+}
+
+func TestAccrue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	day := date(2025, time.March, 1)
+	rates := Rates{db.AccountTypeSavings: big.NewRat(35, 1000), db.AccountTypeBusiness: big.NewRat(0, 1)}
+
+	store.EXPECT().
+		ListInterestBearingAccounts(gomock.Any(), gomock.Eq(db.ListInterestBearingAccountsParams{
+			DayEnd: pgtype.Timestamptz{Time: date(2025, time.March, 2), Valid: true},
+			Types:  []string{db.AccountTypeSavings},
+		})).
+		Times(1).
+		Return([]db.ListInterestBearingAccountsRow{
+			{ID: 1, Type: db.AccountTypeSavings, Currency: "USD", Balance: 100000},
+			{ID: 2, Type: db.AccountTypeSavings, Currency: "USD", Balance: 0},
+			{ID: 3, Type: db.AccountTypeSavings, Currency: "EUR", Balance: 200000},
+		}, nil)
+
+	store.EXPECT().
+		CreateInterestAccrual(gomock.Any(), gomock.Eq(db.CreateInterestAccrualParams{
+			AccountID:    1,
+			AccrualDate:  pgtype.Date{Time: day, Valid: true},
+			Balance:      100000,
+			Rate:         "0.035",
+			AmountMicros: 9589041,
+		})).
+		Times(1).
+		Return(int64(1), nil)
+
+	// Account 3 accrued in an earlier run.
+	store.EXPECT().
+		CreateInterestAccrual(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateInterestAccrualParams) (int64, error) {
+			require.Equal(t, int64(3), arg.AccountID)
+			return 0, nil
+		})
+
+	// The time of day and time zone of the date do not matter.
+	accrual, err := Accrue(context.Background(), store, rates, day.Add(20*time.Hour).In(time.FixedZone("", 5*3600)))
+	require.NoError(t, err)
+	require.Equal(t, Accrual{Date: day, Accounts: 2, Created: 1, Micros: 9589041}, accrual)
+}
+
+func TestAccrueNoRates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListInterestBearingAccounts(gomock.Any(), gomock.Any()).Times(0)
+
+	accrual, err := Accrue(context.Background(), store, Rates{}, date(2025, time.March, 1))
+	require.NoError(t, err)
+	require.Zero(t, accrual.Accounts)
+}
+
+func TestPost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	month := date(2025, time.February, 1)
+
+	store.EXPECT().
+		ListAccountsWithUnpostedInterest(gomock.Any(), gomock.Eq(pgtype.Date{Time: date(2025, time.March, 1), Valid: true})).
+		Times(1).
+		Return([]int64{1, 2, 3}, nil)
+
+	store.EXPECT().
+		PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{AccountID: 1, Month: month})).
+		Times(1).
+		Return(db.InterestPosting{ID: 1, AccountID: 1, Amount: 26}, nil)
+	store.EXPECT().
+		PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{AccountID: 2, Month: month})).
+		Times(1).
+		Return(db.InterestPosting{}, db.ErrInterestAlreadyPosted)
+	store.EXPECT().
+		PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{AccountID: 3, Month: month})).
+		Times(1).
+		Return(db.InterestPosting{}, db.ErrAccountFrozen)
+
+	posting, err := Post(context.Background(), store, date(2025, time.February, 17))
+	require.NoError(t, err)
+	require.Equal(t, Posting{
+		Month:    month,
+		Posted:   1,
+		Skipped:  1,
+		Failures: []Failure{{AccountID: 3, Error: db.ErrAccountFrozen.Error()}},
+	}, posting)
+}
+
+func TestPostListFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		ListAccountsWithUnpostedInterest(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil, errors.New("connection reset"))
+	store.EXPECT().PostInterestTx(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := Post(context.Background(), store, date(2025, time.February, 1))
+	require.Error(t, err)
+}
@@ -0,0 +1,130 @@
+// Package ledger reports on the bank's general ledger.
+//
+// Every account is booked to a ledger account of the chart of accounts:
+// customer accounts to customer deposits, system accounts to their own
+// code. Balances are summed from the entries of the accounts; a positive
+// balance is a credit and a negative one a debit. As every transfer books
+// the same amount to two accounts, the debits and credits of each currency
+// are equal. The balance stored on each account is checked against its
+// entries, so money created outside a transfer shows up as well.
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	db "github.com/shevgn/simplebank/db/sqlc"
+)
+
+var isCode = regexp.MustCompile(`^[1-5][0-9]{3}$`).MatchString
+
+// TypeOfCode returns the type of the ledger accounts a code is for. Codes
+// have four digits, and the first is the type in the order of
+// db.LedgerTypes: 1 for assets up to 5 for expenses.
+func TypeOfCode(code string) (string, error) {
+	if !isCode(code) {
+		return "", fmt.Errorf("ledger code %q is not four digits starting with 1 to 5", code)
+	}
+
+	return db.LedgerTypes[code[0]-'1'], nil
+}
+
+// Store is the subset of db.Store used to report on the ledger.
+type Store interface {
+	ListTrialBalance(ctx context.Context, customerCode string) ([]db.ListTrialBalanceRow, error)
+}
+
+// Line is a ledger account's balance in a currency.
+type Line struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Accounts int64  `json:"accounts"`
+	Debit    int64  `json:"debit"`
+	Credit   int64  `json:"credit"`
+	// Mismatched counts the accounts whose balance differs from the sum of
+	// their entries.
+	Mismatched int64 `json:"mismatched"`
+}
+
+// Currency is the trial balance of a currency.
+type Currency struct {
+	Currency string `json:"currency"`
+	Lines    []Line `json:"lines"`
+	Debits   int64  `json:"debits"`
+	Credits  int64  `json:"credits"`
+	// Mismatched counts the accounts whose balance differs from the sum of
+	// their entries.
+	Mismatched int64 `json:"mismatched"`
+}
+
+// Balanced reports whether the debits equal the credits.
+func (c Currency) Balanced() bool {
+	return c.Debits == c.Credits
+}
+
+// Reconciled reports whether every account's balance matches its entries.
+func (c Currency) Reconciled() bool {
+	return c.Mismatched == 0
+}
+
+// TrialBalance lists the balance of every ledger account that has accounts,
+// per currency.
+type TrialBalance struct {
+	Currencies []Currency `json:"currencies"`
+}
+
+// Balanced reports whether every currency is balanced.
+func (t TrialBalance) Balanced() bool {
+	for _, currency := range t.Currencies {
+		if !currency.Balanced() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Reconciled reports whether every currency is reconciled.
+func (t TrialBalance) Reconciled() bool {
+	for _, currency := range t.Currencies {
+		if !currency.Reconciled() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewTrialBalance reads the trial balance of the whole ledger.
+func NewTrialBalance(ctx context.Context, store Store) (TrialBalance, error) {
+	rows, err := store.ListTrialBalance(ctx, db.LedgerCustomerDeposits)
+	if err != nil {
+		return TrialBalance{}, fmt.Errorf("cannot read trial balance: %w", err)
+	}
+
+	var report TrialBalance
+
+	// Rows come ordered by currency, so each currency's rows are together.
+	for _, row := range rows {
+		if n := len(report.Currencies); n == 0 || report.Currencies[n-1].Currency != row.Currency {
+			report.Currencies = append(report.Currencies, Currency{Currency: row.Currency})
+		}
+		currency := &report.Currencies[len(report.Currencies)-1]
+
+		line := Line{Code: row.Code, Name: row.Name, Type: row.Type, Accounts: row.Accounts, Mismatched: row.Mismatched}
+		if row.Balance < 0 {
+			line.Debit = -row.Balance
+		} else {
+			line.Credit = row.Balance
+		}
+
+		currency.Lines = append(currency.Lines, line)
+		currency.Debits += line.Debit
+		currency.Credits += line.Credit
+		currency.Mismatched += line.Mismatched
+	}
+
+	return report, nil
+}
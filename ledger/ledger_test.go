@@ -0,0 +1,97 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNewTrialBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		ListTrialBalance(gomock.Any(), gomock.Eq(db.LedgerCustomerDeposits)).
+		Times(1).
+		Return([]db.ListTrialBalanceRow{
+			{Code: db.LedgerCash, Name: "Cash", Type: db.LedgerTypeAsset, Currency: util.EUR, Accounts: 1, Balance: -5000},
+			{Code: db.LedgerCustomerDeposits, Name: "Customer deposits", Type: db.LedgerTypeLiability, Currency: util.EUR, Accounts: 3, Balance: 4990},
+			{Code: db.LedgerFeeRevenue, Name: "Fee revenue", Type: db.LedgerTypeIncome, Currency: util.EUR, Accounts: 1, Balance: 10},
+			{Code: db.LedgerCustomerDeposits, Name: "Customer deposits", Type: db.LedgerTypeLiability, Currency: util.USD, Accounts: 2, Balance: 120, Mismatched: 1},
+			{Code: db.LedgerInterestExpense, Name: "Interest expense", Type: db.LedgerTypeExpense, Currency: util.USD, Accounts: 1, Balance: -100},
+		}, nil)
+
+	report, err := NewTrialBalance(context.Background(), store)
+	require.NoError(t, err)
+	require.Len(t, report.Currencies, 2)
+
+	eur := report.Currencies[0]
+	require.Equal(t, util.EUR, eur.Currency)
+	require.Len(t, eur.Lines, 3)
+	require.Equal(t, int64(5000), eur.Lines[0].Debit)
+	require.Equal(t, int64(4990), eur.Lines[1].Credit)
+	require.Equal(t, int64(5000), eur.Debits)
+	require.Equal(t, int64(5000), eur.Credits)
+	require.True(t, eur.Balanced())
+	require.True(t, eur.Reconciled())
+
+	// 20 of customer deposits were not booked against any other account,
+	// and one account's balance does not match its entries.
+	usd := report.Currencies[1]
+	require.Equal(t, int64(100), usd.Debits)
+	require.Equal(t, int64(120), usd.Credits)
+	require.False(t, usd.Balanced())
+	require.Equal(t, int64(1), usd.Mismatched)
+	require.False(t, usd.Reconciled())
+
+	require.False(t, report.Balanced())
+	require.False(t, report.Reconciled())
+}
+
+func TestNewTrialBalanceEmpty(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().ListTrialBalance(gomock.Any(), gomock.Any()).Times(1).Return([]db.ListTrialBalanceRow{}, nil)
+
+	report, err := NewTrialBalance(context.Background(), store)
+	require.NoError(t, err)
+	require.Empty(t, report.Currencies)
+	require.True(t, report.Balanced())
+	require.True(t, report.Reconciled())
+}
+
+func TestNewTrialBalanceFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().ListTrialBalance(gomock.Any(), gomock.Any()).Times(1).Return(nil, errors.New("connection reset"))
+
+	_, err := NewTrialBalance(context.Background(), store)
+	require.Error(t, err)
+}
+
+func TestTypeOfCode(t *testing.T) {
+	for code, ledgerType := range map[string]string{
+		db.LedgerCash:             db.LedgerTypeAsset,
+		db.LedgerCustomerDeposits: db.LedgerTypeLiability,
+		db.LedgerOpeningBalances:  db.LedgerTypeEquity,
+		db.LedgerFXGains:          db.LedgerTypeIncome,
+		db.LedgerInterestExpense:  db.LedgerTypeExpense,
+	} {
+		got, err := TypeOfCode(code)
+		require.NoError(t, err)
+		require.Equal(t, ledgerType, got, code)
+	}
+
+	for _, code := range []string{"", "100", "10000", "6100", "0100", "1a00"} {
+		_, err := TypeOfCode(code)
+		require.Error(t, err, code)
+	}
+}
@@ -0,0 +1,135 @@
+// Package logging builds the application's structured JSON logger.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the request ID between clients and services.
+const RequestIDHeader = "X-Request-ID"
+
+// Redacted replaces the value of sensitive attributes and body fields.
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are never written to the log. Keys are matched
+// case-insensitively against attribute names and JSON body fields.
+var sensitiveKeys = map[string]bool{
+	"password":      true,
+	"old_password":  true,
+	"new_password":  true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"authorization": true,
+}
+
+// IsSensitive reports whether values stored under key must be redacted.
+func IsSensitive(key string) bool {
+	return sensitiveKeys[strings.ToLower(key)]
+}
+
+// New returns a JSON logger writing to w. Sensitive attributes are redacted
+// and the request ID and username stored in the context are added to every
+// record logged with one of the *Context methods.
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redactAttr,
+	})
+
+	return slog.New(&contextHandler{Handler: handler})
+}
+
+// ParseLevel converts a level name such as "debug" or "WARN" into a
+// slog.Level, defaulting to info for an empty name.
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if name == "" {
+		return slog.LevelInfo, nil
+	}
+
+	err := level.UnmarshalText([]byte(name))
+
+	return level, err
+}
+
+func redactAttr(_ []string, attr slog.Attr) slog.Attr {
+	if IsSensitive(attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+
+	return attr
+}
+
+// validRequestID restricts client supplied IDs to something safe to log and
+// echo back.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestIDOrNew returns requestID if it is acceptable as a request ID, or a
+// newly generated one otherwise.
+func RequestIDOrNew(requestID string) string {
+	if validRequestID.MatchString(requestID) {
+		return requestID
+	}
+
+	return uuid.NewString()
+}
+
+type requestIDKey struct{}
+
+type usernameKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID stored in ctx, if any.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+
+	return requestID
+}
+
+// WithUsername returns a copy of ctx carrying the authenticated username.
+func WithUsername(ctx context.Context, username string) context.Context {
+	return context.WithValue(ctx, usernameKey{}, username)
+}
+
+// Username returns the authenticated username stored in ctx, if any.
+func Username(ctx context.Context) string {
+	username, _ := ctx.Value(usernameKey{}).(string)
+
+	return username
+}
+
+// contextHandler adds request-scoped attributes from the context.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+
+	if username := Username(ctx); username != "" {
+		record.AddAttrs(slog.String("username", username))
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func decodeLine(t *testing.T, buf *bytes.Buffer) map[string]any {
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+
+	return line
+}
+
+func TestLoggerRedactsAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo)
+
+	logger.Info("login", slog.String("username", "alice"), slog.String("Password", "secret"))
+
+	line := decodeLine(t, &buf)
+	require.Equal(t, "alice", line["username"])
+	require.Equal(t, Redacted, line["Password"])
+}
+
+func TestLoggerAddsContextAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo).With(slog.String("component", "test"))
+
+	ctx := WithUsername(WithRequestID(context.Background(), "req-1"), "alice")
+	logger.InfoContext(ctx, "hello")
+
+	line := decodeLine(t, &buf)
+	require.Equal(t, "req-1", line["request_id"])
+	require.Equal(t, "alice", line["username"])
+	require.Equal(t, "test", line["component"])
+}
+
+func TestLoggerLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelWarn)
+
+	logger.Info("dropped")
+	require.Zero(t, buf.Len())
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("")
+	require.NoError(t, err)
+	require.Equal(t, slog.LevelInfo, level)
+
+	level, err = ParseLevel("DEBUG")
+	require.NoError(t, err)
+	require.Equal(t, slog.LevelDebug, level)
+
+	_, err = ParseLevel("verbose")
+	require.Error(t, err)
+}
+
+func TestRequestIDOrNew(t *testing.T) {
+	require.Equal(t, "abc-123", RequestIDOrNew("abc-123"))
+
+	generated := RequestIDOrNew("")
+	require.NotEmpty(t, generated)
+	require.NotEqual(t, generated, RequestIDOrNew(""))
+
+	require.NotEqual(t, "bad id\n", RequestIDOrNew("bad id\n"))
+}
+
+func TestRedactJSON(t *testing.T) {
+	body := []byte(`{"username":"alice","password":"secret","nested":{"refresh_token":"x"},"items":[{"token":"y"}]}`)
+
+	var redacted map[string]any
+	require.NoError(t, json.Unmarshal(RedactJSON(body), &redacted))
+
+	require.Equal(t, "alice", redacted["username"])
+	require.Equal(t, Redacted, redacted["password"])
+	require.Equal(t, Redacted, redacted["nested"].(map[string]any)["refresh_token"])
+	require.Equal(t, Redacted, redacted["items"].([]any)[0].(map[string]any)["token"])
+
+	require.JSONEq(t, `"[REDACTED]"`, string(RedactJSON([]byte(`password=secret`))))
+	require.Nil(t, RedactJSON(nil))
+}
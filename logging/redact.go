@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"encoding/json"
+)
+
+// RedactJSON returns body with the values of sensitive fields replaced,
+// at any depth. Bodies that are not valid JSON are dropped entirely, since
+// there is no way to tell which part of them is a secret.
+func RedactJSON(body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		redacted, _ := json.Marshal(Redacted)
+		return redacted
+	}
+
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return nil
+	}
+
+	return redacted
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if IsSensitive(key) {
+				v[key] = Redacted
+				continue
+			}
+
+			v[key] = redactValue(field)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+
+	return value
+}
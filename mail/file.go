@@ -0,0 +1,79 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileSender writes every message to its own .eml file in a directory, where
+// it can be opened with a mail client. It is meant for local development.
+type FileSender struct {
+	from string
+	dir  string
+	now  func() time.Time
+}
+
+// NewFileSender creates a FileSender that sends from the given address and
+// writes to dir, creating it if needed.
+func NewFileSender(from, dir string) *FileSender {
+	return &FileSender{
+		from: from,
+		dir:  dir,
+		now:  time.Now,
+	}
+}
+
+// Send implements Sender.
+func (s *FileSender) Send(ctx context.Context, msg Message) error {
+	if msg.From == "" {
+		msg.From = s.from
+	}
+
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return fmt.Errorf("cannot create mail directory: %w", err)
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+
+	now := s.now()
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000Z"), hex.EncodeToString(suffix))
+	path := filepath.Join(s.dir, name)
+
+	if err := os.WriteFile(path, formatMessage(msg, now), 0o640); err != nil {
+		return fmt.Errorf("cannot write mail: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Mail written",
+		slog.String("to", msg.To),
+		slog.String("subject", msg.Subject),
+		slog.String("path", path),
+	)
+
+	return nil
+}
+
+// formatMessage renders msg as an RFC 5322 message with CRLF line endings.
+func formatMessage(msg Message, date time.Time) []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "From: %s\r\n", msg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return []byte(b.String())
+}
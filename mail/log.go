@@ -0,0 +1,34 @@
+package mail
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogSender writes every message to the default logger instead of sending
+// it. It is meant for local development: links in the body, such as password
+// reset tokens, end up in the log.
+type LogSender struct {
+	from string
+}
+
+// NewLogSender creates a LogSender that sends from the given address.
+func NewLogSender(from string) *LogSender {
+	return &LogSender{from: from}
+}
+
+// Send implements Sender.
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	if msg.From == "" {
+		msg.From = s.from
+	}
+
+	slog.InfoContext(ctx, "Mail sent",
+		slog.String("from", msg.From),
+		slog.String("to", msg.To),
+		slog.String("subject", msg.Subject),
+		slog.String("body", msg.Body),
+	)
+
+	return nil
+}
@@ -0,0 +1,41 @@
+// Package mail sends transactional email, such as password reset links,
+// through a pluggable Sender.
+package mail
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shevgn/simplebank/util"
+)
+
+// Supported values of Config.Mailer.
+const (
+	MailerLog  = "log"
+	MailerFile = "file"
+)
+
+// Message is a plain text email.
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers messages. Implementations fill in From when it is empty.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New returns the sender selected by config.Mailer.
+func New(config *util.Config) (Sender, error) {
+	switch config.Mailer {
+	case "", MailerLog:
+		return NewLogSender(config.MailFrom), nil
+	case MailerFile:
+		return NewFileSender(config.MailFrom, config.MailDir), nil
+	default:
+		return nil, fmt.Errorf("unsupported mailer %q", config.Mailer)
+	}
+}
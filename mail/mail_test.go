@@ -0,0 +1,71 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shevgn/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	sender, err := New(&util.Config{Mailer: MailerLog})
+	require.NoError(t, err)
+	require.IsType(t, &LogSender{}, sender)
+
+	sender, err = New(&util.Config{Mailer: MailerFile, MailDir: t.TempDir()})
+	require.NoError(t, err)
+	require.IsType(t, &FileSender{}, sender)
+
+	_, err = New(&util.Config{Mailer: "smtp"})
+	require.Error(t, err)
+}
+
+func TestFileSender(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	sender := NewFileSender("bank@example.com", dir)
+
+	err := sender.Send(context.Background(), Message{
+		To:      "alice@example.com",
+		Subject: "Hello",
+		Body:    "line 1\nline 2\n",
+	})
+	require.NoError(t, err)
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, ".eml", filepath.Ext(files[0].Name()))
+
+	data, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	require.NoError(t, err)
+	require.Contains(t, string(data), "From: bank@example.com\r\n")
+	require.Contains(t, string(data), "To: alice@example.com\r\n")
+	require.Contains(t, string(data), "Subject: Hello\r\n")
+	require.Contains(t, string(data), "\r\n\r\nline 1\r\nline 2\r\n")
+}
+
+func TestNewPasswordResetMessage(t *testing.T) {
+	msg, err := NewPasswordResetMessage(
+		"alice@example.com", "Alice", "https://bank.example.com/reset?lang=en", "abc-123", time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, "alice@example.com", msg.To)
+	require.Contains(t, msg.Body, "Hello Alice")
+	require.Contains(t, msg.Body, "https://bank.example.com/reset?lang=en&token=abc-123")
+	require.Contains(t, msg.Body, "1h0m0s")
+
+	_, err = NewPasswordResetMessage("alice@example.com", "Alice", "://bad", "abc", time.Hour)
+	require.Error(t, err)
+}
+
+func TestNewVerifyEmailMessage(t *testing.T) {
+	msg, err := NewVerifyEmailMessage(
+		"alice@example.com", "Alice", "http://localhost:8080/users/verify_email", "abc-123", 24*time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, "alice@example.com", msg.To)
+	require.Contains(t, msg.Body, "Hello Alice")
+	require.Contains(t, msg.Body, "http://localhost:8080/users/verify_email?token=abc-123")
+}
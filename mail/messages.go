@@ -0,0 +1,72 @@
+package mail
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// NewPasswordResetMessage asks the user to follow a link, built from
+// resetURL and the reset token, to choose a new password.
+func NewPasswordResetMessage(to, fullName, resetURL, token string, validFor time.Duration) (Message, error) {
+	link, err := withToken(resetURL, token)
+	if err != nil {
+		return Message{}, err
+	}
+
+	body := fmt.Sprintf(`Hello %s,
+
+Someone asked to reset the password of your SimpleBank account. To choose a
+new password, open the link below within %s:
+
+%s
+
+If you did not ask for this, you can ignore this email; your password stays
+the same.
+`, fullName, validFor, link)
+
+	return Message{
+		To:      to,
+		Subject: "Reset your SimpleBank password",
+		Body:    body,
+	}, nil
+}
+
+// withToken adds token to the query of rawURL.
+func withToken(rawURL, token string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid link: %w", err)
+	}
+
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// NewVerifyEmailMessage asks a new user to confirm their address by
+// following a link built from verifyURL and the verification token.
+func NewVerifyEmailMessage(to, fullName, verifyURL, token string, validFor time.Duration) (Message, error) {
+	link, err := withToken(verifyURL, token)
+	if err != nil {
+		return Message{}, err
+	}
+
+	body := fmt.Sprintf(`Hello %s,
+
+Welcome to SimpleBank! Please confirm your email address by opening the link
+below within %s:
+
+%s
+
+You can create transfers once your email is confirmed.
+`, fullName, validFor, link)
+
+	return Message{
+		To:      to,
+		Subject: "Confirm your SimpleBank email address",
+		Body:    body,
+	}, nil
+}
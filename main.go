@@ -3,32 +3,513 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/shevgn/simplebank/api"
+	"github.com/shevgn/simplebank/db/migration"
 	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/gapi"
+	"github.com/shevgn/simplebank/health"
+	"github.com/shevgn/simplebank/logging"
+	"github.com/shevgn/simplebank/mail"
+	"github.com/shevgn/simplebank/metrics"
+	"github.com/shevgn/simplebank/notify"
+	"github.com/shevgn/simplebank/outbox"
+	"github.com/shevgn/simplebank/pb"
+	"github.com/shevgn/simplebank/ratelimit"
+	"github.com/shevgn/simplebank/telemetry"
 	"github.com/shevgn/simplebank/util"
+	"github.com/shevgn/simplebank/worker"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
+var interruptSignals = []os.Signal{
+	os.Interrupt,
+	syscall.SIGTERM,
+}
+
+// shutdownFunc stops a running component, giving up once ctx expires.
+type shutdownFunc func(ctx context.Context) error
+
 func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		fatal("Command failed", err)
+	}
+}
+
+// newRootCommand serves the API when run without a subcommand.
+func newRootCommand() *cobra.Command {
+	var config *util.Config
+
+	root := &cobra.Command{
+		Use:   "simplebank",
+		Short: "Simple bank API server",
+		Args:  cobra.NoArgs,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			// Arguments are valid at this point; errors from here on are
+			// not usage errors.
+			cmd.SilenceUsage = true
+
+			// The servers log to stdout; other commands keep stdout for
+			// their output.
+			logOutput := cmd.ErrOrStderr()
+			if !cmd.HasParent() || cmd.Name() == "serve" {
+				logOutput = os.Stdout
+			}
+
+			var err error
+			config, err = loadConfig(logOutput)
+
+			return err
+		},
+		Run: func(_ *cobra.Command, _ []string) {
+			serve(config)
+		},
+		SilenceErrors:     true,
+		CompletionOptions: cobra.CompletionOptions{DisableDefaultCmd: true},
+	}
+
+	getConfig := func() *util.Config { return config }
+
+	root.AddCommand(
+		&cobra.Command{
+			Use:   "serve",
+			Short: "Run the HTTP, gRPC and gateway servers",
+			Args:  cobra.NoArgs,
+			Run: func(_ *cobra.Command, _ []string) {
+				serve(config)
+			},
+		},
+		newMigrateCommand(getConfig),
+	)
+	root.AddCommand(newAdminCommands(getConfig)...)
+
+	return root
+}
+
+// loadConfig reads the configuration and installs the default logger.
+func loadConfig(logOutput io.Writer) (*util.Config, error) {
 	config, err := util.LoadConfig(".")
 	if err != nil {
-		log.Fatal("Cannot load config:", err)
+		return nil, fmt.Errorf("cannot load config: %w", err)
+	}
+
+	level, err := logging.ParseLevel(config.LogLevel)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level: %w", err)
+	}
+
+	slog.SetDefault(logging.New(logOutput, level))
+
+	return config, nil
+}
+
+func serve(config *util.Config) {
+	if config.MigrateOnStart {
+		if err := migrateUp(config); err != nil {
+			fatal("Cannot migrate database", err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), interruptSignals...)
+	defer stop()
+
+	shutdownTracing, err := telemetry.Setup(ctx, config)
+	if err != nil {
+		fatal("Cannot set up tracing", err)
+	}
+
+	connPool, err := pgxpool.New(context.Background(), config.DBSource)
+	if err != nil {
+		fatal("Cannot connect to database", err)
+	}
+
+	store := db.NewStore(connPool)
+
+	limiter, err := ratelimit.New(config.RateLimitBackend, store)
+	if err != nil {
+		fatal("Cannot create rate limiter", err)
+	}
+
+	mailer, err := mail.New(config)
+	if err != nil {
+		fatal("Cannot create mailer", err)
+	}
+
+	taskQueue := worker.NewPostgresQueue(store, config.WorkerMaxAttempts)
+
+	eventSink, err := outbox.New(config)
+	if err != nil {
+		fatal("Cannot create outbox sink", err)
+	}
+
+	// Events also fan out to the webhook endpoints of the users involved.
+	eventSink = outbox.MultiSink{eventSink, worker.NewWebhookFanout(store, taskQueue, config.WebhookMaxAttempts)}
+
+	// Balance changes committed on any replica reach the streams of this one.
+	hub := notify.NewHub()
+
+	prometheus.MustRegister(metrics.NewPoolCollector(connPool))
+
+	workers := health.NewWorkers()
+
+	checker := health.NewChecker()
+	checker.Register("database", connPool.Ping)
+	checker.Register("migrations", func(ctx context.Context) error {
+		return migration.CheckVersion(ctx, connPool)
+	})
+	checker.Register("workers", workers.Check)
+
+	group, ctx := errgroup.WithContext(ctx)
+
+	shutdowns := []shutdownFunc{
+		runGinServer(group, config, store, checker, limiter, taskQueue, hub),
+		runGRPCServer(group, config, store, limiter, taskQueue, hub, workers),
+		runGatewayServer(group, config, workers),
+	}
+
+	backgroundShutdowns := []shutdownFunc{
+		runTaskProcessor(config, store, mailer, taskQueue, workers),
+		runOutboxRelay(config, store, eventSink, workers),
+		runAccountListener(config, hub, workers),
+	}
+
+	group.Go(func() error {
+		<-ctx.Done()
+		slog.Info("Shutting down")
+		checker.SetShuttingDown()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+		defer cancel()
+
+		err := shutdown(shutdownCtx, shutdowns)
+
+		// Stop background workers after the servers. They only finish the
+		// batch they are running: queued tasks and unpublished events stay
+		// in the database for the next start.
+		if backgroundErr := shutdown(shutdownCtx, backgroundShutdowns); backgroundErr != nil {
+			err = errors.Join(err, backgroundErr)
+		}
+
+		closePool(shutdownCtx, connPool)
+
+		// Flush spans last so those of draining requests are exported too.
+		if tracingErr := shutdownTracing(shutdownCtx); tracingErr != nil {
+			slog.Error("Cannot flush traces", slog.Any("error", tracingErr))
+		}
+
+		return err
+	})
+
+	if err := group.Wait(); err != nil {
+		fatal("Server error", err)
+	}
+
+	slog.Info("Server stopped")
+}
+
+// fatal logs err and exits, like log.Fatal does for the standard logger.
+func fatal(msg string, err error) {
+	slog.Error(msg, slog.Any("error", err))
+	os.Exit(1)
+}
+
+// shutdown runs every shutdown function concurrently under the same deadline.
+func shutdown(ctx context.Context, shutdowns []shutdownFunc) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	for _, fn := range shutdowns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := fn(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// closePool waits for acquired connections to be released, but no longer
+// than the shutdown deadline allows.
+func closePool(ctx context.Context, connPool *pgxpool.Pool) {
+	done := make(chan struct{})
+	go func() {
+		connPool.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		slog.Info("Database pool closed")
+	case <-ctx.Done():
+		slog.Warn("Timed out closing database pool")
+	}
+}
+
+func runGinServer(
+	group *errgroup.Group,
+	config *util.Config,
+	store db.Store,
+	checker *health.Checker,
+	limiter ratelimit.Limiter,
+	distributor worker.Distributor,
+	hub *notify.Hub,
+) shutdownFunc {
+	server := api.NewServer(config, store, checker, limiter, distributor, hub)
+
+	group.Go(func() error {
+		slog.Info("Starting HTTP server", slog.String("address", config.ServerAddress))
+
+		return server.Start(config.ServerAddress)
+	})
+
+	return server.Shutdown
+}
+
+func runGRPCServer(
+	group *errgroup.Group,
+	config *util.Config,
+	store db.Store,
+	limiter ratelimit.Limiter,
+	distributor worker.Distributor,
+	hub *notify.Hub,
+	workers *health.Workers,
+) shutdownFunc {
+	server, err := gapi.NewServer(config, store, limiter, distributor, hub)
+	if err != nil {
+		fatal("Cannot create gRPC server", err)
 	}
 
-	conn, err := pgxpool.New(context.Background(), config.DBSource)
+	grpcServer := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(gapi.LoggerInterceptor, server.RateLimitInterceptor, server.AuthInterceptor),
+		grpc.StreamInterceptor(server.StreamAuthInterceptor),
+	)
+	pb.RegisterSimpleBankServer(grpcServer, server)
+	reflection.Register(grpcServer)
+
+	listener, err := net.Listen("tcp", config.GRPCServerAddress)
 	if err != nil {
-		log.Fatal("Cannot connect to database:", err)
+		fatal("Cannot create gRPC listener", err)
 	}
 
-	defer conn.Close()
+	workers.Register("grpc_server")
+
+	group.Go(func() error {
+		slog.Info("Starting gRPC server", slog.String("address", listener.Addr().String()))
+
+		workers.Started("grpc_server")
+		defer workers.Stopped("grpc_server")
+
+		err := grpcServer.Serve(listener)
+		if errors.Is(err, grpc.ErrServerStopped) {
+			return nil
+		}
+
+		return err
+	})
+
+	return func(ctx context.Context) error {
+		server.StopStreams()
+
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			grpcServer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// runTaskProcessor runs queued background tasks, and enqueues the periodic
+// ones on distributor when they are due, until shut down.
+func runTaskProcessor(
+	config *util.Config,
+	store db.Store,
+	mailer mail.Sender,
+	distributor worker.Distributor,
+	workers *health.Workers,
+) shutdownFunc {
+	handlers := worker.NewTaskHandlers(config, store, mailer).Handlers()
+	processor := worker.NewProcessor(store, handlers, worker.ProcessorConfig{
+		Concurrency:    config.WorkerConcurrency,
+		PollInterval:   config.WorkerPollInterval,
+		RetryBaseDelay: config.WorkerRetryBaseDelay,
+		RetryMaxDelay:  config.WorkerRetryMaxDelay,
+		Schedule: func(ctx context.Context, now time.Time) error {
+			return worker.Schedule(ctx, distributor, now)
+		},
+	})
+
+	workers.Register("task_processor")
+
+	go func() {
+		slog.Info("Starting task processor", slog.Int("concurrency", config.WorkerConcurrency))
+
+		workers.Started("task_processor")
+		defer workers.Stopped("task_processor")
+
+		processor.Run()
+	}()
+
+	return processor.Shutdown
+}
 
-	store := db.NewStore(conn)
-	server := api.NewServer(config, store)
+// runOutboxRelay publishes outbox events to sink until shut down.
+func runOutboxRelay(config *util.Config, store db.Store, sink outbox.Sink, workers *health.Workers) shutdownFunc {
+	relay := outbox.NewRelay(store, sink, int32(config.OutboxBatchSize), config.OutboxPollInterval)
 
-	err = server.Run(config.ServerAddress)
+	workers.Register("outbox_relay")
+
+	go func() {
+		slog.Info("Starting outbox relay", slog.String("sink", config.OutboxSink))
+
+		workers.Started("outbox_relay")
+		defer workers.Stopped("outbox_relay")
+
+		relay.Run()
+	}()
+
+	return relay.Shutdown
+}
+
+// runAccountListener passes balance changes from Postgres to hub until shut
+// down.
+func runAccountListener(config *util.Config, hub *notify.Hub, workers *health.Workers) shutdownFunc {
+	listener := notify.NewListener(config.DBSource, hub)
+
+	workers.Register("account_listener")
+
+	go func() {
+		slog.Info("Starting account notification listener")
+
+		workers.Started("account_listener")
+		defer workers.Stopped("account_listener")
+
+		listener.Run()
+	}()
+
+	return listener.Shutdown
+}
+
+// gatewayHeaderMatcher passes the request ID between HTTP clients and the
+// gRPC server in both directions, on top of the gateway's default headers.
+func gatewayHeaderMatcher(key string) (string, bool) {
+	if strings.EqualFold(key, logging.RequestIDHeader) {
+		return key, true
+	}
+
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// runGatewayServer translates HTTP/JSON requests into calls against the gRPC
+// server, so gateway traffic passes through the same interceptors.
+func runGatewayServer(group *errgroup.Group, config *util.Config, workers *health.Workers) shutdownFunc {
+	jsonOption := runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+		MarshalOptions: protojson.MarshalOptions{
+			UseProtoNames:   true,
+			EmitUnpopulated: true,
+		},
+		UnmarshalOptions: protojson.UnmarshalOptions{
+			DiscardUnknown: true,
+		},
+	})
+
+	grpcMux := runtime.NewServeMux(
+		jsonOption,
+		runtime.WithIncomingHeaderMatcher(gatewayHeaderMatcher),
+		runtime.WithOutgoingHeaderMatcher(gatewayHeaderMatcher),
+	)
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		gapi.GatewayDialOption(),
+	}
+
+	err := pb.RegisterSimpleBankHandlerFromEndpoint(context.Background(), grpcMux, config.GRPCServerAddress, opts)
 	if err != nil {
-		log.Fatal("Cannot start server:", err)
+		fatal("Cannot register gateway handler", err)
 	}
+
+	// otelhttp picks up the caller's trace context, which the gRPC client
+	// handler then forwards to the gRPC server.
+	handler := withoutStreamDeadlines(otelhttp.NewHandler(grpcMux, "http_gateway"))
+	httpServer := api.NewHTTPServer(config, config.HTTPGatewayAddress, handler)
+
+	workers.Register("http_gateway")
+
+	group.Go(func() error {
+		slog.Info("Starting HTTP gateway server", slog.String("address", config.HTTPGatewayAddress))
+
+		workers.Started("http_gateway")
+		defer workers.Stopped("http_gateway")
+
+		err := httpServer.ListenAndServe()
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+
+		return err
+	})
+
+	return httpServer.Shutdown
+}
+
+// gatewayStreamPaths serve server-streaming RPCs through the gateway.
+var gatewayStreamPaths = map[string]bool{
+	"/v1/accounts/stream": true,
+}
+
+// withoutStreamDeadlines lifts the server's read and write timeouts for
+// streaming responses, which stay open for as long as the client listens.
+func withoutStreamDeadlines(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if gatewayStreamPaths[r.URL.Path] {
+			rc := http.NewResponseController(w)
+			_ = rc.SetReadDeadline(time.Time{})
+			_ = rc.SetWriteDeadline(time.Time{})
+		}
+
+		next.ServeHTTP(w, r)
+	})
 }
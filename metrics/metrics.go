@@ -0,0 +1,146 @@
+// Package metrics exposes Prometheus metrics for the simplebank application.
+//
+// Labels are limited to values from small fixed sets (route templates, status
+// codes, currencies, task types and outcome names) to keep series cardinality
+// low.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "simplebank"
+
+// Outcome label values
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Failed login reasons
+const (
+	ReasonUserNotFound      = "user_not_found"
+	ReasonIncorrectPassword = "incorrect_password"
+	ReasonUserDisabled      = "user_disabled"
+	ReasonAccountLocked     = "account_locked"
+	ReasonRateLimited       = "rate_limited"
+)
+
+// Background job outcomes
+const (
+	JobResultDone  = "done"
+	JobResultRetry = "retry"
+	JobResultDead  = "dead"
+)
+
+var (
+	// HTTPRequestDuration observes handled HTTP requests by route template
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of HTTP requests by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// TransfersTotal counts completed transfers
+	TransfersTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfers_total",
+		Help:      "Number of completed transfers by currency.",
+	}, []string{"currency"})
+
+	// TransferAmountTotal sums transferred amounts in minor currency units
+	TransferAmountTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfer_amount_total",
+		Help:      "Sum of transferred amounts in minor units by currency.",
+	}, []string{"currency"})
+
+	// FailedLoginsTotal counts rejected login attempts
+	FailedLoginsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "failed_logins_total",
+		Help:      "Number of rejected login attempts by reason.",
+	}, []string{"reason"})
+
+	// TokenRenewalsTotal counts access token renewals
+	TokenRenewalsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "token_renewals_total",
+		Help:      "Number of access token renewals by result.",
+	}, []string{"result"})
+
+	// JobsTotal counts background job runs
+	JobsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "worker",
+		Name:      "jobs_total",
+		Help:      "Number of background job runs by task type and result.",
+	}, []string{"task", "result"})
+
+	// JobDuration observes the run time of background jobs
+	JobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "worker",
+		Name:      "job_duration_seconds",
+		Help:      "Duration of background job runs by task type.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"task"})
+
+	// OutboxEventsTotal counts attempts to publish outbox events
+	OutboxEventsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "events_total",
+		Help:      "Number of outbox event publish attempts by event type and result.",
+	}, []string{"event", "result"})
+
+	// StreamSubscribers counts open real-time account streams
+	StreamSubscribers = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "stream",
+		Name:      "subscribers",
+		Help:      "Number of open real-time account streams.",
+	})
+)
+
+// ObserveTransfer records a completed transfer
+func ObserveTransfer(currency string, amount int64) {
+	TransfersTotal.WithLabelValues(currency).Inc()
+	TransferAmountTotal.WithLabelValues(currency).Add(float64(amount))
+}
+
+// ObserveFailedLogin records a rejected login attempt
+func ObserveFailedLogin(reason string) {
+	FailedLoginsTotal.WithLabelValues(reason).Inc()
+}
+
+// ObserveTokenRenewal records an access token renewal attempt
+func ObserveTokenRenewal(success bool) {
+	result := ResultSuccess
+	if !success {
+		result = ResultFailure
+	}
+
+	TokenRenewalsTotal.WithLabelValues(result).Inc()
+}
+
+// ObserveJob records a background job run
+func ObserveJob(task, result string, duration time.Duration) {
+	JobsTotal.WithLabelValues(task, result).Inc()
+	JobDuration.WithLabelValues(task).Observe(duration.Seconds())
+}
+
+// ObserveOutboxEvent records an attempt to publish an outbox event
+func ObserveOutboxEvent(event string, success bool) {
+	result := ResultSuccess
+	if !success {
+		result = ResultFailure
+	}
+
+	OutboxEventsTotal.WithLabelValues(event, result).Inc()
+}
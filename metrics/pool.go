@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolStater is implemented by *pgxpool.Pool
+type PoolStater interface {
+	Stat() *pgxpool.Stat
+}
+
+// PoolCollector exports pgxpool statistics, read on every scrape
+type PoolCollector struct {
+	pool PoolStater
+
+	acquiredConns           *prometheus.Desc
+	idleConns               *prometheus.Desc
+	constructingConns       *prometheus.Desc
+	totalConns              *prometheus.Desc
+	maxConns                *prometheus.Desc
+	acquireCount            *prometheus.Desc
+	acquireDuration         *prometheus.Desc
+	canceledAcquireCount    *prometheus.Desc
+	emptyAcquireCount       *prometheus.Desc
+	newConnsCount           *prometheus.Desc
+	maxLifetimeDestroyCount *prometheus.Desc
+	maxIdleDestroyCount     *prometheus.Desc
+}
+
+// NewPoolCollector creates a collector for the given pool
+func NewPoolCollector(pool PoolStater) *PoolCollector {
+	desc := func(name string, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+
+	return &PoolCollector{
+		pool:                    pool,
+		acquiredConns:           desc("acquired_conns", "Connections currently acquired from the pool."),
+		idleConns:               desc("idle_conns", "Idle connections in the pool."),
+		constructingConns:       desc("constructing_conns", "Connections being established."),
+		totalConns:              desc("total_conns", "Total connections in the pool."),
+		maxConns:                desc("max_conns", "Maximum size of the pool."),
+		acquireCount:            desc("acquires_total", "Successful connection acquisitions."),
+		acquireDuration:         desc("acquire_duration_seconds_total", "Time spent acquiring connections."),
+		canceledAcquireCount:    desc("canceled_acquires_total", "Acquisitions canceled by their context."),
+		emptyAcquireCount:       desc("empty_acquires_total", "Acquisitions that had to wait for a connection."),
+		newConnsCount:           desc("new_conns_total", "Connections opened by the pool."),
+		maxLifetimeDestroyCount: desc("max_lifetime_destroys_total", "Connections closed for exceeding max lifetime."),
+		maxIdleDestroyCount:     desc("max_idle_destroys_total", "Connections closed for exceeding max idle time."),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.constructingConns
+	ch <- c.totalConns
+	ch <- c.maxConns
+	ch <- c.acquireCount
+	ch <- c.acquireDuration
+	ch <- c.canceledAcquireCount
+	ch <- c.emptyAcquireCount
+	ch <- c.newConnsCount
+	ch <- c.maxLifetimeDestroyCount
+	ch <- c.maxIdleDestroyCount
+}
+
+// Collect implements prometheus.Collector
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	gauge := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value)
+	}
+
+	counter := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value)
+	}
+
+	gauge(c.acquiredConns, float64(stat.AcquiredConns()))
+	gauge(c.idleConns, float64(stat.IdleConns()))
+	gauge(c.constructingConns, float64(stat.ConstructingConns()))
+	gauge(c.totalConns, float64(stat.TotalConns()))
+	gauge(c.maxConns, float64(stat.MaxConns()))
+	counter(c.acquireCount, float64(stat.AcquireCount()))
+	counter(c.acquireDuration, stat.AcquireDuration().Seconds())
+	counter(c.canceledAcquireCount, float64(stat.CanceledAcquireCount()))
+	counter(c.emptyAcquireCount, float64(stat.EmptyAcquireCount()))
+	counter(c.newConnsCount, float64(stat.NewConnsCount()))
+	counter(c.maxLifetimeDestroyCount, float64(stat.MaxLifetimeDestroyCount()))
+	counter(c.maxIdleDestroyCount, float64(stat.MaxIdleDestroyCount()))
+}
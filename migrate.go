@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/shevgn/simplebank/db/migration"
+	"github.com/shevgn/simplebank/util"
+	"github.com/spf13/cobra"
+)
+
+// newMigrateCommand manages the schema with the migrations embedded in the
+// binary. config is resolved when a subcommand runs, after the root command
+// has loaded it.
+func newMigrateCommand(config func() *util.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage the database schema",
+	}
+
+	var all bool
+
+	down := &cobra.Command{
+		Use:   "down [N]",
+		Short: "Revert the last N migrations (default 1)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			steps := 1
+			if all {
+				if len(args) > 0 {
+					return fmt.Errorf("--all cannot be combined with a number of steps")
+				}
+				steps = 0
+			} else if len(args) > 0 {
+				n, err := strconv.Atoi(args[0])
+				if err != nil || n < 1 {
+					return fmt.Errorf("invalid number of steps %q", args[0])
+				}
+				steps = n
+			}
+
+			return withMigrator(config(), func(m *migration.Migrator) error {
+				return m.Down(steps)
+			})
+		},
+	}
+	down.Flags().BoolVar(&all, "all", false, "revert every migration")
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "up",
+			Short: "Apply all pending migrations",
+			Args:  cobra.NoArgs,
+			RunE: func(_ *cobra.Command, _ []string) error {
+				return migrateUp(config())
+			},
+		},
+		down,
+		&cobra.Command{
+			Use:   "version",
+			Short: "Print the current schema version",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				return withMigrator(config(), func(m *migration.Migrator) error {
+					version, dirty, err := m.Version()
+					if err != nil {
+						return err
+					}
+
+					if dirty {
+						_, err = fmt.Fprintf(cmd.OutOrStdout(), "%d (dirty)\n", version)
+					} else {
+						_, err = fmt.Fprintf(cmd.OutOrStdout(), "%d\n", version)
+					}
+
+					return err
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "force VERSION",
+			Short: "Set the schema version without running migrations and clear the dirty flag",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(_ *cobra.Command, args []string) error {
+				version, err := strconv.Atoi(args[0])
+				if err != nil {
+					return fmt.Errorf("invalid version %q", args[0])
+				}
+
+				return withMigrator(config(), func(m *migration.Migrator) error {
+					return m.Force(version)
+				})
+			},
+		},
+	)
+
+	return cmd
+}
+
+// migrateUp brings the schema to the latest embedded version.
+func migrateUp(config *util.Config) error {
+	return withMigrator(config, func(m *migration.Migrator) error {
+		if err := m.Up(); err != nil {
+			return err
+		}
+
+		version, _, err := m.Version()
+		if err != nil {
+			return err
+		}
+
+		slog.Info("Database migrated", slog.Uint64("version", uint64(version)))
+
+		return nil
+	})
+}
+
+func withMigrator(config *util.Config, fn func(m *migration.Migrator) error) (err error) {
+	m, err := migration.NewMigrator(config.DBSource)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if closeErr := m.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	return fn(m)
+}
@@ -0,0 +1,122 @@
+// Package notify pushes balance changes to connected clients as they commit.
+//
+// TransferTx announces every balance change on db.AccountEventsChannel. A
+// Listener on each replica receives the notifications from Postgres and
+// publishes them to the replica's Hub, which passes them to the streams of
+// the account owner, so clients get updates whichever replica committed the
+// transfer.
+package notify
+
+import (
+	"sync"
+
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/metrics"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind before it
+// is dropped
+const subscriberBuffer = 64
+
+// Hub passes account notifications to the subscriptions of their owner.
+type Hub struct {
+	mu     sync.Mutex
+	owners map[string]map[*Subscription]struct{}
+}
+
+// NewHub creates a hub without subscriptions.
+func NewHub() *Hub {
+	return &Hub{owners: make(map[string]map[*Subscription]struct{})}
+}
+
+// Subscription receives the notifications of one owner's accounts.
+type Subscription struct {
+	hub    *Hub
+	owner  string
+	events chan db.AccountNotification
+	closed bool
+}
+
+// Subscribe starts receiving the notifications of owner's accounts. The
+// subscription must be closed when no longer needed.
+func (h *Hub) Subscribe(owner string) *Subscription {
+	sub := &Subscription{
+		hub:    h,
+		owner:  owner,
+		events: make(chan db.AccountNotification, subscriberBuffer),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.owners[owner] == nil {
+		h.owners[owner] = make(map[*Subscription]struct{})
+	}
+
+	h.owners[owner][sub] = struct{}{}
+	metrics.StreamSubscribers.Inc()
+
+	return sub
+}
+
+// Events returns the channel notifications arrive on. It is closed when the
+// subscription is dropped: because the subscriber fell too far behind, or
+// because the hub may have missed notifications. Clients should then reload
+// their balances before subscribing again.
+func (s *Subscription) Events() <-chan db.AccountNotification {
+	return s.events
+}
+
+// Close stops the subscription.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	s.hub.remove(s)
+}
+
+// Publish passes n to the subscriptions of its owner without blocking.
+// Subscriptions whose buffer is full are dropped.
+func (h *Hub) Publish(n db.AccountNotification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.owners[n.Owner] {
+		select {
+		case sub.events <- n:
+		default:
+			h.remove(sub)
+		}
+	}
+}
+
+// Reset drops every subscription. The Listener calls it after losing its
+// connection, since notifications sent meanwhile are lost.
+func (h *Hub) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, subs := range h.owners {
+		for sub := range subs {
+			h.remove(sub)
+		}
+	}
+}
+
+// remove closes sub and forgets it. The caller holds h.mu.
+func (h *Hub) remove(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+
+	sub.closed = true
+	close(sub.events)
+	metrics.StreamSubscribers.Dec()
+
+	subs := h.owners[sub.owner]
+	delete(subs, sub)
+
+	if len(subs) == 0 {
+		delete(h.owners, sub.owner)
+	}
+}
@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"testing"
+
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func notification(owner string, entryID int64) db.AccountNotification {
+	return db.AccountNotification{
+		Type:                db.EventAccountCredited,
+		Owner:               owner,
+		AccountEntryPayload: db.AccountEntryPayload{AccountID: 1, EntryID: entryID, Amount: 10, Balance: 10},
+	}
+}
+
+func TestHubPublish(t *testing.T) {
+	hub := NewHub()
+
+	alice1 := hub.Subscribe("alice")
+	alice2 := hub.Subscribe("alice")
+	bob := hub.Subscribe("bob")
+	defer bob.Close()
+
+	hub.Publish(notification("alice", 1))
+
+	for _, sub := range []*Subscription{alice1, alice2} {
+		n := <-sub.Events()
+		require.Equal(t, int64(1), n.EntryID)
+	}
+
+	require.Empty(t, bob.Events())
+
+	alice1.Close()
+	alice1.Close()
+
+	_, ok := <-alice1.Events()
+	require.False(t, ok)
+
+	hub.Publish(notification("alice", 2))
+	require.Equal(t, int64(2), (<-alice2.Events()).EntryID)
+	alice2.Close()
+
+	// Publishing to an owner without subscribers does nothing.
+	hub.Publish(notification("alice", 3))
+	require.NotContains(t, hub.owners, "alice")
+}
+
+func TestHubDropsSlowSubscriber(t *testing.T) {
+	hub := NewHub()
+
+	sub := hub.Subscribe("alice")
+	defer sub.Close()
+
+	for i := range subscriberBuffer + 1 {
+		hub.Publish(notification("alice", int64(i+1)))
+	}
+
+	received := 0
+	for range sub.Events() {
+		received++
+	}
+
+	require.Equal(t, subscriberBuffer, received)
+	require.Empty(t, hub.owners)
+}
+
+func TestHubReset(t *testing.T) {
+	hub := NewHub()
+
+	alice := hub.Subscribe("alice")
+	bob := hub.Subscribe("bob")
+
+	hub.Reset()
+
+	for _, sub := range []*Subscription{alice, bob} {
+		_, ok := <-sub.Events()
+		require.False(t, ok)
+		sub.Close()
+	}
+
+	require.Empty(t, hub.owners)
+}
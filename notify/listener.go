@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	db "github.com/shevgn/simplebank/db/sqlc"
+)
+
+const (
+	// minReconnectDelay is the wait before reconnecting after the first
+	// failure; it doubles with every further failure
+	minReconnectDelay = time.Second
+	// maxReconnectDelay caps the wait between reconnection attempts
+	maxReconnectDelay = 30 * time.Second
+)
+
+// Listener receives account notifications from Postgres on a dedicated
+// connection and publishes them to a Hub.
+type Listener struct {
+	dbSource string
+	hub      *Hub
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewListener creates a listener that connects to dbSource. It uses its own
+// connection rather than one from the pool, since it holds it for as long as
+// it runs.
+func NewListener(dbSource string, hub *Hub) *Listener {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Listener{
+		dbSource: dbSource,
+		hub:      hub,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+// Run listens until Shutdown is called, reconnecting with backoff when the
+// connection is lost.
+func (l *Listener) Run() {
+	defer close(l.done)
+
+	delay := minReconnectDelay
+
+	for {
+		connected, err := l.listen(l.ctx)
+		if l.ctx.Err() != nil {
+			return
+		}
+
+		// Notifications sent while disconnected are lost, so streams must
+		// start over from fresh balances.
+		l.hub.Reset()
+
+		if connected {
+			delay = minReconnectDelay
+		}
+
+		slog.Error("Account notification listener disconnected",
+			slog.Duration("retry_in", delay),
+			slog.Any("error", err),
+		)
+
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		delay = min(2*delay, maxReconnectDelay)
+	}
+}
+
+// listen connects and publishes notifications until the connection fails.
+// It reports whether it got as far as listening.
+func (l *Listener) listen(ctx context.Context) (bool, error) {
+	conn, err := pgx.Connect(ctx, l.dbSource)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{db.AccountEventsChannel}.Sanitize()); err != nil {
+		return false, err
+	}
+
+	slog.Debug("Listening for account notifications", slog.String("channel", db.AccountEventsChannel))
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+
+		var n db.AccountNotification
+		if err := json.Unmarshal([]byte(notification.Payload), &n); err != nil {
+			slog.Error("Cannot decode account notification", slog.Any("error", err))
+			continue
+		}
+
+		l.hub.Publish(n)
+	}
+}
+
+// Shutdown stops the listener and waits for it to disconnect, or for ctx to
+// expire.
+func (l *Listener) Shutdown(ctx context.Context) error {
+	l.cancel()
+
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,67 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// httpTimeout bounds a single delivery to an HTTP sink
+const httpTimeout = 10 * time.Second
+
+// HTTPSink POSTs every event as JSON to a URL. Any 2xx response accepts the
+// event. The Idempotency-Key header carries the event ID, so the receiver
+// can drop redeliveries.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSink creates a sink that posts to url.
+func NewHTTPSink(url string) *HTTPSink {
+	return &HTTPSink{
+		url: url,
+		client: &http.Client{
+			Timeout:   httpTimeout,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+	}
+}
+
+// Publish implements Sink.
+func (s *HTTPSink) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", strconv.FormatInt(event.ID, 10))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Drain the body so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("outbox sink responded with %s", resp.Status)
+	}
+
+	return nil
+}
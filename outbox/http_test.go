@@ -0,0 +1,55 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPSink(t *testing.T) {
+	event := randomEvent(42)
+
+	testCases := []struct {
+		name   string
+		status int
+		check  func(t *testing.T, err error)
+	}{
+		{
+			name:   "Accepted",
+			status: http.StatusAccepted,
+			check: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:   "Rejected",
+			status: http.StatusServiceUnavailable,
+			check: func(t *testing.T, err error) {
+				require.ErrorContains(t, err, "503")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, http.MethodPost, r.Method)
+				require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				require.Equal(t, "42", r.Header.Get("Idempotency-Key"))
+
+				var got Event
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+				require.Equal(t, event, got)
+
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			tc.check(t, NewHTTPSink(server.URL).Publish(context.Background(), event))
+		})
+	}
+}
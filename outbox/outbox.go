@@ -0,0 +1,85 @@
+// Package outbox relays domain events from the outbox table to a pluggable
+// Sink. Events are written by db.Store in the transaction that caused them,
+// so they are never lost or published for rolled back changes.
+//
+// Delivery is at least once: a sink may see an event again after a crash or
+// a failed commit, and should use Event.ID to drop duplicates. Events of the
+// same aggregate are delivered in the order they were written.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/util"
+)
+
+// Supported values of Config.OutboxSink.
+const (
+	SinkStdout = "stdout"
+	SinkFile   = "file"
+	SinkHTTP   = "http"
+)
+
+// Event is the JSON envelope sinks deliver.
+type Event struct {
+	ID            int64           `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// NewEvent converts an outbox row to an Event.
+func NewEvent(row db.Outbox) Event {
+	return Event{
+		ID:            row.ID,
+		Type:          row.EventType,
+		AggregateType: row.AggregateType,
+		AggregateID:   row.AggregateID,
+		Payload:       row.Payload,
+		CreatedAt:     row.CreatedAt.Time,
+	}
+}
+
+// Sink delivers events to another system. Publish returns once the event
+// is durably accepted; an error makes the relay try again later.
+type Sink interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// New returns the sink selected by config.OutboxSink.
+func New(config *util.Config) (Sink, error) {
+	switch config.OutboxSink {
+	case "", SinkStdout:
+		return NewWriterSink(os.Stdout), nil
+	case SinkFile:
+		return NewFileSink(config.OutboxFile), nil
+	case SinkHTTP:
+		return NewHTTPSink(config.OutboxHTTPURL), nil
+	default:
+		return nil, fmt.Errorf("unsupported outbox sink %q", config.OutboxSink)
+	}
+}
+
+// MultiSink publishes every event to all of its sinks.
+type MultiSink []Sink
+
+// Publish implements Sink. The event is offered to every sink even if one
+// fails; on error it is published to all of them again later.
+func (m MultiSink) Publish(ctx context.Context, event Event) error {
+	var errs []error
+	for _, sink := range m {
+		if err := sink.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,148 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/metrics"
+)
+
+const (
+	// relayTimeout bounds a single batch, including publishing
+	relayTimeout = time.Minute
+	// publishedRetention is how long published events are kept for
+	// inspection and replay
+	publishedRetention = 7 * 24 * time.Hour
+	// sweepInterval is how often old published events are deleted
+	sweepInterval = time.Hour
+)
+
+// Store is the subset of db.Store used by the Relay.
+type Store interface {
+	RelayOutboxTx(
+		ctx context.Context,
+		limit int32,
+		publish func(ctx context.Context, event db.Outbox) error,
+	) (db.RelayOutboxTxResult, error)
+	DeletePublishedOutboxEvents(ctx context.Context, publishedBefore pgtype.Timestamptz) (int64, error)
+}
+
+// Relay polls the outbox and publishes new events to a sink. Every replica
+// may run one; only the one holding the relay lock publishes at a time.
+type Relay struct {
+	store        Store
+	sink         Sink
+	batchSize    int32
+	pollInterval time.Duration
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewRelay creates a relay that publishes up to batchSize events to sink
+// every pollInterval, or right away while a backlog remains.
+func NewRelay(store Store, sink Sink, batchSize int32, pollInterval time.Duration) *Relay {
+	return &Relay{
+		store:        store,
+		sink:         sink,
+		batchSize:    batchSize,
+		pollInterval: pollInterval,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+}
+
+// Run relays events until Shutdown is called.
+func (r *Relay) Run() {
+	defer close(r.done)
+
+	var lastSweep time.Time
+
+	for {
+		if time.Since(lastSweep) >= sweepInterval {
+			r.sweep()
+			lastSweep = time.Now()
+		}
+
+		wait := r.pollInterval
+		if r.relay() {
+			wait = 0
+		}
+
+		select {
+		case <-r.stop:
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// relay publishes one batch and reports whether a full batch went out, in
+// which case more events are likely waiting.
+func (r *Relay) relay() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), relayTimeout)
+	defer cancel()
+
+	result, err := r.store.RelayOutboxTx(ctx, r.batchSize, func(ctx context.Context, row db.Outbox) error {
+		err := r.sink.Publish(ctx, NewEvent(row))
+		metrics.ObserveOutboxEvent(row.EventType, err == nil)
+
+		if err != nil {
+			slog.WarnContext(ctx, "Cannot publish outbox event",
+				slog.Int64("event_id", row.ID),
+				slog.String("event", row.EventType),
+				slog.Int("attempts", int(row.Attempts)+1),
+				slog.Any("error", err),
+			)
+		}
+
+		return err
+	})
+	if err != nil {
+		slog.Error("Cannot relay outbox", slog.Any("error", err))
+		return false
+	}
+
+	if result.Published > 0 || result.Failed > 0 {
+		slog.Debug("Relayed outbox",
+			slog.Int("published", result.Published),
+			slog.Int("failed", result.Failed),
+			slog.Int("held", result.Held),
+		)
+	}
+
+	return result.Failed == 0 && int32(result.Published) == r.batchSize
+}
+
+// sweep deletes published events past their retention.
+func (r *Relay) sweep() {
+	ctx, cancel := context.WithTimeout(context.Background(), relayTimeout)
+	defer cancel()
+
+	before := pgtype.Timestamptz{Time: time.Now().Add(-publishedRetention), Valid: true}
+
+	deleted, err := r.store.DeletePublishedOutboxEvents(ctx, before)
+	if err != nil {
+		slog.Error("Cannot delete published outbox events", slog.Any("error", err))
+	} else if deleted > 0 {
+		slog.Debug("Deleted published outbox events", slog.Int64("count", deleted))
+	}
+}
+
+// Shutdown stops the relay after the batch in flight, or when ctx expires.
+// Events of an interrupted batch are published again by the next relay.
+func (r *Relay) Shutdown(ctx context.Context) error {
+	r.stopOnce.Do(func() { close(r.stop) })
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,137 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// sinkFunc adapts a function to Sink.
+type sinkFunc func(ctx context.Context, event Event) error
+
+func (f sinkFunc) Publish(ctx context.Context, event Event) error {
+	return f(ctx, event)
+}
+
+// relayRows stubs RelayOutboxTx to pass rows to the relay's publish function
+// and count the outcomes.
+func relayRows(rows ...db.Outbox) any {
+	return func(
+		ctx context.Context,
+		_ int32,
+		publish func(context.Context, db.Outbox) error,
+	) (db.RelayOutboxTxResult, error) {
+		var result db.RelayOutboxTxResult
+		for _, row := range rows {
+			if err := publish(ctx, row); err != nil {
+				result.Failed++
+			} else {
+				result.Published++
+			}
+		}
+
+		return result, nil
+	}
+}
+
+func TestRelay(t *testing.T) {
+	rows := []db.Outbox{
+		{ID: 1, EventType: db.EventTransferCreated, AggregateType: db.AggregateTransfer, AggregateID: 1},
+		{ID: 2, EventType: db.EventAccountDebited, AggregateType: db.AggregateAccount, AggregateID: 1},
+	}
+
+	testCases := []struct {
+		name      string
+		batchSize int32
+		publish   func(ctx context.Context, event Event) error
+		wantMore  bool
+	}{
+		{
+			name:      "FullBatch",
+			batchSize: 2,
+			publish:   func(context.Context, Event) error { return nil },
+			wantMore:  true,
+		},
+		{
+			name:      "PartialBatch",
+			batchSize: 10,
+			publish:   func(context.Context, Event) error { return nil },
+			wantMore:  false,
+		},
+		{
+			name:      "SinkFailure",
+			batchSize: 2,
+			publish: func(_ context.Context, event Event) error {
+				if event.ID == 2 {
+					return errors.New("sink unavailable")
+				}
+				return nil
+			},
+			wantMore: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			var published []int64
+			sink := sinkFunc(func(ctx context.Context, event Event) error {
+				published = append(published, event.ID)
+				return tc.publish(ctx, event)
+			})
+
+			store.EXPECT().
+				RelayOutboxTx(gomock.Any(), gomock.Eq(tc.batchSize), gomock.Any()).
+				Times(1).
+				DoAndReturn(relayRows(rows...))
+
+			relay := NewRelay(store, sink, tc.batchSize, time.Second)
+			require.Equal(t, tc.wantMore, relay.relay())
+			require.Equal(t, []int64{1, 2}, published)
+		})
+	}
+}
+
+func TestRelayRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	published := make(chan int64, 1)
+	sink := sinkFunc(func(_ context.Context, event Event) error {
+		published <- event.ID
+		return nil
+	})
+
+	store.EXPECT().DeletePublishedOutboxEvents(gomock.Any(), gomock.Any()).Times(1)
+	gomock.InOrder(
+		store.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(relayRows(db.Outbox{ID: 1})),
+		store.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			AnyTimes().
+			Return(db.RelayOutboxTxResult{}, nil),
+	)
+
+	relay := NewRelay(store, sink, 10, 10*time.Millisecond)
+	go relay.Run()
+
+	select {
+	case id := <-published:
+		require.Equal(t, int64(1), id)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.NoError(t, relay.Shutdown(ctx))
+}
@@ -0,0 +1,78 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// WriterSink writes every event as a line of JSON.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink creates a sink that writes to w.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// Publish implements Sink.
+func (s *WriterSink) Publish(_ context.Context, event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.w.Write(append(line, '\n'))
+
+	return err
+}
+
+// FileSink appends every event as a line of JSON to a file. It is meant for
+// local development and tests of consumers.
+type FileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileSink creates a sink that appends to the file at path, creating it
+// and its directory if needed.
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+// Publish implements Sink. The file is synced before returning, so a
+// published event survives a crash.
+func (s *FileSink) Publish(_ context.Context, event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return fmt.Errorf("cannot create outbox directory: %w", err)
+	}
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("cannot open outbox file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("cannot write outbox file: %w", err)
+	}
+
+	return f.Sync()
+}
@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func randomEvent(id int64) Event {
+	return Event{
+		ID:            id,
+		Type:          "TransferCreated",
+		AggregateType: "transfer",
+		AggregateID:   id,
+		Payload:       json.RawMessage(`{"amount":10}`),
+		CreatedAt:     time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func requireEventLines(t *testing.T, data string, want ...Event) {
+	lines := strings.Split(strings.TrimSuffix(data, "\n"), "\n")
+	require.Len(t, lines, len(want))
+
+	for i, line := range lines {
+		var got Event
+		require.NoError(t, json.Unmarshal([]byte(line), &got))
+		require.Equal(t, want[i], got)
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterSink(&buf)
+
+	events := []Event{randomEvent(1), randomEvent(2)}
+	for _, event := range events {
+		require.NoError(t, sink.Publish(context.Background(), event))
+	}
+
+	requireEventLines(t, buf.String(), events...)
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox", "events.jsonl")
+	sink := NewFileSink(path)
+
+	events := []Event{randomEvent(1), randomEvent(2)}
+	for _, event := range events {
+		require.NoError(t, sink.Publish(context.Background(), event))
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	requireEventLines(t, string(data), events...)
+}
@@ -0,0 +1,184 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: service_simple_bank.proto
+
+package pb
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	reflect "reflect"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+var File_service_simple_bank_proto protoreflect.FileDescriptor
+
+const file_service_simple_bank_proto_rawDesc = "" +
+	"\n" +
+	"\x19service_simple_bank.proto\x12\x02pb\x1a\raccount.proto\x1a\x1cgoogle/api/annotations.proto\x1a\x1bgoogle/protobuf/empty.proto\x1a\rsession.proto\x1a\x0etransfer.proto\x1a\n" +
+	"user.proto\x1a\rwebhook.proto2\x88\x13\n" +
+	"\n" +
+	"SimpleBank\x12Q\n" +
+	"\n" +
+	"CreateUser\x12\x15.pb.CreateUserRequest\x1a\x16.pb.CreateUserResponse\"\x14\x82\xd3\xe4\x93\x02\x0e:\x01*\"\t/v1/users\x12T\n" +
+	"\tLoginUser\x12\x14.pb.LoginUserRequest\x1a\x15.pb.LoginUserResponse\"\x1a\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/users/login\x12f\n" +
+	"\x0eChangePassword\x12\x19.pb.ChangePasswordRequest\x1a\x1a.pb.ChangePasswordResponse\"\x1d\x82\xd3\xe4\x93\x02\x17:\x01*\x1a\x12/v1/users/password\x12\x86\x01\n" +
+	"\x14RequestPasswordReset\x12\x1f.pb.RequestPasswordResetRequest\x1a .pb.RequestPasswordResetResponse\"+\x82\xd3\xe4\x93\x02%:\x01*\" /v1/users/password/reset_request\x12i\n" +
+	"\rResetPassword\x12\x18.pb.ResetPasswordRequest\x1a\x19.pb.ResetPasswordResponse\"#\x82\xd3\xe4\x93\x02\x1d:\x01*\"\x18/v1/users/password/reset\x12^\n" +
+	"\vVerifyEmail\x12\x16.pb.VerifyEmailRequest\x1a\x17.pb.VerifyEmailResponse\"\x1e\x82\xd3\xe4\x93\x02\x18\x12\x16/v1/users/verify_email\x12m\n" +
+	"\x11ResendVerifyEmail\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"(\x82\xd3\xe4\x93\x02\":\x01*\"\x1d/v1/users/verify_email/resend\x12q\n" +
+	"\x10RenewAccessToken\x12\x1b.pb.RenewAccessTokenRequest\x1a\x1c.pb.RenewAccessTokenResponse\"\"\x82\xd3\xe4\x93\x02\x1c:\x01*\"\x17/v1/tokens/renew_access\x12]\n" +
+	"\rCreateAccount\x12\x18.pb.CreateAccountRequest\x1a\x19.pb.CreateAccountResponse\"\x17\x82\xd3\xe4\x93\x02\x11:\x01*\"\f/v1/accounts\x12V\n" +
+	"\n" +
+	"GetAccount\x12\x15.pb.GetAccountRequest\x1a\x16.pb.GetAccountResponse\"\x19\x82\xd3\xe4\x93\x02\x13\x12\x11/v1/accounts/{id}\x12f\n" +
+	"\x13StreamAccountEvents\x12\x1e.pb.StreamAccountEventsRequest\x1a\x10.pb.AccountEvent\"\x1b\x82\xd3\xe4\x93\x02\x15\x12\x13/v1/accounts/stream0\x01\x12W\n" +
+	"\fListAccounts\x12\x17.pb.ListAccountsRequest\x1a\x18.pb.ListAccountsResponse\"\x14\x82\xd3\xe4\x93\x02\x0e\x12\f/v1/accounts\x12\\\n" +
+	"\rDeleteAccount\x12\x18.pb.DeleteAccountRequest\x1a\x16.google.protobuf.Empty\"\x19\x82\xd3\xe4\x93\x02\x13*\x11/v1/accounts/{id}\x12b\n" +
+	"\fCloseAccount\x12\x17.pb.CloseAccountRequest\x1a\x18.pb.CloseAccountResponse\"\x1f\x82\xd3\xe4\x93\x02\x19\"\x17/v1/accounts/{id}/close\x12f\n" +
+	"\rReopenAccount\x12\x18.pb.ReopenAccountRequest\x1a\x19.pb.ReopenAccountResponse\" \x82\xd3\xe4\x93\x02\x1a\"\x18/v1/accounts/{id}/reopen\x12a\n" +
+	"\x0eCreateTransfer\x12\x19.pb.CreateTransferRequest\x1a\x1a.pb.CreateTransferResponse\"\x18\x82\xd3\xe4\x93\x02\x12:\x01*\"\r/v1/transfers\x12d\n" +
+	"\rQuoteTransfer\x12\x18.pb.QuoteTransferRequest\x1a\x19.pb.QuoteTransferResponse\"\x1e\x82\xd3\xe4\x93\x02\x18:\x01*\"\x13/v1/transfers/quote\x12r\n" +
+	"\x12CreateBulkTransfer\x12\x1d.pb.CreateBulkTransferRequest\x1a\x1e.pb.CreateBulkTransferResponse\"\x1d\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/v1/transfers/bulk\x12u\n" +
+	"\x15CreateWebhookEndpoint\x12 .pb.CreateWebhookEndpointRequest\x1a!.pb.CreateWebhookEndpointResponse\"\x17\x82\xd3\xe4\x93\x02\x11:\x01*\"\f/v1/webhooks\x12o\n" +
+	"\x14ListWebhookEndpoints\x12\x1f.pb.ListWebhookEndpointsRequest\x1a .pb.ListWebhookEndpointsResponse\"\x14\x82\xd3\xe4\x93\x02\x0e\x12\f/v1/webhooks\x12l\n" +
+	"\x15DeleteWebhookEndpoint\x12 .pb.DeleteWebhookEndpointRequest\x1a\x16.google.protobuf.Empty\"\x19\x82\xd3\xe4\x93\x02\x13*\x11/v1/webhooks/{id}\x12\x82\x01\n" +
+	"\x15ListWebhookDeliveries\x12 .pb.ListWebhookDeliveriesRequest\x1a!.pb.ListWebhookDeliveriesResponse\"$\x82\xd3\xe4\x93\x02\x1e\x12\x1c/v1/webhooks/{id}/deliveries\x12y\n" +
+	"\x13TestWebhookEndpoint\x12\x1e.pb.TestWebhookEndpointRequest\x1a\x1f.pb.TestWebhookEndpointResponse\"!\x82\xd3\xe4\x93\x02\x1b:\x01*\"\x16/v1/webhooks/{id}/testB!Z\x1fgithub.com/shevgn/simplebank/pbb\x06proto3"
+
+var file_service_simple_bank_proto_goTypes = []any{
+	(*CreateUserRequest)(nil),             // 0: pb.CreateUserRequest
+	(*LoginUserRequest)(nil),              // 1: pb.LoginUserRequest
+	(*ChangePasswordRequest)(nil),         // 2: pb.ChangePasswordRequest
+	(*RequestPasswordResetRequest)(nil),   // 3: pb.RequestPasswordResetRequest
+	(*ResetPasswordRequest)(nil),          // 4: pb.ResetPasswordRequest
+	(*VerifyEmailRequest)(nil),            // 5: pb.VerifyEmailRequest
+	(*emptypb.Empty)(nil),                 // 6: google.protobuf.Empty
+	(*RenewAccessTokenRequest)(nil),       // 7: pb.RenewAccessTokenRequest
+	(*CreateAccountRequest)(nil),          // 8: pb.CreateAccountRequest
+	(*GetAccountRequest)(nil),             // 9: pb.GetAccountRequest
+	(*StreamAccountEventsRequest)(nil),    // 10: pb.StreamAccountEventsRequest
+	(*ListAccountsRequest)(nil),           // 11: pb.ListAccountsRequest
+	(*DeleteAccountRequest)(nil),          // 12: pb.DeleteAccountRequest
+	(*CloseAccountRequest)(nil),           // 13: pb.CloseAccountRequest
+	(*ReopenAccountRequest)(nil),          // 14: pb.ReopenAccountRequest
+	(*CreateTransferRequest)(nil),         // 15: pb.CreateTransferRequest
+	(*QuoteTransferRequest)(nil),          // 16: pb.QuoteTransferRequest
+	(*CreateBulkTransferRequest)(nil),     // 17: pb.CreateBulkTransferRequest
+	(*CreateWebhookEndpointRequest)(nil),  // 18: pb.CreateWebhookEndpointRequest
+	(*ListWebhookEndpointsRequest)(nil),   // 19: pb.ListWebhookEndpointsRequest
+	(*DeleteWebhookEndpointRequest)(nil),  // 20: pb.DeleteWebhookEndpointRequest
+	(*ListWebhookDeliveriesRequest)(nil),  // 21: pb.ListWebhookDeliveriesRequest
+	(*TestWebhookEndpointRequest)(nil),    // 22: pb.TestWebhookEndpointRequest
+	(*CreateUserResponse)(nil),            // 23: pb.CreateUserResponse
+	(*LoginUserResponse)(nil),             // 24: pb.LoginUserResponse
+	(*ChangePasswordResponse)(nil),        // 25: pb.ChangePasswordResponse
+	(*RequestPasswordResetResponse)(nil),  // 26: pb.RequestPasswordResetResponse
+	(*ResetPasswordResponse)(nil),         // 27: pb.ResetPasswordResponse
+	(*VerifyEmailResponse)(nil),           // 28: pb.VerifyEmailResponse
+	(*RenewAccessTokenResponse)(nil),      // 29: pb.RenewAccessTokenResponse
+	(*CreateAccountResponse)(nil),         // 30: pb.CreateAccountResponse
+	(*GetAccountResponse)(nil),            // 31: pb.GetAccountResponse
+	(*AccountEvent)(nil),                  // 32: pb.AccountEvent
+	(*ListAccountsResponse)(nil),          // 33: pb.ListAccountsResponse
+	(*CloseAccountResponse)(nil),          // 34: pb.CloseAccountResponse
+	(*ReopenAccountResponse)(nil),         // 35: pb.ReopenAccountResponse
+	(*CreateTransferResponse)(nil),        // 36: pb.CreateTransferResponse
+	(*QuoteTransferResponse)(nil),         // 37: pb.QuoteTransferResponse
+	(*CreateBulkTransferResponse)(nil),    // 38: pb.CreateBulkTransferResponse
+	(*CreateWebhookEndpointResponse)(nil), // 39: pb.CreateWebhookEndpointResponse
+	(*ListWebhookEndpointsResponse)(nil),  // 40: pb.ListWebhookEndpointsResponse
+	(*ListWebhookDeliveriesResponse)(nil), // 41: pb.ListWebhookDeliveriesResponse
+	(*TestWebhookEndpointResponse)(nil),   // 42: pb.TestWebhookEndpointResponse
+}
+var file_service_simple_bank_proto_depIdxs = []int32{
+	0,  // 0: pb.SimpleBank.CreateUser:input_type -> pb.CreateUserRequest
+	1,  // 1: pb.SimpleBank.LoginUser:input_type -> pb.LoginUserRequest
+	2,  // 2: pb.SimpleBank.ChangePassword:input_type -> pb.ChangePasswordRequest
+	3,  // 3: pb.SimpleBank.RequestPasswordReset:input_type -> pb.RequestPasswordResetRequest
+	4,  // 4: pb.SimpleBank.ResetPassword:input_type -> pb.ResetPasswordRequest
+	5,  // 5: pb.SimpleBank.VerifyEmail:input_type -> pb.VerifyEmailRequest
+	6,  // 6: pb.SimpleBank.ResendVerifyEmail:input_type -> google.protobuf.Empty
+	7,  // 7: pb.SimpleBank.RenewAccessToken:input_type -> pb.RenewAccessTokenRequest
+	8,  // 8: pb.SimpleBank.CreateAccount:input_type -> pb.CreateAccountRequest
+	9,  // 9: pb.SimpleBank.GetAccount:input_type -> pb.GetAccountRequest
+	10, // 10: pb.SimpleBank.StreamAccountEvents:input_type -> pb.StreamAccountEventsRequest
+	11, // 11: pb.SimpleBank.ListAccounts:input_type -> pb.ListAccountsRequest
+	12, // 12: pb.SimpleBank.DeleteAccount:input_type -> pb.DeleteAccountRequest
+	13, // 13: pb.SimpleBank.CloseAccount:input_type -> pb.CloseAccountRequest
+	14, // 14: pb.SimpleBank.ReopenAccount:input_type -> pb.ReopenAccountRequest
+	15, // 15: pb.SimpleBank.CreateTransfer:input_type -> pb.CreateTransferRequest
+	16, // 16: pb.SimpleBank.QuoteTransfer:input_type -> pb.QuoteTransferRequest
+	17, // 17: pb.SimpleBank.CreateBulkTransfer:input_type -> pb.CreateBulkTransferRequest
+	18, // 18: pb.SimpleBank.CreateWebhookEndpoint:input_type -> pb.CreateWebhookEndpointRequest
+	19, // 19: pb.SimpleBank.ListWebhookEndpoints:input_type -> pb.ListWebhookEndpointsRequest
+	20, // 20: pb.SimpleBank.DeleteWebhookEndpoint:input_type -> pb.DeleteWebhookEndpointRequest
+	21, // 21: pb.SimpleBank.ListWebhookDeliveries:input_type -> pb.ListWebhookDeliveriesRequest
+	22, // 22: pb.SimpleBank.TestWebhookEndpoint:input_type -> pb.TestWebhookEndpointRequest
+	23, // 23: pb.SimpleBank.CreateUser:output_type -> pb.CreateUserResponse
+	24, // 24: pb.SimpleBank.LoginUser:output_type -> pb.LoginUserResponse
+	25, // 25: pb.SimpleBank.ChangePassword:output_type -> pb.ChangePasswordResponse
+	26, // 26: pb.SimpleBank.RequestPasswordReset:output_type -> pb.RequestPasswordResetResponse
+	27, // 27: pb.SimpleBank.ResetPassword:output_type -> pb.ResetPasswordResponse
+	28, // 28: pb.SimpleBank.VerifyEmail:output_type -> pb.VerifyEmailResponse
+	6,  // 29: pb.SimpleBank.ResendVerifyEmail:output_type -> google.protobuf.Empty
+	29, // 30: pb.SimpleBank.RenewAccessToken:output_type -> pb.RenewAccessTokenResponse
+	30, // 31: pb.SimpleBank.CreateAccount:output_type -> pb.CreateAccountResponse
+	31, // 32: pb.SimpleBank.GetAccount:output_type -> pb.GetAccountResponse
+	32, // 33: pb.SimpleBank.StreamAccountEvents:output_type -> pb.AccountEvent
+	33, // 34: pb.SimpleBank.ListAccounts:output_type -> pb.ListAccountsResponse
+	6,  // 35: pb.SimpleBank.DeleteAccount:output_type -> google.protobuf.Empty
+	34, // 36: pb.SimpleBank.CloseAccount:output_type -> pb.CloseAccountResponse
+	35, // 37: pb.SimpleBank.ReopenAccount:output_type -> pb.ReopenAccountResponse
+	36, // 38: pb.SimpleBank.CreateTransfer:output_type -> pb.CreateTransferResponse
+	37, // 39: pb.SimpleBank.QuoteTransfer:output_type -> pb.QuoteTransferResponse
+	38, // 40: pb.SimpleBank.CreateBulkTransfer:output_type -> pb.CreateBulkTransferResponse
+	39, // 41: pb.SimpleBank.CreateWebhookEndpoint:output_type -> pb.CreateWebhookEndpointResponse
+	40, // 42: pb.SimpleBank.ListWebhookEndpoints:output_type -> pb.ListWebhookEndpointsResponse
+	6,  // 43: pb.SimpleBank.DeleteWebhookEndpoint:output_type -> google.protobuf.Empty
+	41, // 44: pb.SimpleBank.ListWebhookDeliveries:output_type -> pb.ListWebhookDeliveriesResponse
+	42, // 45: pb.SimpleBank.TestWebhookEndpoint:output_type -> pb.TestWebhookEndpointResponse
+	23, // [23:46] is the sub-list for method output_type
+	0,  // [0:23] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_service_simple_bank_proto_init() }
+func file_service_simple_bank_proto_init() {
+	if File_service_simple_bank_proto != nil {
+		return
+	}
+	file_account_proto_init()
+	file_session_proto_init()
+	file_transfer_proto_init()
+	file_user_proto_init()
+	file_webhook_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_service_simple_bank_proto_rawDesc), len(file_service_simple_bank_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   0,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_service_simple_bank_proto_goTypes,
+		DependencyIndexes: file_service_simple_bank_proto_depIdxs,
+	}.Build()
+	File_service_simple_bank_proto = out.File
+	file_service_simple_bank_proto_goTypes = nil
+	file_service_simple_bank_proto_depIdxs = nil
+}
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLimiter(t *testing.T) {
+	now := time.Now()
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+
+	rate := Rate{Limit: 2, Period: time.Minute}
+
+	for i := range 2 {
+		result, err := limiter.Allow(context.Background(), "key", rate)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 1-i, result.Remaining)
+	}
+
+	result, err := limiter.Allow(context.Background(), "key", rate)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+	require.Equal(t, 30*time.Second, result.RetryAfter)
+
+	// Other keys have their own bucket.
+	result, err = limiter.Allow(context.Background(), "other", rate)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	now = now.Add(30 * time.Second)
+
+	result, err = limiter.Allow(context.Background(), "key", rate)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+	require.Zero(t, result.Remaining)
+}
+
+func TestMemoryLimiterSweep(t *testing.T) {
+	now := time.Now()
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+
+	rate := Rate{Limit: 1, Period: time.Second}
+
+	_, err := limiter.Allow(context.Background(), "key", rate)
+	require.NoError(t, err)
+	require.Len(t, limiter.buckets, 1)
+
+	now = now.Add(sweepInterval)
+
+	_, err = limiter.Allow(context.Background(), "other", rate)
+	require.NoError(t, err)
+	require.Len(t, limiter.buckets, 1)
+	require.Contains(t, limiter.buckets, "other")
+}
+
+func TestNew(t *testing.T) {
+	limiter, err := New(BackendMemory, nil)
+	require.NoError(t, err)
+	require.IsType(t, &MemoryLimiter{}, limiter)
+
+	limiter, err = New(BackendPostgres, nil)
+	require.NoError(t, err)
+	require.IsType(t, &PostgresLimiter{}, limiter)
+
+	_, err = New("redis", nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/shevgn/simplebank/db/sqlc"
+)
+
+// staleAfter is how long a bucket is kept after its last request. Buckets
+// of every rate used for login refill completely well within this time.
+const staleAfter = 24 * time.Hour
+
+// Store is the subset of db.Store used by PostgresLimiter.
+type Store interface {
+	TakeRateLimitToken(ctx context.Context, arg db.TakeRateLimitTokenParams) (db.TakeRateLimitTokenRow, error)
+	DeleteStaleRateLimits(ctx context.Context, before pgtype.Timestamptz) (int64, error)
+}
+
+// PostgresLimiter keeps buckets in the rate_limits table, so all replicas
+// share the same limits. Each check is a single atomic upsert.
+type PostgresLimiter struct {
+	store Store
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// NewPostgresLimiter creates a limiter backed by store.
+func NewPostgresLimiter(store Store) *PostgresLimiter {
+	return &PostgresLimiter{store: store}
+}
+
+// Allow implements Limiter.
+func (l *PostgresLimiter) Allow(ctx context.Context, key string, rate Rate) (Result, error) {
+	l.sweep(ctx)
+
+	row, err := l.store.TakeRateLimitToken(ctx, db.TakeRateLimitTokenParams{
+		Key:             key,
+		Burst:           float64(rate.Limit),
+		RefillPerSecond: rate.refillPerSecond(),
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	return newResult(rate, row.Allowed, row.Tokens), nil
+}
+
+// sweep deletes stale buckets at most once per sweepInterval per replica.
+func (l *PostgresLimiter) sweep(ctx context.Context) {
+	l.mu.Lock()
+	now := time.Now()
+	due := now.Sub(l.lastSweep) >= sweepInterval
+	if due {
+		l.lastSweep = now
+	}
+	l.mu.Unlock()
+
+	if !due {
+		return
+	}
+
+	before := pgtype.Timestamptz{Time: now.Add(-staleAfter), Valid: true}
+	if _, err := l.store.DeleteStaleRateLimits(ctx, before); err != nil {
+		slog.WarnContext(ctx, "Cannot delete stale rate limits", slog.Any("error", err))
+	}
+}
@@ -0,0 +1,79 @@
+// Package ratelimit implements token bucket rate limiting with pluggable
+// storage: in memory for a single replica, or in PostgreSQL when limits must
+// hold across replicas.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Supported values of Config.RateLimitBackend.
+const (
+	BackendMemory   = "memory"
+	BackendPostgres = "postgres"
+)
+
+// Rate allows Limit requests per Period, in bursts of up to Limit requests.
+type Rate struct {
+	Limit  int
+	Period time.Duration
+}
+
+// refillPerSecond is how many tokens the bucket regains every second.
+func (r Rate) refillPerSecond() float64 {
+	return float64(r.Limit) / r.Period.Seconds()
+}
+
+// retryAfter is how long a bucket holding tokens needs to refill one token.
+func (r Rate) retryAfter(tokens float64) time.Duration {
+	missing := 1 - tokens
+	if missing <= 0 {
+		return 0
+	}
+
+	return time.Duration(math.Ceil(missing / r.refillPerSecond() * float64(time.Second)))
+}
+
+// Result describes the outcome of a rate limit check.
+type Result struct {
+	Allowed bool
+	// Remaining is the number of requests that would currently be allowed.
+	Remaining int
+	// RetryAfter is how long to wait before the next request is allowed,
+	// zero if it is allowed now.
+	RetryAfter time.Duration
+}
+
+func newResult(rate Rate, allowed bool, tokens float64) Result {
+	result := Result{
+		Allowed:   allowed,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+	}
+
+	if !allowed {
+		result.RetryAfter = rate.retryAfter(tokens)
+	}
+
+	return result
+}
+
+// Limiter takes a token from the bucket identified by key.
+type Limiter interface {
+	Allow(ctx context.Context, key string, rate Rate) (Result, error)
+}
+
+// New returns a limiter for the given backend. store is only used by the
+// postgres backend.
+func New(backend string, store Store) (Limiter, error) {
+	switch backend {
+	case "", BackendMemory:
+		return NewMemoryLimiter(), nil
+	case BackendPostgres:
+		return NewPostgresLimiter(store), nil
+	default:
+		return nil, fmt.Errorf("unsupported rate limit backend %q", backend)
+	}
+}
@@ -0,0 +1,273 @@
+package statement
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+const camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.08"
+
+// camt053Writer writes an ISO 20022 camt.053.001.08 bank to customer
+// statement. The schema puts the closing balance and the entry totals before
+// the entries, so entries are spooled to a temporary file and copied out
+// after the balances in End.
+type camt053Writer struct {
+	w      io.Writer
+	header Header
+
+	spool   *os.File
+	entries *xmlWriter
+
+	credits     int
+	debits      int
+	creditTotal int64
+	debitTotal  int64
+}
+
+// NewCamt053Writer creates a Writer for camt.053 statements. It holds a
+// temporary file until End or Close is called.
+func NewCamt053Writer(w io.Writer) Writer {
+	return &camt053Writer{w: w}
+}
+
+func (c *camt053Writer) Begin(header Header) error {
+	c.header = header
+
+	spool, err := os.CreateTemp("", "statement-*.xml")
+	if err != nil {
+		return fmt.Errorf("cannot create spool file: %w", err)
+	}
+
+	c.spool = spool
+	// Entries are children of Document/BkToCstmrStmt/Stmt.
+	c.entries = newXMLWriter(spool)
+	c.entries.depth = 3
+
+	return nil
+}
+
+func (c *camt053Writer) Line(line Line) error {
+	x := c.entries
+
+	if line.Amount < 0 {
+		c.debits++
+		c.debitTotal -= line.Amount
+	} else {
+		c.credits++
+		c.creditTotal += line.Amount
+	}
+
+	x.open("Ntry")
+	camtAmount(x, line.Amount, c.header.Currency)
+	x.element("CdtDbtInd", creditDebit(line.Amount))
+	x.open("Sts")
+	x.element("Cd", "BOOK")
+	x.close("Sts")
+	x.open("BookgDt")
+	x.element("DtTm", isoTime(line.Time))
+	x.close("BookgDt")
+	x.element("AcctSvcrRef", strconv.FormatInt(line.EntryID, 10))
+
+	x.open("BkTxCd")
+	if line.TransferID == 0 {
+		x.open("Prtry")
+		x.element("Cd", "ADJUSTMENT")
+		x.close("Prtry")
+	} else {
+		family := "RCDT"
+		if line.Amount < 0 {
+			family = "ICDT"
+		}
+
+		x.open("Domn")
+		x.element("Cd", "PMNT")
+		x.open("Fmly")
+		x.element("Cd", family)
+		x.element("SubFmlyCd", "BOOK")
+		x.close("Fmly")
+		x.close("Domn")
+	}
+	x.close("BkTxCd")
+
+	if line.TransferID != 0 {
+		c.transactionDetails(line)
+	}
+
+	x.element("AddtlNtryInf", truncate(line.Description, 500))
+	x.close("Ntry")
+
+	return x.err
+}
+
+// transactionDetails names the transfer and the account on its other side,
+// the creditor of a debit or the debtor of a credit.
+func (c *camt053Writer) transactionDetails(line Line) {
+	x := c.entries
+
+	party, account := "Dbtr", "DbtrAcct"
+	if line.Amount < 0 {
+		party, account = "Cdtr", "CdtrAcct"
+	}
+
+	x.open("NtryDtls")
+	x.open("TxDtls")
+	x.open("Refs")
+	x.element("TxId", strconv.FormatInt(line.TransferID, 10))
+	x.close("Refs")
+	x.open("RltdPties")
+	x.open(party)
+	x.open("Pty")
+	x.element("Nm", truncate(line.CounterpartyOwner, 140))
+	x.close("Pty")
+	x.close(party)
+	x.open(account)
+	camtAccountID(x, line.CounterpartyAccountID)
+	x.close(account)
+	x.close("RltdPties")
+	x.close("TxDtls")
+	x.close("NtryDtls")
+}
+
+func (c *camt053Writer) End(summary Summary) error {
+	if err := c.entries.flush(); err != nil {
+		return err
+	}
+
+	x := newXMLWriter(c.w)
+	h := c.header
+	id := fmt.Sprintf("%d-%s-%s", h.AccountID, h.From.Format("20060102"), h.To.Format("20060102"))
+
+	x.raw(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	x.open("Document", "xmlns", camt053Namespace)
+	x.open("BkToCstmrStmt")
+	x.open("GrpHdr")
+	x.element("MsgId", "STMT-"+id)
+	x.element("CreDtTm", isoTime(h.GeneratedAt))
+	x.close("GrpHdr")
+
+	x.open("Stmt")
+	x.element("Id", id)
+	x.element("CreDtTm", isoTime(h.GeneratedAt))
+	x.open("FrToDt")
+	x.element("FrDtTm", isoTime(h.From))
+	x.element("ToDtTm", isoTime(periodEnd(h).Add(-time.Second)))
+	x.close("FrToDt")
+
+	x.open("Acct")
+	camtAccountID(x, h.AccountID)
+	x.element("Ccy", h.Currency)
+	x.open("Ownr")
+	x.element("Nm", truncate(h.Owner, 140))
+	x.close("Ownr")
+	x.close("Acct")
+
+	camtBalance(x, "OPBD", h.OpeningBalance, h.Currency, h.From)
+	camtBalance(x, "CLBD", summary.ClosingBalance, h.Currency, h.To)
+
+	x.open("TxsSummry")
+	x.open("TtlNtries")
+	x.element("NbOfNtries", strconv.Itoa(summary.EntryCount))
+	x.element("Sum", FormatAmount(c.creditTotal+c.debitTotal))
+	x.open("TtlNetNtry")
+	x.element("Amt", FormatAmount(abs(summary.TotalCredits+summary.TotalDebits)))
+	x.element("CdtDbtInd", creditDebit(summary.TotalCredits+summary.TotalDebits))
+	x.close("TtlNetNtry")
+	x.close("TtlNtries")
+	x.open("TtlCdtNtries")
+	x.element("NbOfNtries", strconv.Itoa(c.credits))
+	x.element("Sum", FormatAmount(c.creditTotal))
+	x.close("TtlCdtNtries")
+	x.open("TtlDbtNtries")
+	x.element("NbOfNtries", strconv.Itoa(c.debits))
+	x.element("Sum", FormatAmount(c.debitTotal))
+	x.close("TtlDbtNtries")
+	x.close("TxsSummry")
+
+	if err := x.flush(); err != nil {
+		return err
+	}
+
+	if _, err := c.spool.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("cannot rewind spool file: %w", err)
+	}
+
+	if _, err := io.Copy(c.w, c.spool); err != nil {
+		return err
+	}
+
+	x.depth = 3
+	x.close("Stmt")
+	x.close("BkToCstmrStmt")
+	x.close("Document")
+
+	if err := x.flush(); err != nil {
+		return err
+	}
+
+	return c.Close()
+}
+
+// Close removes the spool file.
+func (c *camt053Writer) Close() error {
+	if c.spool == nil {
+		return nil
+	}
+
+	name := c.spool.Name()
+	c.spool.Close()
+	c.spool = nil
+
+	return os.Remove(name)
+}
+
+func camtBalance(x *xmlWriter, code string, amount int64, currency string, date time.Time) {
+	x.open("Bal")
+	x.open("Tp")
+	x.open("CdOrPrtry")
+	x.element("Cd", code)
+	x.close("CdOrPrtry")
+	x.close("Tp")
+	camtAmount(x, amount, currency)
+	x.element("CdtDbtInd", creditDebit(amount))
+	x.open("Dt")
+	x.element("Dt", date.Format(DateLayout))
+	x.close("Dt")
+	x.close("Bal")
+}
+
+// camtAmount writes the magnitude of amount; its sign goes in CdtDbtInd.
+func camtAmount(x *xmlWriter, amount int64, currency string) {
+	x.element("Amt", FormatAmount(abs(amount)), "Ccy", currency)
+}
+
+func camtAccountID(x *xmlWriter, id int64) {
+	x.open("Id")
+	x.open("Othr")
+	x.element("Id", strconv.FormatInt(id, 10))
+	x.close("Othr")
+	x.close("Id")
+}
+
+func creditDebit(amount int64) string {
+	if amount < 0 {
+		return "DBIT"
+	}
+
+	return "CRDT"
+}
+
+func abs(amount int64) int64 {
+	if amount < 0 {
+		return -amount
+	}
+
+	return amount
+}
+
+// isoTime formats t as an ISO 8601 date and time in UTC.
+func isoTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
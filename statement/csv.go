@@ -0,0 +1,73 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+var csvColumns = []string{
+	"date",
+	"entry_id",
+	"transfer_id",
+	"description",
+	"counterparty_account_id",
+	"counterparty_owner",
+	"amount",
+	"balance",
+}
+
+// csvWriter writes a row per entry between an opening and a closing balance
+// row. Amounts are decimals.
+type csvWriter struct {
+	w      *csv.Writer
+	header Header
+}
+
+// NewCSVWriter creates a Writer for CSV statements.
+func NewCSVWriter(w io.Writer) Writer {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) Begin(header Header) error {
+	c.header = header
+
+	if err := c.w.Write(csvColumns); err != nil {
+		return err
+	}
+
+	return c.balanceRow(header.From, "Opening balance", header.OpeningBalance)
+}
+
+func (c *csvWriter) Line(line Line) error {
+	counterparty := ""
+	if line.CounterpartyAccountID != 0 {
+		counterparty = strconv.FormatInt(line.CounterpartyAccountID, 10)
+	}
+
+	return c.w.Write([]string{
+		line.Time.UTC().Format(time.RFC3339),
+		strconv.FormatInt(line.EntryID, 10),
+		strconv.FormatInt(line.TransferID, 10),
+		line.Description,
+		counterparty,
+		line.CounterpartyOwner,
+		FormatAmount(line.Amount),
+		FormatAmount(line.Balance),
+	})
+}
+
+func (c *csvWriter) End(summary Summary) error {
+	if err := c.balanceRow(c.header.To, "Closing balance", summary.ClosingBalance); err != nil {
+		return err
+	}
+
+	c.w.Flush()
+
+	return c.w.Error()
+}
+
+func (c *csvWriter) balanceRow(date time.Time, description string, balance int64) error {
+	return c.w.Write([]string{date.Format(DateLayout), "", "", description, "", "", "", FormatAmount(balance)})
+}
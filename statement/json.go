@@ -0,0 +1,115 @@
+package statement
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+type jsonHeader struct {
+	AccountID      int64     `json:"account_id"`
+	Owner          string    `json:"owner"`
+	Currency       string    `json:"currency"`
+	From           string    `json:"from"`
+	To             string    `json:"to"`
+	GeneratedAt    time.Time `json:"generated_at"`
+	OpeningBalance int64     `json:"opening_balance"`
+}
+
+type jsonLine struct {
+	EntryID               int64     `json:"entry_id"`
+	TransferID            int64     `json:"transfer_id"`
+	CreatedAt             time.Time `json:"created_at"`
+	Description           string    `json:"description"`
+	CounterpartyAccountID int64     `json:"counterparty_account_id"`
+	CounterpartyOwner     string    `json:"counterparty_owner"`
+	Amount                int64     `json:"amount"`
+	Balance               int64     `json:"balance"`
+}
+
+type jsonSummary struct {
+	ClosingBalance int64 `json:"closing_balance"`
+	TotalCredits   int64 `json:"total_credits"`
+	TotalDebits    int64 `json:"total_debits"`
+	EntryCount     int   `json:"entry_count"`
+}
+
+// jsonWriter writes a single object with the header fields, an "entries"
+// array and the summary fields. Amounts are in minor units, as elsewhere in
+// the API. The object is written piece by piece, so it is only complete
+// once End returns.
+type jsonWriter struct {
+	w     *bufio.Writer
+	lines int
+}
+
+// NewJSONWriter creates a Writer for JSON statements.
+func NewJSONWriter(w io.Writer) Writer {
+	return &jsonWriter{w: bufio.NewWriter(w)}
+}
+
+func (j *jsonWriter) Begin(header Header) error {
+	data, err := json.Marshal(jsonHeader{
+		AccountID:      header.AccountID,
+		Owner:          header.Owner,
+		Currency:       header.Currency,
+		From:           header.From.Format(DateLayout),
+		To:             header.To.Format(DateLayout),
+		GeneratedAt:    header.GeneratedAt,
+		OpeningBalance: header.OpeningBalance,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Leave the object open for the entries.
+	j.w.Write(data[:len(data)-1])
+	_, err = j.w.WriteString(`,"entries":[`)
+
+	return err
+}
+
+func (j *jsonWriter) Line(line Line) error {
+	data, err := json.Marshal(jsonLine{
+		EntryID:               line.EntryID,
+		TransferID:            line.TransferID,
+		CreatedAt:             line.Time,
+		Description:           line.Description,
+		CounterpartyAccountID: line.CounterpartyAccountID,
+		CounterpartyOwner:     line.CounterpartyOwner,
+		Amount:                line.Amount,
+		Balance:               line.Balance,
+	})
+	if err != nil {
+		return err
+	}
+
+	if j.lines > 0 {
+		j.w.WriteByte(',')
+	}
+
+	j.lines++
+	_, err = j.w.Write(data)
+
+	return err
+}
+
+func (j *jsonWriter) End(summary Summary) error {
+	data, err := json.Marshal(jsonSummary{
+		ClosingBalance: summary.ClosingBalance,
+		TotalCredits:   summary.TotalCredits,
+		TotalDebits:    summary.TotalDebits,
+		EntryCount:     summary.EntryCount,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Close the entries and append the summary fields to the object.
+	j.w.WriteString("],")
+	j.w.Write(data[1:])
+	j.w.WriteByte('\n')
+
+	return j.w.Flush()
+}
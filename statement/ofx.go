@@ -0,0 +1,128 @@
+package statement
+
+import (
+	"io"
+	"strconv"
+	"time"
+)
+
+// ofxBankID identifies the bank in BANKACCTFROM, which allows nine
+// characters.
+const ofxBankID = "SIMPLEBNK"
+
+// OFX limits the payee name to 32 characters and the memo to 255.
+const (
+	ofxNameLength = 32
+	ofxMemoLength = 255
+)
+
+// ofxWriter writes an OFX 2.1.1 bank statement response. OFX has no
+// opening balance, so the statement carries only the closing balance as
+// the ledger balance after the transaction list.
+type ofxWriter struct {
+	x      *xmlWriter
+	header Header
+}
+
+// NewOFXWriter creates a Writer for OFX statements.
+func NewOFXWriter(w io.Writer) Writer {
+	return &ofxWriter{x: newXMLWriter(w)}
+}
+
+func (o *ofxWriter) Begin(header Header) error {
+	o.header = header
+	x := o.x
+
+	x.raw(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n")
+	x.raw(`<?OFX OFXHEADER="200" VERSION="211" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n")
+	x.open("OFX")
+
+	x.open("SIGNONMSGSRSV1")
+	x.open("SONRS")
+	o.status()
+	x.element("DTSERVER", ofxTime(header.GeneratedAt))
+	x.element("LANGUAGE", "ENG")
+	x.close("SONRS")
+	x.close("SIGNONMSGSRSV1")
+
+	x.open("BANKMSGSRSV1")
+	x.open("STMTTRNRS")
+	x.element("TRNUID", "0")
+	o.status()
+	x.open("STMTRS")
+	x.element("CURDEF", header.Currency)
+	x.open("BANKACCTFROM")
+	x.element("BANKID", ofxBankID)
+	x.element("ACCTID", strconv.FormatInt(header.AccountID, 10))
+	x.element("ACCTTYPE", "CHECKING")
+	x.close("BANKACCTFROM")
+	x.open("BANKTRANLIST")
+	x.element("DTSTART", ofxTime(header.From))
+	x.element("DTEND", ofxTime(periodEnd(header)))
+
+	return x.err
+}
+
+func (o *ofxWriter) Line(line Line) error {
+	x := o.x
+
+	trnType := "CREDIT"
+	if line.Amount < 0 {
+		trnType = "DEBIT"
+	}
+
+	x.open("STMTTRN")
+	x.element("TRNTYPE", trnType)
+	x.element("DTPOSTED", ofxTime(line.Time))
+	x.element("TRNAMT", FormatAmount(line.Amount))
+	x.element("FITID", strconv.FormatInt(line.EntryID, 10))
+
+	if line.TransferID != 0 {
+		x.element("REFNUM", strconv.FormatInt(line.TransferID, 10))
+		x.element("NAME", truncate(line.CounterpartyOwner, ofxNameLength))
+		x.open("BANKACCTTO")
+		x.element("BANKID", ofxBankID)
+		x.element("ACCTID", strconv.FormatInt(line.CounterpartyAccountID, 10))
+		x.element("ACCTTYPE", "CHECKING")
+		x.close("BANKACCTTO")
+	}
+
+	x.element("MEMO", truncate(line.Description, ofxMemoLength))
+	x.close("STMTTRN")
+
+	return x.err
+}
+
+func (o *ofxWriter) End(summary Summary) error {
+	x := o.x
+
+	x.close("BANKTRANLIST")
+	x.open("LEDGERBAL")
+	x.element("BALAMT", FormatAmount(summary.ClosingBalance))
+	x.element("DTASOF", ofxTime(periodEnd(o.header)))
+	x.close("LEDGERBAL")
+	x.close("STMTRS")
+	x.close("STMTTRNRS")
+	x.close("BANKMSGSRSV1")
+	x.close("OFX")
+
+	return x.flush()
+}
+
+func (o *ofxWriter) status() {
+	o.x.open("STATUS")
+	o.x.element("CODE", "0")
+	o.x.element("SEVERITY", "INFO")
+	o.x.close("STATUS")
+}
+
+// periodEnd is the end of the last day of the statement, which is also the
+// start of the next statement.
+func periodEnd(header Header) time.Time {
+	return header.To.AddDate(0, 0, 1)
+}
+
+// ofxTime formats t in the OFX datetime format, in UTC.
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:GMT]"
+}
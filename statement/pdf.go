@@ -0,0 +1,280 @@
+package statement
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// A4 page layout in points
+const (
+	pdfPageWidth  = 595.28
+	pdfPageHeight = 841.89
+	pdfMargin     = 50.0
+	pdfFontSize   = 9.0
+	pdfLineHeight = 14.0
+)
+
+// Column positions: dates and descriptions start at their x, amounts and
+// balances end at it.
+const (
+	pdfDateX        = pdfMargin
+	pdfDescriptionX = pdfMargin + 110
+	pdfAmountX      = pdfPageWidth - pdfMargin - 90
+	pdfBalanceX     = pdfPageWidth - pdfMargin
+)
+
+// pdfDescriptionLength keeps descriptions clear of the amount column
+const pdfDescriptionLength = 48
+
+// Fixed object numbers; pages take the numbers after them. The page tree is
+// written last, once every page is known.
+const (
+	pdfCatalogObject  = 1
+	pdfPagesObject    = 2
+	pdfFontObject     = 3
+	pdfBoldFontObject = 4
+	pdfFirstObject    = 5
+)
+
+// pdfWriter writes a PDF document page by page: each page is written out as
+// soon as it is full, and only the offsets of the objects written so far
+// are kept for the cross-reference table at the end.
+type pdfWriter struct {
+	w       io.Writer
+	written int64
+	err     error
+
+	offsets []int64 // by object number; 0 is unused
+	pages   []int
+
+	header  Header
+	content bytes.Buffer
+	y       float64
+}
+
+// NewPDFWriter creates a Writer for PDF statements, laid out on A4 pages.
+func NewPDFWriter(w io.Writer) Writer {
+	return &pdfWriter{w: w, offsets: make([]int64, pdfFirstObject)}
+}
+
+func (p *pdfWriter) Begin(header Header) error {
+	p.header = header
+
+	// The comment of high bytes marks the file as binary.
+	p.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+
+	p.object(pdfCatalogObject, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pdfPagesObject))
+	p.object(pdfFontObject, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	p.object(pdfBoldFontObject, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	p.startPage()
+
+	p.text("F2", 16, pdfMargin, p.y, "Account statement")
+	p.y -= 2 * pdfLineHeight
+
+	for _, line := range []string{
+		fmt.Sprintf("Account: %d (%s)", header.AccountID, header.Currency),
+		"Owner: " + header.Owner,
+		fmt.Sprintf("Period: %s to %s", header.From.Format(DateLayout), header.To.Format(DateLayout)),
+		"Generated: " + header.GeneratedAt.UTC().Format(time.RFC1123),
+	} {
+		p.text("F1", pdfFontSize, pdfMargin, p.y, line)
+		p.y -= pdfLineHeight
+	}
+
+	p.y -= pdfLineHeight
+	p.tableHeader()
+	p.row("F2", header.From.Format(DateLayout), "Opening balance", "", FormatAmount(header.OpeningBalance))
+
+	return p.err
+}
+
+func (p *pdfWriter) Line(line Line) error {
+	if p.y < pdfMargin+2*pdfLineHeight {
+		p.endPage()
+		p.startPage()
+		p.tableHeader()
+	}
+
+	p.row("F1",
+		line.Time.UTC().Format("2006-01-02 15:04"),
+		truncate(line.Description, pdfDescriptionLength),
+		FormatAmount(line.Amount),
+		FormatAmount(line.Balance),
+	)
+
+	return p.err
+}
+
+func (p *pdfWriter) End(summary Summary) error {
+	if p.y < pdfMargin+5*pdfLineHeight {
+		p.endPage()
+		p.startPage()
+	}
+
+	p.row("F2", p.header.To.Format(DateLayout), "Closing balance", "", FormatAmount(summary.ClosingBalance))
+	p.y -= pdfLineHeight
+
+	for _, line := range []string{
+		fmt.Sprintf("Entries: %d", summary.EntryCount),
+		"Total credits: " + FormatAmount(summary.TotalCredits),
+		"Total debits: " + FormatAmount(summary.TotalDebits),
+	} {
+		p.text("F1", pdfFontSize, pdfMargin, p.y, line)
+		p.y -= pdfLineHeight
+	}
+
+	p.endPage()
+
+	kids := make([]string, len(p.pages))
+	for i, page := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+	}
+
+	p.object(pdfPagesObject, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+
+	xref := p.written
+	p.printf("xref\n0 %d\n0000000000 65535 f \n", len(p.offsets))
+
+	for _, offset := range p.offsets[1:] {
+		p.printf("%010d 00000 n \n", offset)
+	}
+
+	p.printf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(p.offsets), pdfCatalogObject, xref)
+
+	return p.err
+}
+
+func (p *pdfWriter) startPage() {
+	p.content.Reset()
+	p.y = pdfPageHeight - pdfMargin
+}
+
+// endPage writes the current page, numbered at the bottom.
+func (p *pdfWriter) endPage() {
+	p.text("F1", 8, pdfMargin, pdfMargin/2, fmt.Sprintf("Page %d", len(p.pages)+1))
+
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write(p.content.Bytes())
+	zw.Close()
+
+	contents := p.newObject()
+	p.stream(contents, compressed.Bytes())
+
+	page := p.newObject()
+	p.object(page, fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Contents %d 0 R "+
+			"/Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> >>",
+		pdfPagesObject, pdfPageWidth, pdfPageHeight, contents, pdfFontObject, pdfBoldFontObject,
+	))
+
+	p.pages = append(p.pages, page)
+}
+
+func (p *pdfWriter) tableHeader() {
+	p.row("F2", "Date", "Description", "Amount", "Balance")
+	fmt.Fprintf(&p.content, "0.5 w %.2f %.2f m %.2f %.2f l S\n",
+		pdfMargin, p.y+pdfLineHeight-3, pdfPageWidth-pdfMargin, p.y+pdfLineHeight-3)
+}
+
+func (p *pdfWriter) row(font, date, description, amount, balance string) {
+	p.text(font, pdfFontSize, pdfDateX, p.y, date)
+	p.text(font, pdfFontSize, pdfDescriptionX, p.y, description)
+	p.text(font, pdfFontSize, pdfAmountX-textWidth(amount, pdfFontSize), p.y, amount)
+	p.text(font, pdfFontSize, pdfBalanceX-textWidth(balance, pdfFontSize), p.y, balance)
+	p.y -= pdfLineHeight
+}
+
+func (p *pdfWriter) text(font string, size, x, y float64, s string) {
+	if s == "" {
+		return
+	}
+
+	fmt.Fprintf(&p.content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(s))
+}
+
+// newObject reserves the next object number.
+func (p *pdfWriter) newObject() int {
+	p.offsets = append(p.offsets, 0)
+	return len(p.offsets) - 1
+}
+
+func (p *pdfWriter) object(number int, body string) {
+	p.offsets[number] = p.written
+	p.printf("%d 0 obj\n%s\nendobj\n", number, body)
+}
+
+func (p *pdfWriter) stream(number int, data []byte) {
+	p.offsets[number] = p.written
+	p.printf("%d 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", number, len(data))
+	p.write(data)
+	p.printf("\nendstream\nendobj\n")
+}
+
+func (p *pdfWriter) printf(format string, args ...any) {
+	p.write(fmt.Appendf(nil, format, args...))
+}
+
+func (p *pdfWriter) write(data []byte) {
+	if p.err != nil {
+		return
+	}
+
+	n, err := p.w.Write(data)
+	p.written += int64(n)
+	p.err = err
+}
+
+// pdfString escapes s for a PDF string in WinAnsiEncoding. Characters
+// outside Latin-1 are replaced.
+func pdfString(s string) string {
+	var b strings.Builder
+
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0) || r > 0xff:
+			b.WriteByte('?')
+		default:
+			b.WriteByte(byte(r))
+		}
+	}
+
+	return b.String()
+}
+
+// textWidth returns the width of s set in Helvetica. It is exact for the
+// digits and signs of amounts, which are all that is right-aligned.
+func textWidth(s string, size float64) float64 {
+	units := 0
+	for _, r := range s {
+		switch r {
+		case '.', ',', ' ':
+			units += 278
+		case '-':
+			units += 333
+		default:
+			units += 556
+		}
+	}
+
+	return float64(units) * size / 1000
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	runes := []rune(s)
+
+	return string(runes[:n-3]) + "..."
+}
@@ -0,0 +1,209 @@
+// Package statement renders account statements: the opening balance of a
+// period, every entry in it with the counterparty and the running balance,
+// and the closing balance.
+//
+// Entries are read from the database a page at a time and written out as
+// they arrive, so a statement of any length is produced in constant memory.
+package statement
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/shevgn/simplebank/db/sqlc"
+)
+
+// pageSize is how many entries are read from the database at a time
+const pageSize = 500
+
+// DateLayout is the layout of statement period dates.
+const DateLayout = "2006-01-02"
+
+// Header describes the statement before its first line.
+type Header struct {
+	AccountID int64
+	Owner     string
+	Currency  string
+	// From is the first day of the period and To the last, both in UTC.
+	From           time.Time
+	To             time.Time
+	OpeningBalance int64
+	GeneratedAt    time.Time
+}
+
+// Line is an entry on the account. Balance is the balance after the entry.
+type Line struct {
+	EntryID               int64
+	TransferID            int64
+	Time                  time.Time
+	Description           string
+	CounterpartyAccountID int64
+	CounterpartyOwner     string
+	Amount                int64
+	Balance               int64
+}
+
+// Summary closes the statement.
+type Summary struct {
+	ClosingBalance int64
+	TotalCredits   int64
+	// TotalDebits is the sum of the negative amounts, so it is not positive.
+	TotalDebits int64
+	EntryCount  int
+}
+
+// Writer renders a statement. Begin is called once, then Line for every
+// entry in order, then End. A Writer that holds resources also implements
+// io.Closer, and Generate closes it before returning.
+type Writer interface {
+	Begin(header Header) error
+	Line(line Line) error
+	End(summary Summary) error
+}
+
+// Format is a statement file format.
+type Format struct {
+	ContentType string
+	Extension   string
+	NewWriter   func(w io.Writer) Writer
+}
+
+var formats = map[string]Format{
+	"csv":  {ContentType: "text/csv; charset=utf-8", Extension: "csv", NewWriter: NewCSVWriter},
+	"json": {ContentType: "application/json; charset=utf-8", Extension: "json", NewWriter: NewJSONWriter},
+	"pdf":  {ContentType: "application/pdf", Extension: "pdf", NewWriter: NewPDFWriter},
+	"ofx":  {ContentType: "application/x-ofx", Extension: "ofx", NewWriter: NewOFXWriter},
+	// ISO 20022 camt.053, the XML bank to customer statement.
+	"camt053": {ContentType: "application/xml; charset=utf-8", Extension: "xml", NewWriter: NewCamt053Writer},
+}
+
+// LookupFormat returns the format called name.
+func LookupFormat(name string) (Format, bool) {
+	format, ok := formats[name]
+	return format, ok
+}
+
+// FormatNames returns the names of the supported formats in sorted order.
+func FormatNames() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// Store is the subset of db.Store used to generate statements.
+type Store interface {
+	GetOpeningBalance(ctx context.Context, arg db.GetOpeningBalanceParams) (int64, error)
+	ListStatementEntries(ctx context.Context, arg db.ListStatementEntriesParams) ([]db.ListStatementEntriesRow, error)
+}
+
+// Generate writes the statement of account for the days from through to,
+// both inclusive and in UTC, to w.
+func Generate(ctx context.Context, store Store, account db.Account, from, to time.Time, w Writer) error {
+	if closer, ok := w.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	start := pgtype.Timestamp{Time: from, Valid: true}
+	end := pgtype.Timestamp{Time: to.AddDate(0, 0, 1), Valid: true}
+
+	opening, err := store.GetOpeningBalance(ctx, db.GetOpeningBalanceParams{
+		AccountID: account.ID,
+		FromTime:  start,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot get opening balance: %w", err)
+	}
+
+	err = w.Begin(Header{
+		AccountID:      account.ID,
+		Owner:          account.Owner,
+		Currency:       account.Currency,
+		From:           from,
+		To:             to,
+		OpeningBalance: opening,
+		GeneratedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	summary := Summary{ClosingBalance: opening}
+	arg := db.ListStatementEntriesParams{
+		AccountID: account.ID,
+		FromTime:  start,
+		ToTime:    end,
+		PageSize:  pageSize,
+	}
+
+	for {
+		rows, err := store.ListStatementEntries(ctx, arg)
+		if err != nil {
+			return fmt.Errorf("cannot list entries: %w", err)
+		}
+
+		for _, row := range rows {
+			summary.ClosingBalance += row.Amount
+			summary.EntryCount++
+
+			if row.Amount < 0 {
+				summary.TotalDebits += row.Amount
+			} else {
+				summary.TotalCredits += row.Amount
+			}
+
+			err := w.Line(Line{
+				EntryID:               row.ID,
+				TransferID:            row.TransferID,
+				Time:                  row.CreatedAt.Time,
+				Description:           describe(row),
+				CounterpartyAccountID: row.CounterpartyAccountID,
+				CounterpartyOwner:     row.CounterpartyOwner,
+				Amount:                row.Amount,
+				Balance:               summary.ClosingBalance,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(rows) < pageSize {
+			break
+		}
+
+		arg.AfterID = rows[len(rows)-1].ID
+	}
+
+	return w.End(summary)
+}
+
+func describe(row db.ListStatementEntriesRow) string {
+	switch {
+	case row.TransferID == 0:
+		return "Balance adjustment"
+	case row.Amount < 0:
+		return fmt.Sprintf("Transfer to account %d (%s)", row.CounterpartyAccountID, row.CounterpartyOwner)
+	default:
+		return fmt.Sprintf("Transfer from account %d (%s)", row.CounterpartyAccountID, row.CounterpartyOwner)
+	}
+}
+
+// FormatAmount formats an amount in minor units as a decimal, e.g. -1234 as
+// "-12.34". Every supported currency has two decimal places.
+func FormatAmount(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
@@ -0,0 +1,131 @@
+package statement
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// recorder is a Writer that keeps what it is given.
+type recorder struct {
+	header  Header
+	lines   []Line
+	summary Summary
+}
+
+func (r *recorder) Begin(header Header) error {
+	r.header = header
+	return nil
+}
+
+func (r *recorder) Line(line Line) error {
+	r.lines = append(r.lines, line)
+	return nil
+}
+
+func (r *recorder) End(summary Summary) error {
+	r.summary = summary
+	return nil
+}
+
+func statementRows(firstID int64, n int) []db.ListStatementEntriesRow {
+	rows := make([]db.ListStatementEntriesRow, n)
+	for i := range rows {
+		rows[i] = db.ListStatementEntriesRow{
+			ID:                    firstID + int64(i),
+			Amount:                10,
+			TransferID:            firstID + int64(i),
+			CreatedAt:             pgtype.Timestamp{Time: time.Date(2026, 9, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+			CounterpartyAccountID: 9,
+			CounterpartyOwner:     "bob",
+		}
+	}
+
+	return rows
+}
+
+func TestGenerate(t *testing.T) {
+	account := db.Account{ID: 3, Owner: "alice", Currency: "USD"}
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)
+
+	firstPage := statementRows(1, pageSize)
+	firstPage[0].Amount = -50
+
+	secondPage := statementRows(pageSize+1, 2)
+	secondPage[1].TransferID = 0
+
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	store.EXPECT().
+		GetOpeningBalance(gomock.Any(), gomock.Eq(db.GetOpeningBalanceParams{
+			AccountID: account.ID,
+			FromTime:  pgtype.Timestamp{Time: from, Valid: true},
+		})).
+		Times(1).
+		Return(int64(100), nil)
+
+	arg := db.ListStatementEntriesParams{
+		AccountID: account.ID,
+		FromTime:  pgtype.Timestamp{Time: from, Valid: true},
+		ToTime:    pgtype.Timestamp{Time: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		PageSize:  pageSize,
+	}
+	store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Eq(arg)).Times(1).Return(firstPage, nil)
+
+	arg.AfterID = pageSize
+	store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Eq(arg)).Times(1).Return(secondPage, nil)
+
+	var w recorder
+	require.NoError(t, Generate(context.Background(), store, account, from, to, &w))
+
+	require.Equal(t, int64(100), w.header.OpeningBalance)
+	require.Equal(t, account.Owner, w.header.Owner)
+	require.Len(t, w.lines, pageSize+2)
+
+	require.Equal(t, int64(50), w.lines[0].Balance)
+	require.Equal(t, "Transfer to account 9 (bob)", w.lines[0].Description)
+	require.Equal(t, int64(60), w.lines[1].Balance)
+	require.Equal(t, "Transfer from account 9 (bob)", w.lines[1].Description)
+	require.Equal(t, "Balance adjustment", w.lines[pageSize+1].Description)
+
+	wantClosing := int64(100 - 50 + 10*(pageSize+1))
+	require.Equal(t, wantClosing, w.lines[pageSize+1].Balance)
+	require.Equal(t, Summary{
+		ClosingBalance: wantClosing,
+		TotalCredits:   10 * (pageSize + 1),
+		TotalDebits:    -50,
+		EntryCount:     pageSize + 2,
+	}, w.summary)
+}
+
+func TestFormatAmount(t *testing.T) {
+	for amount, want := range map[int64]string{
+		0:     "0.00",
+		5:     "0.05",
+		1234:  "12.34",
+		-1234: "-12.34",
+		-7:    "-0.07",
+	} {
+		require.Equal(t, want, FormatAmount(amount))
+	}
+}
+
+func TestFormats(t *testing.T) {
+	require.Equal(t, []string{"camt053", "csv", "json", "ofx", "pdf"}, FormatNames())
+
+	for _, name := range FormatNames() {
+		format, ok := LookupFormat(name)
+		require.True(t, ok)
+		require.NotEmpty(t, format.ContentType)
+		require.NotEmpty(t, format.Extension)
+	}
+
+	_, ok := LookupFormat("xls")
+	require.False(t, ok)
+}
@@ -0,0 +1,271 @@
+package statement
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeStatement(t *testing.T, w Writer, lines int) {
+	header := Header{
+		AccountID:      3,
+		Owner:          "alice",
+		Currency:       "EUR",
+		From:           time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		To:             time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		OpeningBalance: 1000,
+		GeneratedAt:    time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, w.Begin(header))
+
+	balance := header.OpeningBalance
+	for i := range lines {
+		balance -= 25
+		require.NoError(t, w.Line(Line{
+			EntryID:               int64(i + 1),
+			TransferID:            int64(i + 1),
+			Time:                  time.Date(2026, 9, 2, 10, 30, 0, 0, time.UTC),
+			Description:           "Transfer to account 9 (bob (the builder))",
+			CounterpartyAccountID: 9,
+			CounterpartyOwner:     "bob",
+			Amount:                -25,
+			Balance:               balance,
+		}))
+	}
+
+	require.NoError(t, w.End(Summary{
+		ClosingBalance: balance,
+		TotalDebits:    -25 * int64(lines),
+		EntryCount:     lines,
+	}))
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writeStatement(t, NewCSVWriter(&buf), 2)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 5)
+
+	require.Equal(t, csvColumns, records[0])
+	require.Equal(t, []string{"2026-09-01", "", "", "Opening balance", "", "", "", "10.00"}, records[1])
+	require.Equal(t, []string{
+		"2026-09-02T10:30:00Z", "1", "1", "Transfer to account 9 (bob (the builder))", "9", "bob", "-0.25", "9.75",
+	}, records[2])
+	require.Equal(t, []string{"2026-09-30", "", "", "Closing balance", "", "", "", "9.50"}, records[4])
+}
+
+func TestJSONWriter(t *testing.T) {
+	for _, lines := range []int{0, 3} {
+		t.Run(strconv.Itoa(lines), func(t *testing.T) {
+			var buf bytes.Buffer
+			writeStatement(t, NewJSONWriter(&buf), lines)
+
+			var statement struct {
+				jsonHeader
+				Entries []jsonLine `json:"entries"`
+				jsonSummary
+			}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &statement))
+
+			require.Equal(t, int64(3), statement.AccountID)
+			require.Equal(t, "2026-09-01", statement.From)
+			require.Equal(t, int64(1000), statement.OpeningBalance)
+			require.Len(t, statement.Entries, lines)
+			require.Equal(t, int64(1000-25*lines), statement.ClosingBalance)
+			require.Equal(t, lines, statement.EntryCount)
+		})
+	}
+}
+
+func TestPDFWriter(t *testing.T) {
+	// Enough lines for several pages.
+	const lines = 150
+
+	var buf bytes.Buffer
+	writeStatement(t, NewPDFWriter(&buf), lines)
+
+	pdf := buf.Bytes()
+	require.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+
+	// Every cross-reference entry points at its object.
+	match := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+	require.NotNil(t, match)
+
+	xref, err := strconv.Atoi(string(match[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(pdf[xref:], []byte("xref\n")))
+
+	table := regexp.MustCompile(`(\d{10}) 00000 n \n`).FindAllSubmatch(pdf[xref:], -1)
+	require.NotEmpty(t, table)
+
+	for i, entry := range table {
+		offset, err := strconv.Atoi(string(entry[1]))
+		require.NoError(t, err)
+		require.True(t, bytes.HasPrefix(pdf[offset:], fmt.Appendf(nil, "%d 0 obj\n", i+1)), "object %d", i+1)
+	}
+
+	pages := regexp.MustCompile(`/Count (\d+)`).FindSubmatch(pdf)
+	require.NotNil(t, pages)
+	count, err := strconv.Atoi(string(pages[1]))
+	require.NoError(t, err)
+	require.Greater(t, count, 1)
+
+	// The last page holds the closing balance.
+	streams := regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindAllSubmatch(pdf, -1)
+	require.Len(t, streams, count)
+
+	zr, err := zlib.NewReader(bytes.NewReader(streams[count-1][1]))
+	require.NoError(t, err)
+
+	content, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	require.Contains(t, string(content), "(Closing balance)")
+	require.Contains(t, string(content), "(-27.50)")
+}
+
+func TestPDFString(t *testing.T) {
+	require.Equal(t, `bob \(the builder\) \\ caf`+"\xe9"+` ?`, pdfString("bob (the builder) \\ café €"))
+}
+
+// requireSchemaValid validates document against the XML schema in testdata
+// with xmllint. Locally the test is skipped without xmllint or the schema;
+// in CI, where both must be present, either missing fails the test.
+func requireSchemaValid(t *testing.T, schema string, document []byte) {
+	skip := t.Skip
+	if os.Getenv("CI") != "" {
+		skip = t.Fatal
+	}
+
+	xmllint, err := exec.LookPath("xmllint")
+	if err != nil {
+		skip("xmllint is not installed")
+	}
+
+	if _, err := os.Stat(filepath.Join("testdata", schema)); err != nil {
+		skip(fmt.Sprintf("schema %s is not vendored: %s", schema, err))
+	}
+
+	path := filepath.Join(t.TempDir(), "statement.xml")
+	require.NoError(t, os.WriteFile(path, document, 0o600))
+
+	output, err := exec.Command(xmllint, "--noout", "--schema", filepath.Join("testdata", schema), path).CombinedOutput()
+	require.NoError(t, err, string(output))
+}
+
+func TestOFXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writeStatement(t, NewOFXWriter(&buf), 2)
+
+	require.True(t, bytes.Contains(buf.Bytes(), []byte(`<?OFX OFXHEADER="200" VERSION="211"`)))
+
+	var ofx struct {
+		Currency     string `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>CURDEF"`
+		AccountID    string `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKACCTFROM>ACCTID"`
+		Start        string `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKTRANLIST>DTSTART"`
+		End          string `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKTRANLIST>DTEND"`
+		Transactions []struct {
+			Type   string `xml:"TRNTYPE"`
+			Amount string `xml:"TRNAMT"`
+			FITID  string `xml:"FITID"`
+			Name   string `xml:"NAME"`
+			ToID   string `xml:"BANKACCTTO>ACCTID"`
+			Memo   string `xml:"MEMO"`
+		} `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKTRANLIST>STMTTRN"`
+		LedgerBalance string `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>LEDGERBAL>BALAMT"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &ofx))
+
+	require.Equal(t, "EUR", ofx.Currency)
+	require.Equal(t, "3", ofx.AccountID)
+	require.Equal(t, "20260901000000.000[0:GMT]", ofx.Start)
+	require.Equal(t, "20261001000000.000[0:GMT]", ofx.End)
+	require.Len(t, ofx.Transactions, 2)
+	require.Equal(t, "DEBIT", ofx.Transactions[0].Type)
+	require.Equal(t, "-0.25", ofx.Transactions[0].Amount)
+	require.Equal(t, "1", ofx.Transactions[0].FITID)
+	require.Equal(t, "bob", ofx.Transactions[0].Name)
+	require.Equal(t, "9", ofx.Transactions[0].ToID)
+	require.Equal(t, "Transfer to account 9 (bob (the builder))", ofx.Transactions[0].Memo)
+	require.Equal(t, "9.50", ofx.LedgerBalance)
+
+	// OFX2_Protocol.xsd refers to OFX_Common.xsd and OFX_Banking.xsd in the
+	// same directory.
+	requireSchemaValid(t, "ofx211/OFX2_Protocol.xsd", buf.Bytes())
+}
+
+func TestCamt053Writer(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCamt053Writer(&buf)
+	writeStatement(t, w, 2)
+	require.Nil(t, w.(*camt053Writer).spool)
+
+	var document struct {
+		XMLName xml.Name
+		Stmt    struct {
+			AccountID string `xml:"Acct>Id>Othr>Id"`
+			Balances  []struct {
+				Code      string `xml:"Tp>CdOrPrtry>Cd"`
+				Amount    string `xml:"Amt"`
+				Indicator string `xml:"CdtDbtInd"`
+				Date      string `xml:"Dt>Dt"`
+			} `xml:"Bal"`
+			Entries      string `xml:"TxsSummry>TtlNtries>NbOfNtries"`
+			DebitEntries string `xml:"TxsSummry>TtlDbtNtries>NbOfNtries"`
+			DebitSum     string `xml:"TxsSummry>TtlDbtNtries>Sum"`
+			Entry        []struct {
+				Amount    string `xml:"Amt"`
+				Indicator string `xml:"CdtDbtInd"`
+				Creditor  string `xml:"NtryDtls>TxDtls>RltdPties>Cdtr>Pty>Nm"`
+				Account   string `xml:"NtryDtls>TxDtls>RltdPties>CdtrAcct>Id>Othr>Id"`
+			} `xml:"Ntry"`
+		} `xml:"BkToCstmrStmt>Stmt"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &document))
+
+	require.Equal(t, camt053Namespace, document.XMLName.Space)
+	require.Equal(t, "3", document.Stmt.AccountID)
+	require.Len(t, document.Stmt.Balances, 2)
+	require.Equal(t, "OPBD", document.Stmt.Balances[0].Code)
+	require.Equal(t, "10.00", document.Stmt.Balances[0].Amount)
+	require.Equal(t, "CLBD", document.Stmt.Balances[1].Code)
+	require.Equal(t, "9.50", document.Stmt.Balances[1].Amount)
+	require.Equal(t, "2026-09-30", document.Stmt.Balances[1].Date)
+	require.Equal(t, "2", document.Stmt.Entries)
+	require.Equal(t, "2", document.Stmt.DebitEntries)
+	require.Equal(t, "0.50", document.Stmt.DebitSum)
+	require.Len(t, document.Stmt.Entry, 2)
+	require.Equal(t, "0.25", document.Stmt.Entry[0].Amount)
+	require.Equal(t, "DBIT", document.Stmt.Entry[0].Indicator)
+	require.Equal(t, "bob", document.Stmt.Entry[0].Creditor)
+	require.Equal(t, "9", document.Stmt.Entry[0].Account)
+
+	requireSchemaValid(t, "camt.053.001.08.xsd", buf.Bytes())
+}
+
+func TestCamt053WriterClose(t *testing.T) {
+	w := NewCamt053Writer(io.Discard).(*camt053Writer)
+	require.NoError(t, w.Begin(Header{}))
+
+	spool := w.spool.Name()
+	require.FileExists(t, spool)
+
+	require.NoError(t, w.Close())
+	require.NoFileExists(t, spool)
+	require.NoError(t, w.Close())
+}
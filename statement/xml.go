@@ -0,0 +1,80 @@
+package statement
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// xmlWriter writes indented XML one element at a time. The first write
+// error sticks and is returned by flush.
+type xmlWriter struct {
+	w     *bufio.Writer
+	depth int
+	err   error
+}
+
+func newXMLWriter(w io.Writer) *xmlWriter {
+	return &xmlWriter{w: bufio.NewWriter(w)}
+}
+
+// open starts element name. attrs are pairs of attribute names and values.
+func (x *xmlWriter) open(name string, attrs ...string) {
+	x.indent()
+	x.startTag(name, attrs)
+	x.write(">\n")
+	x.depth++
+}
+
+func (x *xmlWriter) close(name string) {
+	x.depth--
+	x.indent()
+	x.write("</" + name + ">\n")
+}
+
+// element writes element name holding the text value.
+func (x *xmlWriter) element(name, value string, attrs ...string) {
+	x.indent()
+	x.startTag(name, attrs)
+	x.write(">" + escapeXML(value) + "</" + name + ">\n")
+}
+
+func (x *xmlWriter) raw(s string) {
+	x.write(s)
+}
+
+func (x *xmlWriter) flush() error {
+	if x.err != nil {
+		return x.err
+	}
+
+	return x.w.Flush()
+}
+
+func (x *xmlWriter) startTag(name string, attrs []string) {
+	x.write("<" + name)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		x.write(" " + attrs[i] + `="` + escapeXML(attrs[i+1]) + `"`)
+	}
+}
+
+func (x *xmlWriter) indent() {
+	x.write(strings.Repeat("  ", x.depth))
+}
+
+func (x *xmlWriter) write(s string) {
+	if x.err != nil {
+		return
+	}
+
+	_, x.err = x.w.WriteString(s)
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	// Writes to a strings.Builder do not fail.
+	_ = xml.EscapeText(&b, []byte(s))
+
+	return b.String()
+}
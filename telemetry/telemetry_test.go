@@ -0,0 +1,35 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shevgn/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup(t *testing.T) {
+	for _, exporter := range []string{"", ExporterNone, ExporterStdout, ExporterOTLP} {
+		t.Run(exporter, func(t *testing.T) {
+			config := &util.Config{
+				TracingExporter:    exporter,
+				TracingSampleRatio: 1,
+				OTLPEndpoint:       "localhost:4317",
+				OTLPInsecure:       true,
+			}
+
+			shutdown, err := Setup(context.Background(), config)
+			require.NoError(t, err)
+			require.NoError(t, shutdown(context.Background()))
+
+			require.Contains(t, otel.GetTextMapPropagator().Fields(), "traceparent")
+		})
+	}
+}
+
+func TestSetupUnsupportedExporter(t *testing.T) {
+	shutdown, err := Setup(context.Background(), &util.Config{TracingExporter: "zipkin"})
+	require.Error(t, err)
+	require.Nil(t, shutdown)
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 const minSecretKeyLength = 32
@@ -26,8 +27,23 @@ func NewJWTMaker(secretKey string) (Maker, error) {
 
 // CreateToken creates a token with a given duration
 func (j *JWTMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
+	return j.sign(NewPayload(username, duration))
+}
+
+// CreateSessionToken creates an access token for the login session
+// sessionID with a given duration
+func (j *JWTMaker) CreateSessionToken(
+	username string,
+	sessionID uuid.UUID,
+	duration time.Duration,
+) (string, *Payload, error) {
 	payload := NewPayload(username, duration)
+	payload.SessionID = sessionID
+
+	return j.sign(payload)
+}
 
+func (j *JWTMaker) sign(payload *Payload) (string, *Payload, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
 
 	signedToken, err := token.SignedString([]byte(j.secretKey))
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/shevgn/simplebank/util"
 	"github.com/stretchr/testify/require"
 )
@@ -33,6 +34,23 @@ func TestJWTMaker(t *testing.T) {
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
 }
 
+func TestJWTMakerSessionToken(t *testing.T) {
+	maker, err := NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	username := util.RandomOwner()
+	sessionID := uuid.New()
+
+	token, _, err := maker.CreateSessionToken(username, sessionID, time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, username, payload.Username)
+	require.Equal(t, sessionID, payload.SessionID)
+	require.NotEqual(t, sessionID, payload.ID)
+}
+
 func TestExpiredJWTToken(t *testing.T) {
 	maker, err := NewJWTMaker(util.RandomString(32))
 	require.NoError(t, err)
@@ -1,12 +1,20 @@
 // Package token provides a way to handle tokens
 package token
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Maker interface {
 	// CreteToken creates a token with a given duration
 	CreateToken(username string, duration time.Duration) (string, *Payload, error)
 
+	// CreateSessionToken creates an access token for the login session
+	// sessionID with a given duration
+	CreateSessionToken(username string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error)
+
 	// VerifyToken verifies a token and returns a payload
 	VerifyToken(token string) (*Payload, error)
 }
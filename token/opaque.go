@@ -0,0 +1,32 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// opaqueTokenBytes is the entropy of an opaque token, 256 bits
+const opaqueTokenBytes = 32
+
+// NewOpaqueToken returns a random, URL-safe token for single-use links such
+// as password resets. Unlike access tokens it carries no claims; only its
+// hash is stored, see HashOpaqueToken.
+func NewOpaqueToken() (string, error) {
+	b := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashOpaqueToken returns the hex-encoded SHA-256 of token. Opaque tokens are
+// long and random, so a fast unsalted hash is enough to keep a database leak
+// from exposing usable tokens.
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -18,8 +18,11 @@ var (
 
 // Payload is a token payload
 type Payload struct {
-	ID        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	// SessionID is the login session an access token was issued for; it is
+	// zero for refresh tokens, whose ID is the session ID.
+	SessionID uuid.UUID `json:"session_id,omitzero"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
@@ -1,6 +1,19 @@
 package util
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -8,29 +21,411 @@ import (
 
 // Config stores all configuration values
 type Config struct {
-	DBSource             string        `mapstructure:"DB_SOURCE"`
-	ServerAddress        string        `mapstructure:"SERVER_ADDRESS"`
-	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	Environment             string        `mapstructure:"APP_ENV"`
+	DBSource                string        `mapstructure:"DB_SOURCE"`
+	ServerAddress           string        `mapstructure:"SERVER_ADDRESS"`
+	GRPCServerAddress       string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	HTTPGatewayAddress      string        `mapstructure:"HTTP_GATEWAY_ADDRESS"`
+	TokenSymmetricKey       string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	AccessTokenDuration     time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration    time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	HTTPReadTimeout         time.Duration `mapstructure:"HTTP_READ_TIMEOUT"`
+	HTTPWriteTimeout        time.Duration `mapstructure:"HTTP_WRITE_TIMEOUT"`
+	HTTPIdleTimeout         time.Duration `mapstructure:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout         time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	MigrateOnStart          bool          `mapstructure:"MIGRATE_ON_START"`
+	LogLevel                string        `mapstructure:"LOG_LEVEL"`
+	TracingExporter         string        `mapstructure:"TRACING_EXPORTER"`
+	TracingSampleRatio      float64       `mapstructure:"TRACING_SAMPLE_RATIO"`
+	OTLPEndpoint            string        `mapstructure:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	OTLPInsecure            bool          `mapstructure:"OTEL_EXPORTER_OTLP_INSECURE"`
+	RateLimitBackend        string        `mapstructure:"RATE_LIMIT_BACKEND"`
+	LoginRateLimitPerIP     int           `mapstructure:"LOGIN_RATE_LIMIT_PER_IP"`
+	LoginRateLimitPerUser   int           `mapstructure:"LOGIN_RATE_LIMIT_PER_USERNAME"`
+	LoginRateLimitPeriod    time.Duration `mapstructure:"LOGIN_RATE_LIMIT_PERIOD"`
+	TrustedProxies          string        `mapstructure:"TRUSTED_PROXIES"`
+	LoginMaxFailedAttempts  int           `mapstructure:"LOGIN_MAX_FAILED_ATTEMPTS"`
+	LoginLockoutDuration    time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+	LoginMaxLockoutDuration time.Duration `mapstructure:"LOGIN_MAX_LOCKOUT_DURATION"`
+	Mailer                  string        `mapstructure:"MAILER"`
+	MailFrom                string        `mapstructure:"MAIL_FROM"`
+	MailDir                 string        `mapstructure:"MAIL_DIR"`
+	PasswordResetURL        string        `mapstructure:"PASSWORD_RESET_URL"`
+	PasswordResetDuration   time.Duration `mapstructure:"PASSWORD_RESET_TOKEN_DURATION"`
+	VerifyEmailURL          string        `mapstructure:"VERIFY_EMAIL_URL"`
+	VerifyEmailDuration     time.Duration `mapstructure:"VERIFY_EMAIL_TOKEN_DURATION"`
+	WorkerConcurrency       int           `mapstructure:"WORKER_CONCURRENCY"`
+	WorkerPollInterval      time.Duration `mapstructure:"WORKER_POLL_INTERVAL"`
+	WorkerMaxAttempts       int           `mapstructure:"WORKER_MAX_ATTEMPTS"`
+	WorkerRetryBaseDelay    time.Duration `mapstructure:"WORKER_RETRY_BASE_DELAY"`
+	WorkerRetryMaxDelay     time.Duration `mapstructure:"WORKER_RETRY_MAX_DELAY"`
+	OutboxSink              string        `mapstructure:"OUTBOX_SINK"`
+	OutboxFile              string        `mapstructure:"OUTBOX_FILE"`
+	OutboxHTTPURL           string        `mapstructure:"OUTBOX_HTTP_URL"`
+	OutboxBatchSize         int           `mapstructure:"OUTBOX_BATCH_SIZE"`
+	OutboxPollInterval      time.Duration `mapstructure:"OUTBOX_POLL_INTERVAL"`
+	WebhookTimeout          time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
+	WebhookMaxAttempts      int           `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookAllowedNetworks  string        `mapstructure:"WEBHOOK_ALLOWED_NETWORKS"`
+	InterestRates           string        `mapstructure:"INTEREST_RATES"`
 }
 
-// LoadConfig loads configuration from the given path
+// DefaultEnvironment is the profile used when APP_ENV is not set
+const DefaultEnvironment = "development"
+
+// secretFileSuffix marks variables that name a file holding the value of
+// another variable, e.g. TOKEN_SYMMETRIC_KEY_FILE=/run/secrets/token_key.
+const secretFileSuffix = "_FILE"
+
+// minTokenSymmetricKeyLength matches the minimum enforced by token.NewJWTMaker
+const minTokenSymmetricKeyLength = 32
+
+// LoadConfig loads configuration from the given path. Sources are applied in
+// increasing order of precedence:
+//
+//   - built-in defaults
+//   - app.env
+//   - config.yaml
+//   - config.<APP_ENV>.yaml, e.g. config.production.yaml
+//   - secrets read from the files named by *_FILE variables
+//   - environment variables
+//
+// Every file is optional, so the service can be configured from the
+// environment alone. The result is validated before it is returned.
 func LoadConfig(path string) (*Config, error) {
-	viper.AddConfigPath(path)
-	viper.SetConfigName("app")
-	viper.SetConfigType("env")
+	v := viper.New()
+	setDefaults(v)
 
-	viper.AutomaticEnv()
+	keys := configKeys()
+	for _, key := range keys {
+		if err := v.BindEnv(key); err != nil {
+			return nil, err
+		}
+	}
 
-	err := viper.ReadInConfig()
-	if err != nil {
+	if err := mergeConfigFile(v, filepath.Join(path, "app.env")); err != nil {
+		return nil, err
+	}
+
+	if err := mergeConfigFile(v, filepath.Join(path, "config.yaml")); err != nil {
+		return nil, err
+	}
+
+	environment := v.GetString("APP_ENV")
+	if err := mergeConfigFile(v, filepath.Join(path, fmt.Sprintf("config.%s.yaml", environment))); err != nil {
+		return nil, err
+	}
+
+	if err := readSecretFiles(v, keys); err != nil {
 		return nil, err
 	}
 
 	var config Config
+	if err := v.Unmarshal(&config); err != nil {
+		return nil, fmt.Errorf("cannot decode config: %w", err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("APP_ENV", DefaultEnvironment)
+	v.SetDefault("HTTP_READ_TIMEOUT", 10*time.Second)
+	v.SetDefault("HTTP_WRITE_TIMEOUT", 30*time.Second)
+	v.SetDefault("HTTP_IDLE_TIMEOUT", 120*time.Second)
+	v.SetDefault("SHUTDOWN_TIMEOUT", 30*time.Second)
+	v.SetDefault("LOG_LEVEL", "info")
+	v.SetDefault("TRACING_EXPORTER", "none")
+	v.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+	v.SetDefault("RATE_LIMIT_BACKEND", "memory")
+	v.SetDefault("LOGIN_RATE_LIMIT_PER_IP", 20)
+	v.SetDefault("LOGIN_RATE_LIMIT_PER_USERNAME", 5)
+	v.SetDefault("LOGIN_RATE_LIMIT_PERIOD", time.Minute)
+	v.SetDefault("LOGIN_MAX_FAILED_ATTEMPTS", 5)
+	v.SetDefault("LOGIN_LOCKOUT_DURATION", time.Minute)
+	v.SetDefault("LOGIN_MAX_LOCKOUT_DURATION", time.Hour)
+	v.SetDefault("MAILER", "log")
+	v.SetDefault("MAIL_FROM", "SimpleBank <no-reply@simplebank.local>")
+	v.SetDefault("MAIL_DIR", "tmp/mail")
+	v.SetDefault("PASSWORD_RESET_URL", "http://localhost:8080/reset_password")
+	v.SetDefault("PASSWORD_RESET_TOKEN_DURATION", time.Hour)
+	v.SetDefault("VERIFY_EMAIL_URL", "http://localhost:8080/users/verify_email")
+	v.SetDefault("VERIFY_EMAIL_TOKEN_DURATION", 24*time.Hour)
+	v.SetDefault("WORKER_CONCURRENCY", 4)
+	v.SetDefault("WORKER_POLL_INTERVAL", time.Second)
+	v.SetDefault("WORKER_MAX_ATTEMPTS", 5)
+	v.SetDefault("WORKER_RETRY_BASE_DELAY", 5*time.Second)
+	v.SetDefault("WORKER_RETRY_MAX_DELAY", time.Hour)
+	v.SetDefault("OUTBOX_SINK", "stdout")
+	v.SetDefault("OUTBOX_FILE", "tmp/outbox/events.jsonl")
+	v.SetDefault("OUTBOX_BATCH_SIZE", 100)
+	v.SetDefault("OUTBOX_POLL_INTERVAL", time.Second)
+	v.SetDefault("WEBHOOK_TIMEOUT", 10*time.Second)
+	v.SetDefault("WEBHOOK_MAX_ATTEMPTS", 8)
+}
+
+// configKeys lists the mapstructure keys of Config, which double as the
+// names of environment variables.
+func configKeys() []string {
+	t := reflect.TypeFor[Config]()
+
+	keys := make([]string, 0, t.NumField())
+	for i := range t.NumField() {
+		keys = append(keys, t.Field(i).Tag.Get("mapstructure"))
+	}
+
+	return keys
+}
+
+// mergeConfigFile merges the file at path into v, if it exists. The format
+// is taken from the file extension.
+func mergeConfigFile(v *viper.Viper, path string) error {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	v.SetConfigFile(path)
+	if err := v.MergeInConfig(); err != nil {
+		return fmt.Errorf("cannot read config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// readSecretFiles sets every key whose *_FILE environment variable is set to
+// the contents of that file. Setting both a variable and its *_FILE form is
+// ambiguous and rejected.
+func readSecretFiles(v *viper.Viper, keys []string) error {
+	var errs []error
+
+	for _, key := range keys {
+		path, ok := os.LookupEnv(key + secretFileSuffix)
+		if !ok {
+			continue
+		}
+
+		if _, ok := os.LookupEnv(key); ok {
+			errs = append(errs, fmt.Errorf("%s and %s%s are both set", key, key, secretFileSuffix))
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s%s: %w", key, secretFileSuffix, err))
+			continue
+		}
+
+		v.Set(key, strings.TrimRight(string(data), "\r\n"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate checks every setting and reports all problems at once
+func (c *Config) Validate() error {
+	var errs []error
+
+	invalid := func(key string, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if c.DBSource == "" {
+		invalid("DB_SOURCE", "must be set")
+	}
+
+	for key, address := range map[string]string{
+		"SERVER_ADDRESS":       c.ServerAddress,
+		"GRPC_SERVER_ADDRESS":  c.GRPCServerAddress,
+		"HTTP_GATEWAY_ADDRESS": c.HTTPGatewayAddress,
+	} {
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			invalid(key, "must be a host:port address, got %q", address)
+		}
+	}
+
+	if len(c.TokenSymmetricKey) < minTokenSymmetricKeyLength {
+		invalid("TOKEN_SYMMETRIC_KEY", "must be at least %d characters long", minTokenSymmetricKeyLength)
+	}
+
+	if c.AccessTokenDuration <= 0 {
+		invalid("ACCESS_TOKEN_DURATION", "must be positive")
+	}
 
-	err = viper.Unmarshal(&config)
+	if c.RefreshTokenDuration <= c.AccessTokenDuration {
+		invalid("REFRESH_TOKEN_DURATION", "must be longer than ACCESS_TOKEN_DURATION")
+	}
+
+	for key, timeout := range map[string]time.Duration{
+		"HTTP_READ_TIMEOUT":  c.HTTPReadTimeout,
+		"HTTP_WRITE_TIMEOUT": c.HTTPWriteTimeout,
+		"HTTP_IDLE_TIMEOUT":  c.HTTPIdleTimeout,
+		"SHUTDOWN_TIMEOUT":   c.ShutdownTimeout,
+	} {
+		if timeout <= 0 {
+			invalid(key, "must be positive")
+		}
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		invalid("LOG_LEVEL", "must be one of debug, info, warn or error, got %q", c.LogLevel)
+	}
+
+	switch c.TracingExporter {
+	case "none", "stdout":
+	case "otlp":
+		if c.OTLPEndpoint == "" {
+			invalid("OTEL_EXPORTER_OTLP_ENDPOINT", "must be set when TRACING_EXPORTER is otlp")
+		}
+	default:
+		invalid("TRACING_EXPORTER", "must be one of none, stdout or otlp, got %q", c.TracingExporter)
+	}
+
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		invalid("TRACING_SAMPLE_RATIO", "must be between 0 and 1")
+	}
+
+	switch c.RateLimitBackend {
+	case "memory", "postgres":
+	default:
+		invalid("RATE_LIMIT_BACKEND", "must be memory or postgres, got %q", c.RateLimitBackend)
+	}
+
+	for key, value := range map[string]int{
+		"LOGIN_RATE_LIMIT_PER_IP":       c.LoginRateLimitPerIP,
+		"LOGIN_RATE_LIMIT_PER_USERNAME": c.LoginRateLimitPerUser,
+		"LOGIN_MAX_FAILED_ATTEMPTS":     c.LoginMaxFailedAttempts,
+	} {
+		if value <= 0 {
+			invalid(key, "must be positive")
+		}
+	}
+
+	if c.LoginRateLimitPeriod <= 0 {
+		invalid("LOGIN_RATE_LIMIT_PERIOD", "must be positive")
+	}
+
+	if _, err := ParseNetworks(c.TrustedProxies); err != nil {
+		invalid("TRUSTED_PROXIES", "%s", err)
+	}
+
+	if c.LoginLockoutDuration <= 0 {
+		invalid("LOGIN_LOCKOUT_DURATION", "must be positive")
+	}
+
+	if c.LoginMaxLockoutDuration < c.LoginLockoutDuration {
+		invalid("LOGIN_MAX_LOCKOUT_DURATION", "must not be shorter than LOGIN_LOCKOUT_DURATION")
+	}
+
+	switch c.Mailer {
+	case "log":
+	case "file":
+		if c.MailDir == "" {
+			invalid("MAIL_DIR", "must be set when MAILER is file")
+		}
+	default:
+		invalid("MAILER", "must be log or file, got %q", c.Mailer)
+	}
+
+	if _, err := mail.ParseAddress(c.MailFrom); err != nil {
+		invalid("MAIL_FROM", "must be an email address, got %q", c.MailFrom)
+	}
+
+	for key, link := range map[string]string{
+		"PASSWORD_RESET_URL": c.PasswordResetURL,
+		"VERIFY_EMAIL_URL":   c.VerifyEmailURL,
+	} {
+		if u, err := url.Parse(link); err != nil || !u.IsAbs() {
+			invalid(key, "must be an absolute URL, got %q", link)
+		}
+	}
+
+	for key, duration := range map[string]time.Duration{
+		"PASSWORD_RESET_TOKEN_DURATION": c.PasswordResetDuration,
+		"VERIFY_EMAIL_TOKEN_DURATION":   c.VerifyEmailDuration,
+		"WORKER_POLL_INTERVAL":          c.WorkerPollInterval,
+		"WORKER_RETRY_BASE_DELAY":       c.WorkerRetryBaseDelay,
+		"OUTBOX_POLL_INTERVAL":          c.OutboxPollInterval,
+		"WEBHOOK_TIMEOUT":               c.WebhookTimeout,
+	} {
+		if duration <= 0 {
+			invalid(key, "must be positive")
+		}
+	}
+
+	for key, value := range map[string]int{
+		"WORKER_CONCURRENCY":   c.WorkerConcurrency,
+		"WORKER_MAX_ATTEMPTS":  c.WorkerMaxAttempts,
+		"OUTBOX_BATCH_SIZE":    c.OutboxBatchSize,
+		"WEBHOOK_MAX_ATTEMPTS": c.WebhookMaxAttempts,
+	} {
+		if value <= 0 {
+			invalid(key, "must be positive")
+		}
+	}
+
+	if c.WorkerRetryMaxDelay < c.WorkerRetryBaseDelay {
+		invalid("WORKER_RETRY_MAX_DELAY", "must not be shorter than WORKER_RETRY_BASE_DELAY")
+	}
+
+	switch c.OutboxSink {
+	case "stdout":
+	case "file":
+		if c.OutboxFile == "" {
+			invalid("OUTBOX_FILE", "must be set when OUTBOX_SINK is file")
+		}
+	case "http":
+		if u, err := url.Parse(c.OutboxHTTPURL); err != nil || !u.IsAbs() {
+			invalid("OUTBOX_HTTP_URL", "must be an absolute URL when OUTBOX_SINK is http, got %q", c.OutboxHTTPURL)
+		}
+	default:
+		invalid("OUTBOX_SINK", "must be one of stdout, file or http, got %q", c.OutboxSink)
+	}
+
+	if _, err := ParseNetworks(c.WebhookAllowedNetworks); err != nil {
+		invalid("WEBHOOK_ALLOWED_NETWORKS", "%s", err)
+	}
+
+	if _, err := ParseRates(c.InterestRates); err != nil {
+		invalid("INTEREST_RATES", "%s", err)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	slices.SortFunc(errs, func(a, b error) int {
+		return strings.Compare(a.Error(), b.Error())
+	})
+
+	return fmt.Errorf("invalid config:\n%w", errors.Join(errs...))
+}
+
+// TrustedProxyNetworks returns the networks in TRUSTED_PROXIES, whose
+// X-Forwarded-For headers are believed when working out a client's IP.
+// Validate rejects a list that does not parse; here it is treated as empty,
+// so no proxy is trusted.
+func (c *Config) TrustedProxyNetworks() []netip.Prefix {
+	networks, err := ParseNetworks(c.TrustedProxies)
+	if err != nil {
+		return nil
+	}
+
+	return networks
+}
+
+// WebhookNetworks returns the networks in WEBHOOK_ALLOWED_NETWORKS, which
+// webhooks may reach even though they are not public. Validate rejects a
+// list that does not parse; here it is treated as empty.
+func (c *Config) WebhookNetworks() []netip.Prefix {
+	networks, err := ParseNetworks(c.WebhookAllowedNetworks)
+	if err != nil {
+		return nil
+	}
 
-	return &config, err
+	return networks
 }
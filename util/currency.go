@@ -5,6 +5,11 @@ const (
 	EUR = "EUR"
 )
 
+// SupportedCurrencies returns all currencies accepted by the bank
+func SupportedCurrencies() []string {
+	return []string{USD, EUR}
+}
+
 // IsSupportedCurrency returns true if the currency is supported
 func IsSupportedCurrency(currency string) bool {
 	switch currency {
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// ParseNetworks parses a comma separated list of CIDR prefixes or single IP
+// addresses, such as "10.1.0.0/16,127.0.0.1". A single address is read as a
+// prefix covering only that address. An empty string has no networks.
+func ParseNetworks(s string) ([]netip.Prefix, error) {
+	var networks []netip.Prefix
+	if strings.TrimSpace(s) == "" {
+		return networks, nil
+	}
+
+	for item := range strings.SplitSeq(s, ",") {
+		item = strings.TrimSpace(item)
+
+		if strings.Contains(item, "/") {
+			prefix, err := netip.ParsePrefix(item)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a CIDR prefix or IP address", item)
+			}
+
+			networks = append(networks, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(item)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a CIDR prefix or IP address", item)
+		}
+
+		networks = append(networks, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	return networks, nil
+}
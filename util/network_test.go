@@ -0,0 +1,27 @@
+package util
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNetworks(t *testing.T) {
+	networks, err := ParseNetworks("10.1.2.3/16, 127.0.0.1,::1")
+	require.NoError(t, err)
+	require.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.1.0.0/16"),
+		netip.MustParsePrefix("127.0.0.1/32"),
+		netip.MustParsePrefix("::1/128"),
+	}, networks)
+
+	networks, err = ParseNetworks(" ")
+	require.NoError(t, err)
+	require.Empty(t, networks)
+
+	for _, s := range []string{"localhost", "10.0.0.0/33", "10.0.0.1,", "10.0.0"} {
+		_, err := ParseNetworks(s)
+		require.Error(t, err, s)
+	}
+}
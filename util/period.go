@@ -0,0 +1,16 @@
+package util
+
+import "time"
+
+// StartOfDay returns the UTC date t falls on, at midnight.
+func StartOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// StartOfMonth returns the first day of the UTC month t falls in, at
+// midnight.
+func StartOfMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,16 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartOfPeriod(t *testing.T) {
+	// 00:30 on the 1st in UTC+1 is still the last day of the month in UTC.
+	at := time.Date(2026, time.November, 1, 0, 30, 0, 0, time.FixedZone("CET", 3600))
+
+	require.Equal(t, time.Date(2026, time.October, 31, 0, 0, 0, 0, time.UTC), StartOfDay(at))
+	require.Equal(t, time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC), StartOfMonth(at))
+}
@@ -0,0 +1,57 @@
+package util
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+var isDecimal = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`).MatchString
+
+// ParseRate parses an annual rate written as a decimal fraction, such as
+// "0.035" for 3.5%. It is kept exact, as binary floating point cannot hold
+// most decimal rates. Rates above 1 are rejected.
+func ParseRate(s string) (*big.Rat, error) {
+	if !isDecimal(s) {
+		return nil, fmt.Errorf("rate %q is not a decimal fraction such as 0.035", s)
+	}
+
+	rate, _ := new(big.Rat).SetString(s)
+	if rate.Cmp(big.NewRat(1, 1)) > 0 {
+		return nil, fmt.Errorf("rate %q is above 1", s)
+	}
+
+	return rate, nil
+}
+
+// ParseRates parses a comma separated list of name=rate pairs, such as
+// "savings=0.035,business=0.01", into rates by name. An empty string has no
+// rates.
+func ParseRates(s string) (map[string]*big.Rat, error) {
+	rates := make(map[string]*big.Rat)
+	if strings.TrimSpace(s) == "" {
+		return rates, nil
+	}
+
+	for pair := range strings.SplitSeq(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%q is not a name=rate pair", pair)
+		}
+
+		if _, ok := rates[name]; ok {
+			return nil, fmt.Errorf("%s has more than one rate", name)
+		}
+
+		rate, err := ParseRate(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		rates[name] = rate
+	}
+
+	return rates, nil
+}
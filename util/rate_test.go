@@ -0,0 +1,40 @@
+package util
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRate(t *testing.T) {
+	rate, err := ParseRate("0.035")
+	require.NoError(t, err)
+	require.Equal(t, big.NewRat(7, 200), rate)
+
+	rate, err = ParseRate("0")
+	require.NoError(t, err)
+	require.Zero(t, rate.Sign())
+
+	for _, s := range []string{"", "3.5%", "-0.01", "1e-3", "7/200", "1.5", ".5"} {
+		_, err := ParseRate(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestParseRates(t *testing.T) {
+	rates, err := ParseRates("savings=0.035, business = 0.001")
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	require.Equal(t, big.NewRat(7, 200), rates["savings"])
+	require.Equal(t, big.NewRat(1, 1000), rates["business"])
+
+	rates, err = ParseRates("")
+	require.NoError(t, err)
+	require.Empty(t, rates)
+
+	for _, s := range []string{"savings", "=0.01", "savings=0.01,savings=0.02", "savings=high"} {
+		_, err := ParseRates(s)
+		require.Error(t, err, s)
+	}
+}
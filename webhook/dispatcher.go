@@ -0,0 +1,127 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// maxDrainBody is how much of a response is read so that the connection can
+// be reused. The body itself is never kept.
+const maxDrainBody = 4 << 10
+
+// Store is the subset of db.Store used by the Dispatcher.
+type Store interface {
+	RecordWebhookDeliveryAttempt(ctx context.Context, arg db.RecordWebhookDeliveryAttemptParams) (db.WebhookDelivery, error)
+}
+
+// Dispatcher sends deliveries to their endpoints and records every attempt.
+type Dispatcher struct {
+	store  Store
+	guard  *Guard
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewDispatcher creates a dispatcher that gives endpoints timeout to respond.
+// Redirects are not followed, so a delivery only reaches the registered URL,
+// and connections to addresses the guard forbids are refused. Proxies are
+// not used, as the guard could only check the proxy's address.
+func NewDispatcher(store Store, timeout time.Duration, guard *Guard) *Dispatcher {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = guard.dialer().DialContext
+
+	return &Dispatcher{
+		store: store,
+		guard: guard,
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: otelhttp.NewTransport(transport),
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		now: time.Now,
+	}
+}
+
+// CheckURL rejects a webhook URL whose host is a forbidden address; see
+// Guard.CheckURL.
+func (d *Dispatcher) CheckURL(rawURL string) error {
+	return d.guard.CheckURL(rawURL)
+}
+
+// Deliver sends delivery to endpoint and records the attempt. A failed
+// attempt leaves the delivery pending for a retry, unless final is set, in
+// which case it is marked failed. It returns the updated delivery, whose
+// status tells whether the endpoint accepted it; an error means the attempt
+// could not be recorded.
+func (d *Dispatcher) Deliver(
+	ctx context.Context,
+	endpoint db.WebhookEndpoint,
+	delivery db.WebhookDelivery,
+	final bool,
+) (db.WebhookDelivery, error) {
+	responseStatus, sendErr := d.send(ctx, endpoint, delivery)
+
+	arg := db.RecordWebhookDeliveryAttemptParams{
+		ID:             delivery.ID,
+		Status:         db.WebhookDeliverySucceeded,
+		ResponseStatus: int32(responseStatus),
+	}
+
+	if sendErr != nil {
+		arg.LastError = sendErr.Error()
+		arg.Status = db.WebhookDeliveryPending
+		if final {
+			arg.Status = db.WebhookDeliveryFailed
+		}
+	}
+
+	updated, err := d.store.RecordWebhookDeliveryAttempt(ctx, arg)
+	if err != nil {
+		return delivery, fmt.Errorf("cannot record webhook delivery: %w", err)
+	}
+
+	return updated, nil
+}
+
+// send posts the delivery and returns the response status, 0 if there was
+// no response.
+func (d *Dispatcher) send(ctx context.Context, endpoint db.WebhookEndpoint, delivery db.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "SimpleBank-Webhooks/1.0")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+
+	now := d.now()
+	req.Header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(endpoint.Secret, now, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBody))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with %s", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
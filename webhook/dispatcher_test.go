@@ -0,0 +1,135 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// testGuard lets deliveries reach httptest servers on the loopback address.
+var testGuard = NewGuard([]netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")})
+
+func TestDispatcherDeliver(t *testing.T) {
+	testCases := []struct {
+		name       string
+		status     int
+		final      bool
+		wantStatus string
+		wantError  string
+	}{
+		{name: "Succeeded", status: http.StatusOK, wantStatus: db.WebhookDeliverySucceeded},
+		{name: "Retry", status: http.StatusServiceUnavailable, wantStatus: db.WebhookDeliveryPending, wantError: "503"},
+		{name: "Final", status: http.StatusBadRequest, final: true, wantStatus: db.WebhookDeliveryFailed, wantError: "400"},
+		{name: "Redirect", status: http.StatusFound, wantStatus: db.WebhookDeliveryPending, wantError: "302"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			secret, err := NewSecret()
+			require.NoError(t, err)
+
+			delivery := db.WebhookDelivery{
+				ID:        42,
+				EventType: db.EventAccountCredited,
+				Payload:   []byte(`{"type":"AccountCredited"}`),
+				Status:    db.WebhookDeliveryPending,
+			}
+
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				require.Equal(t, delivery.Payload, body)
+				require.Equal(t, "42", r.Header.Get(DeliveryHeader))
+				require.Equal(t, delivery.EventType, r.Header.Get(EventHeader))
+
+				err = Verify(secret, r.Header.Get(SignatureHeader), r.Header.Get(TimestampHeader), body,
+					time.Now(), time.Minute)
+				require.NoError(t, err)
+
+				if tc.status == http.StatusFound {
+					http.Redirect(w, r, "/elsewhere", tc.status)
+					return
+				}
+
+				w.WriteHeader(tc.status)
+				_, _ = io.WriteString(w, "busy\n")
+			}))
+			defer receiver.Close()
+
+			store := mockdb.NewMockStore(gomock.NewController(t))
+			store.EXPECT().
+				RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.RecordWebhookDeliveryAttemptParams) (db.WebhookDelivery, error) {
+					require.Equal(t, delivery.ID, arg.ID)
+					require.Equal(t, int32(tc.status), arg.ResponseStatus)
+					require.True(t, strings.Contains(arg.LastError, tc.wantError))
+					require.NotContains(t, arg.LastError, "busy")
+
+					return db.WebhookDelivery{ID: arg.ID, Status: arg.Status, LastError: arg.LastError}, nil
+				})
+
+			dispatcher := NewDispatcher(store, time.Second, testGuard)
+			endpoint := db.WebhookEndpoint{ID: 1, Url: receiver.URL, Secret: secret}
+
+			updated, err := dispatcher.Deliver(context.Background(), endpoint, delivery, tc.final)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantStatus, updated.Status)
+		})
+	}
+}
+
+func TestDispatcherUnreachable(t *testing.T) {
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	url := receiver.URL
+	receiver.Close()
+
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	store.EXPECT().
+		RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.RecordWebhookDeliveryAttemptParams) (db.WebhookDelivery, error) {
+			require.Zero(t, arg.ResponseStatus)
+			require.NotEmpty(t, arg.LastError)
+
+			return db.WebhookDelivery{ID: arg.ID, Status: arg.Status}, nil
+		})
+
+	dispatcher := NewDispatcher(store, time.Second, testGuard)
+	updated, err := dispatcher.Deliver(context.Background(), db.WebhookEndpoint{Url: url}, db.WebhookDelivery{ID: 1}, false)
+	require.NoError(t, err)
+	require.Equal(t, db.WebhookDeliveryPending, updated.Status)
+}
+
+func TestDispatcherForbiddenAddress(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Error("delivery reached a loopback address")
+	}))
+	defer receiver.Close()
+
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	store.EXPECT().
+		RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.RecordWebhookDeliveryAttemptParams) (db.WebhookDelivery, error) {
+			require.Zero(t, arg.ResponseStatus)
+			require.Contains(t, arg.LastError, ErrForbiddenAddress.Error())
+
+			return db.WebhookDelivery{ID: arg.ID, Status: arg.Status}, nil
+		})
+
+	dispatcher := NewDispatcher(store, time.Second, NewGuard(nil))
+	updated, err := dispatcher.Deliver(context.Background(), db.WebhookEndpoint{Url: receiver.URL}, db.WebhookDelivery{ID: 1}, true)
+	require.NoError(t, err)
+	require.Equal(t, db.WebhookDeliveryFailed, updated.Status)
+}
@@ -0,0 +1,94 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// ErrForbiddenAddress is returned when a webhook URL points at, or resolves
+// to, an address that is not on the public internet.
+var ErrForbiddenAddress = errors.New("webhook URL must point to a public address")
+
+// Guard keeps webhooks away from loopback, private, link-local, unspecified
+// and multicast addresses, so endpoints cannot be used to reach services
+// inside the bank's network. Addresses in the allowed networks are let
+// through anyway.
+type Guard struct {
+	allowed []netip.Prefix
+}
+
+// NewGuard creates a guard that lets through addresses in allowed.
+func NewGuard(allowed []netip.Prefix) *Guard {
+	return &Guard{allowed: allowed}
+}
+
+// Allowed reports whether webhooks may be delivered to addr.
+func (g *Guard) Allowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	for _, network := range g.allowed {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+
+	return !addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified()
+}
+
+// CheckURL rejects a webhook URL whose host is a forbidden IP address or
+// localhost. Other host names are only checked once they are resolved, when
+// a delivery is made.
+func (g *Guard) CheckURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		host = "127.0.0.1"
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return nil
+	}
+
+	if !g.Allowed(addr) {
+		return ErrForbiddenAddress
+	}
+
+	return nil
+}
+
+// control is a net.Dialer Control func. It runs after the host name has been
+// resolved, right before connecting, so a name that resolves to a forbidden
+// address is caught however often its DNS answer changes.
+func (g *Guard) control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("cannot parse dial address %q: %w", address, err)
+	}
+
+	if !g.Allowed(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+
+	return nil
+}
+
+// dialer returns a dialer that refuses forbidden addresses.
+func (g *Guard) dialer() *net.Dialer {
+	return &net.Dialer{Control: g.control}
+}
@@ -0,0 +1,39 @@
+package webhook
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuardAllowed(t *testing.T) {
+	guard := NewGuard([]netip.Prefix{netip.MustParsePrefix("10.20.0.0/16")})
+
+	for _, addr := range []string{"93.184.215.14", "2606:2800:21f:cb07:6820:80da:af6b:8b2c", "10.20.1.1"} {
+		require.True(t, guard.Allowed(netip.MustParseAddr(addr)), addr)
+	}
+
+	for _, addr := range []string{
+		"127.0.0.1", "::1", "10.0.0.1", "172.16.5.4", "192.168.1.1", "fd00::1",
+		"169.254.169.254", "fe80::1", "0.0.0.0", "::", "224.0.0.1", "ff02::1",
+		"::ffff:127.0.0.1", "::ffff:169.254.169.254",
+	} {
+		require.False(t, guard.Allowed(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestGuardCheckURL(t *testing.T) {
+	guard := NewGuard(nil)
+
+	for _, url := range []string{"https://example.com/hooks", "http://93.184.215.14:8080/"} {
+		require.NoError(t, guard.CheckURL(url), url)
+	}
+
+	for _, url := range []string{
+		"http://127.0.0.1/", "http://[::1]:8080/", "http://169.254.169.254/latest/meta-data",
+		"http://192.168.0.10/", "http://localhost:6379/", "http://api.localhost./", "http://0.0.0.0/",
+	} {
+		require.ErrorIs(t, guard.CheckURL(url), ErrForbiddenAddress, url)
+	}
+}
@@ -0,0 +1,128 @@
+// Package webhook delivers account activity to endpoints registered by users.
+//
+// Every delivery is a POST of the event as JSON, signed with the endpoint's
+// secret: the SignatureHeader carries "v1=" followed by the hex HMAC-SHA256
+// of the TimestampHeader value, a dot and the body. Receivers should check
+// the signature with Verify and reject old timestamps to stop replays.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/outbox"
+	"github.com/shevgn/simplebank/token"
+)
+
+// Headers of every delivery.
+const (
+	SignatureHeader = "X-Simplebank-Signature"
+	TimestampHeader = "X-Simplebank-Timestamp"
+	EventHeader     = "X-Simplebank-Event"
+	DeliveryHeader  = "X-Simplebank-Delivery"
+)
+
+// signatureVersion prefixes signatures so the scheme can change later.
+const signatureVersion = "v1="
+
+// secretPrefix makes webhook secrets easy to recognise, e.g. in secret
+// scanners.
+const secretPrefix = "whsec_"
+
+// EventTest is the type of the event sent by a test delivery.
+const EventTest = "WebhookTest"
+
+// EventTypes are the events endpoints can subscribe to.
+var EventTypes = []string{
+	db.EventTransferCreated,
+	db.EventAccountDebited,
+	db.EventAccountCredited,
+}
+
+var (
+	// ErrInvalidSignature is returned by Verify when the signature does not
+	// match the body
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrExpiredTimestamp is returned by Verify when the delivery is older
+	// than the allowed tolerance
+	ErrExpiredTimestamp = errors.New("webhook timestamp is outside the tolerance")
+)
+
+// ValidEventType reports whether endpoints can subscribe to eventType.
+func ValidEventType(eventType string) bool {
+	return slices.Contains(EventTypes, eventType)
+}
+
+// NewSecret returns a random signing secret for a new endpoint.
+func NewSecret() (string, error) {
+	secret, err := token.NewOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	return secretPrefix + secret, nil
+}
+
+// Sign returns the signature header value for body sent at timestamp.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	return signatureVersion + hex.EncodeToString(mac(secret, strconv.FormatInt(timestamp.Unix(), 10), body))
+}
+
+// Verify checks the signature and timestamp header values of a delivery
+// received at now. Deliveries older than tolerance are rejected.
+func Verify(secret, signature, timestamp string, body []byte, now time.Time, tolerance time.Duration) error {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid webhook timestamp %q", timestamp)
+	}
+
+	if age := now.Sub(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return ErrExpiredTimestamp
+	}
+
+	hexMAC, ok := strings.CutPrefix(signature, signatureVersion)
+	if !ok {
+		return ErrInvalidSignature
+	}
+
+	got, err := hex.DecodeString(hexMAC)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if !hmac.Equal(got, mac(secret, timestamp, body)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func mac(secret, timestamp string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(timestamp))
+	h.Write([]byte("."))
+	h.Write(body)
+
+	return h.Sum(nil)
+}
+
+// NewTestPayload returns the body of a test delivery to endpoint. It has the
+// shape of a real event so receivers can exercise their parsing.
+func NewTestPayload(endpoint db.WebhookEndpoint, now time.Time) ([]byte, error) {
+	return json.Marshal(outbox.Event{
+		Type:          EventTest,
+		AggregateType: "webhook_endpoint",
+		AggregateID:   endpoint.ID,
+		Payload:       json.RawMessage(`{"message":"This is a test delivery."}`),
+		CreatedAt:     now,
+	})
+}
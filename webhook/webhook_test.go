@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(secret, secretPrefix))
+
+	now := time.Now()
+	body := []byte(`{"type":"TransferCreated"}`)
+	signature := Sign(secret, now, body)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	testCases := []struct {
+		name      string
+		secret    string
+		signature string
+		timestamp string
+		body      []byte
+		now       time.Time
+		wantErr   error
+	}{
+		{
+			name:      "OK",
+			secret:    secret,
+			signature: signature,
+			timestamp: timestamp,
+			body:      body,
+			now:       now.Add(time.Minute),
+		},
+		{
+			name:      "TamperedBody",
+			secret:    secret,
+			signature: signature,
+			timestamp: timestamp,
+			body:      []byte(`{"type":"TransferCreated","amount":1}`),
+			now:       now,
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:      "WrongSecret",
+			secret:    secret + "x",
+			signature: signature,
+			timestamp: timestamp,
+			body:      body,
+			now:       now,
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:      "ReplayedTimestamp",
+			secret:    secret,
+			signature: Sign(secret, now.Add(-time.Hour), body),
+			timestamp: timestamp,
+			body:      body,
+			now:       now,
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:      "UnknownVersion",
+			secret:    secret,
+			signature: "v2=" + strings.TrimPrefix(signature, signatureVersion),
+			timestamp: timestamp,
+			body:      body,
+			now:       now,
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:      "Expired",
+			secret:    secret,
+			signature: signature,
+			timestamp: timestamp,
+			body:      body,
+			now:       now.Add(10 * time.Minute),
+			wantErr:   ErrExpiredTimestamp,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Verify(tc.secret, tc.signature, tc.timestamp, tc.body, tc.now, 5*time.Minute)
+			require.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestValidEventType(t *testing.T) {
+	require.True(t, ValidEventType(db.EventTransferCreated))
+	require.False(t, ValidEventType(EventTest))
+	require.False(t, ValidEventType(""))
+}
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/shevgn/simplebank/worker (interfaces: Distributor)
+//
+// Generated by this command:
+//
+//	mockgen -package mockworker -destination worker/mock/distributor.go github.com/shevgn/simplebank/worker Distributor
+//
+
+// Package mockworker is a generated GoMock package.
+package mockworker
+
+import (
+	context "context"
+	reflect "reflect"
+
+	worker "github.com/shevgn/simplebank/worker"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDistributor is a mock of Distributor interface.
+type MockDistributor struct {
+	ctrl     *gomock.Controller
+	recorder *MockDistributorMockRecorder
+	isgomock struct{}
+}
+
+// MockDistributorMockRecorder is the mock recorder for MockDistributor.
+type MockDistributorMockRecorder struct {
+	mock *MockDistributor
+}
+
+// NewMockDistributor creates a new mock instance.
+func NewMockDistributor(ctrl *gomock.Controller) *MockDistributor {
+	mock := &MockDistributor{ctrl: ctrl}
+	mock.recorder = &MockDistributorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDistributor) EXPECT() *MockDistributorMockRecorder {
+	return m.recorder
+}
+
+// Enqueue mocks base method.
+func (m *MockDistributor) Enqueue(ctx context.Context, task worker.Task) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", ctx, task)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockDistributorMockRecorder) Enqueue(ctx, task any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockDistributor)(nil).Enqueue), ctx, task)
+}
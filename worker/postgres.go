@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/shevgn/simplebank/db/sqlc"
+)
+
+// Store is the subset of db.Store used by the queue and the processor.
+type Store interface {
+	EnqueueJob(ctx context.Context, arg db.EnqueueJobParams) (db.Job, error)
+	ClaimJobs(ctx context.Context, maxJobs int32) ([]db.Job, error)
+	CompleteJob(ctx context.Context, id int64) error
+	RetryJob(ctx context.Context, arg db.RetryJobParams) error
+	KillJob(ctx context.Context, arg db.KillJobParams) error
+	RequeueStuckJobs(ctx context.Context, lockedBefore pgtype.Timestamptz) (int64, error)
+	DeleteDoneJobs(ctx context.Context, updatedBefore pgtype.Timestamptz) (int64, error)
+}
+
+// PostgresQueue enqueues tasks into the jobs table.
+type PostgresQueue struct {
+	store       Store
+	maxAttempts int
+}
+
+// NewPostgresQueue creates a queue backed by store. Tasks that do not set
+// MaxAttempts are attempted up to maxAttempts times.
+func NewPostgresQueue(store Store, maxAttempts int) *PostgresQueue {
+	return &PostgresQueue{store: store, maxAttempts: maxAttempts}
+}
+
+// JobParams returns the row that enqueues task, for callers that insert it
+// in their own transaction.
+func (q *PostgresQueue) JobParams(task Task) db.EnqueueJobParams {
+	maxAttempts := task.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = q.maxAttempts
+	}
+
+	runAt := task.RunAt
+	if runAt.IsZero() {
+		runAt = time.Now()
+	}
+
+	return db.EnqueueJobParams{
+		Type:        task.Type,
+		Payload:     task.Payload,
+		MaxAttempts: int32(maxAttempts),
+		RunAt:       pgtype.Timestamptz{Time: runAt, Valid: true},
+		Key:         task.Key,
+	}
+}
+
+// Enqueue implements Distributor. A task whose key is taken is dropped.
+func (q *PostgresQueue) Enqueue(ctx context.Context, task Task) error {
+	job, err := q.store.EnqueueJob(ctx, q.JobParams(task))
+	if task.Key != "" && errors.Is(err, db.ErrRecordNotFound) {
+		slog.DebugContext(ctx, "Task already enqueued", slog.String("task", task.Type), slog.String("key", task.Key))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	slog.DebugContext(ctx, "Task enqueued", slog.String("task", task.Type), slog.Int64("job_id", job.ID))
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPostgresQueueEnqueue(t *testing.T) {
+	runAt := time.Now().Add(time.Hour)
+
+	testCases := []struct {
+		name            string
+		task            Task
+		wantMaxAttempts int32
+		wantRunAt       func(t *testing.T, got time.Time)
+	}{
+		{
+			name:            "Defaults",
+			task:            Task{Type: "test", Payload: []byte(`{}`)},
+			wantMaxAttempts: 5,
+			wantRunAt: func(t *testing.T, got time.Time) {
+				require.WithinDuration(t, time.Now(), got, time.Second)
+			},
+		},
+		{
+			name:            "Overrides",
+			task:            Task{Type: "test", Payload: []byte(`{}`), RunAt: runAt, MaxAttempts: 1},
+			wantMaxAttempts: 1,
+			wantRunAt: func(t *testing.T, got time.Time) {
+				require.Equal(t, runAt, got)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			store.EXPECT().
+				EnqueueJob(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.EnqueueJobParams) (db.Job, error) {
+					require.Equal(t, tc.task.Type, arg.Type)
+					require.Equal(t, tc.task.Payload, arg.Payload)
+					require.Equal(t, tc.wantMaxAttempts, arg.MaxAttempts)
+					tc.wantRunAt(t, arg.RunAt.Time)
+
+					return db.Job{ID: 1}, nil
+				})
+
+			queue := NewPostgresQueue(store, 5)
+			require.NoError(t, queue.Enqueue(context.Background(), tc.task))
+		})
+	}
+}
+
+func TestPostgresQueueEnqueueKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	// A taken key returns no row.
+	gomock.InOrder(
+		store.EXPECT().
+			EnqueueJob(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, arg db.EnqueueJobParams) (db.Job, error) {
+				require.Equal(t, "test:2026-10-17", arg.Key)
+				return db.Job{}, db.ErrRecordNotFound
+			}),
+		store.EXPECT().
+			EnqueueJob(gomock.Any(), gomock.Any()).
+			Return(db.Job{}, db.ErrRecordNotFound),
+	)
+
+	queue := NewPostgresQueue(store, 5)
+	require.NoError(t, queue.Enqueue(context.Background(), Task{Type: "test", Key: "test:2026-10-17"}))
+	require.ErrorIs(t, queue.Enqueue(context.Background(), Task{Type: "test"}), db.ErrRecordNotFound)
+}
@@ -0,0 +1,294 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/metrics"
+)
+
+const (
+	// taskTimeout bounds the run time of a single task
+	taskTimeout = 30 * time.Second
+	// storeTimeout bounds the queries that record the outcome of a task
+	storeTimeout = 10 * time.Second
+	// stuckAfter is how long a job may stay running before it is assumed
+	// that its processor died and the job is requeued. It must be well
+	// above taskTimeout.
+	stuckAfter = 5 * time.Minute
+	// doneRetention is how long finished jobs are kept for inspection
+	doneRetention = 7 * 24 * time.Hour
+	// sweepInterval is how often stuck and old jobs are cleaned up
+	sweepInterval = time.Minute
+)
+
+// JobInfo describes the job a handler is running.
+type JobInfo struct {
+	ID          int64
+	Attempt     int
+	MaxAttempts int
+}
+
+// LastAttempt reports whether the job is marked dead if this attempt fails.
+func (j JobInfo) LastAttempt() bool {
+	return j.Attempt >= j.MaxAttempts
+}
+
+type jobInfoKey struct{}
+
+// JobFromContext returns the job run by the handler that got ctx.
+func JobFromContext(ctx context.Context) (JobInfo, bool) {
+	info, ok := ctx.Value(jobInfoKey{}).(JobInfo)
+	return info, ok
+}
+
+// ProcessorConfig tunes a Processor.
+type ProcessorConfig struct {
+	// Concurrency is the maximum number of tasks run at the same time.
+	Concurrency int
+	// PollInterval is how long to wait before polling an empty queue again.
+	PollInterval time.Duration
+	// RetryBaseDelay is the delay before the first retry; it doubles with
+	// every further attempt up to RetryMaxDelay.
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// Schedule, if set, enqueues the periodic tasks due at now. It runs at
+	// every sweep, so the tasks it enqueues must be keyed.
+	Schedule func(ctx context.Context, now time.Time) error
+}
+
+// Processor claims due jobs from the jobs table and runs their handlers.
+// Any number of processors may share a table: each job is claimed by one
+// of them with FOR UPDATE SKIP LOCKED.
+type Processor struct {
+	store    Store
+	handlers Handlers
+	config   ProcessorConfig
+
+	// slots holds a token for every running task
+	slots   chan struct{}
+	running sync.WaitGroup
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewProcessor creates a processor that runs jobs with handlers.
+func NewProcessor(store Store, handlers Handlers, config ProcessorConfig) *Processor {
+	return &Processor{
+		store:    store,
+		handlers: handlers,
+		config:   config,
+		slots:    make(chan struct{}, config.Concurrency),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Run processes jobs until Shutdown is called.
+func (p *Processor) Run() {
+	defer close(p.done)
+
+	var lastSweep time.Time
+
+	for {
+		if time.Since(lastSweep) >= sweepInterval {
+			p.sweep()
+			lastSweep = time.Now()
+		}
+
+		claimed := p.claim()
+
+		// Poll again right away while the queue keeps filling every free
+		// slot, otherwise wait for a slot or the next poll.
+		wait := p.config.PollInterval
+		if claimed > 0 && claimed == cap(p.slots) {
+			wait = 0
+		}
+
+		select {
+		case <-p.stop:
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// claim starts a task for every free slot that has a due job, and returns
+// how many were started.
+func (p *Processor) claim() int {
+	free := cap(p.slots) - len(p.slots)
+	if free == 0 {
+		return 0
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	jobs, err := p.store.ClaimJobs(ctx, int32(free))
+	if err != nil {
+		slog.Error("Cannot claim jobs", slog.Any("error", err))
+		return 0
+	}
+
+	for _, job := range jobs {
+		p.slots <- struct{}{}
+		p.running.Add(1)
+
+		go func() {
+			defer func() {
+				<-p.slots
+				p.running.Done()
+			}()
+
+			p.process(job)
+		}()
+	}
+
+	return len(jobs)
+}
+
+// process runs a claimed job and records its outcome.
+func (p *Processor) process(job db.Job) {
+	logger := slog.With(
+		slog.String("task", job.Type),
+		slog.Int64("job_id", job.ID),
+		slog.Int("attempt", int(job.Attempts)),
+	)
+
+	start := time.Now()
+
+	var err error
+	if handler, ok := p.handlers[job.Type]; ok {
+		ctx, cancel := context.WithTimeout(context.Background(), taskTimeout)
+		ctx = context.WithValue(ctx, jobInfoKey{}, JobInfo{
+			ID:          job.ID,
+			Attempt:     int(job.Attempts),
+			MaxAttempts: int(job.MaxAttempts),
+		})
+		err = runHandler(ctx, handler, job.Payload)
+		cancel()
+	} else {
+		err = Permanent(errors.New("no handler for task"))
+	}
+
+	duration := time.Since(start)
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	var result string
+
+	switch {
+	case err == nil:
+		result = metrics.JobResultDone
+		err = p.store.CompleteJob(ctx, job.ID)
+		logger.Info("Task processed", slog.Duration("duration", duration))
+	case IsPermanent(err) || job.Attempts >= job.MaxAttempts:
+		result = metrics.JobResultDead
+		logger.Error("Task failed, giving up", slog.Any("error", err))
+		err = p.store.KillJob(ctx, db.KillJobParams{ID: job.ID, LastError: err.Error()})
+	default:
+		result = metrics.JobResultRetry
+		delay := p.backoff(int(job.Attempts))
+		logger.Warn("Task failed, will retry", slog.Any("error", err), slog.Duration("retry_in", delay))
+		err = p.store.RetryJob(ctx, db.RetryJobParams{
+			ID:        job.ID,
+			LastError: err.Error(),
+			RunAt:     pgtype.Timestamptz{Time: time.Now().Add(delay), Valid: true},
+		})
+	}
+
+	metrics.ObserveJob(job.Type, result, duration)
+
+	// The job stays running and is requeued by the sweep once it is stuck.
+	if err != nil {
+		logger.Error("Cannot record task outcome", slog.Any("error", err))
+	}
+}
+
+// backoff returns the delay before retrying a job that failed its attempt-th
+// attempt: RetryBaseDelay doubled for every earlier attempt, capped at
+// RetryMaxDelay, with up to half of it taken off at random so jobs that
+// failed together do not retry together.
+func (p *Processor) backoff(attempt int) time.Duration {
+	delay := p.config.RetryMaxDelay
+	if shift := attempt - 1; shift < 32 {
+		if d := p.config.RetryBaseDelay << shift; d > 0 && d < delay {
+			delay = d
+		}
+	}
+
+	return delay - rand.N(delay/2+1)
+}
+
+// sweep requeues jobs left running by a processor that died, deletes old
+// finished jobs and enqueues the periodic tasks that are due.
+func (p *Processor) sweep() {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	now := time.Now()
+
+	requeued, err := p.store.RequeueStuckJobs(ctx, pgtype.Timestamptz{Time: now.Add(-stuckAfter), Valid: true})
+	if err != nil {
+		slog.Error("Cannot requeue stuck jobs", slog.Any("error", err))
+	} else if requeued > 0 {
+		slog.Warn("Requeued stuck jobs", slog.Int64("count", requeued))
+	}
+
+	deleted, err := p.store.DeleteDoneJobs(ctx, pgtype.Timestamptz{Time: now.Add(-doneRetention), Valid: true})
+	if err != nil {
+		slog.Error("Cannot delete finished jobs", slog.Any("error", err))
+	} else if deleted > 0 {
+		slog.Debug("Deleted finished jobs", slog.Int64("count", deleted))
+	}
+
+	if p.config.Schedule != nil {
+		if err := p.config.Schedule(ctx, now); err != nil {
+			slog.Error("Cannot enqueue scheduled tasks", slog.Any("error", err))
+		}
+	}
+}
+
+// runHandler turns a panic in handler into an error, so one bad task does
+// not stop the processor.
+func runHandler(ctx context.Context, handler HandlerFunc, payload []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return handler(ctx, payload)
+}
+
+// Shutdown stops claiming jobs and waits for running tasks to finish or for
+// ctx to expire, whichever comes first. Tasks still running when ctx
+// expires are requeued by a later sweep. Run must have been started.
+func (p *Processor) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() { close(p.stop) })
+
+	finished := make(chan struct{})
+	go func() {
+		<-p.done
+		p.running.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		slog.Warn("Timed out waiting for running tasks", slog.Int("running", len(p.slots)))
+		return ctx.Err()
+	}
+}
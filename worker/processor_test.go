@@ -0,0 +1,190 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var testProcessorConfig = ProcessorConfig{
+	Concurrency:    2,
+	PollInterval:   10 * time.Millisecond,
+	RetryBaseDelay: time.Second,
+	RetryMaxDelay:  time.Minute,
+}
+
+func TestProcessorProcess(t *testing.T) {
+	handlers := Handlers{
+		"ok": func(context.Context, []byte) error {
+			return nil
+		},
+		"fail": func(context.Context, []byte) error {
+			return errors.New("failed")
+		},
+		"permanent": func(context.Context, []byte) error {
+			return Permanent(errors.New("bad payload"))
+		},
+		"panic": func(context.Context, []byte) error {
+			panic("boom")
+		},
+	}
+
+	testCases := []struct {
+		name       string
+		job        db.Job
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name: "Done",
+			job:  db.Job{ID: 1, Type: "ok", Attempts: 1, MaxAttempts: 3},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CompleteJob(gomock.Any(), gomock.Eq(int64(1))).Times(1)
+			},
+		},
+		{
+			name: "Retry",
+			job:  db.Job{ID: 1, Type: "fail", Attempts: 2, MaxAttempts: 3},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RetryJob(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.RetryJobParams) error {
+						require.Equal(t, int64(1), arg.ID)
+						require.Equal(t, "failed", arg.LastError)
+						// Second attempt: between half of and twice the base delay.
+						require.WithinRange(t, arg.RunAt.Time,
+							time.Now().Add(time.Second-100*time.Millisecond),
+							time.Now().Add(2*time.Second))
+
+						return nil
+					})
+			},
+		},
+		{
+			name: "RetryPanic",
+			job:  db.Job{ID: 1, Type: "panic", Attempts: 1, MaxAttempts: 3},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RetryJob(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.RetryJobParams) error {
+						require.Equal(t, "panic: boom", arg.LastError)
+						return nil
+					})
+			},
+		},
+		{
+			name: "DeadOutOfAttempts",
+			job:  db.Job{ID: 1, Type: "fail", Attempts: 3, MaxAttempts: 3},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					KillJob(gomock.Any(), gomock.Eq(db.KillJobParams{ID: 1, LastError: "failed"})).
+					Times(1)
+			},
+		},
+		{
+			name: "DeadPermanent",
+			job:  db.Job{ID: 1, Type: "permanent", Attempts: 1, MaxAttempts: 3},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					KillJob(gomock.Any(), gomock.Eq(db.KillJobParams{ID: 1, LastError: "bad payload"})).
+					Times(1)
+			},
+		},
+		{
+			name: "DeadUnknownType",
+			job:  db.Job{ID: 1, Type: "unknown", Attempts: 1, MaxAttempts: 3},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					KillJob(gomock.Any(), gomock.Eq(db.KillJobParams{ID: 1, LastError: "no handler for task"})).
+					Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			NewProcessor(store, handlers, testProcessorConfig).process(tc.job)
+		})
+	}
+}
+
+func TestProcessorBackoff(t *testing.T) {
+	processor := NewProcessor(nil, nil, testProcessorConfig)
+
+	for _, tc := range []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{attempt: 1, max: time.Second},
+		{attempt: 2, max: 2 * time.Second},
+		{attempt: 4, max: 8 * time.Second},
+		{attempt: 7, max: time.Minute},
+		{attempt: 100, max: time.Minute},
+	} {
+		for range 20 {
+			delay := processor.backoff(tc.attempt)
+			require.LessOrEqual(t, delay, tc.max)
+			require.GreaterOrEqual(t, delay, tc.max/2)
+		}
+	}
+}
+
+func TestProcessorRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	processed := make(chan string, 3)
+	handlers := Handlers{
+		"record": func(_ context.Context, payload []byte) error {
+			processed <- string(payload)
+			return nil
+		},
+	}
+
+	store.EXPECT().RequeueStuckJobs(gomock.Any(), gomock.Any()).Times(1)
+	store.EXPECT().DeleteDoneJobs(gomock.Any(), gomock.Any()).Times(1)
+
+	// The first poll fills both slots, so the next poll follows right away
+	// and claims the remaining job.
+	gomock.InOrder(
+		store.EXPECT().ClaimJobs(gomock.Any(), gomock.Eq(int32(2))).Times(1).Return([]db.Job{
+			{ID: 1, Type: "record", Payload: []byte("1"), Attempts: 1, MaxAttempts: 1},
+			{ID: 2, Type: "record", Payload: []byte("2"), Attempts: 1, MaxAttempts: 1},
+		}, nil),
+		store.EXPECT().ClaimJobs(gomock.Any(), gomock.Any()).Times(1).Return([]db.Job{
+			{ID: 3, Type: "record", Payload: []byte("3"), Attempts: 1, MaxAttempts: 1},
+		}, nil),
+		store.EXPECT().ClaimJobs(gomock.Any(), gomock.Any()).AnyTimes().Return(nil, nil),
+	)
+	store.EXPECT().CompleteJob(gomock.Any(), gomock.Any()).Times(3)
+
+	processor := NewProcessor(store, handlers, testProcessorConfig)
+	go processor.Run()
+
+	var got []string
+	for range 3 {
+		select {
+		case payload := <-processed:
+			got = append(got, payload)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for jobs")
+		}
+	}
+	require.ElementsMatch(t, []string{"1", "2", "3"}, got)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.NoError(t, processor.Shutdown(ctx))
+}
@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shevgn/simplebank/util"
+)
+
+// postInterestDelay holds back the month's interest posting after the month
+// ends, so the accrual for its last day runs first.
+const postInterestDelay = time.Hour
+
+// ScheduledTasks returns the periodic tasks due at now: the interest accrual
+// for the previous UTC day, and the interest posting and maintenance fees
+// for the previous UTC month. Every task is keyed by its period, so returning it again, at a
+// later call or on another replica, enqueues nothing while its job is kept.
+// Finished jobs are kept for doneRetention, so monthly tasks are only
+// returned in the month's first days; after that the month would be run
+// again.
+func ScheduledTasks(now time.Time) ([]Task, error) {
+	today := util.StartOfDay(now)
+	month := util.StartOfMonth(now)
+
+	accrue, err := NewAccrueInterestTask(today.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []Task{accrue}
+	if now.Sub(month) >= doneRetention {
+		return tasks, nil
+	}
+
+	post, err := NewPostInterestTask(month.AddDate(0, -1, 0))
+	if err != nil {
+		return nil, err
+	}
+	post.RunAt = month.Add(postInterestDelay)
+
+	charge, err := NewChargeMaintenanceFeesTask(month.AddDate(0, -1, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(tasks, post, charge), nil
+}
+
+// Schedule enqueues the tasks ScheduledTasks returns for now. It is run by
+// the processor at every sweep.
+func Schedule(ctx context.Context, distributor Distributor, now time.Time) error {
+	tasks, err := ScheduledTasks(now)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, task := range tasks {
+		if err := distributor.Enqueue(ctx, task); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func taskKeys(tasks []Task) []string {
+	keys := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		keys = append(keys, task.Key)
+	}
+
+	return keys
+}
+
+func TestScheduledTasks(t *testing.T) {
+	// Early in the month the previous month is posted, an hour after it
+	// ended, and charged maintenance fees.
+	tasks, err := ScheduledTasks(time.Date(2026, 10, 1, 0, 5, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"accrue_interest:2026-09-30",
+		"post_interest:2026-09",
+		"charge_maintenance_fees:2026-09",
+	}, taskKeys(tasks))
+	require.Equal(t, time.Date(2026, 10, 1, 1, 0, 0, 0, time.UTC), tasks[1].RunAt)
+
+	// Later on, only the previous day is accrued.
+	tasks, err = ScheduledTasks(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, []string{"accrue_interest:2026-10-17"}, taskKeys(tasks))
+	require.Zero(t, tasks[0].RunAt)
+
+	// Days and months are UTC.
+	tasks, err = ScheduledTasks(time.Date(2026, 11, 1, 0, 30, 0, 0, time.FixedZone("CET", 3600)))
+	require.NoError(t, err)
+	require.Equal(t, []string{"accrue_interest:2026-10-30"}, taskKeys(tasks))
+}
+
+func TestSchedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	now := time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)
+
+	var keys []string
+
+	store.EXPECT().
+		EnqueueJob(gomock.Any(), gomock.Any()).
+		Times(3).
+		DoAndReturn(func(_ context.Context, arg db.EnqueueJobParams) (db.Job, error) {
+			keys = append(keys, arg.Key)
+			if len(keys) == 1 {
+				return db.Job{}, errors.New("connection reset")
+			}
+
+			return db.Job{ID: 1}, nil
+		})
+
+	// A task that cannot be enqueued does not keep the others from it.
+	err := Schedule(context.Background(), NewPostgresQueue(store, 5), now)
+	require.ErrorContains(t, err, "connection reset")
+	require.Equal(t, []string{"accrue_interest:2026-10-01", "post_interest:2026-09", "charge_maintenance_fees:2026-09"}, keys)
+}
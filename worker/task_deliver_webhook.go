@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	db "github.com/shevgn/simplebank/db/sqlc"
+)
+
+// TaskDeliverWebhook sends a webhook delivery to its endpoint.
+const TaskDeliverWebhook = "deliver_webhook"
+
+// PayloadDeliverWebhook is the payload of TaskDeliverWebhook.
+type PayloadDeliverWebhook struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+// NewDeliverWebhookTask creates a TaskDeliverWebhook for a delivery that is
+// attempted up to maxAttempts times.
+func NewDeliverWebhookTask(deliveryID int64, maxAttempts int) (Task, error) {
+	task, err := NewTask(TaskDeliverWebhook, PayloadDeliverWebhook{DeliveryID: deliveryID})
+	task.MaxAttempts = maxAttempts
+
+	return task, err
+}
+
+// DeliverWebhook sends a pending delivery. A failed attempt is retried by the
+// processor with backoff; the delivery is marked failed on the last one.
+// Deliveries of deleted endpoints are dropped.
+func (h *TaskHandlers) DeliverWebhook(ctx context.Context, p PayloadDeliverWebhook) error {
+	delivery, err := h.store.GetWebhookDelivery(ctx, p.DeliveryID)
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return Permanent(fmt.Errorf("webhook delivery %d not found", p.DeliveryID))
+	}
+	if err != nil {
+		return fmt.Errorf("cannot get webhook delivery: %w", err)
+	}
+
+	if delivery.Status != db.WebhookDeliveryPending {
+		return nil
+	}
+
+	endpoint, err := h.store.GetWebhookEndpoint(ctx, delivery.EndpointID)
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return Permanent(fmt.Errorf("webhook endpoint %d not found", delivery.EndpointID))
+	}
+	if err != nil {
+		return fmt.Errorf("cannot get webhook endpoint: %w", err)
+	}
+
+	job, _ := JobFromContext(ctx)
+
+	delivery, err = h.webhooks.Deliver(ctx, endpoint, delivery, job.LastAttempt())
+	if err != nil {
+		return err
+	}
+
+	if delivery.Status != db.WebhookDeliverySucceeded {
+		return errors.New(delivery.LastError)
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package worker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDeliverWebhook(t *testing.T) {
+	config := &util.Config{WebhookTimeout: time.Second, WebhookAllowedNetworks: "127.0.0.1"}
+
+	task, err := NewDeliverWebhookTask(5, 3)
+	require.NoError(t, err)
+	require.Equal(t, TaskDeliverWebhook, task.Type)
+	require.Equal(t, 3, task.MaxAttempts)
+
+	delivery := db.WebhookDelivery{
+		ID:         5,
+		EndpointID: 2,
+		EventType:  db.EventAccountDebited,
+		Payload:    []byte(`{}`),
+		Status:     db.WebhookDeliveryPending,
+	}
+
+	testCases := []struct {
+		name          string
+		status        int
+		attempt       int
+		wantStatus    string
+		wantErr       bool
+		wantPermanent bool
+	}{
+		{name: "OK", status: http.StatusOK, attempt: 1, wantStatus: db.WebhookDeliverySucceeded},
+		{name: "Retry", status: http.StatusBadGateway, attempt: 1, wantStatus: db.WebhookDeliveryPending, wantErr: true},
+		{name: "LastAttempt", status: http.StatusBadGateway, attempt: 3, wantStatus: db.WebhookDeliveryFailed, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tc.status)
+			}))
+			defer receiver.Close()
+
+			store := mockdb.NewMockStore(gomock.NewController(t))
+			store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(delivery, nil)
+			store.EXPECT().
+				GetWebhookEndpoint(gomock.Any(), gomock.Eq(delivery.EndpointID)).
+				Times(1).
+				Return(db.WebhookEndpoint{ID: delivery.EndpointID, Url: receiver.URL, Secret: "whsec_test"}, nil)
+			store.EXPECT().
+				RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.RecordWebhookDeliveryAttemptParams) (db.WebhookDelivery, error) {
+					require.Equal(t, tc.wantStatus, arg.Status)
+
+					return db.WebhookDelivery{ID: arg.ID, Status: arg.Status, LastError: arg.LastError}, nil
+				})
+
+			ctx := context.WithValue(context.Background(), jobInfoKey{}, JobInfo{ID: 1, Attempt: tc.attempt, MaxAttempts: 3})
+
+			handlers := NewTaskHandlers(config, store, nil).Handlers()
+			err := handlers[TaskDeliverWebhook](ctx, task.Payload)
+			if tc.wantErr {
+				require.Error(t, err)
+				require.False(t, IsPermanent(err))
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("AlreadyDelivered", func(t *testing.T) {
+		succeeded := delivery
+		succeeded.Status = db.WebhookDeliverySucceeded
+
+		store := mockdb.NewMockStore(gomock.NewController(t))
+		store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(succeeded, nil)
+		store.EXPECT().GetWebhookEndpoint(gomock.Any(), gomock.Any()).Times(0)
+
+		handlers := NewTaskHandlers(config, store, nil).Handlers()
+		require.NoError(t, handlers[TaskDeliverWebhook](context.Background(), task.Payload))
+	})
+
+	t.Run("EndpointDeleted", func(t *testing.T) {
+		store := mockdb.NewMockStore(gomock.NewController(t))
+		store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(delivery, nil)
+		store.EXPECT().
+			GetWebhookEndpoint(gomock.Any(), gomock.Eq(delivery.EndpointID)).
+			Times(1).
+			Return(db.WebhookEndpoint{}, db.ErrRecordNotFound)
+
+		handlers := NewTaskHandlers(config, store, nil).Handlers()
+		err := handlers[TaskDeliverWebhook](context.Background(), task.Payload)
+		require.True(t, IsPermanent(err))
+	})
+}
@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/shevgn/simplebank/interest"
+)
+
+const (
+	// TaskAccrueInterest records a day of interest on savings products.
+	TaskAccrueInterest = "accrue_interest"
+	// TaskPostInterest pays the interest accrued up to the end of a month.
+	TaskPostInterest = "post_interest"
+)
+
+// PayloadAccrueInterest is the payload of TaskAccrueInterest.
+type PayloadAccrueInterest struct {
+	// Date is the UTC day to accrue, at midnight.
+	Date time.Time `json:"date"`
+}
+
+// PayloadPostInterest is the payload of TaskPostInterest.
+type PayloadPostInterest struct {
+	// Month is the first day of the UTC month to post, at midnight.
+	Month time.Time `json:"month"`
+}
+
+// NewAccrueInterestTask creates a TaskAccrueInterest for the UTC day of
+// date. It is keyed by the day, so the day is queued once.
+func NewAccrueInterestTask(date time.Time) (Task, error) {
+	date = interest.Day(date)
+
+	task, err := NewTask(TaskAccrueInterest, PayloadAccrueInterest{Date: date})
+	task.Key = TaskAccrueInterest + ":" + date.Format(time.DateOnly)
+
+	return task, err
+}
+
+// NewPostInterestTask creates a TaskPostInterest for the UTC month of
+// month. It is keyed by the month, so the month is queued once.
+func NewPostInterestTask(month time.Time) (Task, error) {
+	month = interest.Month(month)
+
+	task, err := NewTask(TaskPostInterest, PayloadPostInterest{Month: month})
+	task.Key = TaskPostInterest + ":" + month.Format("2006-01")
+
+	return task, err
+}
+
+// AccrueInterest records the day's interest at the rates in INTEREST_RATES.
+// Accounts that already accrued for the day are skipped, so a retry picks
+// up where a failed attempt stopped.
+func (h *TaskHandlers) AccrueInterest(ctx context.Context, p PayloadAccrueInterest) error {
+	rates, err := interest.ParseRates(h.config.InterestRates)
+	if err != nil {
+		return Permanent(fmt.Errorf("invalid INTEREST_RATES: %w", err))
+	}
+
+	accrual, err := interest.Accrue(ctx, h.store, rates, p.Date)
+	if err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Interest accrued",
+		slog.String("date", accrual.Date.Format(time.DateOnly)),
+		slog.Int("accounts", accrual.Accounts),
+		slog.Int("created", accrual.Created))
+
+	return nil
+}
+
+// PostInterest pays the month's interest. Accounts that cannot be paid fail
+// the task, and the retry pays only them: accounts already paid for the
+// month are skipped.
+func (h *TaskHandlers) PostInterest(ctx context.Context, p PayloadPostInterest) error {
+	posting, err := interest.Post(ctx, h.store, p.Month)
+	if err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Interest posted",
+		slog.String("month", posting.Month.Format("2006-01")),
+		slog.Int("posted", posting.Posted),
+		slog.Int("skipped", posting.Skipped),
+		slog.Int("failed", len(posting.Failures)))
+
+	if len(posting.Failures) > 0 {
+		first := posting.Failures[0]
+		return fmt.Errorf("cannot post interest to %d accounts, first account %d: %s",
+			len(posting.Failures), first.AccountID, first.Error)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAccrueInterest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	day := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+	task, err := NewAccrueInterestTask(day.Add(13 * time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, TaskAccrueInterest, task.Type)
+	require.Equal(t, "accrue_interest:2026-10-17", task.Key)
+
+	store.EXPECT().
+		ListInterestBearingAccounts(gomock.Any(), gomock.Eq(db.ListInterestBearingAccountsParams{
+			DayEnd: pgtype.Timestamptz{Time: day.AddDate(0, 0, 1), Valid: true},
+			Types:  []string{db.AccountTypeSavings},
+		})).
+		Times(1).
+		Return([]db.ListInterestBearingAccountsRow{{ID: 1, Type: db.AccountTypeSavings, Balance: 100000}}, nil)
+	store.EXPECT().
+		CreateInterestAccrual(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(int64(1), nil)
+
+	handlers := NewTaskHandlers(&util.Config{InterestRates: "savings=0.035"}, store, nil).Handlers()
+	require.NoError(t, handlers[TaskAccrueInterest](context.Background(), task.Payload))
+
+	// Rates that do not parse will not parse on a retry either.
+	handlers = NewTaskHandlers(&util.Config{InterestRates: "savings=high"}, store, nil).Handlers()
+	err = handlers[TaskAccrueInterest](context.Background(), task.Payload)
+	require.True(t, IsPermanent(err))
+}
+
+func TestPostInterest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	month := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	task, err := NewPostInterestTask(month.AddDate(0, 0, 20))
+	require.NoError(t, err)
+	require.Equal(t, TaskPostInterest, task.Type)
+	require.Equal(t, "post_interest:2026-09", task.Key)
+
+	gomock.InOrder(
+		store.EXPECT().
+			ListAccountsWithUnpostedInterest(gomock.Any(), gomock.Eq(pgtype.Date{Time: month.AddDate(0, 1, 0), Valid: true})).
+			Return([]int64{1, 2}, nil),
+		store.EXPECT().
+			PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{AccountID: 1, Month: month})).
+			Return(db.InterestPosting{ID: 1}, nil),
+		store.EXPECT().
+			PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{AccountID: 2, Month: month})).
+			Return(db.InterestPosting{}, db.ErrAccountFrozen),
+		// The retry only has account 2 left.
+		store.EXPECT().
+			ListAccountsWithUnpostedInterest(gomock.Any(), gomock.Any()).
+			Return([]int64{2}, nil),
+		store.EXPECT().
+			PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{AccountID: 2, Month: month})).
+			Return(db.InterestPosting{ID: 2}, nil),
+	)
+
+	handlers := NewTaskHandlers(&util.Config{}, store, nil).Handlers()
+
+	err = handlers[TaskPostInterest](context.Background(), task.Payload)
+	require.ErrorContains(t, err, "first account 2")
+	require.False(t, IsPermanent(err))
+
+	require.NoError(t, handlers[TaskPostInterest](context.Background(), task.Payload))
+}
@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/shevgn/simplebank/fees"
+	"github.com/shevgn/simplebank/util"
+)
+
+// TaskChargeMaintenanceFees charges the maintenance fees of a month.
+const TaskChargeMaintenanceFees = "charge_maintenance_fees"
+
+// PayloadChargeMaintenanceFees is the payload of TaskChargeMaintenanceFees.
+type PayloadChargeMaintenanceFees struct {
+	// Month is the first day of the UTC month to charge, at midnight.
+	Month time.Time `json:"month"`
+}
+
+// NewChargeMaintenanceFeesTask creates a TaskChargeMaintenanceFees for the
+// UTC month of month. It is keyed by the month, so the month is queued once.
+func NewChargeMaintenanceFeesTask(month time.Time) (Task, error) {
+	month = util.StartOfMonth(month)
+
+	task, err := NewTask(TaskChargeMaintenanceFees, PayloadChargeMaintenanceFees{Month: month})
+	task.Key = TaskChargeMaintenanceFees + ":" + month.Format("2006-01")
+
+	return task, err
+}
+
+// ChargeMaintenanceFees charges the month's maintenance fees. Accounts that
+// cannot be charged fail the task, and the retry charges only them: accounts
+// already charged for the month are skipped.
+func (h *TaskHandlers) ChargeMaintenanceFees(ctx context.Context, p PayloadChargeMaintenanceFees) error {
+	result, err := fees.ChargeMaintenance(ctx, h.store, p.Month)
+	if err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Maintenance fees charged",
+		slog.String("month", result.Month.Format("2006-01")),
+		slog.Int("charged", result.Charged),
+		slog.Int("skipped", result.Skipped),
+		slog.Int("failed", len(result.Failures)))
+
+	if len(result.Failures) > 0 {
+		first := result.Failures[0]
+		return fmt.Errorf("cannot charge maintenance fees to %d accounts, first account %d: %s",
+			len(result.Failures), first.AccountID, first.Error)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestChargeMaintenanceFees(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	month := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	task, err := NewChargeMaintenanceFeesTask(month.AddDate(0, 0, 20))
+	require.NoError(t, err)
+	require.Equal(t, TaskChargeMaintenanceFees, task.Type)
+	require.Equal(t, "charge_maintenance_fees:2026-09", task.Key)
+
+	gomock.InOrder(
+		store.EXPECT().
+			ListMaintenanceFeeAccounts(gomock.Any(), gomock.Eq(pgtype.Timestamptz{Time: month.AddDate(0, 1, 0), Valid: true})).
+			Return([]int64{1, 2}, nil),
+		store.EXPECT().
+			ChargeMaintenanceFeeTx(gomock.Any(), gomock.Eq(db.ChargeMaintenanceFeeTxParams{AccountID: 1, Month: month})).
+			Return(db.Fee{ID: 1}, nil),
+		store.EXPECT().
+			ChargeMaintenanceFeeTx(gomock.Any(), gomock.Eq(db.ChargeMaintenanceFeeTxParams{AccountID: 2, Month: month})).
+			Return(db.Fee{}, db.ErrAccountFrozen),
+		// The retry skips account 1, which was charged.
+		store.EXPECT().
+			ListMaintenanceFeeAccounts(gomock.Any(), gomock.Any()).
+			Return([]int64{1, 2}, nil),
+		store.EXPECT().
+			ChargeMaintenanceFeeTx(gomock.Any(), gomock.Eq(db.ChargeMaintenanceFeeTxParams{AccountID: 1, Month: month})).
+			Return(db.Fee{}, db.ErrFeeAlreadyCharged),
+		store.EXPECT().
+			ChargeMaintenanceFeeTx(gomock.Any(), gomock.Eq(db.ChargeMaintenanceFeeTxParams{AccountID: 2, Month: month})).
+			Return(db.Fee{ID: 2}, nil),
+	)
+
+	handlers := NewTaskHandlers(&util.Config{}, store, nil).Handlers()
+
+	err = handlers[TaskChargeMaintenanceFees](context.Background(), task.Payload)
+	require.ErrorContains(t, err, "first account 2")
+
+	require.NoError(t, handlers[TaskChargeMaintenanceFees](context.Background(), task.Payload))
+}
@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/mail"
+	"github.com/shevgn/simplebank/token"
+)
+
+// TaskSendPasswordReset mails a password reset link to the user with an
+// email address.
+const TaskSendPasswordReset = "send_password_reset"
+
+// PayloadSendPasswordReset is the payload of TaskSendPasswordReset.
+type PayloadSendPasswordReset struct {
+	Email string `json:"email"`
+}
+
+// NewSendPasswordResetTask creates a TaskSendPasswordReset for email.
+func NewSendPasswordResetTask(email string) (Task, error) {
+	return NewTask(TaskSendPasswordReset, PayloadSendPasswordReset{Email: email})
+}
+
+// SendPasswordReset creates a reset token for the user with the email and
+// mails it. Reset requests are queued for any email, so there is nothing to
+// do when no active user has it.
+func (h *TaskHandlers) SendPasswordReset(ctx context.Context, p PayloadSendPasswordReset) error {
+	user, err := h.store.GetUserByEmail(ctx, p.Email)
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot get user: %w", err)
+	}
+
+	if user.IsDisabled {
+		slog.InfoContext(ctx, "Password reset requested for disabled user", slog.String("username", user.Username))
+		return nil
+	}
+
+	resetToken, err := token.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	_, err = h.store.CreatePasswordResetToken(ctx, db.CreatePasswordResetTokenParams{
+		Username:  user.Username,
+		TokenHash: token.HashOpaqueToken(resetToken),
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(h.config.PasswordResetDuration), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("cannot save reset token: %w", err)
+	}
+
+	msg, err := mail.NewPasswordResetMessage(
+		user.Email, user.FullName, h.config.PasswordResetURL, resetToken, h.config.PasswordResetDuration)
+	if err != nil {
+		return err
+	}
+
+	return h.mailer.Send(ctx, msg)
+}
@@ -0,0 +1,98 @@
+package worker
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/mail"
+	mockmail "github.com/shevgn/simplebank/mail/mock"
+	"github.com/shevgn/simplebank/token"
+	"github.com/shevgn/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSendPasswordReset(t *testing.T) {
+	user := db.User{
+		Username: util.RandomOwner(),
+		FullName: util.RandomString(10),
+		Email:    util.RandomEmail(),
+	}
+
+	config := &util.Config{
+		PasswordResetURL:      "http://localhost:8080/reset_password",
+		PasswordResetDuration: time.Hour,
+	}
+
+	task, err := NewSendPasswordResetTask(user.Email)
+	require.NoError(t, err)
+	require.Equal(t, TaskSendPasswordReset, task.Type)
+
+	t.Run("OK", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		store := mockdb.NewMockStore(ctrl)
+		mailer := mockmail.NewMockSender(ctrl)
+
+		var tokenHash string
+
+		store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).Times(1).Return(user, nil)
+		store.EXPECT().
+			CreatePasswordResetToken(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, arg db.CreatePasswordResetTokenParams) (db.PasswordResetToken, error) {
+				require.Equal(t, user.Username, arg.Username)
+				require.WithinDuration(t, time.Now().Add(time.Hour), arg.ExpiresAt.Time, time.Minute)
+				tokenHash = arg.TokenHash
+
+				return db.PasswordResetToken{}, nil
+			})
+		mailer.EXPECT().
+			Send(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, msg mail.Message) error {
+				require.Equal(t, user.Email, msg.To)
+
+				// The mailed token is the one whose hash was stored.
+				link := msg.Body[strings.Index(msg.Body, config.PasswordResetURL):]
+				link = link[:strings.IndexByte(link, '\n')]
+				u, err := url.Parse(link)
+				require.NoError(t, err)
+				require.Equal(t, tokenHash, token.HashOpaqueToken(u.Query().Get("token")))
+
+				return nil
+			})
+
+		handlers := NewTaskHandlers(config, store, mailer).Handlers()
+		require.NoError(t, handlers[TaskSendPasswordReset](context.Background(), task.Payload))
+	})
+
+	t.Run("UnknownEmail", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		store := mockdb.NewMockStore(ctrl)
+
+		store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).Times(1).Return(db.User{}, db.ErrRecordNotFound)
+		store.EXPECT().CreatePasswordResetToken(gomock.Any(), gomock.Any()).Times(0)
+
+		handlers := NewTaskHandlers(config, store, nil).Handlers()
+		require.NoError(t, handlers[TaskSendPasswordReset](context.Background(), task.Payload))
+	})
+
+	t.Run("DisabledUser", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		store := mockdb.NewMockStore(ctrl)
+
+		disabled := user
+		disabled.IsDisabled = true
+
+		store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).Times(1).Return(disabled, nil)
+		store.EXPECT().CreatePasswordResetToken(gomock.Any(), gomock.Any()).Times(0)
+
+		handlers := NewTaskHandlers(config, store, nil).Handlers()
+		require.NoError(t, handlers[TaskSendPasswordReset](context.Background(), task.Payload))
+	})
+}
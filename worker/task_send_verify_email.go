@@ -0,0 +1,100 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/mail"
+	"github.com/shevgn/simplebank/token"
+	"github.com/shevgn/simplebank/util"
+	"github.com/shevgn/simplebank/webhook"
+)
+
+// TaskSendVerifyEmail mails a new user a link to verify their email.
+const TaskSendVerifyEmail = "send_verify_email"
+
+// PayloadSendVerifyEmail is the payload of TaskSendVerifyEmail.
+type PayloadSendVerifyEmail struct {
+	Username string `json:"username"`
+}
+
+// NewSendVerifyEmailTask creates a TaskSendVerifyEmail for username.
+func NewSendVerifyEmailTask(username string) (Task, error) {
+	return NewTask(TaskSendVerifyEmail, PayloadSendVerifyEmail{Username: username})
+}
+
+// TaskHandlers runs the tasks of the application.
+type TaskHandlers struct {
+	store    db.Store
+	mailer   mail.Sender
+	webhooks *webhook.Dispatcher
+	config   *util.Config
+}
+
+// NewTaskHandlers creates the task handlers.
+func NewTaskHandlers(config *util.Config, store db.Store, mailer mail.Sender) *TaskHandlers {
+	return &TaskHandlers{
+		store:    store,
+		mailer:   mailer,
+		webhooks: webhook.NewDispatcher(store, config.WebhookTimeout, webhook.NewGuard(config.WebhookNetworks())),
+		config:   config,
+	}
+}
+
+// Handlers returns a handler for every task type.
+func (h *TaskHandlers) Handlers() Handlers {
+	return Handlers{
+		TaskSendVerifyEmail:       Typed(h.SendVerifyEmail),
+		TaskSendPasswordReset:     Typed(h.SendPasswordReset),
+		TaskDeliverWebhook:        Typed(h.DeliverWebhook),
+		TaskAccrueInterest:        Typed(h.AccrueInterest),
+		TaskPostInterest:          Typed(h.PostInterest),
+		TaskChargeMaintenanceFees: Typed(h.ChargeMaintenanceFees),
+	}
+}
+
+// SendVerifyEmail creates a verification token for the user and mails it.
+// Users who are already verified are skipped; users who no longer exist fail
+// the task permanently.
+func (h *TaskHandlers) SendVerifyEmail(ctx context.Context, p PayloadSendVerifyEmail) error {
+	user, err := h.store.GetUser(ctx, p.Username)
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return Permanent(fmt.Errorf("user %q not found", p.Username))
+	}
+	if err != nil {
+		return fmt.Errorf("cannot get user: %w", err)
+	}
+
+	if user.IsEmailVerified {
+		slog.InfoContext(ctx, "Email already verified", slog.String("username", user.Username))
+		return nil
+	}
+
+	verifyToken, err := token.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	_, err = h.store.CreateVerifyEmail(ctx, db.CreateVerifyEmailParams{
+		Username:  user.Username,
+		Email:     user.Email,
+		TokenHash: token.HashOpaqueToken(verifyToken),
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(h.config.VerifyEmailDuration), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("cannot save verification token: %w", err)
+	}
+
+	msg, err := mail.NewVerifyEmailMessage(
+		user.Email, user.FullName, h.config.VerifyEmailURL, verifyToken, h.config.VerifyEmailDuration)
+	if err != nil {
+		return err
+	}
+
+	return h.mailer.Send(ctx, msg)
+}
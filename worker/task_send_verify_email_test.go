@@ -0,0 +1,106 @@
+package worker
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/mail"
+	mockmail "github.com/shevgn/simplebank/mail/mock"
+	"github.com/shevgn/simplebank/token"
+	"github.com/shevgn/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSendVerifyEmail(t *testing.T) {
+	user := db.User{
+		Username: util.RandomOwner(),
+		FullName: util.RandomString(10),
+		Email:    util.RandomEmail(),
+	}
+
+	config := &util.Config{
+		VerifyEmailURL:      "http://localhost:8080/users/verify_email",
+		VerifyEmailDuration: time.Hour,
+	}
+
+	task, err := NewSendVerifyEmailTask(user.Username)
+	require.NoError(t, err)
+	require.Equal(t, TaskSendVerifyEmail, task.Type)
+
+	t.Run("OK", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		store := mockdb.NewMockStore(ctrl)
+		mailer := mockmail.NewMockSender(ctrl)
+
+		var tokenHash string
+
+		store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+		store.EXPECT().
+			CreateVerifyEmail(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, arg db.CreateVerifyEmailParams) (db.VerifyEmail, error) {
+				require.Equal(t, user.Username, arg.Username)
+				require.Equal(t, user.Email, arg.Email)
+				tokenHash = arg.TokenHash
+
+				return db.VerifyEmail{}, nil
+			})
+		mailer.EXPECT().
+			Send(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, msg mail.Message) error {
+				require.Equal(t, user.Email, msg.To)
+
+				link := msg.Body[strings.Index(msg.Body, config.VerifyEmailURL):]
+				link = link[:strings.IndexByte(link, '\n')]
+				u, err := url.Parse(link)
+				require.NoError(t, err)
+				require.Equal(t, tokenHash, token.HashOpaqueToken(u.Query().Get("token")))
+
+				return nil
+			})
+
+		handlers := NewTaskHandlers(config, store, mailer).Handlers()
+		require.NoError(t, handlers[TaskSendVerifyEmail](context.Background(), task.Payload))
+	})
+
+	t.Run("AlreadyVerified", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		store := mockdb.NewMockStore(ctrl)
+		mailer := mockmail.NewMockSender(ctrl)
+
+		verified := user
+		verified.IsEmailVerified = true
+
+		store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(verified, nil)
+		store.EXPECT().CreateVerifyEmail(gomock.Any(), gomock.Any()).Times(0)
+		mailer.EXPECT().Send(gomock.Any(), gomock.Any()).Times(0)
+
+		handlers := NewTaskHandlers(config, store, mailer).Handlers()
+		require.NoError(t, handlers[TaskSendVerifyEmail](context.Background(), task.Payload))
+	})
+
+	t.Run("UserNotFound", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		store := mockdb.NewMockStore(ctrl)
+
+		store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.User{}, db.ErrRecordNotFound)
+		store.EXPECT().CreateVerifyEmail(gomock.Any(), gomock.Any()).Times(0)
+
+		handlers := NewTaskHandlers(config, store, nil).Handlers()
+		err := handlers[TaskSendVerifyEmail](context.Background(), task.Payload)
+		require.True(t, IsPermanent(err))
+	})
+
+	t.Run("InvalidPayload", func(t *testing.T) {
+		handlers := NewTaskHandlers(config, nil, nil).Handlers()
+		err := handlers[TaskSendVerifyEmail](context.Background(), []byte("{"))
+		require.True(t, IsPermanent(err))
+	})
+}
@@ -0,0 +1,107 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/outbox"
+	"github.com/shevgn/simplebank/webhook"
+)
+
+// WebhookFanout is an outbox.Sink that turns account activity into webhook
+// deliveries to the endpoints of the users involved, each sent by its own
+// TaskDeliverWebhook.
+type WebhookFanout struct {
+	store       db.Store
+	queue       *PostgresQueue
+	maxAttempts int
+}
+
+// NewWebhookFanout creates a fan-out that enqueues deliveries on queue, each
+// attempted up to maxAttempts times.
+func NewWebhookFanout(store db.Store, queue *PostgresQueue, maxAttempts int) *WebhookFanout {
+	return &WebhookFanout{
+		store:       store,
+		queue:       queue,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Publish implements outbox.Sink.
+func (f *WebhookFanout) Publish(ctx context.Context, event outbox.Event) error {
+	if !webhook.ValidEventType(event.Type) {
+		return nil
+	}
+
+	owners, err := f.owners(ctx, event)
+	if err != nil {
+		return err
+	}
+
+	endpoints, err := f.store.ListWebhookEndpointsForEvent(ctx, db.ListWebhookEndpointsForEventParams{
+		Owners:    owners,
+		EventType: event.Type,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot list webhook endpoints: %w", err)
+	}
+
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.store.EnqueueWebhookDeliveriesTx(ctx, db.EnqueueWebhookDeliveriesTxParams{
+		Endpoints: endpoints,
+		EventID:   event.ID,
+		EventType: event.Type,
+		Payload:   payload,
+		NewJob: func(delivery db.WebhookDelivery) (db.EnqueueJobParams, error) {
+			task, err := NewDeliverWebhookTask(delivery.ID, f.maxAttempts)
+			if err != nil {
+				return db.EnqueueJobParams{}, err
+			}
+
+			return f.queue.JobParams(task), nil
+		},
+	})
+
+	return err
+}
+
+// owners returns the users whose accounts the event concerns.
+func (f *WebhookFanout) owners(ctx context.Context, event outbox.Event) ([]string, error) {
+	var accountIDs []int64
+
+	switch event.AggregateType {
+	case db.AggregateAccount:
+		accountIDs = []int64{event.AggregateID}
+	case db.AggregateTransfer:
+		var payload db.TransferCreatedPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("cannot decode %s payload: %w", event.Type, err)
+		}
+
+		accountIDs = []int64{payload.FromAccountID, payload.ToAccountID}
+	default:
+		return nil, nil
+	}
+
+	owners := make([]string, 0, len(accountIDs))
+	for _, id := range accountIDs {
+		account, err := f.store.GetAccount(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get account %d: %w", id, err)
+		}
+
+		owners = append(owners, account.Owner)
+	}
+
+	return owners, nil
+}
@@ -0,0 +1,80 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	mockdb "github.com/shevgn/simplebank/db/mock"
+	db "github.com/shevgn/simplebank/db/sqlc"
+	"github.com/shevgn/simplebank/outbox"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestWebhookFanout(t *testing.T) {
+	payload, err := json.Marshal(db.TransferCreatedPayload{TransferID: 9, FromAccountID: 1, ToAccountID: 2, Amount: 10})
+	require.NoError(t, err)
+
+	event := outbox.Event{
+		ID:            77,
+		Type:          db.EventTransferCreated,
+		AggregateType: db.AggregateTransfer,
+		AggregateID:   9,
+		Payload:       payload,
+	}
+
+	endpoints := []db.WebhookEndpoint{{ID: 3, Owner: "alice"}, {ID: 4, Owner: "bob"}}
+
+	t.Run("OK", func(t *testing.T) {
+		store := mockdb.NewMockStore(gomock.NewController(t))
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(1))).Times(1).Return(db.Account{ID: 1, Owner: "alice"}, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(2))).Times(1).Return(db.Account{ID: 2, Owner: "bob"}, nil)
+		store.EXPECT().
+			ListWebhookEndpointsForEvent(gomock.Any(), gomock.Eq(db.ListWebhookEndpointsForEventParams{
+				Owners:    []string{"alice", "bob"},
+				EventType: db.EventTransferCreated,
+			})).
+			Times(1).
+			Return(endpoints, nil)
+		store.EXPECT().
+			EnqueueWebhookDeliveriesTx(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, arg db.EnqueueWebhookDeliveriesTxParams) ([]db.WebhookDelivery, error) {
+				require.Equal(t, endpoints, arg.Endpoints)
+				require.Equal(t, event.ID, arg.EventID)
+
+				var sent outbox.Event
+				require.NoError(t, json.Unmarshal(arg.Payload, &sent))
+				require.Equal(t, event.Type, sent.Type)
+
+				job, err := arg.NewJob(db.WebhookDelivery{ID: 12})
+				require.NoError(t, err)
+				require.Equal(t, TaskDeliverWebhook, job.Type)
+				require.Equal(t, int32(8), job.MaxAttempts)
+				require.JSONEq(t, `{"delivery_id":12}`, string(job.Payload))
+
+				return nil, nil
+			})
+
+		fanout := NewWebhookFanout(store, NewPostgresQueue(store, 5), 8)
+		require.NoError(t, fanout.Publish(context.Background(), event))
+	})
+
+	t.Run("NoEndpoints", func(t *testing.T) {
+		store := mockdb.NewMockStore(gomock.NewController(t))
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(2).Return(db.Account{Owner: "alice"}, nil)
+		store.EXPECT().ListWebhookEndpointsForEvent(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil)
+		store.EXPECT().EnqueueWebhookDeliveriesTx(gomock.Any(), gomock.Any()).Times(0)
+
+		fanout := NewWebhookFanout(store, NewPostgresQueue(store, 5), 8)
+		require.NoError(t, fanout.Publish(context.Background(), event))
+	})
+
+	t.Run("UnsubscribableEvent", func(t *testing.T) {
+		store := mockdb.NewMockStore(gomock.NewController(t))
+
+		fanout := NewWebhookFanout(store, NewPostgresQueue(store, 5), 8)
+		require.NoError(t, fanout.Publish(context.Background(), outbox.Event{Type: "UserCreated"}))
+	})
+}
@@ -0,0 +1,88 @@
+// Package worker runs background tasks, such as sending emails, outside of
+// request handling. Handlers enqueue a Task through a Distributor and a
+// Processor runs the registered HandlerFunc for its type.
+//
+// Tasks are stored in the jobs table, so they survive restarts and are
+// shared by all replicas. Failed tasks are retried with exponential backoff
+// until they run out of attempts and are marked dead.
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Task is a unit of background work. Payload is the JSON encoding of the
+// type-specific payload.
+type Task struct {
+	Type    string
+	Payload []byte
+	// RunAt delays the task until the given time; zero runs it right away.
+	RunAt time.Time
+	// MaxAttempts overrides the queue's default number of attempts.
+	MaxAttempts int
+	// Key, if set, names the run, such as the day a periodic task is for.
+	// A task is not enqueued while a job with the same key exists.
+	Key string
+}
+
+// NewTask creates a task of taskType with payload encoded as JSON.
+func NewTask[P any](taskType string, payload P) (Task, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Task{}, fmt.Errorf("cannot encode %s payload: %w", taskType, err)
+	}
+
+	return Task{Type: taskType, Payload: data}, nil
+}
+
+// HandlerFunc runs a task with the given payload.
+type HandlerFunc func(ctx context.Context, payload []byte) error
+
+// Handlers maps task types to the function that runs them.
+type Handlers map[string]HandlerFunc
+
+// Typed adapts a handler of decoded payloads to a HandlerFunc. Payloads
+// that cannot be decoded fail permanently, as retrying will not fix them.
+func Typed[P any](fn func(ctx context.Context, payload P) error) HandlerFunc {
+	return func(ctx context.Context, data []byte) error {
+		var payload P
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return Permanent(fmt.Errorf("cannot decode payload: %w", err))
+		}
+
+		return fn(ctx, payload)
+	}
+}
+
+// Distributor enqueues tasks for a processor to run.
+type Distributor interface {
+	Enqueue(ctx context.Context, task Task) error
+}
+
+// permanentError marks a task failure that retrying will not fix.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the task is marked dead instead of retried.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped with Permanent.
+func IsPermanent(err error) bool {
+	var perm *permanentError
+	return errors.As(err, &perm)
+}